@@ -0,0 +1,344 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const addressImportBatchSize = 1000
+
+// addressTableIndexes are built on the staging table once it is loaded and
+// renamed along with it. Keep them in step with the addresses migrations.
+var addressTableIndexes = []struct {
+	Name       string
+	Definition string
+}{
+	{Name: "addresses_lat_lng_idx", Definition: "(lat, lng)"},
+	{Name: "addresses_search_text_trgm_idx", Definition: "USING gin (search_text gin_trgm_ops)"},
+}
+
+// addressImportColumns maps the column or property names used by BAG and
+// OSM extracts onto address fields. Names are matched case-insensitively.
+var addressImportColumns = map[string][]string{
+	"street":       {"street", "straat", "openbareruimtenaam", "openbare_ruimte", "addr:street"},
+	"house_number": {"house_number", "housenumber", "huisnummer", "addr:housenumber"},
+	"letter":       {"huisletter"},
+	"addition":     {"huisnummertoevoeging", "toevoeging"},
+	"postcode":     {"postcode", "postal_code", "addr:postcode"},
+	"city":         {"city", "woonplaats", "woonplaatsnaam", "addr:city"},
+	"lat":          {"lat", "latitude"},
+	"lng":          {"lng", "lon", "longitude"},
+}
+
+// AddressImportResult counts the rows of an import-addresses run.
+type AddressImportResult struct {
+	Imported int
+	Skipped  int
+}
+
+// addressFromFields builds an address from named fields. It returns false
+// when the street, house number or coordinates are missing or invalid.
+func addressFromFields(lookup func(field string) string) (localAddress, bool) {
+	address := localAddress{
+		Street:      strings.TrimSpace(lookup("street")),
+		HouseNumber: strings.TrimSpace(lookup("house_number") + lookup("letter")),
+		Postcode:    normalizeImportPostcode(lookup("postcode")),
+		City:        strings.TrimSpace(lookup("city")),
+	}
+	if addition := strings.TrimSpace(lookup("addition")); addition != "" {
+		address.HouseNumber += "-" + addition
+	}
+	if address.Street == "" || address.HouseNumber == "" {
+		return localAddress{}, false
+	}
+
+	lat, latErr := strconv.ParseFloat(strings.TrimSpace(lookup("lat")), 64)
+	lng, lngErr := strconv.ParseFloat(strings.TrimSpace(lookup("lng")), 64)
+	if latErr != nil || lngErr != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return localAddress{}, false
+	}
+	address.Lat = lat
+	address.Lng = lng
+	return address, true
+}
+
+// normalizeImportPostcode writes Dutch postcodes as "1234 AB".
+func normalizeImportPostcode(raw string) string {
+	compact := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(raw), " ", ""))
+	if len(compact) == 6 {
+		return compact[:4] + " " + compact[4:]
+	}
+	return compact
+}
+
+// readAddressCSV reads a CSV extract with a header row. The separator is a
+// comma, or a semicolon when the header line has no commas, as in many BAG
+// exports.
+func readAddressCSV(r io.Reader, emit func(localAddress) error) (int, error) {
+	buffered := bufio.NewReader(r)
+	reader := csv.NewReader(buffered)
+	// Peek returns whatever is buffered when the file is shorter.
+	peeked, _ := buffered.Peek(4096)
+	headerLine, _, _ := strings.Cut(string(peeked), "\n")
+	if strings.Contains(headerLine, ";") && !strings.Contains(headerLine, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("read header: %w", err)
+	}
+	index := addressColumnIndex(header)
+	for _, required := range []string{"street", "house_number", "lat", "lng"} {
+		if _, ok := index[required]; !ok {
+			return 0, fmt.Errorf("missing %s column", required)
+		}
+	}
+
+	skipped := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return skipped, nil
+		}
+		if err != nil {
+			return skipped, err
+		}
+		address, ok := addressFromFields(func(field string) string {
+			if column, ok := index[field]; ok && column < len(record) {
+				return record[column]
+			}
+			return ""
+		})
+		if !ok {
+			skipped++
+			continue
+		}
+		if err := emit(address); err != nil {
+			return skipped, err
+		}
+	}
+}
+
+func addressColumnIndex(header []string) map[string]int {
+	index := make(map[string]int)
+	for column, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for field, aliases := range addressImportColumns {
+			if _, seen := index[field]; !seen && containsString(aliases, name) {
+				index[field] = column
+			}
+		}
+	}
+	return index
+}
+
+// readAddressGeoJSON streams the Point features of a FeatureCollection, so
+// extracts larger than memory can be imported.
+func readAddressGeoJSON(r io.Reader, emit func(localAddress) error) (int, error) {
+	decoder := json.NewDecoder(r)
+	if err := seekGeoJSONFeatures(decoder); err != nil {
+		return 0, err
+	}
+
+	skipped := 0
+	for decoder.More() {
+		var feature struct {
+			Geometry *struct {
+				Type        string    `json:"type"`
+				Coordinates []float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]any `json:"properties"`
+		}
+		if err := decoder.Decode(&feature); err != nil {
+			return skipped, err
+		}
+		if feature.Geometry == nil || feature.Geometry.Type != "Point" || len(feature.Geometry.Coordinates) < 2 {
+			skipped++
+			continue
+		}
+
+		properties := make(map[string]string, len(feature.Properties))
+		for key, value := range feature.Properties {
+			switch v := value.(type) {
+			case string:
+				properties[strings.ToLower(key)] = v
+			case float64:
+				properties[strings.ToLower(key)] = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		coordinates := feature.Geometry.Coordinates
+		address, ok := addressFromFields(func(field string) string {
+			switch field {
+			case "lat":
+				return strconv.FormatFloat(coordinates[1], 'f', -1, 64)
+			case "lng":
+				return strconv.FormatFloat(coordinates[0], 'f', -1, 64)
+			}
+			for _, alias := range addressImportColumns[field] {
+				if value, ok := properties[alias]; ok {
+					return value
+				}
+			}
+			return ""
+		})
+		if !ok {
+			skipped++
+			continue
+		}
+		if err := emit(address); err != nil {
+			return skipped, err
+		}
+	}
+	return skipped, nil
+}
+
+// seekGeoJSONFeatures advances the decoder into the "features" array.
+func seekGeoJSONFeatures(decoder *json.Decoder) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return errors.New("expected a GeoJSON FeatureCollection")
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if key, _ := token.(string); key == "features" {
+			token, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			if delim, ok := token.(json.Delim); !ok || delim != '[' {
+				return errors.New("features must be an array")
+			}
+			return nil
+		}
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return err
+		}
+	}
+	return errors.New("no features in GeoJSON")
+}
+
+// readAddressFile picks the reader by extension: .csv, or .geojson/.json.
+func readAddressFile(path string, emit func(localAddress) error) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return readAddressCSV(file, emit)
+	case ".geojson", ".json":
+		return readAddressGeoJSON(file, emit)
+	default:
+		return 0, fmt.Errorf("unsupported address file %q (expected .csv, .geojson or .json)", path)
+	}
+}
+
+// importAddresses loads path into a staging table and swaps it in for the
+// address table. Lookups keep reading the old extract while the file loads;
+// only the final rename takes an exclusive lock, and an error leaves the old
+// table untouched.
+func (a *App) importAddresses(ctx context.Context, path string) (AddressImportResult, error) {
+	var result AddressImportResult
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `CREATE TABLE addresses_staging (LIKE addresses INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`); err != nil {
+		return result, err
+	}
+
+	batch := make([]localAddress, 0, addressImportBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		placeholders := make([]string, 0, len(batch))
+		args := make([]any, 0, len(batch)*7)
+		for _, address := range batch {
+			n := len(args)
+			placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7))
+			args = append(args, address.Street, address.HouseNumber, address.Postcode, address.City, address.Lat, address.Lng, addressSearchText(address))
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO addresses_staging (street, house_number, postcode, city, lat, lng, search_text)
+			VALUES `+strings.Join(placeholders, ", "), args...); err != nil {
+			return err
+		}
+		result.Imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	skipped, err := readAddressFile(path, func(address localAddress) error {
+		batch = append(batch, address)
+		if len(batch) == addressImportBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	result.Skipped = skipped
+	if err != nil {
+		return result, err
+	}
+	if err := flush(); err != nil {
+		return result, err
+	}
+	if result.Imported == 0 {
+		return result, errors.New("no usable addresses in file")
+	}
+
+	for _, statement := range addressSwapStatements() {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return result, err
+		}
+	}
+	return result, tx.Commit()
+}
+
+// addressSwapStatements indexes the loaded staging table and renames it over
+// the address table. The id sequence moves to the staging table first, so
+// dropping the old table does not take it along.
+func addressSwapStatements() []string {
+	statements := []string{`ALTER TABLE addresses_staging ADD CONSTRAINT addresses_staging_pkey PRIMARY KEY (id)`}
+	for _, index := range addressTableIndexes {
+		statements = append(statements, fmt.Sprintf(`CREATE INDEX %s ON addresses_staging %s`, stagingAddressIndexName(index.Name), index.Definition))
+	}
+	statements = append(statements,
+		`ANALYZE addresses_staging`,
+		`ALTER SEQUENCE addresses_id_seq OWNED BY addresses_staging.id`,
+		`DROP TABLE addresses`,
+		`ALTER TABLE addresses_staging RENAME TO addresses`,
+		`ALTER TABLE addresses RENAME CONSTRAINT addresses_staging_pkey TO addresses_pkey`,
+	)
+	for _, index := range addressTableIndexes {
+		statements = append(statements, fmt.Sprintf(`ALTER INDEX %s RENAME TO %s`, stagingAddressIndexName(index.Name), index.Name))
+	}
+	return statements
+}
+
+func stagingAddressIndexName(name string) string {
+	return "addresses_staging_" + strings.TrimPrefix(name, "addresses_")
+}
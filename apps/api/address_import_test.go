@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func collectAddresses(t *testing.T, read func(emit func(localAddress) error) (int, error)) ([]localAddress, int) {
+	t.Helper()
+	addresses := make([]localAddress, 0)
+	skipped, err := read(func(address localAddress) error {
+		addresses = append(addresses, address)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("read addresses: %v", err)
+	}
+	return addresses, skipped
+}
+
+func TestReadAddressCSV(t *testing.T) {
+	bag := "\ufeffopenbareruimtenaam;huisnummer;huisletter;huisnummertoevoeging;postcode;woonplaatsnaam;lat;lon\n" +
+		"Oudegracht;1;;;3511AA;Utrecht;52.0927;5.1188\n" +
+		"Oudegracht;3;a;bis;3511AA;Utrecht;52.0926;5.1190\n" +
+		"Oudegracht;;;;3511AA;Utrecht;52.0926;5.1190\n"
+	addresses, skipped := collectAddresses(t, func(emit func(localAddress) error) (int, error) {
+		return readAddressCSV(strings.NewReader(bag), emit)
+	})
+	if len(addresses) != 2 || skipped != 1 {
+		t.Fatalf("expected two addresses and one skipped row, got %d and %d", len(addresses), skipped)
+	}
+	if addresses[1].HouseNumber != "3a-bis" || addresses[1].Postcode != "3511 AA" || addresses[1].Lng != 5.1190 {
+		t.Fatalf("unexpected BAG address: %#v", addresses[1])
+	}
+
+	osm := "addr:street,addr:housenumber,addr:postcode,addr:city,lat,lng\nDamrak,1,1012 LG,Amsterdam,52.3762,not-a-number\n"
+	addresses, skipped = collectAddresses(t, func(emit func(localAddress) error) (int, error) {
+		return readAddressCSV(strings.NewReader(osm), emit)
+	})
+	if len(addresses) != 0 || skipped != 1 {
+		t.Fatalf("expected invalid coordinates to be skipped, got %d and %d", len(addresses), skipped)
+	}
+
+	if _, err := readAddressCSV(strings.NewReader("street,city\nDamrak,Amsterdam\n"), func(localAddress) error { return nil }); err == nil {
+		t.Fatalf("expected a file without coordinates to be rejected")
+	}
+}
+
+func TestReadAddressGeoJSON(t *testing.T) {
+	geojson := `{"type":"FeatureCollection","name":"osm","features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[4.8963,52.3762]},"properties":{"addr:street":"Damrak","addr:housenumber":"1","addr:postcode":"1012LG","addr:city":"Amsterdam"}},
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[5.1188,52.0927]},"properties":{"openbareruimtenaam":"Oudegracht","huisnummer":1,"woonplaatsnaam":"Utrecht"}},
+		{"type":"Feature","geometry":{"type":"Polygon","coordinates":[]},"properties":{"addr:street":"Damrak","addr:housenumber":"2"}}
+	]}`
+	addresses, skipped := collectAddresses(t, func(emit func(localAddress) error) (int, error) {
+		return readAddressGeoJSON(strings.NewReader(geojson), emit)
+	})
+	if len(addresses) != 2 || skipped != 1 {
+		t.Fatalf("expected two point addresses and one skipped feature, got %d and %d", len(addresses), skipped)
+	}
+	if addresses[0].Lat != 52.3762 || addresses[0].Postcode != "1012 LG" {
+		t.Fatalf("unexpected OSM address: %#v", addresses[0])
+	}
+	if addresses[1].HouseNumber != "1" || addresses[1].City != "Utrecht" {
+		t.Fatalf("unexpected BAG address: %#v", addresses[1])
+	}
+}
+
+func TestAddressSwapStatementsKeepSequenceAndIndexNames(t *testing.T) {
+	statements := addressSwapStatements()
+	position := func(statement string) int {
+		for i, candidate := range statements {
+			if candidate == statement {
+				return i
+			}
+		}
+		t.Fatalf("missing statement %q in %#v", statement, statements)
+		return -1
+	}
+
+	if position(`ALTER SEQUENCE addresses_id_seq OWNED BY addresses_staging.id`) > position(`DROP TABLE addresses`) {
+		t.Fatalf("expected the id sequence to move before the old table is dropped")
+	}
+	rename := position(`ALTER TABLE addresses_staging RENAME TO addresses`)
+	for _, index := range addressTableIndexes {
+		if position(`ALTER INDEX addresses_staging_`+strings.TrimPrefix(index.Name, "addresses_")+` RENAME TO `+index.Name) < rename {
+			t.Fatalf("expected index %s to be renamed after the table swap", index.Name)
+		}
+	}
+}
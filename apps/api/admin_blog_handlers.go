@@ -1,8 +1,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -140,9 +144,18 @@ func (a *App) adminBlogMediaUploadHandler(c *gin.Context) {
 
 	ext := filepath.Ext(file.Filename)
 	newFilename := uuid.New().String() + ext
-	storagePath := filepath.Join(a.cfg.DataRoot, "blog", newFilename)
+	storagePath := path.Join("blog", newFilename)
 
-	if err := c.SaveUploadedFile(file, storagePath); err != nil {
+	var data []byte
+	opened, err := file.Open()
+	if err == nil {
+		data, err = io.ReadAll(opened)
+		_ = opened.Close()
+	}
+	if err == nil {
+		err = a.blobStore().Put(c.Request.Context(), storagePath, data, file.Header.Get("Content-Type"))
+	}
+	if err != nil {
 		a.log.Error("failed to save blog media", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
 		return
@@ -152,7 +165,8 @@ func (a *App) adminBlogMediaUploadHandler(c *gin.Context) {
 		Filename:    newFilename,
 		StoragePath: storagePath,
 		MimeType:    file.Header.Get("Content-Type"),
-		SizeBytes:   file.Size,
+		SizeBytes:   int64(len(data)),
+		SHA256:      sha256Hex(data),
 	}
 
 	if err := a.storeSaveBlogMedia(c.Request.Context(), m); err != nil {
@@ -175,5 +189,22 @@ func (a *App) blogMediaServeHandler(c *gin.Context) {
 		return
 	}
 
-	c.File(m.StoragePath)
+	data, err := a.blobStore().Get(c.Request.Context(), blogMediaStorageKey(m))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			a.log.Error("failed to read blog media", "filename", filename, "error", err)
+		}
+		c.String(http.StatusNotFound, "Media not found")
+		return
+	}
+	c.Data(http.StatusOK, valueOrDefaultString(m.MimeType, blobContentType(m.Filename)), data)
+}
+
+// blogMediaStorageKey returns the blob key of a media file. Rows written
+// before blob storage hold an absolute path under DATA_ROOT/blog instead.
+func blogMediaStorageKey(m *BlogMedia) string {
+	if filepath.IsAbs(m.StoragePath) {
+		return path.Join("blog", m.Filename)
+	}
+	return m.StoragePath
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	adminTemplateGeocoderPath = "templates/admin/geocoder.tmpl"
+	adminGeocoderPath         = "/bikeadmin/geocoder"
+)
+
+type adminGeocoderViewData struct {
+	adminBaseViewData
+	CacheEnabled bool
+	Stats        GeocodeCacheStats
+	HitRate      string
+	Providers    []adminGeocoderProviderRow
+	Lookups      []adminGeocodeLookupRow
+}
+
+type adminGeocoderProviderRow struct {
+	Name         string
+	Timeout      string
+	BreakerState string
+	BreakerOpen  bool
+	Success      uint64
+	NotFound     uint64
+	Errors       uint64
+	Timeouts     uint64
+	CircuitOpen  uint64
+}
+
+type adminGeocodeLookupRow struct {
+	At        string
+	Operation string
+	Provider  string
+	Outcome   string
+	Duration  string
+	Attempts  string
+}
+
+func buildAdminGeocoderStatusRows(lang string, status GeocoderChainStatus) ([]adminGeocoderProviderRow, []adminGeocodeLookupRow) {
+	providers := make([]adminGeocoderProviderRow, 0, len(status.Providers))
+	for _, provider := range status.Providers {
+		providers = append(providers, adminGeocoderProviderRow{
+			Name:         provider.Name,
+			Timeout:      provider.Timeout.String(),
+			BreakerState: adminText(lang, "geocoder_breaker_"+provider.BreakerState),
+			BreakerOpen:  provider.BreakerState == circuitStateOpen,
+			Success:      provider.Counts[geocodeOutcomeSuccess],
+			NotFound:     provider.Counts[geocodeOutcomeNotFound],
+			Errors:       provider.Counts[geocodeOutcomeError],
+			Timeouts:     provider.Counts[geocodeOutcomeTimeout],
+			CircuitOpen:  provider.Counts[geocodeOutcomeCircuitOpen],
+		})
+	}
+
+	lookups := make([]adminGeocodeLookupRow, 0, len(status.Recent))
+	for _, lookup := range status.Recent {
+		provider := lookup.Provider
+		if provider == "" {
+			provider = "—"
+		}
+		lookups = append(lookups, adminGeocodeLookupRow{
+			At:        formatAdminTimestamp(lookup.At.Format(time.RFC3339)),
+			Operation: adminText(lang, "geocoder_operation_"+lookup.Operation),
+			Provider:  provider,
+			Outcome:   adminText(lang, "geocoder_outcome_"+lookup.Outcome),
+			Duration:  lookup.Duration.Round(time.Millisecond).String(),
+			Attempts:  strings.Join(lookup.Attempts, ", "),
+		})
+	}
+	return providers, lookups
+}
+
+func (a *App) adminGeocoderPageHandler(c *gin.Context) {
+	lang := a.adminLanguageFromRequest(c)
+	base := a.adminBaseData(c, "page_title_geocoder", "geocoder")
+	data := adminGeocoderViewData{adminBaseViewData: base, CacheEnabled: a.adminGeocodeCacheStats != nil}
+
+	if data.CacheEnabled {
+		stats, err := a.adminGeocodeCacheStats(c.Request.Context())
+		if err != nil {
+			a.log.Error("failed to load geocode cache stats", "error", err)
+			data.ErrorMessage = adminText(lang, "error_geocode_cache_load_failed")
+			a.renderAdminTemplate(c, http.StatusInternalServerError, adminTemplateGeocoderPath, data)
+			return
+		}
+		data.Stats = stats
+		data.HitRate = fmt.Sprintf("%.1f%%", stats.HitRate())
+	}
+	if a.adminGeocoderStatus != nil {
+		data.Providers, data.Lookups = buildAdminGeocoderStatusRows(lang, a.adminGeocoderStatus())
+	}
+	a.renderAdminTemplate(c, http.StatusOK, adminTemplateGeocoderPath, data)
+}
+
+func (a *App) adminGeocodeCachePurgeSubmitHandler(c *gin.Context) {
+	lang := a.adminLanguageFromRequest(c)
+	if a.adminPurgeGeocodeCache == nil {
+		redirectAdminWithMessage(c, adminGeocoderPath, "error", adminText(lang, "geocode_cache_disabled"))
+		return
+	}
+
+	scope := strings.TrimSpace(c.PostForm("scope"))
+	purged, err := a.adminPurgeGeocodeCache(c.Request.Context(), scope)
+	if err != nil {
+		a.log.Error("failed to purge geocode cache", "error", err, "scope", scope)
+		redirectAdminWithMessage(c, adminGeocoderPath, "error", adminText(lang, "error_geocode_cache_purge_failed"))
+		return
+	}
+
+	a.log.Info("geocode cache purged", "scope", scope, "entries", purged)
+	redirectAdminWithMessage(c, adminGeocoderPath, "notice", fmt.Sprintf("%s %d", adminText(lang, "notice_geocode_cache_purged"), purged))
+}
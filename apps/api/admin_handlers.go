@@ -6,12 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"math"
 	"net/http"
 	"net/url"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 )
@@ -37,7 +39,9 @@ func (a *App) registerAdminRoutes(r *gin.Engine) {
 		admin.GET("/reports/:id", a.adminReportDetailsPageHandler)
 		admin.POST("/reports/:id/status", a.adminReportStatusSubmitHandler)
 		admin.POST("/reports/:id/merge", a.adminMergeSubmitHandler)
+		admin.POST("/reports/:id/photos", a.adminReportPhotosSubmitHandler)
 		admin.GET("/map", a.adminMapPageHandler)
+		admin.GET("/hotspots", a.adminHotspotsPageHandler)
 		admin.GET("/exports", a.adminExportsPageHandler)
 		admin.POST("/exports/generate", a.adminGenerateExportSubmitHandler)
 		admin.GET("/showcase/editor", a.requireRole("admin"), a.adminShowcaseEditorPageHandler)
@@ -46,8 +50,19 @@ func (a *App) registerAdminRoutes(r *gin.Engine) {
 		admin.GET("/content", a.requireRole("admin"), a.adminContentPageHandler)
 		admin.POST("/content", a.requireRole("admin"), a.adminContentSubmitHandler)
 
+		admin.GET("/settings/signal", a.requireRole("admin"), a.adminSignalSettingsPageHandler)
+		admin.POST("/settings/signal", a.requireRole("admin"), a.adminSignalSettingsSubmitHandler)
+		admin.POST("/settings/signal/:id/delete", a.requireRole("admin"), a.adminSignalSettingsDeleteSubmitHandler)
+		admin.GET("/geocoder", a.requireRole("admin"), a.adminGeocoderPageHandler)
+		admin.POST("/geocoder/purge", a.requireRole("admin"), a.adminGeocodeCachePurgeSubmitHandler)
+		admin.GET("/privacy", a.requireRole("admin"), a.adminPrivacyPageHandler)
+
 		admin.GET("/reports/:id/edit", a.requireRole("admin"), a.adminReportEditPageHandler)
 		admin.POST("/reports/:id/edit", a.requireRole("admin"), a.adminReportEditSubmitHandler)
+		admin.GET("/reports/:id/photos/:photoID/redact", a.requireRole("admin"), a.adminPhotoRedactPageHandler)
+		admin.POST("/reports/:id/photos/:photoID/redact", a.requireRole("admin"), a.adminPhotoRedactSubmitHandler)
+		admin.GET("/reports/:id/redactions/:redactionID/original", a.requireRole("admin"), a.adminRedactionOriginalHandler)
+		admin.POST("/reports/:id/legal-hold", a.requireRole("admin"), a.adminLegalHoldSubmitHandler)
 
 		admin.GET("/operators", a.requireRole("admin"), a.adminOperatorsPageHandler)
 		admin.GET("/operators/new", a.requireRole("admin"), a.adminOperatorCreatePageHandler)
@@ -187,8 +202,19 @@ func (a *App) adminShowcaseEditorPageHandler(c *gin.Context) {
 	}
 
 	newPhotoURL := ""
+	afterPhotos := make([]OperatorReportPhotoView, 0)
 	if reportID > 0 && photoID > 0 {
 		newPhotoURL = a.buildOperatorReportPhotoURL(reportID, photoID)
+		photos, err := a.listReportPhotos(c.Request.Context(), reportID)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to load report photos")
+			return
+		}
+		for _, view := range a.toOperatorReportPhotoViews(reportID, photos) {
+			if view.Kind == photoKindAfter && view.ID != photoID {
+				afterPhotos = append(afterPhotos, view)
+			}
+		}
 	}
 
 	for i := range paddedItems {
@@ -196,6 +222,10 @@ func (a *App) adminShowcaseEditorPageHandler(c *gin.Context) {
 			// Instead of needing report_id for all existing showcase items, we can just use the public endpoint we just created
 			paddedItems[i].StoragePath = fmt.Sprintf("/api/v1/showcase/%d/photo", paddedItems[i].Slot)
 		}
+		if paddedItems[i].AfterStoragePath != nil {
+			afterURL := fmt.Sprintf("/api/v1/showcase/%d/photo/after", paddedItems[i].Slot)
+			paddedItems[i].AfterStoragePath = &afterURL
+		}
 	}
 
 	showcaseJSON, _ := json.Marshal(paddedItems)
@@ -208,6 +238,7 @@ func (a *App) adminShowcaseEditorPageHandler(c *gin.Context) {
 		PhotoURL:          newPhotoURL,
 		ShowcaseItems:     paddedItems,
 		ShowcaseJSON:      string(showcaseJSON),
+		AfterPhotos:       afterPhotos,
 	}
 
 	a.renderAdminTemplate(c, http.StatusOK, "templates/admin/showcase_editor.tmpl", data)
@@ -254,7 +285,22 @@ func (a *App) adminShowcaseEditorSubmitHandler(c *gin.Context) {
 		scalePercent = 100
 	}
 
-	if err := a.storeUpsertShowcaseItem(c.Request.Context(), slot, photoID, focalX, focalY, scalePercent, subtitle); err != nil {
+	var afterPhotoID *int
+	if raw := strings.TrimSpace(c.PostForm("after_photo_id")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			redirectAdminWithMessage(c, next, "error", adminText(lang, "error_showcase_update_failed"))
+			return
+		}
+		isAfter, err := a.storeIsAfterPhotoOf(c.Request.Context(), photoID, parsed)
+		if err != nil || !isAfter {
+			redirectAdminWithMessage(c, next, "error", adminText(lang, "error_showcase_after_photo"))
+			return
+		}
+		afterPhotoID = &parsed
+	}
+
+	if err := a.storeUpsertShowcaseItem(c.Request.Context(), slot, photoID, focalX, focalY, scalePercent, subtitle, afterPhotoID); err != nil {
 		a.log.Error("failed to update showcase item", "error", err)
 		redirectAdminWithMessage(c, next, "error", adminText(lang, "error_showcase_update_failed"))
 		return
@@ -341,6 +387,8 @@ func (a *App) adminTriagePageHandler(c *gin.Context) {
 			City:                 valueOrDash(report.City),
 			SignalLabel:          adminSignalLabel(lang, report.SignalStrength),
 			SignalClass:          adminSignalClass(report.SignalStrength),
+			SignalScore:          formatSignalScore(report.SignalScore),
+			PriorityScore:        formatSignalScore(report.PriorityScore),
 			UniqueReporters:      report.SignalSummary.UniqueReporters,
 			LastReconfirmationAt: lastQual,
 			CreatedAt:            formatAdminTimestamp(report.CreatedAt),
@@ -423,12 +471,45 @@ func (a *App) adminReportDetailsPageHandler(c *gin.Context) {
 		})
 	}
 
+	thumbURLs := make(map[int]string, len(details.Photos))
+	for _, photo := range details.Photos {
+		thumbURLs[photo.ID] = photo.ThumbURL
+	}
 	events := make([]adminEventRowView, 0, len(details.Events))
 	for _, event := range details.Events {
-		events = append(events, adminEventRowView{
+		row := adminEventRowView{
 			TypeLabel: adminEventLabel(lang, event.Type),
 			Actor:     event.Actor,
 			CreatedAt: formatAdminTimestamp(event.CreatedAt),
+		}
+		if event.Type == "photo_added" {
+			if kind, ok := event.Metadata["kind"].(string); ok {
+				row.TypeLabel += ": " + adminText(lang, "photo_kind_"+kind)
+			}
+			if photoID, ok := event.Metadata["photo_id"].(float64); ok {
+				row.PhotoURL = thumbURLs[int(photoID)]
+			}
+		}
+		if event.Type == "retention_purged" {
+			if category, ok := event.Metadata["category"].(string); ok {
+				row.TypeLabel += ": " + adminText(lang, "retention_category_"+category)
+			}
+		}
+		events = append(events, row)
+	}
+
+	recurring := make([]adminRecurringGroupRowView, 0, len(details.SignalDetails.RecurringHistory))
+	for _, group := range details.SignalDetails.RecurringHistory {
+		closedAt := adminText(lang, "common_dash")
+		if group.ClosedAt != nil {
+			closedAt = formatAdminTimestamp(*group.ClosedAt)
+		}
+		recurring = append(recurring, adminRecurringGroupRowView{
+			BikeGroupID:  group.ID,
+			CreatedAt:    formatAdminTimestamp(group.CreatedAt),
+			ClosedAt:     closedAt,
+			TotalReports: group.TotalReports,
+			SignalLabel:  adminSignalLabel(lang, group.SignalStrength),
 		})
 	}
 
@@ -440,6 +521,10 @@ func (a *App) adminReportDetailsPageHandler(c *gin.Context) {
 	}
 
 	detailSelf := fmt.Sprintf("/bikeadmin/reports/%d?next=%s", details.Report.ID, url.QueryEscape(next))
+	location := fmt.Sprintf("%.6f, %.6f", details.Report.Location.Lat, details.Report.Location.Lng)
+	if details.Report.LocationSource == locationSourceAddressSearch {
+		location += " (" + adminText(lang, "report_location_source_address_search") + ")"
+	}
 	base := a.adminBaseData(c, "page_title_report", "triage")
 	base.IncludeMapLibre = true
 	data := adminReportDetailViewData{
@@ -450,7 +535,7 @@ func (a *App) adminReportDetailsPageHandler(c *gin.Context) {
 		ActionNext:          detailSelf,
 		StatusLabel:         adminStatusLabel(lang, details.Report.Status),
 		StatusActions:       buildAdminStatusActions(lang, details.Report.Status, detailSelf),
-		Location:            fmt.Sprintf("%.6f, %.6f", details.Report.Location.Lat, details.Report.Location.Lng),
+		Location:            location,
 		Lat:                 details.Report.Location.Lat,
 		Lng:                 details.Report.Location.Lng,
 		TagsLabel:           strings.Join(tags, ", "),
@@ -459,6 +544,7 @@ func (a *App) adminReportDetailsPageHandler(c *gin.Context) {
 		MergeInput:          "",
 		SignalStrengthLabel: adminSignalLabel(lang, details.SignalDetails.SignalStrength),
 		BikeGroupID:         details.SignalDetails.BikeGroup.ID,
+		BikeGroupStateLabel: adminBikeGroupStateLabel(lang, details.SignalDetails.BikeGroup.State),
 		SignalSummary: adminSignalSummaryView{
 			TotalReports:                    details.SignalDetails.SignalSummary.TotalReports,
 			UniqueReporters:                 details.SignalDetails.SignalSummary.UniqueReporters,
@@ -467,12 +553,21 @@ func (a *App) adminReportDetailsPageHandler(c *gin.Context) {
 			FirstQualifying:                 firstQual,
 			LastQualifying:                  lastQual,
 		},
-		Timeline:     timeline,
-		Events:       events,
-		Address:      valueOrDash(details.Report.Address),
-		City:         valueOrDash(details.Report.City),
-		Municipality: valueOrDash(details.Report.Municipality),
-		IsAdmin:      isAdmin,
+		SignalScore:      buildAdminSignalScoreView(details.SignalDetails.SignalScore),
+		RecurringHistory: recurring,
+		Timeline:         timeline,
+		Events:           events,
+		Address:          valueOrDash(details.Report.Address),
+		City:             valueOrDash(details.Report.City),
+		Municipality:     valueOrDash(details.Report.Municipality),
+		IsAdmin:          isAdmin,
+	}
+	if details.LegalHold != nil {
+		data.LegalHold = &adminLegalHoldView{
+			Reason: details.LegalHold.Reason,
+			SetBy:  details.LegalHold.SetBy,
+			SetAt:  formatAdminTimestamp(details.LegalHold.SetAt),
+		}
 	}
 	a.renderAdminTemplate(c, http.StatusOK, adminTemplateReportPath, data)
 }
@@ -521,10 +616,65 @@ func (a *App) adminReportEditPageHandler(c *gin.Context) {
 		Lat:               details.Report.Location.Lat,
 		Lng:               details.Report.Location.Lng,
 		Municipalities:    municipalityList(),
+		SearchQuery:       strings.TrimSpace(c.Query("q")),
+	}
+
+	// A picked search result comes back as query parameters and replaces the
+	// stored location in the form; nothing is saved until the form is posted.
+	lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+	lng, lngErr := strconv.ParseFloat(c.Query("lng"), 64)
+	if latErr == nil && lngErr == nil {
+		data.Lat = lat
+		data.Lng = lng
+		data.Address = strings.TrimSpace(c.Query("address"))
+		data.Postcode = strings.TrimSpace(c.Query("postcode"))
+	}
+
+	if data.SearchQuery != "" {
+		results, err := a.adminSearchAddresses(c.Request.Context(), data.SearchQuery)
+		if err != nil {
+			a.log.Error("admin address search failed", "report_id", reportID, "err", err)
+			data.ErrorMessage = adminText(lang, "error_address_search_failed")
+		}
+		for _, result := range results {
+			values := url.Values{}
+			values.Set("lat", strconv.FormatFloat(result.Lat, 'f', -1, 64))
+			values.Set("lng", strconv.FormatFloat(result.Lng, 'f', -1, 64))
+			values.Set("address", result.Address)
+			values.Set("postcode", result.PostalCode)
+			values.Set("next", next)
+			data.SearchResults = append(data.SearchResults, adminAddressSearchResult{
+				Label:  result.Label,
+				UseURL: fmt.Sprintf("/bikeadmin/reports/%d/edit?%s", reportID, values.Encode()),
+			})
+		}
 	}
 	a.renderAdminTemplate(c, http.StatusOK, "templates/admin/report_edit.tmpl", data)
 }
 
+// adminSearchAddresses runs the same forward geocoder as the citizen address
+// search, limited to the service area, without the per-IP rate limit.
+func (a *App) adminSearchAddresses(ctx context.Context, query string) ([]GeocodeSearchResult, error) {
+	if a.geocoder == nil {
+		return nil, errors.New("no geocoder configured")
+	}
+	if length := utf8.RuneCountInString(query); length < minGeocodeSearchQueryLength || length > maxGeocodeSearchQueryLength {
+		return nil, nil
+	}
+	results, err := a.geocoder.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]GeocodeSearchResult, 0, len(results))
+	for _, result := range results {
+		if a.serviceArea != nil && !a.serviceArea.Contains(result.Lat, result.Lng) {
+			continue
+		}
+		filtered = append(filtered, result)
+	}
+	return filtered, nil
+}
+
 func (a *App) adminReportEditSubmitHandler(c *gin.Context) {
 	lang := a.adminLanguageFromRequest(c)
 	reportID, err := strconv.Atoi(c.Param("id"))
@@ -545,7 +695,7 @@ func (a *App) adminReportEditSubmitHandler(c *gin.Context) {
 
 	editURL := fmt.Sprintf("/bikeadmin/reports/%d/edit?next=%s", reportID, url.QueryEscape(next))
 
-	if municipality != "" && !isValidMunicipality(municipality) {
+	if municipality != "" && !isValidMunicipality(municipality, time.Now().UTC()) {
 		redirectAdminWithMessage(c, editURL, "error", adminText(lang, "error_invalid_municipality"))
 		return
 	}
@@ -561,8 +711,28 @@ func (a *App) adminReportEditSubmitHandler(c *gin.Context) {
 		return
 	}
 
-	if err := a.updateReportDetails(c.Request.Context(), reportID, municipality, address, postcode, lat, lng); err != nil {
-		redirectAdminWithMessage(c, editURL, "error", adminText(lang, "error_report_update_failed"))
+	session, err := getOperatorSession(c)
+	if err != nil {
+		c.Redirect(http.StatusSeeOther, "/bikeadmin/login")
+		return
+	}
+	edit := ReportEdit{
+		Municipality: municipality,
+		Address:      address,
+		Postcode:     postcode,
+		Lat:          lat,
+		Lng:          lng,
+		Regeocode:    c.PostForm("regeocode") == "1",
+		Rematch:      c.PostForm("rematch") == "1",
+	}
+	if _, err := a.adminApplyReportEdit(c.Request.Context(), reportID, edit, session); err != nil {
+		a.log.Error("failed to edit report", "id", reportID, "err", err)
+		message := adminText(lang, "error_report_update_failed")
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && apiErr.Code == "geocoder_unavailable" {
+			message = adminText(lang, "error_report_regeocode_failed")
+		}
+		redirectAdminWithMessage(c, editURL, "error", message)
 		return
 	}
 
@@ -679,7 +849,7 @@ func (a *App) adminMapPageHandler(c *gin.Context) {
 	if err := applySessionMunicipalityScope(filters, session); err != nil {
 		base := a.adminBaseData(c, "page_title_map", "map")
 		base.ErrorMessage = "Access restricted: invalid operator scope"
-		a.renderAdminTemplate(c, http.StatusForbidden, adminTemplateMapPath, adminMapViewData{adminBaseViewData: base, MapData: template.JS("[]")})
+		a.renderAdminTemplate(c, http.StatusForbidden, adminTemplateMapPath, adminMapViewData{adminBaseViewData: base, MapData: template.JS("[]"), HotspotData: template.JS("[]")})
 		return
 	}
 
@@ -687,7 +857,7 @@ func (a *App) adminMapPageHandler(c *gin.Context) {
 	if err != nil {
 		base := a.adminBaseData(c, "page_title_map", "map")
 		base.ErrorMessage = adminText(lang, "error_reports_load_failed")
-		a.renderAdminTemplate(c, http.StatusInternalServerError, adminTemplateMapPath, adminMapViewData{adminBaseViewData: base, MapData: template.JS("[]")})
+		a.renderAdminTemplate(c, http.StatusInternalServerError, adminTemplateMapPath, adminMapViewData{adminBaseViewData: base, MapData: template.JS("[]"), HotspotData: template.JS("[]")})
 		return
 	}
 
@@ -713,15 +883,28 @@ func (a *App) adminMapPageHandler(c *gin.Context) {
 	if marshalErr != nil {
 		base := a.adminBaseData(c, "page_title_map", "map")
 		base.ErrorMessage = adminText(lang, "error_reports_load_failed")
-		a.renderAdminTemplate(c, http.StatusInternalServerError, adminTemplateMapPath, adminMapViewData{adminBaseViewData: base, MapData: template.JS("[]")})
+		a.renderAdminTemplate(c, http.StatusInternalServerError, adminTemplateMapPath, adminMapViewData{adminBaseViewData: base, MapData: template.JS("[]"), HotspotData: template.JS("[]")})
 		return
 	}
 
+	hotspotData := template.JS("[]")
+	scope, _ := filters["city"].(string)
+	var hotspotScope *string
+	if scope != "" {
+		hotspotScope = &scope
+	}
+	if hotspots, err := a.adminListHotspots(c.Request.Context(), hotspotScope); err != nil {
+		a.log.Error("failed to load hotspots for map", "error", err)
+	} else if encodedHotspots, err := json.Marshal(buildAdminMapHotspots(lang, hotspots)); err == nil {
+		hotspotData = template.JS(string(encodedHotspots))
+	}
+
 	base := a.adminBaseData(c, "page_title_map", "map")
 	base.IncludeMapLibre = true
 	data := adminMapViewData{
 		adminBaseViewData: base,
 		MapData:           template.JS(string(encoded)),
+		HotspotData:       hotspotData,
 	}
 	a.renderAdminTemplate(c, http.StatusOK, adminTemplateMapPath, data)
 }
@@ -969,6 +1152,31 @@ func adminSignalLabel(lang, signal string) string {
 	}
 }
 
+func adminBikeGroupStateLabel(lang, state string) string {
+	if state == "" {
+		state = bikeGroupStateOpen
+	}
+	return adminText(lang, "bike_group_state_"+state)
+}
+
+func formatSignalScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', 1, 64)
+}
+
+func buildAdminSignalScoreView(score SignalScore) adminSignalScoreView {
+	return adminSignalScoreView{
+		Score:              formatSignalScore(score.Score),
+		BaseScore:          formatSignalScore(score.BaseScore),
+		DistinctPoints:     formatSignalScore(score.DistinctReconfirmationPoints),
+		SamePoints:         formatSignalScore(score.SameReconfirmationPoints),
+		ReporterPoints:     formatSignalScore(score.ReporterPoints),
+		PersistencePoints:  formatSignalScore(score.PersistencePoints),
+		SeverityMultiplier: "× " + strconv.FormatFloat(score.SeverityMultiplier, 'f', 2, 64),
+		DecayFactor:        "× " + strconv.FormatFloat(score.DecayFactor, 'f', 3, 64),
+		AgeDays:            strconv.FormatFloat(score.AgeDays, 'f', 0, 64),
+	}
+}
+
 func adminEventLabel(lang, eventType string) string {
 	return adminText(lang, "event_"+eventType)
 }
@@ -1177,11 +1385,15 @@ func parseAdminReportFilters(c *gin.Context) adminReportFilters {
 		SignalStrength:              strings.TrimSpace(c.Query("signal_strength")),
 		HasQualifyingReconfirmation: strings.TrimSpace(c.Query("has_qualifying_reconfirmation")),
 		StrongOnly:                  strings.TrimSpace(c.Query("strong_only")),
+		MinPriority:                 strings.TrimSpace(c.Query("min_priority")),
 		Sort:                        strings.TrimSpace(c.Query("sort")),
 	}
-	if filters.Sort != "signal" {
+	if filters.Sort != "signal" && filters.Sort != "priority" {
 		filters.Sort = "newest"
 	}
+	if value, err := strconv.ParseFloat(filters.MinPriority, 64); err != nil || math.IsNaN(value) || value <= 0 || value > 100 {
+		filters.MinPriority = ""
+	}
 	return filters
 }
 
@@ -1208,6 +1420,9 @@ func (f adminReportFilters) toStoreFilters() map[string]any {
 	if f.StrongOnly == "false" {
 		filters["strong_only"] = false
 	}
+	if value, err := strconv.ParseFloat(f.MinPriority, 64); err == nil {
+		filters["min_priority"] = value
+	}
 	filters["sort"] = f.Sort
 	return filters
 }
@@ -1229,6 +1444,9 @@ func (f adminReportFilters) queryString() string {
 	if f.StrongOnly != "" {
 		params.Set("strong_only", f.StrongOnly)
 	}
+	if f.MinPriority != "" {
+		params.Set("min_priority", f.MinPriority)
+	}
 	params.Set("sort", f.Sort)
 	return params.Encode()
 }
@@ -1248,6 +1466,7 @@ func (f adminReportFilters) toView() adminReportFiltersView {
 		SignalStrength:              f.SignalStrength,
 		HasQualifyingReconfirmation: f.HasQualifyingReconfirmation,
 		StrongOnly:                  f.StrongOnly,
+		MinPriority:                 f.MinPriority,
 		Sort:                        f.Sort,
 		CurrentURL:                  f.currentURL(),
 	}
@@ -29,6 +29,9 @@ func newAdminTestServer(t *testing.T) (*App, *gin.Engine) {
 	app.adminListReportCities = func(ctx context.Context, municipality *string) ([]string, error) {
 		return []string{}, nil
 	}
+	app.adminListHotspots = func(ctx context.Context, municipality *string) ([]Hotspot, error) {
+		return []Hotspot{}, nil
+	}
 
 	router := gin.New()
 	app.registerAdminRoutes(router)
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const adminTemplateHotspotsPath = "templates/admin/hotspots.tmpl"
+
+type adminHotspotRowView struct {
+	ID             int
+	Municipality   string
+	Location       string
+	RadiusMeters   float64
+	ReportCount    int
+	BikeGroupCount int
+	RecurrenceRate float64
+	MedianHours    string
+	FirstReportAt  string
+	LastReportAt   string
+}
+
+type adminHotspotsViewData struct {
+	adminBaseViewData
+	Hotspots   []adminHotspotRowView
+	ComputedAt string
+}
+
+type adminMapHotspot struct {
+	ID             int     `json:"id"`
+	Municipality   string  `json:"municipality"`
+	Lat            float64 `json:"lat"`
+	Lng            float64 `json:"lng"`
+	RadiusMeters   float64 `json:"radiusM"`
+	ReportCount    int     `json:"reportCount"`
+	BikeGroupCount int     `json:"bikeGroupCount"`
+	RecurrenceRate float64 `json:"recurrenceRate"`
+	Label          string  `json:"label"`
+}
+
+// sessionHotspotScope returns the municipality a session may see hotspots
+// for; nil means all municipalities (admins).
+func sessionHotspotScope(session OperatorSession) (*string, error) {
+	filters := map[string]any{}
+	if err := applySessionMunicipalityScope(filters, session); err != nil {
+		return nil, err
+	}
+	if municipality, ok := filters["city"].(string); ok {
+		return &municipality, nil
+	}
+	return nil, nil
+}
+
+func (a *App) adminHotspotsPageHandler(c *gin.Context) {
+	lang := a.adminLanguageFromRequest(c)
+	session, _ := getOperatorSession(c)
+	base := a.adminBaseData(c, "page_title_hotspots", "hotspots")
+
+	scope, err := sessionHotspotScope(session)
+	if err != nil {
+		base.ErrorMessage = "Access restricted: invalid operator scope"
+		a.renderAdminTemplate(c, http.StatusForbidden, adminTemplateHotspotsPath, adminHotspotsViewData{adminBaseViewData: base})
+		return
+	}
+
+	hotspots, err := a.adminListHotspots(c.Request.Context(), scope)
+	if err != nil {
+		a.log.Error("failed to load hotspots", "error", err)
+		base.ErrorMessage = adminText(lang, "error_hotspots_load_failed")
+		a.renderAdminTemplate(c, http.StatusInternalServerError, adminTemplateHotspotsPath, adminHotspotsViewData{adminBaseViewData: base})
+		return
+	}
+
+	data := adminHotspotsViewData{
+		adminBaseViewData: base,
+		Hotspots:          make([]adminHotspotRowView, 0, len(hotspots)),
+		ComputedAt:        adminText(lang, "common_dash"),
+	}
+	for _, hotspot := range hotspots {
+		median := adminText(lang, "common_dash")
+		if hotspot.MedianResolutionHours != nil {
+			median = formatResolutionHours(*hotspot.MedianResolutionHours)
+		}
+		data.Hotspots = append(data.Hotspots, adminHotspotRowView{
+			ID:             hotspot.ID,
+			Municipality:   hotspot.Municipality,
+			Location:       fmt.Sprintf("%.5f, %.5f", hotspot.CenterLat, hotspot.CenterLng),
+			RadiusMeters:   hotspot.RadiusMeters,
+			ReportCount:    hotspot.ReportCount,
+			BikeGroupCount: hotspot.BikeGroupCount,
+			RecurrenceRate: hotspot.RecurrenceRate,
+			MedianHours:    median,
+			FirstReportAt:  formatAdminTimestamp(hotspot.FirstReportAt),
+			LastReportAt:   formatAdminTimestamp(hotspot.LastReportAt),
+		})
+		data.ComputedAt = formatAdminTimestamp(hotspot.ComputedAt)
+	}
+	a.renderAdminTemplate(c, http.StatusOK, adminTemplateHotspotsPath, data)
+}
+
+func buildAdminMapHotspots(lang string, hotspots []Hotspot) []adminMapHotspot {
+	points := make([]adminMapHotspot, 0, len(hotspots))
+	for _, hotspot := range hotspots {
+		points = append(points, adminMapHotspot{
+			ID:             hotspot.ID,
+			Municipality:   hotspot.Municipality,
+			Lat:            hotspot.CenterLat,
+			Lng:            hotspot.CenterLng,
+			RadiusMeters:   hotspot.RadiusMeters,
+			ReportCount:    hotspot.ReportCount,
+			BikeGroupCount: hotspot.BikeGroupCount,
+			RecurrenceRate: hotspot.RecurrenceRate,
+			Label:          fmt.Sprintf("%s: %d, %s: %d", adminText(lang, "hotspot_col_reports"), hotspot.ReportCount, adminText(lang, "hotspot_col_bike_groups"), hotspot.BikeGroupCount),
+		})
+	}
+	return points
+}
+
+func formatResolutionHours(hours float64) string {
+	if hours >= 48 {
+		return fmt.Sprintf("%.1f d", hours/24)
+	}
+	return fmt.Sprintf("%.1f h", hours)
+}
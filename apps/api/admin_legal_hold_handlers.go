@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (a *App) adminSetLegalHold(ctx context.Context, reportID int, held bool, reason string, session OperatorSession) error {
+	if a.adminSetReportLegalHold != nil {
+		return a.adminSetReportLegalHold(ctx, reportID, held, reason, session)
+	}
+	return a.setLegalHold(ctx, reportID, held, reason, session)
+}
+
+// adminLegalHoldSubmitHandler places or releases the legal hold that keeps a
+// report out of purge-retention. Only admins reach it.
+func (a *App) adminLegalHoldSubmitHandler(c *gin.Context) {
+	session, err := getOperatorSession(c)
+	if err != nil {
+		c.Redirect(http.StatusSeeOther, "/bikeadmin/login")
+		return
+	}
+	lang := a.adminLanguageFromRequest(c)
+	reportID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	detailURL := fmt.Sprintf("/bikeadmin/reports/%d", reportID)
+
+	held := c.PostForm("action") == "set"
+	if err := a.adminSetLegalHold(c.Request.Context(), reportID, held, c.PostForm("reason"), session); err != nil {
+		a.log.Error("failed to change legal hold", "id", reportID, "held", held, "err", err)
+		redirectAdminWithMessage(c, detailURL, "error", normalizeAdminErrorMessage(err, lang, "error_legal_hold_failed"))
+		return
+	}
+	notice := "notice_legal_hold_release"
+	if held {
+		notice = "notice_legal_hold_set"
+	}
+	redirectAdminWithMessage(c, detailURL, "notice", adminText(lang, notice))
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const adminTemplatePhotoRedactPath = "templates/admin/photo_redact.tmpl"
+
+type adminPhotoRedactViewData struct {
+	adminBaseViewData
+	ReportID      int
+	PublicID      string
+	PhotoID       int
+	PhotoURL      string
+	BackURL       string
+	RetentionDays int
+	Redactions    []adminPhotoRedactionRow
+}
+
+type adminPhotoRedactionRow struct {
+	Version     int
+	Mode        string
+	RegionCount int
+	CreatedBy   string
+	CreatedAt   string
+	OriginalURL string
+	RetainUntil string
+}
+
+func (a *App) adminListPhotoRedactionRecords(ctx context.Context, photoID int) ([]PhotoRedactionRecord, error) {
+	if a.adminListPhotoRedactions != nil {
+		return a.adminListPhotoRedactions(ctx, photoID)
+	}
+	return a.listPhotoRedactions(ctx, photoID)
+}
+
+func (a *App) adminPhotoRedactPageHandler(c *gin.Context) {
+	lang := a.adminLanguageFromRequest(c)
+	reportID, errReport := strconv.Atoi(c.Param("id"))
+	photoID, errPhoto := strconv.Atoi(c.Param("photoID"))
+	if errReport != nil || errPhoto != nil {
+		c.String(http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	backURL := fmt.Sprintf("/bikeadmin/reports/%d", reportID)
+	base := a.adminBaseData(c, "page_title_photo_redact", "triage")
+	data := adminPhotoRedactViewData{
+		adminBaseViewData: base,
+		ReportID:          reportID,
+		PhotoID:           photoID,
+		BackURL:           backURL,
+		RetentionDays:     int(a.cfg.PhotoRedactionRetention / (24 * time.Hour)),
+	}
+
+	details, err := a.adminLoadReportDetails(c.Request.Context(), reportID)
+	if err != nil {
+		a.log.Error("failed to load report for redaction", "id", reportID, "err", err)
+		data.ErrorMessage = adminText(lang, "error_report_load_failed")
+		a.renderAdminTemplate(c, http.StatusInternalServerError, adminTemplatePhotoRedactPath, data)
+		return
+	}
+	data.PublicID = details.Report.PublicID
+	for _, photo := range details.Photos {
+		if photo.ID == photoID {
+			data.PhotoURL = photo.URL
+		}
+	}
+	if data.PhotoURL == "" {
+		data.ErrorMessage = adminText(lang, "photo_missing")
+		a.renderAdminTemplate(c, http.StatusNotFound, adminTemplatePhotoRedactPath, data)
+		return
+	}
+
+	records, err := a.adminListPhotoRedactionRecords(c.Request.Context(), photoID)
+	if err != nil {
+		a.log.Error("failed to list photo redactions", "photo_id", photoID, "err", err)
+		data.ErrorMessage = adminText(lang, "error_report_load_failed")
+	}
+	now := time.Now().UTC()
+	for _, record := range records {
+		row := adminPhotoRedactionRow{
+			Version:     record.Version,
+			Mode:        adminText(lang, "photo_redact_mode_"+record.Mode),
+			RegionCount: record.RegionCount,
+			CreatedBy:   record.CreatedBy,
+			CreatedAt:   formatAdminTimestamp(record.CreatedAt.Format(time.RFC3339)),
+		}
+		if record.PreviousStoragePath != "" && record.RetainUntil != nil && record.RetainUntil.After(now) {
+			row.OriginalURL = fmt.Sprintf("/bikeadmin/reports/%d/redactions/%d/original", reportID, record.ID)
+			row.RetainUntil = formatAdminTimestamp(record.RetainUntil.Format(time.RFC3339))
+		}
+		data.Redactions = append(data.Redactions, row)
+	}
+	a.renderAdminTemplate(c, http.StatusOK, adminTemplatePhotoRedactPath, data)
+}
+
+func (a *App) adminPhotoRedactSubmitHandler(c *gin.Context) {
+	lang := a.adminLanguageFromRequest(c)
+	reportID, errReport := strconv.Atoi(c.Param("id"))
+	photoID, errPhoto := strconv.Atoi(c.Param("photoID"))
+	if errReport != nil || errPhoto != nil {
+		c.String(http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	redactURL := fmt.Sprintf("/bikeadmin/reports/%d/photos/%d/redact", reportID, photoID)
+
+	mode := strings.TrimSpace(c.PostForm("mode"))
+	regions, err := parsePhotoRedactionRegions(c.PostForm("regions"))
+	if err != nil || !isValidPhotoRedactionMode(mode) {
+		redirectAdminWithMessage(c, redactURL, "error", adminText(lang, "error_photo_redact_invalid"))
+		return
+	}
+
+	session, err := getOperatorSession(c)
+	if err != nil {
+		c.Redirect(http.StatusSeeOther, "/bikeadmin/login")
+		return
+	}
+	if err := a.adminApplyPhotoRedaction(c.Request.Context(), reportID, photoID, PhotoRedaction{Mode: mode, Regions: regions}, session); err != nil {
+		a.log.Error("failed to redact photo", "id", reportID, "photo_id", photoID, "err", err)
+		message := adminText(lang, "error_photo_redact_failed")
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			switch apiErr.Code {
+			case "photo_processing_busy":
+				message = apiErr.Message
+			case "photo_redaction_conflict":
+				message = adminText(lang, "error_photo_redact_stale")
+			}
+		}
+		redirectAdminWithMessage(c, redactURL, "error", message)
+		return
+	}
+
+	redirectAdminWithMessage(c, fmt.Sprintf("/bikeadmin/reports/%d", reportID), "notice", adminText(lang, "notice_photo_redacted"))
+}
+
+// adminRedactionOriginalHandler serves a photo version replaced by a
+// redaction while it is retained. Only admins reach it; it is never cached.
+func (a *App) adminRedactionOriginalHandler(c *gin.Context) {
+	reportID, errReport := strconv.Atoi(c.Param("id"))
+	redactionID, errRedaction := strconv.Atoi(c.Param("redactionID"))
+	if errReport != nil || errRedaction != nil {
+		c.String(http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	storagePath, err := a.getRetainedRedactionOriginal(c.Request.Context(), reportID, redactionID)
+	if err != nil {
+		a.log.Error("failed to load redaction original", "redaction_id", redactionID, "err", err)
+		c.String(http.StatusInternalServerError, "Photo could not be loaded")
+		return
+	}
+	if storagePath == "" {
+		c.String(http.StatusNotFound, "Photo not found")
+		return
+	}
+	if _, err := a.blobStore().Stat(c.Request.Context(), storagePath); err != nil {
+		a.log.Error("redaction original missing", "redaction_id", redactionID, "path", storagePath, "err", err)
+		c.String(http.StatusNotFound, "Photo not found")
+		return
+	}
+
+	contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(storagePath)))
+	a.writeProtectedPhoto(c, storagePath, contentType, filepath.Base(storagePath), "private, no-store")
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (a *App) adminAddPhotos(ctx context.Context, reportID int, kind string, photos []PhotoUpload, session OperatorSession) error {
+	if a.adminAddReportPhotos != nil {
+		return a.adminAddReportPhotos(ctx, reportID, kind, photos, session)
+	}
+	_, err := a.addReportPhotos(ctx, reportID, kind, photos, session)
+	return err
+}
+
+// adminReportPhotosSubmitHandler adds operator photos (field checks, after
+// removal) to a report from the report detail page.
+func (a *App) adminReportPhotosSubmitHandler(c *gin.Context) {
+	session, err := getOperatorSession(c)
+	if err != nil {
+		c.Redirect(http.StatusSeeOther, "/bikeadmin/login")
+		return
+	}
+	lang := a.adminLanguageFromRequest(c)
+	reportID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	detailURL := fmt.Sprintf("/bikeadmin/reports/%d", reportID)
+
+	if err := a.ensureReportStatusScope(c.Request.Context(), session, reportID); err != nil {
+		redirectAdminWithMessage(c, detailURL, "error", normalizeAdminErrorMessage(err, lang, "error_photo_add_failed"))
+		return
+	}
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		redirectAdminWithMessage(c, detailURL, "error", adminText(lang, "error_photo_add_failed"))
+		return
+	}
+	photos, err := readMultipartPhotos(c.Request.MultipartForm.File["photos"])
+	if err != nil {
+		redirectAdminWithMessage(c, detailURL, "error", normalizeAdminErrorMessage(err, lang, "error_photo_add_failed"))
+		return
+	}
+
+	if err := a.adminAddPhotos(c.Request.Context(), reportID, strings.TrimSpace(c.PostForm("kind")), photos, session); err != nil {
+		a.log.Error("failed to add report photos", "id", reportID, "err", err)
+		redirectAdminWithMessage(c, detailURL, "error", normalizeAdminErrorMessage(err, lang, "error_photo_add_failed"))
+		return
+	}
+	redirectAdminWithMessage(c, detailURL, "notice", adminText(lang, "notice_photos_added"))
+}
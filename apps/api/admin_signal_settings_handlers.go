@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	adminTemplateSignalSettingsPath = "templates/admin/signal_settings.tmpl"
+	adminSignalSettingsPath         = "/bikeadmin/settings/signal"
+)
+
+type adminSignalParamsFormView struct {
+	Text   map[string]string
+	Params SignalParams
+}
+
+type adminSignalSettingsViewData struct {
+	adminBaseViewData
+	Global         SignalParams
+	GlobalForm     adminSignalParamsFormView
+	Overrides      []adminSignalParamsFormView
+	Municipalities []string
+}
+
+func (a *App) adminSignalSettingsPageHandler(c *gin.Context) {
+	lang := a.adminLanguageFromRequest(c)
+	base := a.adminBaseData(c, "page_title_signal_settings", "signal_settings")
+
+	list, err := a.adminListSignalParams(c.Request.Context())
+	if err != nil {
+		a.log.Error("failed to load signal settings", "error", err)
+		base.ErrorMessage = adminText(lang, "error_signal_settings_load_failed")
+		a.renderAdminTemplate(c, http.StatusInternalServerError, adminTemplateSignalSettingsPath, adminSignalSettingsViewData{
+			adminBaseViewData: base,
+			Global:            defaultSignalParams(),
+			GlobalForm:        adminSignalParamsFormView{Text: base.Text, Params: defaultSignalParams()},
+			Municipalities:    municipalityList(),
+		})
+		return
+	}
+
+	data := adminSignalSettingsViewData{
+		adminBaseViewData: base,
+		Global:            defaultSignalParams(),
+		Overrides:         make([]adminSignalParamsFormView, 0, len(list)),
+		Municipalities:    municipalityList(),
+	}
+	for _, params := range list {
+		if params.Municipality == nil {
+			data.Global = params
+			continue
+		}
+		data.Overrides = append(data.Overrides, adminSignalParamsFormView{Text: base.Text, Params: params})
+	}
+	data.GlobalForm = adminSignalParamsFormView{Text: base.Text, Params: data.Global}
+	a.renderAdminTemplate(c, http.StatusOK, adminTemplateSignalSettingsPath, data)
+}
+
+func (a *App) adminSignalSettingsSubmitHandler(c *gin.Context) {
+	lang := a.adminLanguageFromRequest(c)
+	session, err := getOperatorSession(c)
+	if err != nil {
+		c.Redirect(http.StatusSeeOther, "/bikeadmin/login")
+		return
+	}
+
+	params, err := parseSignalParamsForm(c)
+	if err == nil {
+		err = validateSignalParams(params)
+	}
+	if err != nil {
+		redirectAdminWithMessage(c, adminSignalSettingsPath, "error", adminText(lang, "error_signal_settings_invalid")+" "+err.Error())
+		return
+	}
+
+	if err := a.adminSaveSignalParams(c.Request.Context(), params, session.Email); err != nil {
+		a.log.Error("failed to save signal settings", "error", err, "scope", params.Scope())
+		redirectAdminWithMessage(c, adminSignalSettingsPath, "error", adminText(lang, "error_signal_settings_save_failed"))
+		return
+	}
+
+	redirectAdminWithMessage(c, adminSignalSettingsPath, "notice", adminText(lang, "notice_signal_settings_saved"))
+}
+
+func (a *App) adminSignalSettingsDeleteSubmitHandler(c *gin.Context) {
+	lang := a.adminLanguageFromRequest(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		redirectAdminWithMessage(c, adminSignalSettingsPath, "error", adminText(lang, "error_signal_settings_save_failed"))
+		return
+	}
+
+	if err := a.adminDeleteSignalParams(c.Request.Context(), id); err != nil {
+		a.log.Error("failed to delete signal settings override", "error", err, "id", id)
+		redirectAdminWithMessage(c, adminSignalSettingsPath, "error", adminText(lang, "error_signal_settings_save_failed"))
+		return
+	}
+
+	redirectAdminWithMessage(c, adminSignalSettingsPath, "notice", adminText(lang, "notice_signal_settings_deleted"))
+}
+
+func parseSignalParamsForm(c *gin.Context) (SignalParams, error) {
+	params := SignalParams{}
+	if municipality := strings.TrimSpace(c.PostForm("municipality")); municipality != "" {
+		params.Municipality = &municipality
+	}
+
+	floatFields := []struct {
+		name   string
+		target *float64
+	}{
+		{"signal_match_radius_m", &params.SignalMatchRadiusMeters},
+		{"dedupe_radius_m", &params.DedupeRadiusMeters},
+		{"distance_weight", &params.DistanceWeight},
+		{"tag_overlap_weight", &params.TagOverlapWeight},
+		{"recency_weight", &params.RecencyWeight},
+		{"photo_similarity_weight", &params.PhotoSimilarityWeight},
+	}
+	for _, field := range floatFields {
+		value, err := strconv.ParseFloat(strings.TrimSpace(c.PostForm(field.name)), 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return SignalParams{}, fmt.Errorf("invalid number for %s", field.name)
+		}
+		*field.target = value
+	}
+
+	intFields := []struct {
+		name   string
+		target *int
+	}{
+		{"signal_lookback_days", &params.SignalLookbackDays},
+		{"signal_reconfirmation_gap_days", &params.SignalReconfirmationGapDays},
+		{"strong_signal_min_reporters", &params.StrongSignalMinReporters},
+		{"dedupe_lookback_days", &params.DedupeLookbackDays},
+	}
+	for _, field := range intFields {
+		value, err := strconv.Atoi(strings.TrimSpace(c.PostForm(field.name)))
+		if err != nil {
+			return SignalParams{}, fmt.Errorf("invalid number for %s", field.name)
+		}
+		*field.target = value
+	}
+
+	return params, nil
+}
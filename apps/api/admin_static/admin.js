@@ -2,6 +2,8 @@
   const DEFAULT_CENTER = [5.2913, 52.1326]; // Center of Netherlands
   const DEFAULT_ZOOM = 7; // Show whole country by default
   const CLUSTER_RADIUS = 45;
+  const METERS_PER_PIXEL_AT_Z22 = 0.0373; // Web Mercator at the equator
+  const MIN_HOTSPOT_RADIUS_M = 15;
 
   const OSM_RASTER_STYLE = {
     version: 8,
@@ -41,6 +43,28 @@
         }
       }));
 
+    const rawHotspots = Array.isArray(window.__ADMIN_HOTSPOT_DATA__) ? window.__ADMIN_HOTSPOT_DATA__ : [];
+    const hotspotFeatures = rawHotspots
+      .filter((entry) => typeof entry.lat === 'number' && typeof entry.lng === 'number')
+      .map((entry) => {
+        const radiusM = Math.max(entry.radiusM || 0, MIN_HOTSPOT_RADIUS_M);
+        const metersPerPixel = METERS_PER_PIXEL_AT_Z22 * Math.cos((entry.lat * Math.PI) / 180);
+        return {
+          type: 'Feature',
+          geometry: {
+            type: 'Point',
+            coordinates: [entry.lng, entry.lat]
+          },
+          properties: {
+            id: entry.id,
+            municipality: entry.municipality,
+            label: entry.label,
+            recurrenceRate: entry.recurrenceRate,
+            radiusPxZ22: radiusM / metersPerPixel
+          }
+        };
+      });
+
     const map = new window.maplibregl.Map({
       container: mapElement,
       style: OSM_RASTER_STYLE,
@@ -57,6 +81,40 @@
     }
 
     map.on('load', () => {
+      map.addSource('hotspots', {
+        type: 'geojson',
+        data: {
+          type: 'FeatureCollection',
+          features: hotspotFeatures
+        }
+      });
+
+      map.addLayer({
+        id: 'hotspot-areas',
+        type: 'circle',
+        source: 'hotspots',
+        paint: {
+          'circle-color': '#f0ad4e',
+          'circle-opacity': 0.35,
+          'circle-stroke-width': 2,
+          'circle-stroke-color': '#c77c0e',
+          'circle-radius': ['interpolate', ['exponential', 2], ['zoom'], 0, 0, 22, ['get', 'radiusPxZ22']]
+        }
+      });
+
+      map.on('click', 'hotspot-areas', (e) => {
+        const feature = e.features[0];
+        const { id, municipality, label, recurrenceRate } = feature.properties;
+        const html = `
+          <div class="map-popup">
+            <strong><a href="/bikeadmin/hotspots#hotspot-${id}">Hotspot ${municipality}</a></strong><br>
+            <span>${label}</span><br>
+            <small>${recurrenceRate} / 30d</small>
+          </div>
+        `;
+        new window.maplibregl.Popup().setLngLat(feature.geometry.coordinates.slice()).setHTML(html).addTo(map);
+      });
+
       map.addSource('reports', {
         type: 'geojson',
         data: {
@@ -254,8 +312,97 @@
     });
   };
 
+  const initPhotoRedaction = () => {
+    const editor = document.querySelector('[data-redaction-editor]');
+    const form = document.querySelector('[data-redaction-form]');
+    if (!editor || !form) {
+      return;
+    }
+
+    const input = form.querySelector('[data-redaction-regions]');
+    const submit = form.querySelector('[data-redaction-submit]');
+    const clear = form.querySelector('[data-redaction-clear]');
+    const regions = [];
+    let start = null;
+    let draft = null;
+
+    // Regions are stored as fractions of the photo so they do not depend on
+    // the size the photo is shown at.
+    const pointFor = (event) => {
+      const rect = editor.getBoundingClientRect();
+      return {
+        x: Math.min(Math.max((event.clientX - rect.left) / rect.width, 0), 1),
+        y: Math.min(Math.max((event.clientY - rect.top) / rect.height, 0), 1),
+      };
+    };
+
+    const place = (box, region) => {
+      box.style.left = `${region.x * 100}%`;
+      box.style.top = `${region.y * 100}%`;
+      box.style.width = `${region.w * 100}%`;
+      box.style.height = `${region.h * 100}%`;
+    };
+
+    const regionBetween = (from, to) => ({
+      x: Math.min(from.x, to.x),
+      y: Math.min(from.y, to.y),
+      w: Math.abs(to.x - from.x),
+      h: Math.abs(to.y - from.y),
+    });
+
+    const sync = () => {
+      input.value = JSON.stringify(regions);
+      if (submit) {
+        submit.disabled = regions.length === 0;
+      }
+    };
+
+    editor.addEventListener('pointerdown', (event) => {
+      event.preventDefault();
+      start = pointFor(event);
+      draft = document.createElement('div');
+      draft.className = 'redaction-box';
+      place(draft, regionBetween(start, start));
+      editor.appendChild(draft);
+      editor.setPointerCapture(event.pointerId);
+    });
+
+    editor.addEventListener('pointermove', (event) => {
+      if (!start || !draft) {
+        return;
+      }
+      place(draft, regionBetween(start, pointFor(event)));
+    });
+
+    editor.addEventListener('pointerup', (event) => {
+      if (!start || !draft) {
+        return;
+      }
+      const region = regionBetween(start, pointFor(event));
+      if (region.w < 0.01 || region.h < 0.01) {
+        draft.remove();
+      } else {
+        regions.push(region);
+      }
+      start = null;
+      draft = null;
+      sync();
+    });
+
+    if (clear) {
+      clear.addEventListener('click', () => {
+        regions.length = 0;
+        editor.querySelectorAll('.redaction-box').forEach((box) => box.remove());
+        sync();
+      });
+    }
+
+    sync();
+  };
+
   initMap();
   initDetailMap();
   initPhotoModal();
   initBulkActions();
+  initPhotoRedaction();
 })();
@@ -90,6 +90,7 @@ var (
 			"report_back":                            "Terug naar triage",
 			"report_title":                           "Melding",
 			"report_location":                        "Locatie",
+			"report_location_source_address_search":  "via adreszoeker",
 			"report_tags":                            "Tags",
 			"report_note":                            "Notitie",
 			"report_photos":                          "Foto's",
@@ -197,6 +198,15 @@ var (
 			"report_edit_save":               "Opslaan",
 			"notice_report_updated":          "Melding bijgewerkt.",
 			"error_report_update_failed":     "Melding bijwerken is mislukt.",
+			"report_edit_regeocode":          "Adres en gemeente opnieuw bepalen op basis van de locatie",
+			"report_edit_rematch":            "Fietsgroep opnieuw bepalen",
+			"report_edit_search":             "Adres zoeken",
+			"report_edit_search_submit":      "Zoeken",
+			"report_edit_search_use":         "Gebruiken",
+			"report_edit_search_empty":       "Geen adressen gevonden in het werkgebied.",
+			"error_address_search_failed":    "Adres zoeken is mislukt. Probeer het opnieuw of vul de locatie zelf in.",
+			"error_report_regeocode_failed":  "Adres opzoeken is mislukt. Probeer het opnieuw of vul het adres zelf in.",
+			"event_edited":                   "Bewerkt",
 			"action_disable_reports":         "Berichten uitschakelen",
 			"action_enable_reports":          "Berichten inschakelen",
 			"operator_receives_reports":      "Ontvangt meldingen",
@@ -242,6 +252,199 @@ var (
 			"error_blog_media_upload_failed": "Uploaden van media mislukt.",
 			"status_published":               "Gepubliceerd",
 			"status_draft":                   "Concept",
+
+			"nav_signal_settings":                  "Signaalinstellingen",
+			"page_title_signal_settings":           "Signaal- en ontdubbelingsinstellingen",
+			"signal_settings_hint":                 "Deze parameters bepalen hoe meldingen aan fietsgroepen worden gekoppeld, hoe herbevestigingen tellen en hoe mogelijke dubbele meldingen worden gescoord. Gemeentelijke instellingen gaan voor de algemene instellingen.",
+			"signal_settings_global":               "Algemene instellingen",
+			"signal_settings_override":             "Instellingen voor",
+			"signal_settings_add_override":         "Gemeentelijke instelling toevoegen",
+			"signal_settings_delete_override":      "Gemeentelijke instelling verwijderen",
+			"signal_settings_save":                 "Opslaan",
+			"signal_settings_updated_by":           "Laatst gewijzigd door",
+			"signal_settings_match_radius":         "Koppelstraal fietsgroep (m)",
+			"signal_settings_lookback_days":        "Terugkijkperiode signaal (dagen)",
+			"signal_settings_gap_days":             "Minimale tijd tussen herbevestigingen (dagen)",
+			"signal_settings_min_reporters":        "Minimaal aantal melders voor sterk signaal",
+			"signal_settings_dedupe_radius":        "Straal dubbele meldingen (m)",
+			"signal_settings_dedupe_lookback_days": "Terugkijkperiode dubbele meldingen (dagen)",
+			"signal_settings_distance_weight":      "Gewicht afstand",
+			"signal_settings_tag_overlap_weight":   "Gewicht kenmerkoverlap",
+			"signal_settings_recency_weight":       "Gewicht recentheid",
+			"signal_settings_photo_weight":         "Gewicht fotogelijkenis",
+			"notice_signal_settings_saved":         "Instellingen opgeslagen.",
+			"notice_signal_settings_deleted":       "Gemeentelijke instelling verwijderd.",
+			"error_signal_settings_load_failed":    "Laden van instellingen mislukt.",
+			"error_signal_settings_save_failed":    "Opslaan van instellingen mislukt.",
+			"error_signal_settings_invalid":        "Ongeldige instellingen:",
+
+			"bike_group_state_open":     "open",
+			"bike_group_state_closed":   "gesloten",
+			"report_recurring_title":    "Terugkerende locatie",
+			"report_recurring_hint":     "Op deze plek zijn eerder fietsgroepen gemeld en afgehandeld.",
+			"report_recurring_reports":  "meldingen",
+			"event_bike_group_closed":   "Fietsgroep gesloten",
+			"event_bike_group_reopened": "Fietsgroep heropend",
+
+			"event_municipality_reassigned":          "Gemeente heringedeeld",
+			"event_flagged_near_service_area_border": "Gemarkeerd: net buiten het werkgebied",
+			"event_flagged_photo_location_mismatch":  "Gemarkeerd: foto elders gemaakt",
+			"event_flagged_photo_capture_too_old":    "Gemarkeerd: foto te oud",
+			"event_flagged_photo_reused":             "Gemarkeerd: foto ook elders gebruikt",
+
+			"filter_min_priority":          "Minimale prioriteit",
+			"sort_priority":                "Prioriteit",
+			"col_priority":                 "Prioriteit",
+			"report_signal_score":          "Signaalscore",
+			"report_signal_score_hint":     "De score daalt naarmate de laatste melding langer geleden is. De signaalsterkte volgt uit drempels op deze score.",
+			"signal_score_distinct":        "Herbevestigingen door andere melders",
+			"signal_score_same":            "Herbevestigingen door dezelfde melder",
+			"signal_score_reporters":       "Extra unieke melders",
+			"signal_score_persistence":     "Tijd tussen eerste melding en laatste herbevestiging",
+			"signal_score_severity":        "Ernst van tags (vermenigvuldiger)",
+			"signal_score_base":            "Score bij laatste melding",
+			"signal_score_decay":           "Verval sinds laatste melding",
+			"signal_score_days_since_last": "dagen geleden",
+
+			"nav_hotspots":                  "Hotspots",
+			"page_title_hotspots":           "Hotspots",
+			"hotspots_hint":                 "Plekken waar steeds opnieuw zwerffietsen worden gemeld. Gebruik deze lijst om structurele maatregelen te plannen, zoals extra fietsparkeren of handhaving.",
+			"hotspots_computed_at":          "Laatst berekend:",
+			"hotspots_empty":                "Nog geen hotspots gevonden.",
+			"hotspot_col_location":          "Locatie",
+			"hotspot_col_radius":            "Straal",
+			"hotspot_col_reports":           "Meldingen",
+			"hotspot_col_bike_groups":       "Fietsen",
+			"hotspot_col_recurrence":        "Fietsen per 30 dagen",
+			"hotspot_col_median_resolution": "Mediane afhandeltijd",
+			"hotspot_col_period":            "Periode",
+			"error_hotspots_load_failed":    "Laden van hotspots mislukt.",
+
+			"nav_geocoder":                     "Geocoder",
+			"page_title_geocoder":              "Geocoder",
+			"geocode_cache_hint":               "Adressen bij meldingen worden opgezocht via een externe geocoder. Resultaten worden per locatie van ongeveer 10 meter bewaard, zodat herhaalde opvragingen en backfills de externe diensten niet belasten.",
+			"geocode_cache_title":              "Geocodecache",
+			"geocode_cache_disabled":           "De geocodecache staat uit (GEOCODE_CACHE_TTL_DAYS=0).",
+			"geocode_cache_hits":               "Treffers",
+			"geocode_cache_negative_hits":      "Treffers zonder adres",
+			"geocode_cache_misses":             "Missers",
+			"geocode_cache_hit_rate":           "Trefferpercentage",
+			"geocode_cache_errors":             "Cachefouten",
+			"geocode_cache_entries":            "Opgeslagen locaties",
+			"geocode_cache_negative_entries":   "Waarvan zonder adres",
+			"geocode_cache_expired_entries":    "Waarvan verlopen",
+			"geocode_cache_counters_hint":      "Treffers, missers en fouten tellen sinds de laatste herstart van de API.",
+			"geocode_cache_purge":              "Cache legen",
+			"geocode_cache_purge_expired":      "Verlopen locaties",
+			"geocode_cache_purge_misses":       "Locaties zonder adres",
+			"geocode_cache_purge_all":          "Alle locaties",
+			"notice_geocode_cache_purged":      "Locaties verwijderd uit de cache:",
+			"error_geocode_cache_load_failed":  "Laden van de geocodecache mislukt.",
+			"error_geocode_cache_purge_failed": "Legen van de geocodecache mislukt.",
+
+			"geocoder_providers_title":      "Providers",
+			"geocoder_provider":             "Provider",
+			"geocoder_timeout":              "Time-out",
+			"geocoder_breaker":              "Circuit breaker",
+			"geocoder_breaker_closed":       "Gesloten",
+			"geocoder_breaker_open":         "Open",
+			"geocoder_breaker_half_open":    "Proefaanroep",
+			"geocoder_outcome_success":      "Gevonden",
+			"geocoder_outcome_not_found":    "Niet gevonden",
+			"geocoder_outcome_error":        "Fout",
+			"geocoder_outcome_timeout":      "Time-out",
+			"geocoder_outcome_circuit_open": "Overgeslagen",
+			"geocoder_operation_reverse":    "Adres bij locatie",
+			"geocoder_operation_search":     "Adres zoeken",
+			"geocoder_recent_title":         "Recente opvragingen",
+			"geocoder_recent_empty":         "Nog geen opvragingen sinds de laatste herstart.",
+			"geocoder_lookup_at":            "Tijdstip",
+			"geocoder_lookup_operation":     "Soort",
+			"geocoder_lookup_served_by":     "Beantwoord door",
+			"geocoder_lookup_outcome":       "Resultaat",
+			"geocoder_lookup_duration":      "Duur",
+			"geocoder_lookup_attempts":      "Eerder geprobeerd",
+			"geocoder_counters_hint":        "Providers worden op volgorde geprobeerd. Na herhaalde fouten wordt een provider tijdelijk overgeslagen. Opvragingen die uit de cache komen staan hier niet bij.",
+
+			"page_title_photo_redact":    "Foto afschermen",
+			"report_redact_photo":        "Afschermen",
+			"photo_redact_back":          "Terug naar melding",
+			"photo_redact_hint":          "Sleep rechthoeken over gezichten, kentekens en andere persoonsgegevens. Alleen die delen van de foto worden bewerkt.",
+			"photo_redact_mode":          "Bewerking",
+			"photo_redact_mode_blur":     "Vervagen",
+			"photo_redact_mode_pixelate": "Pixeleren",
+			"photo_redact_retained":      "De vorige versie blijft alleen voor beheerders bewaard gedurende",
+			"photo_redact_days":          "dagen en wordt daarna verwijderd.",
+			"photo_redact_deleted":       "De vorige versie wordt direct verwijderd.",
+			"photo_redact_apply":         "Afschermen toepassen",
+			"photo_redact_clear":         "Selectie wissen",
+			"photo_redact_history":       "Eerdere bewerkingen",
+			"photo_redact_version":       "Versie",
+			"photo_redact_regions":       "Gebieden",
+			"photo_redact_by":            "Door",
+			"photo_redact_original":      "Vorige versie",
+			"photo_redact_view_original": "Bekijken",
+			"photo_redact_until":         "tot",
+			"photo_redact_original_gone": "Verwijderd",
+			"notice_photo_redacted":      "Foto afgeschermd.",
+			"error_photo_redact_invalid": "Selecteer minstens één gebied en een geldige bewerking.",
+			"error_photo_redact_failed":  "Afschermen van de foto is mislukt.",
+			"error_photo_redact_stale":   "De foto is intussen door iemand anders aangepast. Bekijk de nieuwe versie en probeer het opnieuw.",
+			"event_photo_redacted":       "Foto afgeschermd",
+
+			"photo_kind_citizen":         "Melder",
+			"photo_kind_field_check":     "Controle ter plaatse",
+			"photo_kind_after":           "Na verwijdering",
+			"photo_add_kind":             "Soort foto",
+			"photo_add_button":           "Foto's toevoegen",
+			"notice_photos_added":        "Foto's toegevoegd.",
+			"error_photo_add_failed":     "Foto's toevoegen is mislukt.",
+			"event_photo_added":          "Foto toegevoegd",
+			"showcase_after_photo":       "Foto na verwijdering",
+			"showcase_after_photo_hint":  "Kies een foto na verwijdering om als voor-en-na te tonen.",
+			"showcase_after_none":        "Geen",
+			"error_showcase_after_photo": "De foto na verwijdering hoort niet bij deze melding.",
+
+			"legal_hold_title":                  "Juridische bewaarplicht",
+			"legal_hold_active":                 "Deze melding valt onder een juridische bewaarplicht en wordt niet opgeschoond.",
+			"legal_hold_none":                   "Geen bewaarplicht. Foto's, e-mailadres en fingerprint worden volgens het bewaarbeleid opgeschoond.",
+			"legal_hold_reason":                 "Reden",
+			"legal_hold_set_by":                 "Ingesteld door",
+			"legal_hold_set_button":             "Bewaarplicht instellen",
+			"legal_hold_release_button":         "Bewaarplicht opheffen",
+			"notice_legal_hold_set":             "Bewaarplicht ingesteld.",
+			"notice_legal_hold_release":         "Bewaarplicht opgeheven.",
+			"error_legal_hold_failed":           "Bewaarplicht wijzigen is mislukt.",
+			"event_legal_hold_set":              "Bewaarplicht ingesteld",
+			"event_legal_hold_released":         "Bewaarplicht opgeheven",
+			"event_retention_purged":            "Opgeschoond volgens bewaarbeleid",
+			"retention_category_photos":         "foto's",
+			"retention_category_reporter_email": "e-mailadres melder",
+			"retention_category_fingerprint":    "fingerprint",
+
+			"nav_privacy":                     "Privacyverzoeken",
+			"page_title_privacy":              "Privacyverzoeken",
+			"privacy_hint":                    "Verwerkingslog van inzage- en verwijderverzoeken van melders. Het log bevat geen e-mailadressen; zoeken op e-mailadres vergelijkt de hash.",
+			"privacy_search_email":            "E-mailadres",
+			"privacy_search_button":           "Zoeken",
+			"privacy_empty":                   "Geen verzoeken gevonden.",
+			"privacy_col_at":                  "Tijdstip",
+			"privacy_col_user":                "Account",
+			"privacy_col_email_hash":          "E-mailhash",
+			"privacy_col_kind":                "Verzoek",
+			"privacy_col_mode":                "Keuze",
+			"privacy_col_detail":              "Resultaat",
+			"privacy_kind_export":             "Gegevens gedownload",
+			"privacy_kind_deletion_requested": "Verwijdering aangevraagd",
+			"privacy_kind_deletion_completed": "Account verwijderd",
+			"privacy_mode_anonymize":          "Meldingen anonimiseren",
+			"privacy_mode_delete":             "Meldingen verwijderen",
+			"privacy_detail_reports":          "meldingen",
+			"privacy_detail_deleted":          "verwijderd",
+			"privacy_detail_anonymized":       "geanonimiseerd",
+			"privacy_detail_held":             "onder bewaarplicht",
+			"error_privacy_log_load_failed":   "Privacyverzoeken laden is mislukt.",
+			"event_reporter_anonymized":       "Melder geanonimiseerd",
 		},
 		"en": {
 			"app_title":                              "ZwerfFiets Admin",
@@ -309,6 +512,7 @@ var (
 			"report_back":                            "Back to triage",
 			"report_title":                           "Report",
 			"report_location":                        "Location",
+			"report_location_source_address_search":  "from address search",
 			"report_tags":                            "Tags",
 			"report_note":                            "Note",
 			"report_photos":                          "Photos",
@@ -415,6 +619,15 @@ var (
 			"report_edit_save":               "Save",
 			"notice_report_updated":          "Report updated.",
 			"error_report_update_failed":     "Failed to update report.",
+			"report_edit_regeocode":          "Look up address and municipality for the new location",
+			"report_edit_rematch":            "Re-match bike group",
+			"report_edit_search":             "Search address",
+			"report_edit_search_submit":      "Search",
+			"report_edit_search_use":         "Use",
+			"report_edit_search_empty":       "No addresses found in the service area.",
+			"error_address_search_failed":    "Address search failed. Try again or enter the location yourself.",
+			"error_report_regeocode_failed":  "Address lookup failed. Try again or enter the address manually.",
+			"event_edited":                   "Edited",
 			"action_disable_reports":         "Disable reports",
 			"action_enable_reports":          "Enable reports",
 			"operator_receives_reports":      "Receives reports",
@@ -460,6 +673,199 @@ var (
 			"error_blog_media_upload_failed": "Media upload failed.",
 			"status_published":               "Published",
 			"status_draft":                   "Draft",
+
+			"nav_signal_settings":                  "Signal settings",
+			"page_title_signal_settings":           "Signal and dedupe settings",
+			"signal_settings_hint":                 "These parameters control how reports are linked to bike groups, how reconfirmations are counted and how duplicate candidates are scored. Municipality settings take precedence over the global settings.",
+			"signal_settings_global":               "Global settings",
+			"signal_settings_override":             "Settings for",
+			"signal_settings_add_override":         "Add municipality override",
+			"signal_settings_delete_override":      "Delete municipality override",
+			"signal_settings_save":                 "Save",
+			"signal_settings_updated_by":           "Last changed by",
+			"signal_settings_match_radius":         "Bike group match radius (m)",
+			"signal_settings_lookback_days":        "Signal lookback (days)",
+			"signal_settings_gap_days":             "Minimum gap between reconfirmations (days)",
+			"signal_settings_min_reporters":        "Minimum reporters for a strong signal",
+			"signal_settings_dedupe_radius":        "Duplicate radius (m)",
+			"signal_settings_dedupe_lookback_days": "Duplicate lookback (days)",
+			"signal_settings_distance_weight":      "Distance weight",
+			"signal_settings_tag_overlap_weight":   "Tag overlap weight",
+			"signal_settings_recency_weight":       "Recency weight",
+			"signal_settings_photo_weight":         "Photo similarity weight",
+			"notice_signal_settings_saved":         "Settings saved.",
+			"notice_signal_settings_deleted":       "Municipality override deleted.",
+			"error_signal_settings_load_failed":    "Failed to load settings.",
+			"error_signal_settings_save_failed":    "Failed to save settings.",
+			"error_signal_settings_invalid":        "Invalid settings:",
+
+			"bike_group_state_open":     "open",
+			"bike_group_state_closed":   "closed",
+			"report_recurring_title":    "Recurring location",
+			"report_recurring_hint":     "Earlier bike groups at this spot were reported and closed.",
+			"report_recurring_reports":  "reports",
+			"event_bike_group_closed":   "Bike group closed",
+			"event_bike_group_reopened": "Bike group reopened",
+
+			"event_municipality_reassigned":          "Municipality reassigned",
+			"event_flagged_near_service_area_border": "Flagged: just outside the service area",
+			"event_flagged_photo_location_mismatch":  "Flagged: photo taken elsewhere",
+			"event_flagged_photo_capture_too_old":    "Flagged: photo too old",
+			"event_flagged_photo_reused":             "Flagged: photo reused from another report",
+
+			"filter_min_priority":          "Minimum priority",
+			"sort_priority":                "Priority",
+			"col_priority":                 "Priority",
+			"report_signal_score":          "Signal score",
+			"report_signal_score_hint":     "The score drops as the last report gets older. Signal strength follows from thresholds on this score.",
+			"signal_score_distinct":        "Reconfirmations by other reporters",
+			"signal_score_same":            "Reconfirmations by the same reporter",
+			"signal_score_reporters":       "Additional unique reporters",
+			"signal_score_persistence":     "Time between first report and last reconfirmation",
+			"signal_score_severity":        "Tag severity (multiplier)",
+			"signal_score_base":            "Score at last report",
+			"signal_score_decay":           "Decay since last report",
+			"signal_score_days_since_last": "days ago",
+
+			"nav_hotspots":                  "Hotspots",
+			"page_title_hotspots":           "Hotspots",
+			"hotspots_hint":                 "Places where stray bikes are reported over and over. Use this list to plan structural interventions such as extra bike parking or enforcement.",
+			"hotspots_computed_at":          "Last computed:",
+			"hotspots_empty":                "No hotspots found yet.",
+			"hotspot_col_location":          "Location",
+			"hotspot_col_radius":            "Radius",
+			"hotspot_col_reports":           "Reports",
+			"hotspot_col_bike_groups":       "Bikes",
+			"hotspot_col_recurrence":        "Bikes per 30 days",
+			"hotspot_col_median_resolution": "Median time to resolution",
+			"hotspot_col_period":            "Period",
+			"error_hotspots_load_failed":    "Failed to load hotspots.",
+
+			"nav_geocoder":                     "Geocoder",
+			"page_title_geocoder":              "Geocoder",
+			"geocode_cache_hint":               "Report addresses are looked up with an external geocoder. Results are kept per location of about 10 metres, so repeated lookups and backfills do not load the external services.",
+			"geocode_cache_title":              "Geocode cache",
+			"geocode_cache_disabled":           "The geocode cache is turned off (GEOCODE_CACHE_TTL_DAYS=0).",
+			"geocode_cache_hits":               "Hits",
+			"geocode_cache_negative_hits":      "Hits without an address",
+			"geocode_cache_misses":             "Misses",
+			"geocode_cache_hit_rate":           "Hit rate",
+			"geocode_cache_errors":             "Cache errors",
+			"geocode_cache_entries":            "Cached locations",
+			"geocode_cache_negative_entries":   "Of which without an address",
+			"geocode_cache_expired_entries":    "Of which expired",
+			"geocode_cache_counters_hint":      "Hits, misses and errors are counted since the API last restarted.",
+			"geocode_cache_purge":              "Purge cache",
+			"geocode_cache_purge_expired":      "Expired locations",
+			"geocode_cache_purge_misses":       "Locations without an address",
+			"geocode_cache_purge_all":          "All locations",
+			"notice_geocode_cache_purged":      "Locations removed from the cache:",
+			"error_geocode_cache_load_failed":  "Failed to load the geocode cache.",
+			"error_geocode_cache_purge_failed": "Failed to purge the geocode cache.",
+
+			"geocoder_providers_title":      "Providers",
+			"geocoder_provider":             "Provider",
+			"geocoder_timeout":              "Timeout",
+			"geocoder_breaker":              "Circuit breaker",
+			"geocoder_breaker_closed":       "Closed",
+			"geocoder_breaker_open":         "Open",
+			"geocoder_breaker_half_open":    "Trial call",
+			"geocoder_outcome_success":      "Found",
+			"geocoder_outcome_not_found":    "Not found",
+			"geocoder_outcome_error":        "Error",
+			"geocoder_outcome_timeout":      "Timeout",
+			"geocoder_outcome_circuit_open": "Skipped",
+			"geocoder_operation_reverse":    "Address for location",
+			"geocoder_operation_search":     "Address search",
+			"geocoder_recent_title":         "Recent lookups",
+			"geocoder_recent_empty":         "No lookups since the last restart.",
+			"geocoder_lookup_at":            "Time",
+			"geocoder_lookup_operation":     "Kind",
+			"geocoder_lookup_served_by":     "Served by",
+			"geocoder_lookup_outcome":       "Result",
+			"geocoder_lookup_duration":      "Duration",
+			"geocoder_lookup_attempts":      "Tried first",
+			"geocoder_counters_hint":        "Providers are tried in order. A provider that keeps failing is skipped for a while. Lookups answered from the cache are not listed here.",
+
+			"page_title_photo_redact":    "Redact photo",
+			"report_redact_photo":        "Redact",
+			"photo_redact_back":          "Back to report",
+			"photo_redact_hint":          "Drag rectangles over faces, license plates and other personal data. Only those parts of the photo are changed.",
+			"photo_redact_mode":          "Method",
+			"photo_redact_mode_blur":     "Blur",
+			"photo_redact_mode_pixelate": "Pixelate",
+			"photo_redact_retained":      "The previous version is kept for admins only for",
+			"photo_redact_days":          "days and deleted afterwards.",
+			"photo_redact_deleted":       "The previous version is deleted right away.",
+			"photo_redact_apply":         "Apply redaction",
+			"photo_redact_clear":         "Clear selection",
+			"photo_redact_history":       "Earlier redactions",
+			"photo_redact_version":       "Version",
+			"photo_redact_regions":       "Regions",
+			"photo_redact_by":            "By",
+			"photo_redact_original":      "Previous version",
+			"photo_redact_view_original": "View",
+			"photo_redact_until":         "until",
+			"photo_redact_original_gone": "Deleted",
+			"notice_photo_redacted":      "Photo redacted.",
+			"error_photo_redact_invalid": "Select at least one region and a valid method.",
+			"error_photo_redact_failed":  "Failed to redact the photo.",
+			"error_photo_redact_stale":   "Someone else changed the photo in the meantime. Check the new version and try again.",
+			"event_photo_redacted":       "Photo redacted",
+
+			"photo_kind_citizen":         "Reporter",
+			"photo_kind_field_check":     "Field check",
+			"photo_kind_after":           "After removal",
+			"photo_add_kind":             "Photo kind",
+			"photo_add_button":           "Add photos",
+			"notice_photos_added":        "Photos added.",
+			"error_photo_add_failed":     "Failed to add photos.",
+			"event_photo_added":          "Photo added",
+			"showcase_after_photo":       "After-removal photo",
+			"showcase_after_photo_hint":  "Pick an after-removal photo to show as a before/after pair.",
+			"showcase_after_none":        "None",
+			"error_showcase_after_photo": "The after-removal photo does not belong to this report.",
+
+			"legal_hold_title":                  "Legal hold",
+			"legal_hold_active":                 "This report is under legal hold and is not purged.",
+			"legal_hold_none":                   "No legal hold. Photos, e-mail address and fingerprint are purged according to the retention policy.",
+			"legal_hold_reason":                 "Reason",
+			"legal_hold_set_by":                 "Placed by",
+			"legal_hold_set_button":             "Place legal hold",
+			"legal_hold_release_button":         "Release legal hold",
+			"notice_legal_hold_set":             "Legal hold placed.",
+			"notice_legal_hold_release":         "Legal hold released.",
+			"error_legal_hold_failed":           "Failed to change the legal hold.",
+			"event_legal_hold_set":              "Legal hold placed",
+			"event_legal_hold_released":         "Legal hold released",
+			"event_retention_purged":            "Purged by retention policy",
+			"retention_category_photos":         "photos",
+			"retention_category_reporter_email": "reporter e-mail",
+			"retention_category_fingerprint":    "fingerprint",
+
+			"nav_privacy":                     "Privacy requests",
+			"page_title_privacy":              "Privacy requests",
+			"privacy_hint":                    "Processing log of data export and deletion requests by reporters. The log holds no e-mail addresses; searching by e-mail address compares the hash.",
+			"privacy_search_email":            "E-mail address",
+			"privacy_search_button":           "Search",
+			"privacy_empty":                   "No requests found.",
+			"privacy_col_at":                  "Time",
+			"privacy_col_user":                "Account",
+			"privacy_col_email_hash":          "E-mail hash",
+			"privacy_col_kind":                "Request",
+			"privacy_col_mode":                "Choice",
+			"privacy_col_detail":              "Result",
+			"privacy_kind_export":             "Data downloaded",
+			"privacy_kind_deletion_requested": "Deletion requested",
+			"privacy_kind_deletion_completed": "Account deleted",
+			"privacy_mode_anonymize":          "Anonymize reports",
+			"privacy_mode_delete":             "Delete reports",
+			"privacy_detail_reports":          "reports",
+			"privacy_detail_deleted":          "deleted",
+			"privacy_detail_anonymized":       "anonymized",
+			"privacy_detail_held":             "under legal hold",
+			"error_privacy_log_load_failed":   "Failed to load privacy requests.",
+			"event_reporter_anonymized":       "Reporter anonymized",
 		},
 	}
 
@@ -528,6 +934,8 @@ type adminReportRowView struct {
 	City                 string
 	SignalLabel          string
 	SignalClass          string
+	SignalScore          string
+	PriorityScore        string
 	UniqueReporters      int
 	LastReconfirmationAt string
 	CreatedAt            string
@@ -541,6 +949,7 @@ type adminReportFiltersView struct {
 	SignalStrength              string
 	HasQualifyingReconfirmation string
 	StrongOnly                  string
+	MinPriority                 string
 	Sort                        string
 	CurrentURL                  string
 }
@@ -571,10 +980,20 @@ type adminTimelineRowView struct {
 	Description   string
 }
 
+type adminRecurringGroupRowView struct {
+	BikeGroupID  int
+	CreatedAt    string
+	ClosedAt     string
+	TotalReports int
+	SignalLabel  string
+}
+
 type adminEventRowView struct {
 	TypeLabel string
 	Actor     string
 	CreatedAt string
+	// PhotoURL is the thumbnail of the photo a photo_added event refers to.
+	PhotoURL string
 }
 
 type adminSignalSummaryView struct {
@@ -586,6 +1005,18 @@ type adminSignalSummaryView struct {
 	LastQualifying                  string
 }
 
+type adminSignalScoreView struct {
+	Score              string
+	BaseScore          string
+	DistinctPoints     string
+	SamePoints         string
+	ReporterPoints     string
+	PersistencePoints  string
+	SeverityMultiplier string
+	DecayFactor        string
+	AgeDays            string
+}
+
 type adminReportDetailViewData struct {
 	adminBaseViewData
 	ReportID            int
@@ -603,13 +1034,23 @@ type adminReportDetailViewData struct {
 	MergeInput          string
 	SignalStrengthLabel string
 	BikeGroupID         int
+	BikeGroupStateLabel string
 	SignalSummary       adminSignalSummaryView
+	SignalScore         adminSignalScoreView
+	RecurringHistory    []adminRecurringGroupRowView
 	Timeline            []adminTimelineRowView
 	Events              []adminEventRowView
 	Address             string
 	City                string
 	Municipality        string
 	IsAdmin             bool
+	LegalHold           *adminLegalHoldView
+}
+
+type adminLegalHoldView struct {
+	Reason string
+	SetBy  string
+	SetAt  string
 }
 
 type adminReportEditViewData struct {
@@ -622,6 +1063,13 @@ type adminReportEditViewData struct {
 	Lat            float64
 	Lng            float64
 	Municipalities []string
+	SearchQuery    string
+	SearchResults  []adminAddressSearchResult
+}
+
+type adminAddressSearchResult struct {
+	Label  string
+	UseURL string
 }
 
 type adminMapPoint struct {
@@ -638,7 +1086,8 @@ type adminMapPoint struct {
 
 type adminMapViewData struct {
 	adminBaseViewData
-	MapData template.JS
+	MapData     template.JS
+	HotspotData template.JS
 }
 
 type adminExportRowView struct {
@@ -665,6 +1114,7 @@ type adminReportFilters struct {
 	SignalStrength              string
 	HasQualifyingReconfirmation string
 	StrongOnly                  string
+	MinPriority                 string
 	Sort                        string
 }
 
@@ -675,6 +1125,9 @@ type adminShowcaseEditorViewData struct {
 	PhotoURL      string
 	ShowcaseItems []ShowcaseItem
 	ShowcaseJSON  string
+	// AfterPhotos are the after-removal photos of the report PhotoID belongs
+	// to, offered as the second half of a before/after pair.
+	AfterPhotos []OperatorReportPhotoView
 }
 
 type adminBlogListViewData struct {
@@ -3,7 +3,7 @@ package main
 import (
 	"fmt"
 	"net/http"
-	"path/filepath"
+	"path"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -16,6 +16,9 @@ type ShowcaseItemPublic struct {
 	FocalY       int    `json:"focalY"`
 	ScalePercent int    `json:"scalePercent"`
 	PhotoURL     string `json:"photoUrl"`
+	// AfterPhotoURL is set when the photo is paired with an after-removal
+	// photo of the same report.
+	AfterPhotoURL string `json:"afterPhotoUrl,omitempty"`
 }
 
 func (a *App) publicShowcaseItemsHandler(c *gin.Context) {
@@ -28,19 +31,31 @@ func (a *App) publicShowcaseItemsHandler(c *gin.Context) {
 
 	var publicItems []ShowcaseItemPublic
 	for _, item := range items {
-		publicItems = append(publicItems, ShowcaseItemPublic{
+		publicItem := ShowcaseItemPublic{
 			Slot:         item.Slot,
 			Subtitle:     item.Subtitle,
 			FocalX:       item.FocalX,
 			FocalY:       item.FocalY,
 			ScalePercent: item.ScalePercent,
 			PhotoURL:     fmt.Sprintf("%s/api/v1/showcase/%d/photo", a.cfg.PublicBaseURL, item.Slot),
-		})
+		}
+		if item.AfterStoragePath != nil {
+			publicItem.AfterPhotoURL = fmt.Sprintf("%s/api/v1/showcase/%d/photo/after", a.cfg.PublicBaseURL, item.Slot)
+		}
+		publicItems = append(publicItems, publicItem)
 	}
 	a.writeJSON(c, http.StatusOK, gin.H{"items": publicItems})
 }
 
 func (a *App) publicShowcasePhotoHandler(c *gin.Context) {
+	a.serveShowcasePhoto(c, false)
+}
+
+func (a *App) publicShowcaseAfterPhotoHandler(c *gin.Context) {
+	a.serveShowcasePhoto(c, true)
+}
+
+func (a *App) serveShowcasePhoto(c *gin.Context, after bool) {
 	slotStr := c.Param("slot")
 	slot, err := strconv.Atoi(slotStr)
 	if err != nil {
@@ -66,20 +81,14 @@ func (a *App) publicShowcasePhotoHandler(c *gin.Context) {
 		c.Status(http.StatusNotFound)
 		return
 	}
-
-	fullPath := filepath.Join(a.cfg.DataRoot, targetItem.StoragePath)
-
-	if a.isProduction() {
-		c.Header("X-Accel-Redirect", "/_protected_media/"+targetItem.StoragePath)
-		c.Status(http.StatusOK)
-		return
-	}
-
-	ext := filepath.Ext(fullPath)
-	if ext == "" {
-		// Legacy uploaded images might be extensionless. They are mostly JPEGs.
-		c.Header("Content-Type", "image/jpeg")
+	storagePath := targetItem.StoragePath
+	if after {
+		if targetItem.AfterStoragePath == nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		storagePath = *targetItem.AfterStoragePath
 	}
 
-	c.File(fullPath)
+	a.writeProtectedPhoto(c, storagePath, blobContentType(storagePath), path.Base(storagePath), "")
 }
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+const (
+	bikeGroupStateOpen   = "open"
+	bikeGroupStateClosed = "closed"
+	// maxRecurringHistoryDepth bounds the walk over previous_group_id links.
+	maxRecurringHistoryDepth = 20
+)
+
+// bikeGroupStateForReports returns closed once every report in the group is
+// resolved or invalid. A group without reports stays open.
+func bikeGroupStateForReports(reports []Report) string {
+	if len(reports) == 0 {
+		return bikeGroupStateOpen
+	}
+	for _, report := range reports {
+		if containsString(openReportStatuses, report.Status) {
+			return bikeGroupStateOpen
+		}
+	}
+	return bikeGroupStateClosed
+}
+
+// refreshBikeGroupState recomputes the lifecycle state of a group in its own
+// transaction. See refreshBikeGroupStateTx.
+func (a *App) refreshBikeGroupState(ctx context.Context, groupID int, reportID int, actor string) error {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := a.refreshBikeGroupStateTx(ctx, tx, groupID, reportID, actor); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// refreshBikeGroupStateTx recomputes the lifecycle state of a group after one
+// of its reports changed and records the transition on reportID. With a zero
+// reportID the transition is stored without an event.
+func (a *App) refreshBikeGroupStateTx(ctx context.Context, tx *sql.Tx, groupID int, reportID int, actor string) error {
+	var state string
+	err := tx.QueryRowContext(ctx, `SELECT state FROM bike_groups WHERE id = $1 FOR UPDATE`, groupID).Scan(&state)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT status FROM reports WHERE bike_group_id = $1`, groupID)
+	if err != nil {
+		return err
+	}
+	reports := make([]Report, 0)
+	for rows.Next() {
+		var report Report
+		if err := rows.Scan(&report.Status); err != nil {
+			rows.Close()
+			return err
+		}
+		reports = append(reports, report)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	nextState := bikeGroupStateForReports(reports)
+	if nextState == state {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE bike_groups
+		SET state = $1,
+			closed_at = CASE WHEN $1 = 'closed' THEN NOW() ELSE NULL END,
+			updated_at = NOW()
+		WHERE id = $2
+	`, nextState, groupID); err != nil {
+		return err
+	}
+	if reportID == 0 {
+		return nil
+	}
+
+	eventType := "bike_group_closed"
+	if nextState == bikeGroupStateOpen {
+		eventType = "bike_group_reopened"
+	}
+	return a.addEventTx(ctx, tx, reportID, eventType, actor, map[string]any{"bike_group_id": groupID})
+}
+
+// listRecurringLocationHistory follows previous_group_id links from group and
+// returns the earlier, closed groups at the same location, newest first.
+func (a *App) listRecurringLocationHistory(ctx context.Context, group BikeGroup) ([]BikeGroup, error) {
+	history := make([]BikeGroup, 0)
+	seen := map[int]struct{}{group.ID: {}}
+	nextID := group.PreviousGroupID
+	for nextID != nil && len(history) < maxRecurringHistoryDepth {
+		if _, ok := seen[*nextID]; ok {
+			break
+		}
+		seen[*nextID] = struct{}{}
+
+		previous, err := a.getBikeGroupByID(ctx, *nextID)
+		if err != nil {
+			return nil, err
+		}
+		if previous == nil {
+			break
+		}
+		history = append(history, *previous)
+		nextID = previous.PreviousGroupID
+	}
+	return history, nil
+}
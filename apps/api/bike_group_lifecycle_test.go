@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBikeGroupStateForReports(t *testing.T) {
+	cases := []struct {
+		name     string
+		statuses []string
+		want     string
+	}{
+		{"empty group stays open", nil, bikeGroupStateOpen},
+		{"open report keeps group open", []string{"resolved", "triaged"}, bikeGroupStateOpen},
+		{"all resolved closes", []string{"resolved", "resolved"}, bikeGroupStateClosed},
+		{"resolved and invalid closes", []string{"invalid", "resolved"}, bikeGroupStateClosed},
+		{"only invalid closes", []string{"invalid"}, bikeGroupStateClosed},
+	}
+	for _, tc := range cases {
+		reports := make([]Report, 0, len(tc.statuses))
+		for i, status := range tc.statuses {
+			reports = append(reports, Report{ID: i + 1, Status: status})
+		}
+		if got := bikeGroupStateForReports(reports); got != tc.want {
+			t.Fatalf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestAdminReportDetailShowsRecurringLocation(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	closedAt := "2026-01-15T09:00:00Z"
+	app.adminGetReportDetails = func(ctx context.Context, reportID int) (*OperatorReportDetails, error) {
+		return &OperatorReportDetails{
+			Report: Report{ID: 1, PublicID: "PUB-1", Status: "new"},
+			SignalDetails: SignalDetails{
+				BikeGroup: BikeGroup{ID: 12, State: bikeGroupStateOpen},
+				RecurringHistory: []BikeGroup{
+					{ID: 7, State: bikeGroupStateClosed, ClosedAt: &closedAt, CreatedAt: "2025-12-01T09:00:00Z", TotalReports: 3, SignalStrength: "none"},
+				},
+			},
+		}, nil
+	}
+
+	rec := httptest.NewRecorder()
+	req := authenticatedRequest(t, app, http.MethodGet, "/bikeadmin/reports/1", "")
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, adminText("nl", "report_recurring_title")) {
+		t.Fatalf("expected recurring location section")
+	}
+	if !strings.Contains(body, "12 ("+adminText("nl", "bike_group_state_open")+")") {
+		t.Fatalf("expected bike group state label")
+	}
+	if !strings.Contains(body, "3 "+adminText("nl", "report_recurring_reports")) {
+		t.Fatalf("expected recurring group report count")
+	}
+}
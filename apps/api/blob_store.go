@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	storageBackendFilesystem = "filesystem"
+	storageBackendS3         = "s3"
+
+	defaultS3Region     = "us-east-1"
+	defaultS3PresignTTL = 15 * time.Minute
+
+	// fsBlobTempPrefix marks half-written files; List skips them.
+	fsBlobTempPrefix = ".blob-"
+)
+
+// blobStorePrefixes are the top-level key prefixes the API writes to. Tools
+// that walk a whole store stay inside them, so unrelated files under
+// DATA_ROOT are never touched.
+var blobStorePrefixes = []string{"uploads/", "variants/", "redaction-originals/", "exports/", "blog/"}
+
+// BlobStore keeps photos, exports and blog media. Keys are slash-separated
+// paths relative to the store root, the same values the storage_path columns
+// hold, for example uploads/reports/12/<name>.jpg. Missing blobs are reported
+// as os.ErrNotExist.
+type BlobStore interface {
+	Name() string
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Stat(ctx context.Context, key string) (BlobInfo, error)
+	// Delete removes a blob; deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+	// List returns every blob whose key starts with prefix, sorted by key.
+	List(ctx context.Context, prefix string) ([]BlobInfo, error)
+}
+
+type BlobInfo struct {
+	Key  string
+	Size int64
+	// ModTime is when the blob was last written; zero when the store does
+	// not say.
+	ModTime time.Time
+}
+
+// BlobResponseHeaders are sent along with a blob served from a presigned URL.
+type BlobResponseHeaders struct {
+	ContentType        string
+	ContentDisposition string
+	CacheControl       string
+}
+
+// blobPresigner is implemented by stores that can hand out time-limited
+// download URLs, so the API redirects to them instead of streaming the blob.
+type blobPresigner interface {
+	PresignGet(key string, ttl time.Duration, headers BlobResponseHeaders) (string, error)
+}
+
+// newBlobStore builds the named backend from the configuration.
+func newBlobStore(cfg *Config, backend string) (BlobStore, error) {
+	switch backend {
+	case storageBackendFilesystem:
+		return newFilesystemBlobStore(cfg.DataRoot), nil
+	case storageBackendS3:
+		return newS3BlobStore(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
+
+// blobStore returns the configured store. Apps built without one, as in
+// tests, fall back to the filesystem under DATA_ROOT.
+func (a *App) blobStore() BlobStore {
+	if a.blobs != nil {
+		return a.blobs
+	}
+	return newFilesystemBlobStore(a.cfg.DataRoot)
+}
+
+// cleanBlobKey normalizes a storage path to a blob key and rejects keys that
+// are empty, absolute or point outside the store.
+func cleanBlobKey(key string) (string, error) {
+	cleaned := path.Clean(filepath.ToSlash(strings.TrimSpace(key)))
+	if cleaned == "" || cleaned == "." || strings.HasPrefix(cleaned, "/") {
+		return "", fmt.Errorf("invalid storage path")
+	}
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("storage path escapes the store")
+	}
+	return cleaned, nil
+}
+
+// blobContentType guesses a content type from the key's extension.
+// Extensionless legacy uploads are mostly JPEGs.
+func blobContentType(key string) string {
+	ext := strings.ToLower(path.Ext(key))
+	if ext == "" {
+		return "image/jpeg"
+	}
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+type fsBlobStore struct {
+	root string
+}
+
+func newFilesystemBlobStore(root string) *fsBlobStore {
+	return &fsBlobStore{root: filepath.Clean(root)}
+}
+
+func (s *fsBlobStore) Name() string {
+	return storageBackendFilesystem
+}
+
+func (s *fsBlobStore) path(key string) (string, error) {
+	cleaned, err := cleanBlobKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
+
+// Put writes to a temporary file first so a concurrent reader never sees a
+// partially written blob.
+func (s *fsBlobStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(fullPath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, fsBlobTempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), fullPath)
+}
+
+func (s *fsBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(fullPath)
+}
+
+func (s *fsBlobStore) Stat(ctx context.Context, key string) (BlobInfo, error) {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	if info.IsDir() {
+		return BlobInfo{}, os.ErrNotExist
+	}
+	cleaned, _ := cleanBlobKey(key)
+	return BlobInfo{Key: cleaned, Size: info.Size(), ModTime: info.ModTime().UTC()}, nil
+}
+
+func (s *fsBlobStore) Delete(ctx context.Context, key string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(fullPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *fsBlobStore) List(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	prefix = strings.TrimPrefix(filepath.ToSlash(prefix), "/")
+	walkRoot := s.root
+	if dir := path.Dir(prefix); dir != "." {
+		walkRoot = filepath.Join(s.root, filepath.FromSlash(dir))
+	}
+
+	blobs := make([]BlobInfo, 0)
+	err := filepath.WalkDir(walkRoot, func(fullPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), fsBlobTempPrefix) {
+			return nil
+		}
+		relative, err := filepath.Rel(s.root, fullPath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relative)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		blobs = append(blobs, BlobInfo{Key: key, Size: info.Size(), ModTime: info.ModTime().UTC()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].Key < blobs[j].Key })
+	return blobs, nil
+}
+
+// BlobMigrationResult counts the outcome of migrate-storage.
+type BlobMigrationResult struct {
+	Blobs   int
+	Copied  int
+	Skipped int
+	Failed  int
+}
+
+// migrateBlobs copies every blob under blobStorePrefixes from one store to
+// another. Blobs already present in the destination with the same size are
+// skipped, so an interrupted run can simply be repeated. Failures are logged
+// and counted, not fatal. The source is left untouched.
+func (a *App) migrateBlobs(ctx context.Context, from, to BlobStore) (BlobMigrationResult, error) {
+	var result BlobMigrationResult
+	for _, prefix := range blobStorePrefixes {
+		blobs, err := from.List(ctx, prefix)
+		if err != nil {
+			return result, fmt.Errorf("list %s in %s: %w", prefix, from.Name(), err)
+		}
+		for _, blob := range blobs {
+			result.Blobs++
+			if existing, err := to.Stat(ctx, blob.Key); err == nil && existing.Size == blob.Size {
+				result.Skipped++
+				continue
+			}
+			data, err := from.Get(ctx, blob.Key)
+			if err == nil {
+				err = to.Put(ctx, blob.Key, data, blobContentType(blob.Key))
+			}
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return result, err
+				}
+				a.log.Error("failed to copy blob", "key", blob.Key, "from", from.Name(), "to", to.Name(), "err", err)
+				result.Failed++
+				continue
+			}
+			result.Copied++
+		}
+	}
+	return result, nil
+}
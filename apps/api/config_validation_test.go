@@ -82,3 +82,55 @@ func TestValidateReportCreatePayloadRespectsConfiguredAccuracyThreshold(t *testi
 		t.Fatalf("expected invalid_location error code, got %s", apiErr.Code)
 	}
 }
+
+func TestLoadConfigRequiresS3SettingsForS3Backend(t *testing.T) {
+	setupRequiredConfigEnv(t)
+	t.Setenv("STORAGE_BACKEND", "s3")
+	t.Setenv("S3_ENDPOINT", "http://127.0.0.1:9000")
+	t.Setenv("S3_BUCKET", "zwerffiets")
+	t.Setenv("S3_ACCESS_KEY_ID", "")
+	t.Setenv("S3_SECRET_ACCESS_KEY", "")
+
+	if _, err := loadConfig(); err == nil {
+		t.Fatal("expected the s3 backend without credentials to be rejected")
+	}
+
+	t.Setenv("S3_ACCESS_KEY_ID", "minioadmin")
+	t.Setenv("S3_SECRET_ACCESS_KEY", "minioadmin")
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("expected config to load: %v", err)
+	}
+	if cfg.StorageBackend != storageBackendS3 || !cfg.S3ForcePathStyle || cfg.S3PresignTTL != defaultS3PresignTTL {
+		t.Fatalf("unexpected storage config %#v", cfg)
+	}
+
+	t.Setenv("STORAGE_BACKEND", "ftp")
+	if _, err := loadConfig(); err == nil {
+		t.Fatal("expected an unknown storage backend to be rejected")
+	}
+}
+
+func TestLoadConfigParsesRetentionPolicies(t *testing.T) {
+	setupRequiredConfigEnv(t)
+	t.Setenv("RETENTION_PHOTOS_DAYS", "")
+	t.Setenv("RETENTION_REPORTER_EMAIL_DAYS", "90")
+	t.Setenv("RETENTION_FINGERPRINT_DAYS", "0")
+	t.Setenv("RETENTION_EXPORTS_DAYS", "")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("expected config to load: %v", err)
+	}
+	if cfg.RetentionPhotos != defaultRetentionPhotos || cfg.RetentionExports != defaultRetentionExports {
+		t.Fatalf("expected default photo and export retention, got %v and %v", cfg.RetentionPhotos, cfg.RetentionExports)
+	}
+	if retentionDays(cfg.RetentionReporterEmail) != 90 || cfg.RetentionFingerprint != 0 {
+		t.Fatalf("unexpected retention %v / %v", cfg.RetentionReporterEmail, cfg.RetentionFingerprint)
+	}
+
+	t.Setenv("RETENTION_PHOTOS_DAYS", "-1")
+	if _, err := loadConfig(); err == nil {
+		t.Fatal("expected a negative retention to be rejected")
+	}
+}
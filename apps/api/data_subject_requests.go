@@ -0,0 +1,549 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	accountDeletionAnonymize   = "anonymize"
+	accountDeletionDelete      = "delete"
+	accountDeletionTokenExpiry = time.Hour
+
+	dataSubjectRequestExport            = "export"
+	dataSubjectRequestDeletionRequested = "deletion_requested"
+	dataSubjectRequestDeletionCompleted = "deletion_completed"
+
+	adminDataSubjectRequestLimit = 200
+
+	// dataSubjectReportFilter selects the reports that belong to an account
+	// ($1) or were made with its e-mail address ($2) before it linked them.
+	// Export and deletion share it, so the export shows exactly what a
+	// deletion would touch.
+	dataSubjectReportFilter = `user_id = $1 OR lower(reporter_email) = lower($2)`
+)
+
+// UserDataExport is everything a citizen account holds: the user row and the
+// reports linked to it or made with its e-mail address, with their events and
+// photos.
+type UserDataExport struct {
+	User    User
+	Reports []UserDataExportReport
+}
+
+type UserDataExportReport struct {
+	Report Report
+	Events []ReportEvent
+	Photos []ReportPhoto
+}
+
+// AccountDeletionResult is returned when a deletion link is confirmed.
+// Reports under legal hold are anonymized even when deletion was chosen.
+type AccountDeletionResult struct {
+	Mode       string `json:"mode"`
+	Deleted    int    `json:"deletedReports"`
+	Anonymized int    `json:"anonymizedReports"`
+	Held       int    `json:"heldReports"`
+}
+
+// DataSubjectRequest is one entry in the processing log shown to admins.
+type DataSubjectRequest struct {
+	ID        int
+	UserID    int
+	EmailHash string
+	Kind      string
+	Mode      string
+	Detail    map[string]any
+	CreatedAt string
+}
+
+// The archive layout: account.json, reports.json and the citizen's own
+// photos under photos/<public id>/.
+type userArchiveAccount struct {
+	ID          int     `json:"id"`
+	Email       string  `json:"email"`
+	DisplayName *string `json:"displayName"`
+	IsActive    bool    `json:"isActive"`
+	CreatedAt   string  `json:"createdAt"`
+	UpdatedAt   string  `json:"updatedAt"`
+	ExportedAt  string  `json:"exportedAt"`
+}
+
+type userArchiveReport struct {
+	PublicID     string             `json:"publicId"`
+	CreatedAt    string             `json:"createdAt"`
+	UpdatedAt    string             `json:"updatedAt"`
+	Status       string             `json:"status"`
+	Location     ReportLocation     `json:"location"`
+	Tags         []string           `json:"tags"`
+	Note         *string            `json:"note"`
+	Address      *string            `json:"address,omitempty"`
+	City         *string            `json:"city,omitempty"`
+	PostalCode   *string            `json:"postalCode,omitempty"`
+	Municipality *string            `json:"municipality,omitempty"`
+	Events       []userArchiveEvent `json:"events"`
+	Photos       []userArchivePhoto `json:"photos"`
+}
+
+type userArchiveEvent struct {
+	Type      string `json:"type"`
+	CreatedAt string `json:"createdAt"`
+	Status    string `json:"status,omitempty"`
+}
+
+type userArchivePhoto struct {
+	ID        int    `json:"id"`
+	Filename  string `json:"filename"`
+	MimeType  string `json:"mimeType"`
+	SizeBytes int64  `json:"sizeBytes"`
+	CreatedAt string `json:"createdAt"`
+	File      string `json:"file,omitempty"`
+}
+
+func isValidAccountDeletionMode(mode string) bool {
+	return mode == accountDeletionAnonymize || mode == accountDeletionDelete
+}
+
+// hashDataSubjectEmail keys the processing log. It is salted with the signing
+// secret, so the log cannot be matched against a list of addresses without it.
+func (a *App) hashDataSubjectEmail(email string) string {
+	h := sha256.Sum256([]byte(fmt.Sprintf("%s:%s", strings.ToLower(strings.TrimSpace(email)), a.cfg.AppSigningSecret)))
+	return hex.EncodeToString(h[:])
+}
+
+func (a *App) userDataExport(ctx context.Context, userID int) (*UserDataExport, error) {
+	if a.userLoadDataExport != nil {
+		return a.userLoadDataExport(ctx, userID)
+	}
+	return a.loadUserDataExport(ctx, userID)
+}
+
+func (a *App) logDataSubjectRequest(ctx context.Context, entry DataSubjectRequest) error {
+	if a.userLogDataSubjectRequest != nil {
+		return a.userLogDataSubjectRequest(ctx, entry)
+	}
+	return a.insertDataSubjectRequest(ctx, a.db, entry)
+}
+
+func (a *App) accountDeletionToken(ctx context.Context, userID int, mode string) (string, error) {
+	if a.userCreateDeletionToken != nil {
+		return a.userCreateDeletionToken(ctx, userID, mode)
+	}
+	return a.createAccountDeletionToken(ctx, userID, mode)
+}
+
+func (a *App) confirmDeletion(ctx context.Context, token string) (*AccountDeletionResult, error) {
+	if a.userConfirmDeletion != nil {
+		return a.userConfirmDeletion(ctx, token)
+	}
+	return a.confirmAccountDeletion(ctx, token)
+}
+
+func (a *App) dataSubjectRequests(ctx context.Context, emailHash string) ([]DataSubjectRequest, error) {
+	if a.adminListDataSubjectRequests != nil {
+		return a.adminListDataSubjectRequests(ctx, emailHash, adminDataSubjectRequestLimit)
+	}
+	return a.listDataSubjectRequests(ctx, emailHash, adminDataSubjectRequestLimit)
+}
+
+// loadUserDataExport returns nil when the user no longer exists.
+func (a *App) loadUserDataExport(ctx context.Context, userID int) (*UserDataExport, error) {
+	user, err := a.getUserByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	reports, err := a.listDataSubjectReports(ctx, userID, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	export := &UserDataExport{User: *user, Reports: make([]UserDataExportReport, 0, len(reports))}
+	for _, report := range reports {
+		events, err := a.listEvents(ctx, report.ID)
+		if err != nil {
+			return nil, err
+		}
+		photos, err := a.listReportPhotos(ctx, report.ID)
+		if err != nil {
+			return nil, err
+		}
+		export.Reports = append(export.Reports, UserDataExportReport{Report: report, Events: events, Photos: photos})
+	}
+	return export, nil
+}
+
+// listDataSubjectReports returns the reports dataSubjectReportFilter selects,
+// newest first.
+func (a *App) listDataSubjectReports(ctx context.Context, userID int, email string) ([]Report, error) {
+	rows, err := a.db.QueryContext(ctx, reportSelect+` WHERE `+dataSubjectReportFilter+` ORDER BY created_at DESC`, userID, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := make([]Report, 0)
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, rows.Err()
+}
+
+// writeUserDataArchive writes the export as a zip archive. Only photos the
+// citizen uploaded are included; operator photos and internal events such as
+// fraud flags and legal holds are left out. Events carry their type, time
+// and, for status changes, the new status.
+func (a *App) writeUserDataArchive(ctx context.Context, w io.Writer, export *UserDataExport, now time.Time) error {
+	archive := zip.NewWriter(w)
+	writeJSON := func(name string, value any) error {
+		file, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	account := userArchiveAccount{
+		ID:          export.User.ID,
+		Email:       export.User.Email,
+		DisplayName: export.User.DisplayName,
+		IsActive:    export.User.IsActive,
+		CreatedAt:   export.User.CreatedAt,
+		UpdatedAt:   export.User.UpdatedAt,
+		ExportedAt:  now.UTC().Format(time.RFC3339),
+	}
+	if err := writeJSON("account.json", account); err != nil {
+		return err
+	}
+
+	reports := make([]userArchiveReport, 0, len(export.Reports))
+	for _, item := range export.Reports {
+		report := userArchiveReport{
+			PublicID:     item.Report.PublicID,
+			CreatedAt:    item.Report.CreatedAt,
+			UpdatedAt:    item.Report.UpdatedAt,
+			Status:       item.Report.Status,
+			Location:     item.Report.Location,
+			Tags:         item.Report.Tags,
+			Note:         item.Report.Note,
+			Address:      item.Report.Address,
+			City:         item.Report.City,
+			PostalCode:   item.Report.PostalCode,
+			Municipality: item.Report.Municipality,
+			Events:       make([]userArchiveEvent, 0, len(item.Events)),
+			Photos:       make([]userArchivePhoto, 0, len(item.Photos)),
+		}
+		for _, event := range item.Events {
+			if strings.HasPrefix(event.Type, "flagged_") || strings.HasPrefix(event.Type, "legal_hold_") {
+				continue
+			}
+			entry := userArchiveEvent{Type: event.Type, CreatedAt: event.CreatedAt}
+			if event.Type == "status_changed" {
+				entry.Status, _ = event.Metadata["status"].(string)
+			}
+			report.Events = append(report.Events, entry)
+		}
+		for _, photo := range item.Photos {
+			if photo.Kind != photoKindCitizen {
+				continue
+			}
+			entry := userArchivePhoto{ID: photo.ID, Filename: photo.Filename, MimeType: photo.MimeType, SizeBytes: photo.SizeBytes, CreatedAt: photo.CreatedAt}
+			data, err := a.readStoredPhoto(ctx, photo.StoragePath)
+			if err != nil {
+				// The photo is listed without a file rather than failing the export.
+				a.log.Error("failed to read photo for data export", "photo_id", photo.ID, "err", err)
+				report.Photos = append(report.Photos, entry)
+				continue
+			}
+			entry.File = path.Join("photos", item.Report.PublicID, fmt.Sprintf("%d%s", photo.ID, userArchivePhotoExtension(photo)))
+			file, err := archive.Create(entry.File)
+			if err != nil {
+				return err
+			}
+			if _, err := file.Write(data); err != nil {
+				return err
+			}
+			report.Photos = append(report.Photos, entry)
+		}
+		reports = append(reports, report)
+	}
+	if err := writeJSON("reports.json", reports); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+func (a *App) readStoredPhoto(ctx context.Context, storagePath string) ([]byte, error) {
+	key, err := a.resolveExistingPhotoStoragePath(ctx, storagePath)
+	if err != nil {
+		return nil, err
+	}
+	return a.blobStore().Get(ctx, key)
+}
+
+func userArchivePhotoExtension(photo ReportPhoto) string {
+	if ext := strings.ToLower(path.Ext(photo.StoragePath)); ext != "" {
+		return ext
+	}
+	if extensions, err := mime.ExtensionsByType(photo.MimeType); err == nil && len(extensions) > 0 {
+		return extensions[0]
+	}
+	return ".jpg"
+}
+
+func (a *App) createAccountDeletionToken(ctx context.Context, userID int, mode string) (string, error) {
+	token := createMagicLinkToken()
+	_, err := a.db.ExecContext(ctx, `
+		INSERT INTO account_deletion_tokens (user_id, token_hash, mode, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, userID, hashMagicLinkToken(token), mode, time.Now().UTC().Add(accountDeletionTokenExpiry))
+	return token, err
+}
+
+// confirmAccountDeletion consumes a deletion link and removes the account in
+// one transaction. With mode delete the account's reports are deleted with
+// their photos and events; with mode anonymize, and for reports under legal
+// hold, they stay without the account link, reporter e-mail and fingerprint.
+// Reports the account made share a pseudonymous reporter hash afterwards, so
+// signal scores keep counting them as one reporter. Blobs are deleted and
+// bike groups recomputed after the commit.
+func (a *App) confirmAccountDeletion(ctx context.Context, token string) (*AccountDeletionResult, error) {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var userID int
+	var mode string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE account_deletion_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id, mode
+	`, hashMagicLinkToken(strings.TrimSpace(token))).Scan(&userID, &mode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &apiError{Status: http.StatusBadRequest, Code: "invalid_token", Message: "Invalid or expired token"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	var email string
+	if err := tx.QueryRowContext(ctx, `SELECT email FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&email); err != nil {
+		return nil, err
+	}
+
+	type linkedReport struct {
+		id           int
+		bikeGroupID  int
+		municipality *string
+		held         bool
+	}
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, bike_group_id, municipality, legal_hold
+		FROM reports
+		WHERE `+dataSubjectReportFilter+`
+		ORDER BY id ASC
+		FOR UPDATE
+	`, userID, email)
+	if err != nil {
+		return nil, err
+	}
+	linked := make([]linkedReport, 0)
+	for rows.Next() {
+		var report linkedReport
+		if err := rows.Scan(&report.id, &report.bikeGroupID, &report.municipality, &report.held); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		linked = append(linked, report)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &AccountDeletionResult{Mode: mode}
+	deleteIDs := make([]int64, 0)
+	anonymizeIDs := make([]int64, 0)
+	for _, report := range linked {
+		switch {
+		case mode == accountDeletionDelete && !report.held:
+			deleteIDs = append(deleteIDs, int64(report.id))
+		case mode == accountDeletionDelete:
+			result.Held++
+			anonymizeIDs = append(anonymizeIDs, int64(report.id))
+		default:
+			anonymizeIDs = append(anonymizeIDs, int64(report.id))
+		}
+	}
+
+	photos := make([]purgedPhoto, 0)
+	if len(deleteIDs) > 0 {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id, report_id, storage_path, version FROM report_photos WHERE report_id = ANY($1)
+		`, deleteIDs)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var photo purgedPhoto
+			if err := rows.Scan(&photo.ID, &photo.ReportID, &photo.StoragePath, &photo.Version); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			photos = append(photos, photo)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		if err := loadRedactionOriginalsTx(ctx, tx, photos); err != nil {
+			return nil, err
+		}
+		// Photos, events and showcase items cascade with the reports.
+		if _, err := tx.ExecContext(ctx, `DELETE FROM reports WHERE id = ANY($1)`, deleteIDs); err != nil {
+			return nil, err
+		}
+		result.Deleted = len(deleteIDs)
+	}
+	if len(anonymizeIDs) > 0 {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE reports
+			SET user_id = NULL, reporter_email = NULL, reporter_email_confirmed = FALSE, fingerprint_hash = '', reporter_hash = $2
+			WHERE id = ANY($1)
+		`, anonymizeIDs, a.deriveReporterHash(fmt.Sprintf("deleted-user:%d", userID))); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO report_events (report_id, type, actor, metadata)
+			SELECT id, 'reporter_anonymized', 'system', jsonb_build_object('reason', 'account_deleted')
+			FROM unnest($1::int[]) AS id
+		`, anonymizeIDs); err != nil {
+			return nil, err
+		}
+		result.Anonymized = len(anonymizeIDs)
+	}
+
+	// Tokens cascade with the user.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, userID); err != nil {
+		return nil, err
+	}
+	if err := a.insertDataSubjectRequest(ctx, tx, DataSubjectRequest{
+		UserID:    userID,
+		EmailHash: a.hashDataSubjectEmail(email),
+		Kind:      dataSubjectRequestDeletionCompleted,
+		Mode:      mode,
+		Detail: map[string]any{
+			"deleted_reports":    result.Deleted,
+			"anonymized_reports": result.Anonymized,
+			"held_reports":       result.Held,
+			"photos":             len(photos),
+		},
+	}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	a.deletePurgedPhotoBlobs(ctx, photos)
+	recomputed := make(map[int]bool)
+	for _, report := range linked {
+		if recomputed[report.bikeGroupID] {
+			continue
+		}
+		recomputed[report.bikeGroupID] = true
+		params, err := a.loadSignalParams(ctx, report.municipality)
+		if err == nil {
+			err = a.recomputeBikeGroup(ctx, report.bikeGroupID, params)
+		}
+		if err != nil {
+			a.log.Error("failed to recompute bike group after account deletion", "bike_group_id", report.bikeGroupID, "err", err)
+		}
+		if err := a.refreshBikeGroupStateAfterDeletion(ctx, report.bikeGroupID); err != nil {
+			a.log.Error("failed to refresh bike group state after account deletion", "bike_group_id", report.bikeGroupID, "err", err)
+		}
+	}
+	return result, nil
+}
+
+// refreshBikeGroupStateAfterDeletion updates the lifecycle state of a group
+// that lost or anonymized reports. A deleted open report can leave only
+// resolved ones behind, which closes the group. The transition is recorded
+// on the newest report left in the group, or not at all when none is left.
+func (a *App) refreshBikeGroupStateAfterDeletion(ctx context.Context, groupID int) error {
+	var survivorID int
+	err := a.db.QueryRowContext(ctx, `
+		SELECT id FROM reports WHERE bike_group_id = $1 ORDER BY id DESC LIMIT 1
+	`, groupID).Scan(&survivorID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return a.refreshBikeGroupState(ctx, groupID, survivorID, "system")
+}
+
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (a *App) insertDataSubjectRequest(ctx context.Context, db sqlExecer, entry DataSubjectRequest) error {
+	var mode *string
+	if entry.Mode != "" {
+		mode = &entry.Mode
+	}
+	if entry.Detail == nil {
+		entry.Detail = map[string]any{}
+	}
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO data_subject_requests (user_id, email_hash, kind, mode, detail)
+		VALUES ($1, $2, $3, $4, $5)
+	`, entry.UserID, entry.EmailHash, entry.Kind, mode, anyMapToJSON(entry.Detail))
+	return err
+}
+
+// listDataSubjectRequests returns the newest log entries, optionally only
+// those for one e-mail hash.
+func (a *App) listDataSubjectRequests(ctx context.Context, emailHash string, limit int) ([]DataSubjectRequest, error) {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT id, user_id, email_hash, kind, COALESCE(mode, ''), detail, created_at
+		FROM data_subject_requests
+		WHERE $1::text = '' OR email_hash = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`, emailHash, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]DataSubjectRequest, 0)
+	for rows.Next() {
+		var entry DataSubjectRequest
+		var detail []byte
+		var createdAt time.Time
+		if err := rows.Scan(&entry.ID, &entry.UserID, &entry.EmailHash, &entry.Kind, &entry.Mode, &detail, &createdAt); err != nil {
+			return nil, err
+		}
+		entry.Detail = jsonToAnyMap(detail)
+		entry.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
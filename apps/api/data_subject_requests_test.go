@@ -0,0 +1,466 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"zwerffiets/libs/mailer"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newUserDataTestServer(t *testing.T) (*App, *gin.Engine) {
+	t.Helper()
+	app, router := newAdminTestServer(t)
+	app.cfg.PublicBaseURL = "https://zwerffiets.example"
+	api := router.Group("/api/v1")
+	{
+		api.POST("/user/deletion/confirm", app.userDeletionConfirmHandler)
+		user := api.Group("/user")
+		user.Use(app.requireUserSession())
+		{
+			user.GET("/export", app.userDataExportHandler)
+			user.POST("/deletion", app.userDeletionRequestHandler)
+		}
+	}
+	return app, router
+}
+
+func userRequest(t *testing.T, app *App, method, target, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("content-type", "application/json")
+	token, err := app.createUserSessionToken(UserSession{UserID: 42, Email: "citizen@example.com"})
+	if err != nil {
+		t.Fatalf("createUserSessionToken returned error: %v", err)
+	}
+	req.AddCookie(&http.Cookie{Name: userCookieName, Value: token})
+	return req
+}
+
+func readZipEntries(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("invalid zip archive: %v", err)
+	}
+	entries := make(map[string][]byte)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		entries[file.Name] = content
+	}
+	return entries
+}
+
+func TestWriteUserDataArchive(t *testing.T) {
+	ctx := context.Background()
+	app := &App{cfg: &Config{DataRoot: t.TempDir()}, log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	if err := app.blobStore().Put(ctx, "uploads/reports/7/a.jpg", []byte("citizen photo"), "image/jpeg"); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+
+	export := &UserDataExport{
+		User: User{ID: 42, Email: "citizen@example.com", IsActive: true, CreatedAt: "2026-01-02T10:00:00Z"},
+		Reports: []UserDataExportReport{{
+			Report: Report{ID: 7, PublicID: "PUB-7", Status: "resolved", Tags: []string{"rusted"}},
+			Events: []ReportEvent{
+				{Type: "created", CreatedAt: "2026-01-02T10:00:00Z"},
+				{Type: "flagged_duplicate_fingerprint", CreatedAt: "2026-01-02T10:01:00Z"},
+				{Type: "status_changed", CreatedAt: "2026-02-01T09:00:00Z", Metadata: map[string]any{"status": "resolved", "operator": "crew@example.com"}},
+			},
+			Photos: []ReportPhoto{
+				{ID: 3, ReportID: 7, StoragePath: "uploads/reports/7/a.jpg", MimeType: "image/jpeg", Kind: photoKindCitizen},
+				{ID: 4, ReportID: 7, StoragePath: "uploads/reports/7/gone.jpg", MimeType: "image/jpeg", Kind: photoKindCitizen},
+				{ID: 5, ReportID: 7, StoragePath: "uploads/reports/7/after.jpg", MimeType: "image/jpeg", Kind: photoKindAfter},
+			},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := app.writeUserDataArchive(ctx, &buf, export, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("writeUserDataArchive returned error: %v", err)
+	}
+	entries := readZipEntries(t, buf.Bytes())
+	if string(entries["photos/PUB-7/3.jpg"]) != "citizen photo" {
+		t.Fatalf("expected the citizen photo in the archive, got entries %v", len(entries))
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected account.json, reports.json and one photo, got %d entries", len(entries))
+	}
+
+	var account userArchiveAccount
+	if err := json.Unmarshal(entries["account.json"], &account); err != nil || account.Email != "citizen@example.com" || account.ExportedAt != "2026-10-18T12:00:00Z" {
+		t.Fatalf("unexpected account.json %s (%v)", entries["account.json"], err)
+	}
+	var reports []userArchiveReport
+	if err := json.Unmarshal(entries["reports.json"], &reports); err != nil || len(reports) != 1 {
+		t.Fatalf("unexpected reports.json %s (%v)", entries["reports.json"], err)
+	}
+	report := reports[0]
+	if len(report.Events) != 2 || report.Events[1].Status != "resolved" {
+		t.Fatalf("expected internal events to be left out, got %#v", report.Events)
+	}
+	if strings.Contains(string(entries["reports.json"]), "crew@example.com") {
+		t.Fatalf("expected operator details to stay out of the archive")
+	}
+	if len(report.Photos) != 2 || report.Photos[0].File != "photos/PUB-7/3.jpg" || report.Photos[1].File != "" {
+		t.Fatalf("expected the missing photo listed without a file, got %#v", report.Photos)
+	}
+}
+
+func TestUserDataExportHandler(t *testing.T) {
+	app, router := newUserDataTestServer(t)
+	app.cfg.DataRoot = t.TempDir()
+	app.userLoadDataExport = func(ctx context.Context, userID int) (*UserDataExport, error) {
+		if userID != 42 {
+			t.Fatalf("unexpected user %d", userID)
+		}
+		return &UserDataExport{User: User{ID: 42, Email: "citizen@example.com"}, Reports: []UserDataExportReport{}}, nil
+	}
+	var logged []DataSubjectRequest
+	app.userLogDataSubjectRequest = func(ctx context.Context, entry DataSubjectRequest) error {
+		logged = append(logged, entry)
+		return nil
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, userRequest(t, app, http.MethodGet, "/api/v1/user/export", ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Type") != "application/zip" || !strings.HasPrefix(rec.Header().Get("Content-Disposition"), "attachment;") {
+		t.Fatalf("expected a zip attachment, got %q / %q", rec.Header().Get("Content-Type"), rec.Header().Get("Content-Disposition"))
+	}
+	if _, ok := readZipEntries(t, rec.Body.Bytes())["account.json"]; !ok {
+		t.Fatalf("expected account.json in the archive")
+	}
+	if len(logged) != 1 || logged[0].Kind != dataSubjectRequestExport || logged[0].EmailHash != app.hashDataSubjectEmail("Citizen@example.com") {
+		t.Fatalf("expected one hashed export log entry, got %#v", logged)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/user/export", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a session, got %d", rec.Code)
+	}
+}
+
+func TestUserDeletionRequestSendsConfirmationLink(t *testing.T) {
+	app, router := newUserDataTestServer(t)
+	mockP := &mockProvider{}
+	app.mailer = mailer.New(mockP, "test@example.com")
+	app.userCreateDeletionToken = func(ctx context.Context, userID int, mode string) (string, error) {
+		if userID != 42 || mode != accountDeletionDelete {
+			t.Fatalf("unexpected deletion token for %d/%s", userID, mode)
+		}
+		return "tok123", nil
+	}
+	var logged []DataSubjectRequest
+	app.userLogDataSubjectRequest = func(ctx context.Context, entry DataSubjectRequest) error {
+		logged = append(logged, entry)
+		return nil
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, userRequest(t, app, http.MethodPost, "/api/v1/user/deletion", `{"mode":"delete","language":"en"}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(mockP.SentMessages) != 1 {
+		t.Fatalf("expected one email, got %d", len(mockP.SentMessages))
+	}
+	msg := mockP.SentMessages[0]
+	if msg.To[0] != "citizen@example.com" || msg.Subject != accountDeletionContent["en"].Subject || !strings.Contains(msg.Text, "https://zwerffiets.example/account/delete?token=tok123") {
+		t.Fatalf("unexpected confirmation email %#v", msg)
+	}
+	if len(logged) != 1 || logged[0].Kind != dataSubjectRequestDeletionRequested || logged[0].Mode != accountDeletionDelete {
+		t.Fatalf("expected a deletion request log entry, got %#v", logged)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, userRequest(t, app, http.MethodPost, "/api/v1/user/deletion", `{"mode":"everything"}`))
+	if rec.Code != http.StatusBadRequest || len(mockP.SentMessages) != 1 {
+		t.Fatalf("expected an unknown mode to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestUserDeletionConfirm(t *testing.T) {
+	app, router := newUserDataTestServer(t)
+	app.userConfirmDeletion = func(ctx context.Context, token string) (*AccountDeletionResult, error) {
+		if token != "tok123" {
+			return nil, &apiError{Status: http.StatusBadRequest, Code: "invalid_token", Message: "Invalid or expired token"}
+		}
+		return &AccountDeletionResult{Mode: accountDeletionDelete, Deleted: 2, Anonymized: 1, Held: 1}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/user/deletion/confirm", strings.NewReader(`{"token":"tok123"}`))
+	req.Header.Set("content-type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 without a session, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var result AccountDeletionResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil || result.Deleted != 2 || result.Held != 1 {
+		t.Fatalf("unexpected result %s", rec.Body.String())
+	}
+	if cookie := rec.Header().Get("Set-Cookie"); !strings.HasPrefix(cookie, userCookieName+"=;") {
+		t.Fatalf("expected the user session cookie to be cleared, got %q", cookie)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/user/deletion/confirm", strings.NewReader(`{"token":"used"}`))
+	req.Header.Set("content-type", "application/json")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_token") {
+		t.Fatalf("expected invalid_token, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAdminPrivacyPage(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	var searched []string
+	app.adminListDataSubjectRequests = func(ctx context.Context, emailHash string, limit int) ([]DataSubjectRequest, error) {
+		searched = append(searched, emailHash)
+		return []DataSubjectRequest{{
+			ID:        1,
+			UserID:    42,
+			EmailHash: app.hashDataSubjectEmail("citizen@example.com"),
+			Kind:      dataSubjectRequestDeletionCompleted,
+			Mode:      accountDeletionDelete,
+			Detail:    map[string]any{"deleted_reports": float64(2), "anonymized_reports": float64(1), "held_reports": float64(1)},
+			CreatedAt: "2026-10-18T09:30:00Z",
+		}}, nil
+	}
+
+	query := url.Values{"email": {"Citizen@example.com"}}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodGet, "/bikeadmin/privacy?"+query.Encode(), ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if len(searched) != 1 || searched[0] != app.hashDataSubjectEmail("citizen@example.com") {
+		t.Fatalf("expected the search address to be hashed, got %v", searched)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, adminText(adminDefaultLanguage, "privacy_kind_deletion_completed")) || !strings.Contains(body, "2 "+adminText(adminDefaultLanguage, "privacy_detail_deleted")) {
+		t.Fatalf("expected the deletion entry with its counts")
+	}
+	if strings.Count(body, "itizen@example.com") != 1 {
+		t.Fatalf("expected the address only in the search field")
+	}
+
+	municipality := "Amsterdam"
+	session := OperatorSession{Email: "crew@example.com", Role: "municipality_operator", Municipality: &municipality}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequestWithSession(t, app, http.MethodGet, "/bikeadmin/privacy", "", session))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for operators, got %d", rec.Code)
+	}
+}
+
+// dataSubjectTestReport is a row in the fake reports table behind
+// dataSubjectTestConn.
+type dataSubjectTestReport struct {
+	id            int64
+	userID        *int64
+	reporterEmail string
+}
+
+// dataSubjectTestConn is a database/sql driver that answers the queries
+// loadUserDataExport makes. Reports are matched the way
+// dataSubjectReportFilter matches them, and only when the query uses it.
+type dataSubjectTestConn struct {
+	email   string
+	reports []dataSubjectTestReport
+}
+
+func (c *dataSubjectTestConn) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *dataSubjectTestConn) Driver() driver.Driver                        { return nil }
+func (c *dataSubjectTestConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+func (c *dataSubjectTestConn) Close() error { return nil }
+func (c *dataSubjectTestConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("begin is not supported")
+}
+
+func (c *dataSubjectTestConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	created := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	switch {
+	case strings.Contains(query, "FROM users"):
+		return &dataSubjectTestRows{rows: [][]driver.Value{{args[0].Value, c.email, nil, true, created, created}}}, nil
+	case strings.Contains(query, "FROM reports"):
+		if !strings.Contains(query, dataSubjectReportFilter) {
+			return nil, fmt.Errorf("unexpected report selection %q", query)
+		}
+		userID, email := args[0].Value.(int64), args[1].Value.(string)
+		rows := make([][]driver.Value, 0)
+		for _, report := range c.reports {
+			if (report.userID == nil || *report.userID != userID) && !strings.EqualFold(report.reporterEmail, email) {
+				continue
+			}
+			var linkedUser driver.Value
+			if report.userID != nil {
+				linkedUser = *report.userID
+			}
+			rows = append(rows, []driver.Value{
+				report.id, fmt.Sprintf("R%d", report.id), created, created, "new", 52.37, 4.89, 10.0,
+				[]byte(`["flat_tires"]`), nil, "web", nil, int64(1), "", "", false,
+				nil, nil, nil, nil, linkedUser, "gps",
+			})
+		}
+		return &dataSubjectTestRows{rows: rows}, nil
+	default:
+		return &dataSubjectTestRows{}, nil
+	}
+}
+
+type dataSubjectTestRows struct {
+	rows [][]driver.Value
+}
+
+func (r *dataSubjectTestRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+func (r *dataSubjectTestRows) Close() error { return nil }
+func (r *dataSubjectTestRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestLoadUserDataExportIncludesReportsMadeWithTheEmailAddress(t *testing.T) {
+	owner, stranger := int64(42), int64(7)
+	conn := &dataSubjectTestConn{
+		email: "Citizen@Example.com",
+		reports: []dataSubjectTestReport{
+			{id: 1, userID: &owner},
+			{id: 2, reporterEmail: "citizen@example.com"},
+			{id: 3, userID: &stranger, reporterEmail: "someone@example.com"},
+		},
+	}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	app := &App{db: db}
+
+	export, err := app.loadUserDataExport(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("loadUserDataExport returned error: %v", err)
+	}
+	ids := make([]int, 0, len(export.Reports))
+	for _, report := range export.Reports {
+		ids = append(ids, report.Report.ID)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Fatalf("expected the linked and the e-mail-only report, got %v", ids)
+	}
+	if export.Reports[1].Report.UserID != nil {
+		t.Fatalf("expected the e-mail-only report to stay unlinked, got %#v", export.Reports[1].Report)
+	}
+}
+
+// bikeGroupStateTestConn is a database/sql driver holding one bike group and
+// the statuses of the reports left in it after an account deletion.
+type bikeGroupStateTestConn struct {
+	state   string
+	reports map[int64]string
+	events  []string
+}
+
+func (c *bikeGroupStateTestConn) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *bikeGroupStateTestConn) Driver() driver.Driver                        { return nil }
+func (c *bikeGroupStateTestConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+func (c *bikeGroupStateTestConn) Close() error              { return nil }
+func (c *bikeGroupStateTestConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *bikeGroupStateTestConn) Commit() error             { return nil }
+func (c *bikeGroupStateTestConn) Rollback() error           { return nil }
+
+func (c *bikeGroupStateTestConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	switch {
+	case strings.Contains(query, "SELECT id FROM reports"):
+		var newest int64
+		for id := range c.reports {
+			newest = max(newest, id)
+		}
+		if newest == 0 {
+			return &dataSubjectTestRows{}, nil
+		}
+		return &dataSubjectTestRows{rows: [][]driver.Value{{newest}}}, nil
+	case strings.Contains(query, "FROM bike_groups"):
+		return &dataSubjectTestRows{rows: [][]driver.Value{{c.state}}}, nil
+	case strings.Contains(query, "SELECT status FROM reports"):
+		rows := make([][]driver.Value, 0, len(c.reports))
+		for _, status := range c.reports {
+			rows = append(rows, []driver.Value{status})
+		}
+		return &dataSubjectTestRows{rows: rows}, nil
+	default:
+		return nil, fmt.Errorf("unexpected query %q", query)
+	}
+}
+
+func (c *bikeGroupStateTestConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	switch {
+	case strings.Contains(query, "UPDATE bike_groups"):
+		c.state = args[0].Value.(string)
+	case strings.Contains(query, "INSERT INTO report_events"):
+		c.events = append(c.events, fmt.Sprintf("%s on %d", args[1].Value, args[0].Value))
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func TestRefreshBikeGroupStateAfterDeletion(t *testing.T) {
+	cases := []struct {
+		name       string
+		state      string
+		reports    map[int64]string
+		wantState  string
+		wantEvents []string
+	}{
+		{"only resolved reports left", bikeGroupStateOpen, map[int64]string{11: "resolved", 12: "invalid"}, bikeGroupStateClosed, []string{"bike_group_closed on 12"}},
+		{"an open report left", bikeGroupStateOpen, map[int64]string{11: "resolved", 12: "new"}, bikeGroupStateOpen, nil},
+		{"no report left", bikeGroupStateClosed, map[int64]string{}, bikeGroupStateOpen, nil},
+	}
+	for _, tc := range cases {
+		conn := &bikeGroupStateTestConn{state: tc.state, reports: tc.reports}
+		db := sql.OpenDB(conn)
+		app := &App{db: db}
+		if err := app.refreshBikeGroupStateAfterDeletion(context.Background(), 5); err != nil {
+			t.Fatalf("%s: refreshBikeGroupStateAfterDeletion returned error: %v", tc.name, err)
+		}
+		db.Close()
+		if conn.state != tc.wantState || strings.Join(conn.events, ",") != strings.Join(tc.wantEvents, ",") {
+			t.Fatalf("%s: expected %s with events %v, got %s with %v", tc.name, tc.wantState, tc.wantEvents, conn.state, conn.events)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// geocodeCacheScale rounds coordinates to 4 decimals: about 11 m north-south
+	// and 7 m east-west in the Netherlands.
+	geocodeCacheScale = 1e4
+
+	defaultGeocodeCacheTTL         = 180 * 24 * time.Hour
+	defaultGeocodeCacheNegativeTTL = 24 * time.Hour
+
+	geocodeCachePurgeAll     = "all"
+	geocodeCachePurgeExpired = "expired"
+	geocodeCachePurgeMisses  = "misses"
+)
+
+// GeocodeCacheStats combines the lookup counters of the running process with
+// the current contents of the cache table.
+type GeocodeCacheStats struct {
+	Hits            uint64
+	NegativeHits    uint64
+	Misses          uint64
+	Errors          uint64
+	Entries         int64
+	NegativeEntries int64
+	ExpiredEntries  int64
+}
+
+// HitRate returns the share of lookups answered from the cache, in percent.
+func (s GeocodeCacheStats) HitRate() float64 {
+	total := s.Hits + s.NegativeHits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits+s.NegativeHits) / float64(total) * 100
+}
+
+type geocodeCacheKey struct {
+	Lat int64
+	Lng int64
+}
+
+func newGeocodeCacheKey(lat, lng float64) geocodeCacheKey {
+	return geocodeCacheKey{Lat: int64(math.Round(lat * geocodeCacheScale)), Lng: int64(math.Round(lng * geocodeCacheScale))}
+}
+
+// geocodeCacheStore persists cache entries. A nil result is a cached miss.
+type geocodeCacheStore interface {
+	get(ctx context.Context, key geocodeCacheKey, now time.Time) (result *GeocodeResult, found bool, err error)
+	put(ctx context.Context, key geocodeCacheKey, result *GeocodeResult, expiresAt time.Time) error
+	purge(ctx context.Context, scope string, now time.Time) (int64, error)
+	counts(ctx context.Context, now time.Time) (entries, negative, expired int64, err error)
+}
+
+// CachingGeocoder answers lookups from a cache keyed on rounded coordinates
+// and only calls the wrapped geocoder on a miss. Addresses are kept for TTL,
+// lookups that found nothing for NegativeTTL. Errors are never cached, and a
+// failing cache falls through to the wrapped geocoder.
+type CachingGeocoder struct {
+	Next        Geocoder
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	Log         *slog.Logger
+
+	store        geocodeCacheStore
+	now          func() time.Time
+	hits         atomic.Uint64
+	negativeHits atomic.Uint64
+	misses       atomic.Uint64
+	errors       atomic.Uint64
+}
+
+func newCachingGeocoder(next Geocoder, db *sql.DB, ttl, negativeTTL time.Duration, logger *slog.Logger) *CachingGeocoder {
+	return &CachingGeocoder{
+		Next:        next,
+		TTL:         ttl,
+		NegativeTTL: negativeTTL,
+		Log:         logger,
+		store:       &pgGeocodeCacheStore{db: db},
+		now:         func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (g *CachingGeocoder) Geocode(ctx context.Context, lat, lng float64) (*GeocodeResult, error) {
+	key := newGeocodeCacheKey(lat, lng)
+	now := g.now()
+
+	cached, found, err := g.store.get(ctx, key, now)
+	if err != nil {
+		g.errors.Add(1)
+		g.Log.Warn("geocode cache lookup failed", "err", err)
+	} else if found {
+		if cached == nil {
+			g.negativeHits.Add(1)
+		} else {
+			g.hits.Add(1)
+		}
+		return cached, nil
+	}
+
+	g.misses.Add(1)
+	result, err := g.Next.Geocode(ctx, lat, lng)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := g.TTL
+	if result == nil {
+		ttl = g.NegativeTTL
+	}
+	if err := g.store.put(ctx, key, result, now.Add(ttl)); err != nil {
+		g.errors.Add(1)
+		g.Log.Warn("geocode cache store failed", "err", err)
+	}
+	return result, nil
+}
+
+// Search is passed through uncached; address searches rarely repeat.
+func (g *CachingGeocoder) Search(ctx context.Context, query string) ([]GeocodeSearchResult, error) {
+	return g.Next.Search(ctx, query)
+}
+
+// Stats returns the process counters and the current table counts.
+func (g *CachingGeocoder) Stats(ctx context.Context) (GeocodeCacheStats, error) {
+	stats := GeocodeCacheStats{
+		Hits:         g.hits.Load(),
+		NegativeHits: g.negativeHits.Load(),
+		Misses:       g.misses.Load(),
+		Errors:       g.errors.Load(),
+	}
+	var err error
+	stats.Entries, stats.NegativeEntries, stats.ExpiredEntries, err = g.store.counts(ctx, g.now())
+	return stats, err
+}
+
+// Purge deletes all entries, only expired entries, or only cached misses.
+func (g *CachingGeocoder) Purge(ctx context.Context, scope string) (int64, error) {
+	switch scope {
+	case geocodeCachePurgeAll, geocodeCachePurgeExpired, geocodeCachePurgeMisses:
+		return g.store.purge(ctx, scope, g.now())
+	default:
+		return 0, fmt.Errorf("unknown purge scope %q", scope)
+	}
+}
+
+type pgGeocodeCacheStore struct {
+	db *sql.DB
+}
+
+func (s *pgGeocodeCacheStore) get(ctx context.Context, key geocodeCacheKey, now time.Time) (*GeocodeResult, bool, error) {
+	var found bool
+	var result GeocodeResult
+	err := s.db.QueryRowContext(ctx, `
+		SELECT found, address, city, postal_code
+		FROM geocode_cache
+		WHERE lat_key = $1 AND lng_key = $2 AND expires_at > $3
+	`, key.Lat, key.Lng, now).Scan(&found, &result.Address, &result.City, &result.PostalCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if !found {
+		return nil, true, nil
+	}
+	return &result, true, nil
+}
+
+func (s *pgGeocodeCacheStore) put(ctx context.Context, key geocodeCacheKey, result *GeocodeResult, expiresAt time.Time) error {
+	entry := GeocodeResult{}
+	if result != nil {
+		entry = *result
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO geocode_cache (lat_key, lng_key, found, address, city, postal_code, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (lat_key, lng_key) DO UPDATE
+		SET found = EXCLUDED.found,
+		    address = EXCLUDED.address,
+		    city = EXCLUDED.city,
+		    postal_code = EXCLUDED.postal_code,
+		    expires_at = EXCLUDED.expires_at,
+		    created_at = NOW()
+	`, key.Lat, key.Lng, result != nil, entry.Address, entry.City, entry.PostalCode, expiresAt)
+	return err
+}
+
+func (s *pgGeocodeCacheStore) purge(ctx context.Context, scope string, now time.Time) (int64, error) {
+	query := `DELETE FROM geocode_cache`
+	args := []any{}
+	switch scope {
+	case geocodeCachePurgeExpired:
+		query += ` WHERE expires_at <= $1`
+		args = append(args, now)
+	case geocodeCachePurgeMisses:
+		query += ` WHERE found = FALSE`
+	}
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (s *pgGeocodeCacheStore) counts(ctx context.Context, now time.Time) (int64, int64, int64, error) {
+	var entries, negative, expired int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE found = FALSE),
+			COUNT(*) FILTER (WHERE expires_at <= $1)
+		FROM geocode_cache
+	`, now).Scan(&entries, &negative, &expired)
+	return entries, negative, expired, err
+}
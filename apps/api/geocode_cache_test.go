@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type memoryGeocodeCacheEntry struct {
+	result    *GeocodeResult
+	expiresAt time.Time
+}
+
+type memoryGeocodeCacheStore struct {
+	entries map[geocodeCacheKey]memoryGeocodeCacheEntry
+	failGet bool
+}
+
+func (s *memoryGeocodeCacheStore) get(ctx context.Context, key geocodeCacheKey, now time.Time) (*GeocodeResult, bool, error) {
+	if s.failGet {
+		return nil, false, errors.New("cache unavailable")
+	}
+	entry, ok := s.entries[key]
+	if !ok || !entry.expiresAt.After(now) {
+		return nil, false, nil
+	}
+	return entry.result, true, nil
+}
+
+func (s *memoryGeocodeCacheStore) put(ctx context.Context, key geocodeCacheKey, result *GeocodeResult, expiresAt time.Time) error {
+	s.entries[key] = memoryGeocodeCacheEntry{result: result, expiresAt: expiresAt}
+	return nil
+}
+
+func (s *memoryGeocodeCacheStore) purge(ctx context.Context, scope string, now time.Time) (int64, error) {
+	var purged int64
+	for key, entry := range s.entries {
+		if scope == geocodeCachePurgeAll || (scope == geocodeCachePurgeExpired && !entry.expiresAt.After(now)) || (scope == geocodeCachePurgeMisses && entry.result == nil) {
+			delete(s.entries, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (s *memoryGeocodeCacheStore) counts(ctx context.Context, now time.Time) (int64, int64, int64, error) {
+	var negative, expired int64
+	for _, entry := range s.entries {
+		if entry.result == nil {
+			negative++
+		}
+		if !entry.expiresAt.After(now) {
+			expired++
+		}
+	}
+	return int64(len(s.entries)), negative, expired, nil
+}
+
+type countingGeocoder struct {
+	calls   int
+	result  *GeocodeResult
+	results []GeocodeSearchResult
+	err     error
+}
+
+func (g *countingGeocoder) Geocode(ctx context.Context, lat, lng float64) (*GeocodeResult, error) {
+	g.calls++
+	return g.result, g.err
+}
+
+func (g *countingGeocoder) Search(ctx context.Context, query string) ([]GeocodeSearchResult, error) {
+	g.calls++
+	return g.results, g.err
+}
+
+func newTestCachingGeocoder(next Geocoder, now *time.Time) (*CachingGeocoder, *memoryGeocodeCacheStore) {
+	store := &memoryGeocodeCacheStore{entries: map[geocodeCacheKey]memoryGeocodeCacheEntry{}}
+	return &CachingGeocoder{
+		Next:        next,
+		TTL:         30 * 24 * time.Hour,
+		NegativeTTL: time.Hour,
+		Log:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		store:       store,
+		now:         func() time.Time { return *now },
+	}, store
+}
+
+func TestCachingGeocoderServesNearbyLookupsFromCache(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	next := &countingGeocoder{result: &GeocodeResult{Address: "Damrak 1", City: "Amsterdam", PostalCode: "1012 LG"}}
+	cache, _ := newTestCachingGeocoder(next, &now)
+	ctx := context.Background()
+
+	first, err := cache.Geocode(ctx, 52.376201, 4.896301)
+	if err != nil || first == nil || first.Address != "Damrak 1" {
+		t.Fatalf("unexpected first lookup: %#v, %v", first, err)
+	}
+	// A few metres away rounds to the same key.
+	second, err := cache.Geocode(ctx, 52.376189, 4.896279)
+	if err != nil || second == nil || second.City != "Amsterdam" {
+		t.Fatalf("unexpected cached lookup: %#v, %v", second, err)
+	}
+	if next.calls != 1 {
+		t.Fatalf("expected one upstream call, got %d", next.calls)
+	}
+
+	// Roughly 20 m further north is a different key.
+	if _, err := cache.Geocode(ctx, 52.3764, 4.8963); err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if next.calls != 2 {
+		t.Fatalf("expected a second upstream call, got %d", next.calls)
+	}
+
+	now = now.Add(31 * 24 * time.Hour)
+	if _, err := cache.Geocode(ctx, 52.376201, 4.896301); err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if next.calls != 3 {
+		t.Fatalf("expected an expired entry to be refreshed, got %d calls", next.calls)
+	}
+
+	stats, err := cache.Stats(ctx)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.Hits != 1 || stats.Misses != 3 || stats.Entries != 2 {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+}
+
+func TestCachingGeocoderNegativeCacheAndErrors(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	next := &countingGeocoder{}
+	cache, store := newTestCachingGeocoder(next, &now)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		result, err := cache.Geocode(ctx, 53.5, 5.0)
+		if err != nil || result != nil {
+			t.Fatalf("expected a cached miss, got %#v, %v", result, err)
+		}
+	}
+	if next.calls != 1 {
+		t.Fatalf("expected the miss to be cached, got %d calls", next.calls)
+	}
+	now = now.Add(2 * time.Hour)
+	if _, err := cache.Geocode(ctx, 53.5, 5.0); err != nil || next.calls != 2 {
+		t.Fatalf("expected the negative TTL to expire, got %d calls, %v", next.calls, err)
+	}
+
+	next.err = errors.New("upstream down")
+	if _, err := cache.Geocode(ctx, 52.0, 5.0); err == nil {
+		t.Fatalf("expected the upstream error")
+	}
+	if _, ok := store.entries[newGeocodeCacheKey(52.0, 5.0)]; ok {
+		t.Fatalf("expected errors not to be cached")
+	}
+
+	next.err = nil
+	next.result = &GeocodeResult{Address: "Oudegracht 1"}
+	store.failGet = true
+	if result, err := cache.Geocode(ctx, 52.09, 5.12); err != nil || result == nil {
+		t.Fatalf("expected a failing cache to fall through, got %#v, %v", result, err)
+	}
+
+	purged, err := cache.Purge(ctx, geocodeCachePurgeMisses)
+	if err != nil || purged != 1 {
+		t.Fatalf("expected one cached miss purged, got %d, %v", purged, err)
+	}
+	if _, err := cache.Purge(ctx, "everything"); err == nil {
+		t.Fatalf("expected an unknown purge scope to be rejected")
+	}
+}
+
+func TestAdminGeocoderPageAndPurge(t *testing.T) {
+	app, router := newAdminTestServer(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodGet, "/bikeadmin/geocoder", ""))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "GEOCODE_CACHE_TTL_DAYS=0") {
+		t.Fatalf("expected the disabled cache notice, got %d", rec.Code)
+	}
+
+	app.adminGeocodeCacheStats = func(ctx context.Context) (GeocodeCacheStats, error) {
+		return GeocodeCacheStats{Hits: 3, NegativeHits: 1, Misses: 4, Entries: 5}, nil
+	}
+	var purgedScope string
+	app.adminPurgeGeocodeCache = func(ctx context.Context, scope string) (int64, error) {
+		purgedScope = scope
+		return 2, nil
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodGet, "/bikeadmin/geocoder", ""))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "50.0%") {
+		t.Fatalf("expected the hit rate on the page, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodPost, "/bikeadmin/geocoder/purge", "scope=expired"))
+	if rec.Code != http.StatusSeeOther || purgedScope != "expired" {
+		t.Fatalf("expected purge of expired entries, got %d, %q", rec.Code, purgedScope)
+	}
+	if location := rec.Header().Get("Location"); !strings.HasPrefix(location, "/bikeadmin/geocoder?") || !strings.Contains(location, "notice=") {
+		t.Fatalf("expected notice redirect, got %q", location)
+	}
+
+	municipality := "Utrecht"
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequestWithSession(t, app, http.MethodPost, "/bikeadmin/geocoder/purge", "scope=all", OperatorSession{Email: "op@example.com", Role: "municipality_operator", Municipality: &municipality}))
+	if purgedScope == "all" {
+		t.Fatalf("expected operators not to purge the cache")
+	}
+}
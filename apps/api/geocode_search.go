@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	geocodeSearchRateLimitRequests = 20
+	geocodeSearchRateLimitWindow   = time.Minute
+	minGeocodeSearchQueryLength    = 3
+	maxGeocodeSearchQueryLength    = 200
+
+	locationSourceGPS           = "gps"
+	locationSourceAddressSearch = "address_search"
+
+	// geocodeLocationTokenTTL is how long a search result can be used to
+	// submit a report.
+	geocodeLocationTokenTTL = 30 * time.Minute
+	// geocodeLocationTokenTolerance absorbs float formatting between the
+	// search response and the report body (about 10 cm).
+	geocodeLocationTokenTolerance = 1e-6
+)
+
+// geocodeSearchHandler serves forward geocoding for manual location entry.
+// Results outside the service area are dropped, since reports there would be
+// rejected anyway.
+func (a *App) geocodeSearchHandler(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if length := utf8.RuneCountInString(query); length < minGeocodeSearchQueryLength || length > maxGeocodeSearchQueryLength {
+		writeAPIError(c, &apiError{Status: http.StatusBadRequest, Code: "invalid_query", Message: "Search query must be between 3 and 200 characters"})
+		return
+	}
+
+	if !a.checkRateLimit("geocode:"+c.ClientIP(), geocodeSearchRateLimitRequests, geocodeSearchRateLimitWindow, time.Now().UTC()) {
+		writeAPIError(c, &apiError{Status: http.StatusTooManyRequests, Code: "rate_limited", Message: "Too many searches. Please retry later."})
+		return
+	}
+
+	results, err := a.geocoder.Search(c.Request.Context(), query)
+	if err != nil {
+		a.log.Error("geocode search failed", "err", err)
+		writeAPIError(c, &apiError{Status: http.StatusBadGateway, Code: "geocoder_unavailable", Message: "Address search is unavailable"})
+		return
+	}
+
+	filtered := make([]GeocodeSearchResult, 0, len(results))
+	for _, result := range results {
+		if a.serviceArea != nil && !a.serviceArea.Contains(result.Lat, result.Lng) {
+			continue
+		}
+		token, err := a.createLocationToken(result.Lat, result.Lng, time.Now().UTC())
+		if err != nil {
+			writeAPIError(c, err)
+			return
+		}
+		result.LocationToken = token
+		filtered = append(filtered, result)
+	}
+	c.JSON(http.StatusOK, gin.H{"results": filtered})
+}
+
+// createLocationToken signs the coordinates of a search result. A report with
+// location_source address_search must send it back, so the accuracy limit is
+// only lifted for coordinates this API handed out.
+func (a *App) createLocationToken(lat, lng float64, now time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"purpose": locationSourceAddressSearch,
+		"lat":     lat,
+		"lng":     lng,
+		"iat":     now.Unix(),
+		"exp":     now.Add(geocodeLocationTokenTTL).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(a.cfg.AppSigningSecret))
+}
+
+// verifyLocationToken checks that tokenString was issued by the address
+// search for loc and has not expired.
+func (a *App) verifyLocationToken(tokenString string, loc ReportLocation) error {
+	invalid := &apiError{Status: http.StatusBadRequest, Code: "invalid_location", Message: "Address search location could not be verified"}
+	if strings.TrimSpace(tokenString) == "" {
+		return invalid
+	}
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return []byte(a.cfg.AppSigningSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return invalid
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != locationSourceAddressSearch {
+		return invalid
+	}
+	lat, latOK := claims["lat"].(float64)
+	lng, lngOK := claims["lng"].(float64)
+	if !latOK || !lngOK || math.Abs(lat-loc.Lat) > geocodeLocationTokenTolerance || math.Abs(lng-loc.Lng) > geocodeLocationTokenTolerance {
+		return invalid
+	}
+	return nil
+}
+
+// normalizeLocationSource maps an empty source to GPS and rejects unknown ones.
+func normalizeLocationSource(raw string) (string, bool) {
+	switch strings.TrimSpace(raw) {
+	case "", locationSourceGPS:
+		return locationSourceGPS, true
+	case locationSourceAddressSearch:
+		return locationSourceAddressSearch, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newGeocodeSearchTestRouter(t *testing.T, geocoder Geocoder) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	area, err := loadServiceArea("")
+	if err != nil {
+		t.Fatalf("load service area: %v", err)
+	}
+	app := &App{
+		cfg:         &Config{AppSigningSecret: "geocode-search-test-secret"},
+		log:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		geocoder:    geocoder,
+		serviceArea: area,
+		rateBuckets: make(map[string]rateBucket),
+	}
+	router := gin.New()
+	router.GET("/api/v1/geocode/search", app.geocodeSearchHandler)
+	return router
+}
+
+func geocodeSearchRequest(router *gin.Engine, query string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/geocode/search?q="+url.QueryEscape(query), nil)
+	req.RemoteAddr = "192.0.2.10:1234"
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGeocodeSearchHandlerReturnsResultsInServiceArea(t *testing.T) {
+	geocoder := &countingGeocoder{results: []GeocodeSearchResult{
+		{Label: "Damrak 1, Amsterdam", Address: "Damrak 1", City: "Amsterdam", Lat: 52.3762, Lng: 4.8963},
+		{Label: "Damrak 1, Brussel", Address: "Damrak 1", City: "Brussel", Lat: 50.8503, Lng: 4.3517},
+	}}
+	router := newGeocodeSearchTestRouter(t, geocoder)
+
+	rec := geocodeSearchRequest(router, "Damrak 1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Results []GeocodeSearchResult `json:"results"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(body.Results) != 1 || body.Results[0].City != "Amsterdam" {
+		t.Fatalf("expected only the Amsterdam result, got %#v", body.Results)
+	}
+	if body.Results[0].LocationToken == "" {
+		t.Fatalf("expected the result to carry a location token")
+	}
+
+	if rec := geocodeSearchRequest(router, " a "); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected a short query to be rejected, got %d", rec.Code)
+	}
+
+	geocoder.err = errors.New("upstream down")
+	if rec := geocodeSearchRequest(router, "Damrak 1"); rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502 when the geocoder fails, got %d", rec.Code)
+	}
+}
+
+func TestGeocodeSearchHandlerIsRateLimited(t *testing.T) {
+	router := newGeocodeSearchTestRouter(t, &countingGeocoder{})
+
+	for i := 0; i < geocodeSearchRateLimitRequests; i++ {
+		if rec := geocodeSearchRequest(router, "Oudegracht"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i+1, rec.Code)
+		}
+	}
+	if rec := geocodeSearchRequest(router, "Oudegracht"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 after %d searches, got %d", geocodeSearchRateLimitRequests, rec.Code)
+	}
+}
+
+func TestValidateReportCreatePayloadLocationSource(t *testing.T) {
+	payload := ReportCreatePayload{
+		Location:       ReportLocation{Lat: 52.0907, Lng: 5.1214, AccuracyM: 5000},
+		LocationSource: locationSourceAddressSearch,
+		Tags:           []string{"flat_tires"},
+	}
+	if err := validateReportCreatePayload(payload, 3000); err != nil {
+		t.Fatalf("expected an address search to skip the accuracy limit: %v", err)
+	}
+
+	payload.LocationSource = locationSourceGPS
+	if err := validateReportCreatePayload(payload, 3000); err == nil {
+		t.Fatalf("expected a GPS location above the accuracy limit to be rejected")
+	}
+
+	payload.Location.AccuracyM = 10
+	payload.LocationSource = "map_pin"
+	var apiErr *apiError
+	if err := validateReportCreatePayload(payload, 3000); !errors.As(err, &apiErr) || apiErr.Code != "invalid_location" {
+		t.Fatalf("expected an unknown location source to be rejected, got %v", err)
+	}
+}
+
+func TestLocationTokenBindsSearchCoordinates(t *testing.T) {
+	app := &App{cfg: &Config{AppSigningSecret: "geocode-search-test-secret"}}
+	now := time.Now().UTC()
+	token, err := app.createLocationToken(52.0907, 5.1214, now)
+	if err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+
+	if err := app.verifyLocationToken(token, ReportLocation{Lat: 52.0907, Lng: 5.1214, AccuracyM: 5000}); err != nil {
+		t.Fatalf("expected the issued coordinates to verify: %v", err)
+	}
+	if err := app.verifyLocationToken(token, ReportLocation{Lat: 52.3702, Lng: 4.8952}); err == nil {
+		t.Fatalf("expected other coordinates to be rejected")
+	}
+	if err := app.verifyLocationToken("", ReportLocation{Lat: 52.0907, Lng: 5.1214}); err == nil {
+		t.Fatalf("expected a missing token to be rejected")
+	}
+
+	expired, err := app.createLocationToken(52.0907, 5.1214, now.Add(-2*geocodeLocationTokenTTL))
+	if err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+	if err := app.verifyLocationToken(expired, ReportLocation{Lat: 52.0907, Lng: 5.1214}); err == nil {
+		t.Fatalf("expected an expired token to be rejected")
+	}
+
+	other := &App{cfg: &Config{AppSigningSecret: "another-secret"}}
+	if err := other.verifyLocationToken(token, ReportLocation{Lat: 52.0907, Lng: 5.1214}); err == nil {
+		t.Fatalf("expected a token signed with another secret to be rejected")
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -18,9 +20,25 @@ type GeocodeResult struct {
 	PostalCode string
 }
 
-// Geocoder abstraction for address lookup
+// GeocodeSearchResult is a location found for an address query
+type GeocodeSearchResult struct {
+	Label      string  `json:"label"`
+	Address    string  `json:"address"`
+	City       string  `json:"city"`
+	PostalCode string  `json:"postal_code"`
+	Lat        float64 `json:"lat"`
+	Lng        float64 `json:"lng"`
+	// LocationToken is set by the search endpoint, see createLocationToken.
+	LocationToken string `json:"location_token,omitempty"`
+}
+
+// geocodeSearchLimit caps the number of results of a forward search
+const geocodeSearchLimit = 5
+
+// Geocoder abstraction for address lookup (reverse) and address search (forward)
 type Geocoder interface {
 	Geocode(ctx context.Context, lat, lng float64) (*GeocodeResult, error)
+	Search(ctx context.Context, query string) ([]GeocodeSearchResult, error)
 }
 
 // MapboxGeocoder implements Geocoder using Mapbox API v6
@@ -85,6 +103,77 @@ func (g *MapboxGeocoder) Geocode(ctx context.Context, lat, lng float64) (*Geocod
 	}, nil
 }
 
+// Search looks up addresses in the Netherlands via Mapbox forward geocoding
+func (g *MapboxGeocoder) Search(ctx context.Context, query string) ([]GeocodeSearchResult, error) {
+	if g.AccessToken == "" {
+		return nil, errors.New("mapbox access token missing")
+	}
+
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("country", "nl")
+	params.Set("language", "nl")
+	params.Set("limit", strconv.Itoa(geocodeSearchLimit))
+	params.Set("access_token", g.AccessToken)
+	u := "https://api.mapbox.com/search/geocode/v6/forward?" + params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("mapbox error (%d): %s", resp.StatusCode, string(body))
+	}
+
+	var data struct {
+		Features []struct {
+			Geometry struct {
+				Coordinates []float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties struct {
+				Name        string `json:"name"`
+				FullAddress string `json:"full_address"`
+				Context     struct {
+					Place struct {
+						Name string `json:"name"`
+					} `json:"place"`
+					Postcode struct {
+						Name string `json:"name"`
+					} `json:"postcode"`
+				} `json:"context"`
+			} `json:"properties"`
+		} `json:"features"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	results := make([]GeocodeSearchResult, 0, len(data.Features))
+	for _, feat := range data.Features {
+		if len(feat.Geometry.Coordinates) < 2 {
+			continue
+		}
+		results = append(results, GeocodeSearchResult{
+			Label:      feat.Properties.FullAddress,
+			Address:    feat.Properties.Name,
+			City:       feat.Properties.Context.Place.Name,
+			PostalCode: feat.Properties.Context.Postcode.Name,
+			Lat:        feat.Geometry.Coordinates[1],
+			Lng:        feat.Geometry.Coordinates[0],
+		})
+	}
+	return results, nil
+}
+
 // NominatimGeocoder implements Geocoder using OSM Nominatim
 // CAUTION: Requires User-Agent and has strict rate limits (1 req/sec)
 type NominatimGeocoder struct {
@@ -94,7 +183,8 @@ type NominatimGeocoder struct {
 	lastCall  time.Time
 }
 
-func (g *NominatimGeocoder) Geocode(ctx context.Context, lat, lng float64) (*GeocodeResult, error) {
+// throttle keeps calls at least a second apart, as the Nominatim usage policy requires
+func (g *NominatimGeocoder) throttle() {
 	g.mu.Lock()
 	elapsed := time.Since(g.lastCall)
 	if elapsed < time.Second {
@@ -102,6 +192,10 @@ func (g *NominatimGeocoder) Geocode(ctx context.Context, lat, lng float64) (*Geo
 	}
 	g.lastCall = time.Now()
 	g.mu.Unlock()
+}
+
+func (g *NominatimGeocoder) Geocode(ctx context.Context, lat, lng float64) (*GeocodeResult, error) {
+	g.throttle()
 
 	// Nominatim uses lon/lat order in URL but params are lat/lon
 	u := fmt.Sprintf("https://nominatim.openstreetmap.org/reverse?format=jsonv2&lat=%f&lon=%f&addressdetails=1", lat, lng)
@@ -161,17 +255,83 @@ func (g *NominatimGeocoder) Geocode(ctx context.Context, lat, lng float64) (*Geo
 	}, nil
 }
 
-// FallbackGeocoder prioritizes first, falls back to second
-type FallbackGeocoder struct {
-	Primary   Geocoder
-	Secondary Geocoder
-}
+// Search looks up addresses in the Netherlands via Nominatim
+func (g *NominatimGeocoder) Search(ctx context.Context, query string) ([]GeocodeSearchResult, error) {
+	g.throttle()
+
+	params := url.Values{}
+	params.Set("format", "jsonv2")
+	params.Set("q", query)
+	params.Set("countrycodes", "nl")
+	params.Set("addressdetails", "1")
+	params.Set("limit", strconv.Itoa(geocodeSearchLimit))
+	u := "https://nominatim.openstreetmap.org/search?" + params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", g.UserAgent)
+
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nominatim error: %d", resp.StatusCode)
+	}
+
+	var data []struct {
+		Lat         string `json:"lat"`
+		Lon         string `json:"lon"`
+		DisplayName string `json:"display_name"`
+		Address     struct {
+			Road        string `json:"road"`
+			HouseNumber string `json:"house_number"`
+			City        string `json:"city"`
+			Town        string `json:"town"`
+			Village     string `json:"village"`
+			Postcode    string `json:"postcode"`
+		} `json:"address"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	results := make([]GeocodeSearchResult, 0, len(data))
+	for _, item := range data {
+		lat, err := strconv.ParseFloat(item.Lat, 64)
+		if err != nil {
+			continue
+		}
+		lng, err := strconv.ParseFloat(item.Lon, 64)
+		if err != nil {
+			continue
+		}
+
+		city := item.Address.City
+		if city == "" {
+			city = item.Address.Town
+		}
+		if city == "" {
+			city = item.Address.Village
+		}
+		addr := item.Address.Road
+		if item.Address.HouseNumber != "" {
+			addr = fmt.Sprintf("%s %s", addr, item.Address.HouseNumber)
+		}
 
-func (g *FallbackGeocoder) Geocode(ctx context.Context, lat, lng float64) (*GeocodeResult, error) {
-	res, err := g.Primary.Geocode(ctx, lat, lng)
-	if err != nil || res == nil {
-		// Log error if primary failed? For now just fallback
-		return g.Secondary.Geocode(ctx, lat, lng)
+		results = append(results, GeocodeSearchResult{
+			Label:      item.DisplayName,
+			Address:    addr,
+			City:       city,
+			PostalCode: item.Address.Postcode,
+			Lat:        lat,
+			Lng:        lng,
+		})
 	}
-	return res, nil
+	return results, nil
 }
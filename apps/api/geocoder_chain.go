@@ -0,0 +1,394 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	geocodeOperationReverse = "reverse"
+	geocodeOperationSearch  = "search"
+
+	geocodeOutcomeSuccess     = "success"
+	geocodeOutcomeNotFound    = "not_found"
+	geocodeOutcomeError       = "error"
+	geocodeOutcomeTimeout     = "timeout"
+	geocodeOutcomeCircuitOpen = "circuit_open"
+
+	circuitStateClosed   = "closed"
+	circuitStateOpen     = "open"
+	circuitStateHalfOpen = "half_open"
+
+	defaultGeocoderBreakerFailures = 5
+	defaultGeocoderBreakerCooldown = time.Minute
+	geocodeRecentLookupsLimit      = 50
+)
+
+var (
+	geocodeOperations = []string{geocodeOperationReverse, geocodeOperationSearch}
+	geocodeOutcomes   = []string{geocodeOutcomeSuccess, geocodeOutcomeNotFound, geocodeOutcomeError, geocodeOutcomeTimeout, geocodeOutcomeCircuitOpen}
+
+	// defaultGeocoderProviders is the chain used when GEOCODER_PROVIDER is empty.
+	defaultGeocoderProviders = []string{"mapbox", "nominatim"}
+	// defaultGeocoderTimeouts also lists every provider name the chain accepts.
+	defaultGeocoderTimeouts = map[string]time.Duration{
+		"mapbox":    3 * time.Second,
+		"nominatim": 5 * time.Second,
+		"local":     time.Second,
+	}
+
+	errGeocoderCircuitOpen = errors.New("every geocoder provider is unavailable")
+)
+
+// parseGeocoderProviders parses a comma-separated provider chain such as
+// "mapbox,nominatim". An empty value selects the default chain.
+func parseGeocoderProviders(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return append([]string(nil), defaultGeocoderProviders...), nil
+	}
+	providers := make([]string, 0)
+	for _, part := range strings.Split(raw, ",") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" {
+			continue
+		}
+		if _, ok := defaultGeocoderTimeouts[name]; !ok {
+			return nil, fmt.Errorf("GEOCODER_PROVIDER contains unknown provider %q (expected %s)", name, strings.Join(knownGeocoderProviders(), ", "))
+		}
+		if containsString(providers, name) {
+			return nil, fmt.Errorf("GEOCODER_PROVIDER lists %q more than once", name)
+		}
+		providers = append(providers, name)
+	}
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("GEOCODER_PROVIDER must name at least one provider")
+	}
+	return providers, nil
+}
+
+func knownGeocoderProviders() []string {
+	names := make([]string, 0, len(defaultGeocoderTimeouts))
+	for name := range defaultGeocoderTimeouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// circuitBreaker opens after threshold consecutive failures and rejects calls
+// until cooldown has passed. It then lets one trial call through: success
+// closes it again, failure re-opens it for another cooldown. A threshold of
+// zero never opens.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openUntil.IsZero() {
+		return true
+	}
+	if now.Before(b.openUntil) || b.trial {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+func (b *circuitBreaker) record(success bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if success {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		return
+	}
+	b.failures++
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openUntil = now.Add(b.cooldown)
+	}
+}
+
+// release ends a call without an outcome, e.g. when the caller cancelled it.
+// A half-open breaker lets the next call through as the trial instead.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+func (b *circuitBreaker) state(now time.Time) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.openUntil.IsZero():
+		return circuitStateClosed
+	case now.Before(b.openUntil):
+		return circuitStateOpen
+	default:
+		return circuitStateHalfOpen
+	}
+}
+
+type geocodeCounterKey struct {
+	Operation string
+	Outcome   string
+}
+
+type geocoderChainProvider struct {
+	name     string
+	geocoder Geocoder
+	timeout  time.Duration
+	breaker  *circuitBreaker
+	// counters is filled once at construction and only read afterwards.
+	counters map[geocodeCounterKey]*atomic.Uint64
+}
+
+func (p *geocoderChainProvider) count(operation, outcome string) {
+	p.counters[geocodeCounterKey{Operation: operation, Outcome: outcome}].Add(1)
+}
+
+// GeocodeLookup describes one lookup through the chain for the admin status
+// page. Provider is empty when no provider found anything.
+type GeocodeLookup struct {
+	At        time.Time
+	Operation string
+	Provider  string
+	Outcome   string
+	Duration  time.Duration
+	// Attempts lists the providers that were skipped or failed first, as
+	// "name (outcome)".
+	Attempts []string
+}
+
+// GeocoderProviderStatus is the health of one provider in the chain.
+type GeocoderProviderStatus struct {
+	Name         string
+	Timeout      time.Duration
+	BreakerState string
+	// Counts sums both operations per outcome.
+	Counts map[string]uint64
+}
+
+// GeocoderChainStatus is the state shown on the admin geocoder page.
+type GeocoderChainStatus struct {
+	Providers []GeocoderProviderStatus
+	// Recent lists the latest lookups, newest first.
+	Recent []GeocodeLookup
+}
+
+// GeocoderChain tries providers in order until one finds a result. Each call
+// gets the provider's own timeout, and a provider that keeps failing is
+// skipped by its circuit breaker until the cooldown has passed. Every outcome
+// is counted per provider and logged when a provider fails.
+type GeocoderChain struct {
+	Log *slog.Logger
+
+	providers []*geocoderChainProvider
+	breakerAt int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	recentMu sync.Mutex
+	recent   []GeocodeLookup
+}
+
+func newGeocoderChain(logger *slog.Logger, breakerFailures int, breakerCooldown time.Duration) *GeocoderChain {
+	return &GeocoderChain{
+		Log:       logger,
+		breakerAt: breakerFailures,
+		cooldown:  breakerCooldown,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// add appends a provider to the end of the chain. It is not safe to call
+// once the chain is in use.
+func (c *GeocoderChain) add(name string, geocoder Geocoder, timeout time.Duration) {
+	counters := make(map[geocodeCounterKey]*atomic.Uint64, len(geocodeOperations)*len(geocodeOutcomes))
+	for _, operation := range geocodeOperations {
+		for _, outcome := range geocodeOutcomes {
+			counters[geocodeCounterKey{Operation: operation, Outcome: outcome}] = &atomic.Uint64{}
+		}
+	}
+	c.providers = append(c.providers, &geocoderChainProvider{
+		name:     name,
+		geocoder: geocoder,
+		timeout:  timeout,
+		breaker:  &circuitBreaker{threshold: c.breakerAt, cooldown: c.cooldown},
+		counters: counters,
+	})
+}
+
+func (c *GeocoderChain) Geocode(ctx context.Context, lat, lng float64) (*GeocodeResult, error) {
+	var result *GeocodeResult
+	err := c.run(ctx, geocodeOperationReverse, func(ctx context.Context, geocoder Geocoder) (bool, error) {
+		res, err := geocoder.Geocode(ctx, lat, lng)
+		if err != nil {
+			return false, err
+		}
+		result = res
+		return res != nil, nil
+	})
+	return result, err
+}
+
+func (c *GeocoderChain) Search(ctx context.Context, query string) ([]GeocodeSearchResult, error) {
+	var results []GeocodeSearchResult
+	err := c.run(ctx, geocodeOperationSearch, func(ctx context.Context, geocoder Geocoder) (bool, error) {
+		res, err := geocoder.Search(ctx, query)
+		if err != nil {
+			return false, err
+		}
+		results = res
+		return len(res) > 0, nil
+	})
+	return results, err
+}
+
+// run calls providers in order until call reports a result. When none does,
+// provider errors win over "not found", so a lookup that failed somewhere is
+// retried later instead of being cached as empty.
+func (c *GeocoderChain) run(ctx context.Context, operation string, call func(ctx context.Context, geocoder Geocoder) (bool, error)) error {
+	started := c.now()
+	lookup := GeocodeLookup{At: started, Operation: operation, Outcome: geocodeOutcomeNotFound}
+	var errs []error
+	notFound := false
+
+	for _, provider := range c.providers {
+		if !provider.breaker.allow(c.now()) {
+			provider.count(operation, geocodeOutcomeCircuitOpen)
+			lookup.Attempts = append(lookup.Attempts, provider.name+" ("+geocodeOutcomeCircuitOpen+")")
+			continue
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, provider.timeout)
+		found, err := call(callCtx, provider.geocoder)
+		cancel()
+
+		if err != nil && ctx.Err() != nil {
+			// The caller gave up; that says nothing about the provider.
+			provider.breaker.release()
+			errs = append(errs, fmt.Errorf("%s: %w", provider.name, err))
+			break
+		}
+		if err != nil {
+			outcome := geocodeOutcomeError
+			if errors.Is(err, context.DeadlineExceeded) {
+				outcome = geocodeOutcomeTimeout
+			}
+			provider.count(operation, outcome)
+			provider.breaker.record(false, c.now())
+			lookup.Attempts = append(lookup.Attempts, provider.name+" ("+outcome+")")
+			c.Log.Warn("geocoder provider failed", "provider", provider.name, "operation", operation, "outcome", outcome, "err", err)
+			errs = append(errs, fmt.Errorf("%s: %w", provider.name, err))
+			continue
+		}
+
+		provider.breaker.record(true, c.now())
+		if !found {
+			provider.count(operation, geocodeOutcomeNotFound)
+			lookup.Attempts = append(lookup.Attempts, provider.name+" ("+geocodeOutcomeNotFound+")")
+			notFound = true
+			continue
+		}
+
+		provider.count(operation, geocodeOutcomeSuccess)
+		lookup.Provider = provider.name
+		lookup.Outcome = geocodeOutcomeSuccess
+		c.remember(lookup, started)
+		return nil
+	}
+
+	var err error
+	switch {
+	case len(errs) > 0:
+		lookup.Outcome = geocodeOutcomeError
+		err = errors.Join(errs...)
+	case !notFound:
+		lookup.Outcome = geocodeOutcomeCircuitOpen
+		err = errGeocoderCircuitOpen
+	}
+	c.remember(lookup, started)
+	return err
+}
+
+func (c *GeocoderChain) remember(lookup GeocodeLookup, started time.Time) {
+	lookup.Duration = c.now().Sub(started)
+	c.recentMu.Lock()
+	defer c.recentMu.Unlock()
+	c.recent = append(c.recent, lookup)
+	if len(c.recent) > geocodeRecentLookupsLimit {
+		c.recent = append([]GeocodeLookup(nil), c.recent[len(c.recent)-geocodeRecentLookupsLimit:]...)
+	}
+}
+
+// Status returns the provider health and the latest lookups.
+func (c *GeocoderChain) Status() GeocoderChainStatus {
+	now := c.now()
+	status := GeocoderChainStatus{Providers: make([]GeocoderProviderStatus, 0, len(c.providers))}
+	for _, provider := range c.providers {
+		counts := make(map[string]uint64, len(geocodeOutcomes))
+		for key, counter := range provider.counters {
+			counts[key.Outcome] += counter.Load()
+		}
+		status.Providers = append(status.Providers, GeocoderProviderStatus{
+			Name:         provider.name,
+			Timeout:      provider.timeout,
+			BreakerState: provider.breaker.state(now),
+			Counts:       counts,
+		})
+	}
+
+	c.recentMu.Lock()
+	status.Recent = make([]GeocodeLookup, 0, len(c.recent))
+	for i := len(c.recent) - 1; i >= 0; i-- {
+		status.Recent = append(status.Recent, c.recent[i])
+	}
+	c.recentMu.Unlock()
+	return status
+}
+
+// WritePrometheus writes the counters and breaker states in the Prometheus
+// text exposition format.
+func (c *GeocoderChain) WritePrometheus(w io.Writer) error {
+	now := c.now()
+	var b strings.Builder
+	b.WriteString("# HELP zwerffiets_geocoder_requests_total Geocoder provider calls by operation and outcome.\n")
+	b.WriteString("# TYPE zwerffiets_geocoder_requests_total counter\n")
+	for _, provider := range c.providers {
+		for _, operation := range geocodeOperations {
+			for _, outcome := range geocodeOutcomes {
+				value := provider.counters[geocodeCounterKey{Operation: operation, Outcome: outcome}].Load()
+				fmt.Fprintf(&b, "zwerffiets_geocoder_requests_total{provider=%q,operation=%q,outcome=%q} %d\n", provider.name, operation, outcome, value)
+			}
+		}
+	}
+	b.WriteString("# HELP zwerffiets_geocoder_circuit_open Whether the provider's circuit breaker is rejecting calls.\n")
+	b.WriteString("# TYPE zwerffiets_geocoder_circuit_open gauge\n")
+	for _, provider := range c.providers {
+		open := 0
+		if provider.breaker.state(now) == circuitStateOpen {
+			open = 1
+		}
+		fmt.Fprintf(&b, "zwerffiets_geocoder_circuit_open{provider=%q} %d\n", provider.name, open)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type funcGeocoder struct {
+	geocode func(ctx context.Context) (*GeocodeResult, error)
+}
+
+func (g *funcGeocoder) Geocode(ctx context.Context, lat, lng float64) (*GeocodeResult, error) {
+	return g.geocode(ctx)
+}
+
+func (g *funcGeocoder) Search(ctx context.Context, query string) ([]GeocodeSearchResult, error) {
+	result, err := g.geocode(ctx)
+	if err != nil || result == nil {
+		return nil, err
+	}
+	return []GeocodeSearchResult{{Label: result.Address, Address: result.Address, City: result.City}}, nil
+}
+
+func newTestGeocoderChain(now *time.Time, breakerFailures int) *GeocoderChain {
+	chain := newGeocoderChain(slog.New(slog.NewTextHandler(io.Discard, nil)), breakerFailures, time.Minute)
+	chain.now = func() time.Time { return *now }
+	return chain
+}
+
+func TestGeocoderChainFallsThroughProviders(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	chain := newTestGeocoderChain(&now, 5)
+	chain.add("mapbox", &funcGeocoder{geocode: func(ctx context.Context) (*GeocodeResult, error) {
+		return nil, errors.New("401 unauthorized")
+	}}, time.Second)
+	chain.add("nominatim", &funcGeocoder{geocode: func(ctx context.Context) (*GeocodeResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}, 10*time.Millisecond)
+	chain.add("local", &funcGeocoder{geocode: func(ctx context.Context) (*GeocodeResult, error) {
+		return &GeocodeResult{Address: "Oudegracht 1", City: "Utrecht"}, nil
+	}}, time.Second)
+
+	result, err := chain.Geocode(context.Background(), 52.09, 5.12)
+	if err != nil || result == nil || result.City != "Utrecht" {
+		t.Fatalf("expected the third provider to answer, got %#v, %v", result, err)
+	}
+	if _, err := chain.Search(context.Background(), "Oudegracht 1"); err != nil {
+		t.Fatalf("search: %v", err)
+	}
+
+	status := chain.Status()
+	if len(status.Recent) != 2 || status.Recent[0].Operation != geocodeOperationSearch || status.Recent[1].Provider != "local" {
+		t.Fatalf("unexpected recent lookups: %#v", status.Recent)
+	}
+	if got := strings.Join(status.Recent[1].Attempts, ", "); got != "mapbox (error), nominatim (timeout)" {
+		t.Fatalf("unexpected attempts: %q", got)
+	}
+	if status.Providers[0].Counts[geocodeOutcomeError] != 2 || status.Providers[1].Counts[geocodeOutcomeTimeout] != 2 || status.Providers[2].Counts[geocodeOutcomeSuccess] != 2 {
+		t.Fatalf("unexpected provider counts: %#v", status.Providers)
+	}
+
+	var metrics strings.Builder
+	if err := chain.WritePrometheus(&metrics); err != nil {
+		t.Fatalf("write metrics: %v", err)
+	}
+	if !strings.Contains(metrics.String(), `zwerffiets_geocoder_requests_total{provider="nominatim",operation="reverse",outcome="timeout"} 1`) {
+		t.Fatalf("missing timeout counter in metrics:\n%s", metrics.String())
+	}
+}
+
+func TestGeocoderChainCircuitBreaker(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	chain := newTestGeocoderChain(&now, 2)
+	primaryCalls := 0
+	primaryErr := errors.New("503 service unavailable")
+	chain.add("mapbox", &funcGeocoder{geocode: func(ctx context.Context) (*GeocodeResult, error) {
+		primaryCalls++
+		if primaryErr != nil {
+			return nil, primaryErr
+		}
+		return &GeocodeResult{Address: "Damrak 1"}, nil
+	}}, time.Second)
+	chain.add("nominatim", &funcGeocoder{geocode: func(ctx context.Context) (*GeocodeResult, error) {
+		return nil, nil
+	}}, time.Second)
+	ctx := context.Background()
+
+	// Errors win over "not found", so failed lookups are not cached as empty.
+	if _, err := chain.Geocode(ctx, 52.37, 4.89); err == nil {
+		t.Fatalf("expected the provider error")
+	}
+	_, _ = chain.Geocode(ctx, 52.37, 4.89)
+	if result, err := chain.Geocode(ctx, 52.37, 4.89); err != nil || result != nil || primaryCalls != 2 {
+		t.Fatalf("expected the open breaker to skip mapbox, got %#v, %v after %d calls", result, err, primaryCalls)
+	}
+	if state := chain.Status().Providers[0].BreakerState; state != circuitStateOpen {
+		t.Fatalf("expected an open breaker, got %s", state)
+	}
+
+	now = now.Add(2 * time.Minute)
+	primaryErr = nil
+	if result, err := chain.Geocode(ctx, 52.37, 4.89); err != nil || result == nil || primaryCalls != 3 {
+		t.Fatalf("expected a successful trial call after the cooldown, got %#v, %v", result, err)
+	}
+	if state := chain.Status().Providers[0].BreakerState; state != circuitStateClosed {
+		t.Fatalf("expected the breaker to close again, got %s", state)
+	}
+
+	onlyMapbox := newTestGeocoderChain(&now, 1)
+	onlyMapbox.add("mapbox", &funcGeocoder{geocode: func(ctx context.Context) (*GeocodeResult, error) {
+		return nil, errors.New("down")
+	}}, time.Second)
+	_, _ = onlyMapbox.Geocode(ctx, 52.37, 4.89)
+	if _, err := onlyMapbox.Geocode(ctx, 52.37, 4.89); !errors.Is(err, errGeocoderCircuitOpen) {
+		t.Fatalf("expected errGeocoderCircuitOpen when every provider is skipped, got %v", err)
+	}
+}
+
+func TestGeocoderChainIgnoresCallerCancellation(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	chain := newTestGeocoderChain(&now, 1)
+	fallbackCalls := 0
+	chain.add("mapbox", &funcGeocoder{geocode: func(ctx context.Context) (*GeocodeResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}, time.Second)
+	chain.add("nominatim", &funcGeocoder{geocode: func(ctx context.Context) (*GeocodeResult, error) {
+		fallbackCalls++
+		return nil, nil
+	}}, time.Second)
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := chain.Geocode(ctx, 52.37, 4.89); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected the cancellation to be returned, got %v", err)
+		}
+	}
+
+	status := chain.Status()
+	if state := status.Providers[0].BreakerState; state != circuitStateClosed {
+		t.Fatalf("expected cancelled calls to leave the breaker closed, got %s", state)
+	}
+	if status.Providers[0].Counts[geocodeOutcomeError] != 0 || fallbackCalls != 0 {
+		t.Fatalf("expected no failures counted and no fallback calls, got %#v and %d calls", status.Providers[0].Counts, fallbackCalls)
+	}
+}
+
+func TestParseGeocoderProviders(t *testing.T) {
+	providers, err := parseGeocoderProviders("")
+	if err != nil || strings.Join(providers, ",") != "mapbox,nominatim" {
+		t.Fatalf("expected the default chain, got %v, %v", providers, err)
+	}
+	providers, err = parseGeocoderProviders(" Nominatim , mapbox ")
+	if err != nil || strings.Join(providers, ",") != "nominatim,mapbox" {
+		t.Fatalf("expected the configured order, got %v, %v", providers, err)
+	}
+	if _, err := parseGeocoderProviders("mapbox,google"); err == nil {
+		t.Fatalf("expected an unknown provider to be rejected")
+	}
+	if _, err := parseGeocoderProviders("mapbox,mapbox"); err == nil {
+		t.Fatalf("expected a duplicate provider to be rejected")
+	}
+}
+
+func TestMetricsHandlerRequiresToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app := &App{
+		cfg: &Config{},
+		log: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		writeMetrics: func(w io.Writer) error {
+			_, err := io.WriteString(w, "zwerffiets_test 1\n")
+			return err
+		},
+	}
+	router := gin.New()
+	router.GET("/metrics", app.metricsHandler)
+
+	request := func(token string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := request("secret"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without METRICS_TOKEN, got %d", rec.Code)
+	}
+	app.cfg.MetricsToken = "secret"
+	if rec := request("wrong"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with a wrong token, got %d", rec.Code)
+	}
+	if rec := request("secret"); rec.Code != http.StatusOK || rec.Body.String() != "zwerffiets_test 1\n" {
+		t.Fatalf("expected metrics, got %d: %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAdminGeocoderPageShowsProviders(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	app.adminGeocoderStatus = func() GeocoderChainStatus {
+		return GeocoderChainStatus{
+			Providers: []GeocoderProviderStatus{{Name: "mapbox", Timeout: 3 * time.Second, BreakerState: circuitStateOpen, Counts: map[string]uint64{geocodeOutcomeError: 5}}},
+			Recent: []GeocodeLookup{{
+				At:        time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC),
+				Operation: geocodeOperationReverse,
+				Provider:  "nominatim",
+				Outcome:   geocodeOutcomeSuccess,
+				Attempts:  []string{"mapbox (circuit_open)"},
+			}},
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodGet, "/bikeadmin/geocoder", ""))
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, "mapbox (circuit_open)") || !strings.Contains(body, "<td>nominatim</td>") {
+		t.Fatalf("expected providers and recent lookups on the page, got %d", rec.Code)
+	}
+}
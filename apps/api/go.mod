@@ -8,9 +8,11 @@ require (
 	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/google/uuid v1.6.0
 	github.com/jackc/pgx/v5 v5.7.1
+	github.com/jdeng/goheif v0.0.0-20241115163857-e2bbb197c985
 	github.com/resend/resend-go/v2 v2.14.0
 	github.com/stretchr/testify v1.9.0
 	golang.org/x/crypto v0.28.0
+	golang.org/x/image v0.18.0
 	zwerffiets/libs/mailer v0.0.0
 )
 
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	hotspotEpsMeters      = 50.0
+	hotspotMinReports     = 5
+	hotspotLookbackDays   = 365
+	hotspotRecurrenceDays = 30.0
+	metersPerDegreeLat    = 111320.0
+)
+
+// Hotspot is a cluster of stray-bike reports that keeps coming back at the
+// same spot, found by the detect-hotspots job.
+type Hotspot struct {
+	ID           int     `json:"id"`
+	Municipality string  `json:"municipality"`
+	CenterLat    float64 `json:"centerLat"`
+	CenterLng    float64 `json:"centerLng"`
+	RadiusMeters float64 `json:"radiusM"`
+	ReportCount  int     `json:"reportCount"`
+	// BikeGroupCount is the number of distinct bikes (bike groups) in the area.
+	BikeGroupCount int `json:"bikeGroupCount"`
+	// RecurrenceRate is the number of bike groups per 30 days over the analysis window.
+	RecurrenceRate        float64  `json:"recurrenceRate"`
+	MedianResolutionHours *float64 `json:"medianResolutionHours"`
+	FirstReportAt         string   `json:"firstReportAt"`
+	LastReportAt          string   `json:"lastReportAt"`
+	ComputedAt            string   `json:"computedAt"`
+}
+
+type hotspotPoint struct {
+	ReportID    int
+	BikeGroupID int
+	Lat         float64
+	Lng         float64
+	CreatedAt   time.Time
+	ResolvedAt  *time.Time
+}
+
+// dbscanClusters groups points with DBSCAN: a point with at least minPoints
+// neighbours (itself included) within epsMeters seeds a cluster, which grows
+// through the neighbourhoods of its core points. Noise points are dropped.
+// Each cluster is returned as a list of indexes into points.
+func dbscanClusters(points []hotspotPoint, epsMeters float64, minPoints int) [][]int {
+	if len(points) == 0 || minPoints < 1 {
+		return nil
+	}
+
+	order := make([]int, len(points))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return points[order[i]].Lat < points[order[j]].Lat })
+	epsLat := epsMeters / metersPerDegreeLat
+
+	neighbours := func(idx int) []int {
+		origin := points[idx]
+		start := sort.Search(len(order), func(i int) bool { return points[order[i]].Lat >= origin.Lat-epsLat })
+		result := make([]int, 0)
+		for i := start; i < len(order); i++ {
+			candidate := points[order[i]]
+			if candidate.Lat > origin.Lat+epsLat {
+				break
+			}
+			if haversineMeters(origin.Lat, origin.Lng, candidate.Lat, candidate.Lng) <= epsMeters {
+				result = append(result, order[i])
+			}
+		}
+		return result
+	}
+
+	const (
+		unvisited = 0
+		noise     = -1
+	)
+	labels := make([]int, len(points))
+	clusterCount := 0
+	for i := range points {
+		if labels[i] != unvisited {
+			continue
+		}
+		seeds := neighbours(i)
+		if len(seeds) < minPoints {
+			labels[i] = noise
+			continue
+		}
+
+		clusterCount++
+		labels[i] = clusterCount
+		for k := 0; k < len(seeds); k++ {
+			j := seeds[k]
+			if labels[j] == noise {
+				labels[j] = clusterCount
+			}
+			if labels[j] != unvisited {
+				continue
+			}
+			labels[j] = clusterCount
+			if expansion := neighbours(j); len(expansion) >= minPoints {
+				seeds = append(seeds, expansion...)
+			}
+		}
+	}
+
+	clusters := make([][]int, clusterCount)
+	for i, label := range labels {
+		if label > 0 {
+			clusters[label-1] = append(clusters[label-1], i)
+		}
+	}
+	return clusters
+}
+
+// summarizeHotspot computes the stored statistics for one cluster.
+func summarizeHotspot(municipality string, cluster []hotspotPoint, windowDays int) Hotspot {
+	hotspot := Hotspot{Municipality: municipality, ReportCount: len(cluster)}
+	if len(cluster) == 0 {
+		return hotspot
+	}
+
+	groups := make(map[int]struct{}, len(cluster))
+	resolutionHours := make([]float64, 0, len(cluster))
+	first := cluster[0].CreatedAt
+	last := cluster[0].CreatedAt
+	for _, point := range cluster {
+		hotspot.CenterLat += point.Lat
+		hotspot.CenterLng += point.Lng
+		groups[point.BikeGroupID] = struct{}{}
+		if point.CreatedAt.Before(first) {
+			first = point.CreatedAt
+		}
+		if point.CreatedAt.After(last) {
+			last = point.CreatedAt
+		}
+		if point.ResolvedAt != nil && !point.ResolvedAt.Before(point.CreatedAt) {
+			resolutionHours = append(resolutionHours, point.ResolvedAt.Sub(point.CreatedAt).Hours())
+		}
+	}
+	hotspot.CenterLat /= float64(len(cluster))
+	hotspot.CenterLng /= float64(len(cluster))
+
+	for _, point := range cluster {
+		distance := haversineMeters(hotspot.CenterLat, hotspot.CenterLng, point.Lat, point.Lng)
+		hotspot.RadiusMeters = math.Max(hotspot.RadiusMeters, distance)
+	}
+	hotspot.RadiusMeters = math.Round(hotspot.RadiusMeters*10) / 10
+
+	hotspot.BikeGroupCount = len(groups)
+	if windowDays > 0 {
+		rate := float64(hotspot.BikeGroupCount) / (float64(windowDays) / hotspotRecurrenceDays)
+		hotspot.RecurrenceRate = math.Round(rate*100) / 100
+	}
+	if median, ok := medianFloat(resolutionHours); ok {
+		rounded := math.Round(median*10) / 10
+		hotspot.MedianResolutionHours = &rounded
+	}
+	hotspot.FirstReportAt = first.UTC().Format(time.RFC3339)
+	hotspot.LastReportAt = last.UTC().Format(time.RFC3339)
+	return hotspot
+}
+
+// detectHotspots clusters the points of every municipality separately and
+// returns the hotspots ordered by municipality and report count.
+func detectHotspots(pointsByMunicipality map[string][]hotspotPoint, epsMeters float64, minPoints int, windowDays int) []Hotspot {
+	hotspots := make([]Hotspot, 0)
+	for municipality, points := range pointsByMunicipality {
+		for _, cluster := range dbscanClusters(points, epsMeters, minPoints) {
+			members := make([]hotspotPoint, 0, len(cluster))
+			for _, idx := range cluster {
+				members = append(members, points[idx])
+			}
+			hotspots = append(hotspots, summarizeHotspot(municipality, members, windowDays))
+		}
+	}
+	sort.Slice(hotspots, func(i, j int) bool {
+		if hotspots[i].Municipality != hotspots[j].Municipality {
+			return hotspots[i].Municipality < hotspots[j].Municipality
+		}
+		return hotspots[i].ReportCount > hotspots[j].ReportCount
+	})
+	return hotspots
+}
+
+func medianFloat(values []float64) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid], true
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2, true
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func hotspotTestPoints(baseLat, baseLng float64, count int, groupOffset int, created time.Time) []hotspotPoint {
+	points := make([]hotspotPoint, 0, count)
+	for i := 0; i < count; i++ {
+		points = append(points, hotspotPoint{
+			ReportID:    groupOffset + i,
+			BikeGroupID: groupOffset + i,
+			// ~5 m apart, well within the 50 m eps.
+			Lat:       baseLat + float64(i)*0.00005,
+			Lng:       baseLng,
+			CreatedAt: created.Add(time.Duration(i) * 24 * time.Hour),
+		})
+	}
+	return points
+}
+
+func TestDBSCANClustersFindsDenseAreasAndDropsNoise(t *testing.T) {
+	created := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	points := hotspotTestPoints(52.09, 5.12, 6, 100, created)
+	points = append(points, hotspotTestPoints(52.10, 5.13, 5, 200, created)...)
+	// Isolated report ~1 km away.
+	points = append(points, hotspotPoint{ReportID: 300, BikeGroupID: 300, Lat: 52.08, Lng: 5.12, CreatedAt: created})
+
+	clusters := dbscanClusters(points, hotspotEpsMeters, hotspotMinReports)
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(clusters))
+	}
+	sizes := map[int]bool{len(clusters[0]): true, len(clusters[1]): true}
+	if !sizes[6] || !sizes[5] {
+		t.Fatalf("unexpected cluster sizes: %d and %d", len(clusters[0]), len(clusters[1]))
+	}
+	for _, cluster := range clusters {
+		for _, idx := range cluster {
+			if points[idx].ReportID == 300 {
+				t.Fatalf("expected isolated report to be noise")
+			}
+		}
+	}
+
+	if got := dbscanClusters(points[:4], hotspotEpsMeters, hotspotMinReports); len(got) != 0 {
+		t.Fatalf("expected no cluster below min reports, got %d", len(got))
+	}
+}
+
+func TestSummarizeHotspot(t *testing.T) {
+	created := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	points := hotspotTestPoints(52.09, 5.12, 4, 1, created)
+	points[1].BikeGroupID = points[0].BikeGroupID
+	resolvedA := points[0].CreatedAt.Add(10 * time.Hour)
+	resolvedB := points[2].CreatedAt.Add(30 * time.Hour)
+	points[0].ResolvedAt = &resolvedA
+	points[2].ResolvedAt = &resolvedB
+
+	hotspot := summarizeHotspot("Utrecht", points, 90)
+	if hotspot.ReportCount != 4 || hotspot.BikeGroupCount != 3 {
+		t.Fatalf("unexpected counts: %d reports, %d groups", hotspot.ReportCount, hotspot.BikeGroupCount)
+	}
+	if hotspot.RecurrenceRate != 1 {
+		t.Fatalf("expected 3 groups over 90 days to give 1 per 30 days, got %v", hotspot.RecurrenceRate)
+	}
+	if hotspot.MedianResolutionHours == nil || *hotspot.MedianResolutionHours != 20 {
+		t.Fatalf("expected median resolution of 20h, got %v", hotspot.MedianResolutionHours)
+	}
+	if hotspot.FirstReportAt != "2026-01-01T12:00:00Z" || hotspot.LastReportAt != "2026-01-04T12:00:00Z" {
+		t.Fatalf("unexpected period: %s - %s", hotspot.FirstReportAt, hotspot.LastReportAt)
+	}
+	if hotspot.RadiusMeters <= 0 || hotspot.RadiusMeters > 20 {
+		t.Fatalf("unexpected radius: %v", hotspot.RadiusMeters)
+	}
+}
+
+func TestBuildGeoJSONIncludesHotspots(t *testing.T) {
+	hours := 12.0
+	encoded, err := buildGeoJSON([]Report{{ID: 1, PublicID: "PUB-1"}}, []Hotspot{{ID: 9, Municipality: "Utrecht", CenterLat: 52.09, CenterLng: 5.12, ReportCount: 6, MedianResolutionHours: &hours}})
+	if err != nil {
+		t.Fatalf("build geojson: %v", err)
+	}
+	var payload struct {
+		Features []struct {
+			Properties map[string]any `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal([]byte(encoded), &payload); err != nil {
+		t.Fatalf("decode geojson: %v", err)
+	}
+	if len(payload.Features) != 2 {
+		t.Fatalf("expected report and hotspot features, got %d", len(payload.Features))
+	}
+	hotspot := payload.Features[1].Properties
+	if hotspot["feature_type"] != "hotspot" || hotspot["municipality"] != "Utrecht" || hotspot["report_count"] != float64(6) {
+		t.Fatalf("unexpected hotspot properties: %#v", hotspot)
+	}
+}
+
+func TestAdminHotspotsPage_MunicipalityOperatorIsScoped(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	var capturedScope *string
+	app.adminListHotspots = func(ctx context.Context, municipality *string) ([]Hotspot, error) {
+		capturedScope = municipality
+		return []Hotspot{{ID: 3, Municipality: "Utrecht", ReportCount: 7, BikeGroupCount: 5, RecurrenceRate: 0.42, ComputedAt: "2026-02-01T03:00:00Z"}}, nil
+	}
+
+	municipality := "Utrecht"
+	rec := httptest.NewRecorder()
+	req := authenticatedRequestWithSession(t, app, http.MethodGet, "/bikeadmin/hotspots", "", OperatorSession{Email: "muni@example.com", Role: "municipality_operator", Municipality: &municipality})
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rec.Code)
+	}
+	if capturedScope == nil || *capturedScope != "Utrecht" {
+		t.Fatalf("expected hotspots scoped to Utrecht, got %v", capturedScope)
+	}
+	if !strings.Contains(rec.Body.String(), `id="hotspot-3"`) {
+		t.Fatalf("expected hotspot row in page")
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// localGeocoderMaxDistanceM is how far the nearest address may be before a
+// reverse lookup counts as not found.
+const localGeocoderMaxDistanceM = 250
+
+var postcodeQueryPattern = regexp.MustCompile(`\b(\d{4})\s+([a-z]{2})\b`)
+
+// localAddress is one row of the imported address table.
+type localAddress struct {
+	Street      string
+	HouseNumber string
+	Postcode    string
+	City        string
+	Lat         float64
+	Lng         float64
+}
+
+func (a localAddress) addressLine() string {
+	return strings.TrimSpace(a.Street + " " + a.HouseNumber)
+}
+
+// addressBox is the latitude/longitude box around a point that a reverse
+// lookup searches. LngScale shrinks longitude differences to the same scale
+// as latitude differences, so squared distances can be compared cheaply.
+type addressBox struct {
+	Lat, Lng       float64
+	MinLat, MaxLat float64
+	MinLng, MaxLng float64
+	LngScale       float64
+}
+
+func addressBoxAround(lat, lng, radiusM float64) addressBox {
+	const metersPerDegree = 111320.0
+	lngScale := math.Cos(lat * math.Pi / 180)
+	dLat := radiusM / metersPerDegree
+	dLng := radiusM / (metersPerDegree * math.Max(lngScale, 0.01))
+	return addressBox{
+		Lat: lat, Lng: lng,
+		MinLat: lat - dLat, MaxLat: lat + dLat,
+		MinLng: lng - dLng, MaxLng: lng + dLng,
+		LngScale: lngScale,
+	}
+}
+
+// addressSearchText is the lowercased text forward searches match against.
+// Postcodes are stored without the space so "3511 AA" and "3511AA" match.
+func addressSearchText(address localAddress) string {
+	postcode := strings.ReplaceAll(address.Postcode, " ", "")
+	return strings.ToLower(strings.Join(strings.Fields(strings.Join([]string{address.Street, address.HouseNumber, postcode, address.City}, " ")), " "))
+}
+
+// addressSearchTokens splits a query into lowercase tokens that must all
+// occur in the search text.
+func addressSearchTokens(query string) []string {
+	normalized := strings.ToLower(strings.NewReplacer(",", " ", ";", " ").Replace(query))
+	normalized = postcodeQueryPattern.ReplaceAllString(normalized, "$1$2")
+	return strings.Fields(normalized)
+}
+
+// addressStore reads the imported address table.
+type addressStore interface {
+	// nearest returns the address in box closest to its centre, or nil.
+	nearest(ctx context.Context, box addressBox) (*localAddress, error)
+	search(ctx context.Context, tokens []string, limit int) ([]localAddress, error)
+}
+
+// LocalGeocoder answers lookups from the address table filled by
+// import-addresses, without any network access. Reverse lookups take the
+// nearest address within localGeocoderMaxDistanceM of the point.
+type LocalGeocoder struct {
+	store addressStore
+}
+
+func newLocalGeocoder(db *sql.DB) *LocalGeocoder {
+	return &LocalGeocoder{store: &pgAddressStore{db: db}}
+}
+
+func (g *LocalGeocoder) Geocode(ctx context.Context, lat, lng float64) (*GeocodeResult, error) {
+	nearest, err := g.store.nearest(ctx, addressBoxAround(lat, lng, localGeocoderMaxDistanceM))
+	if err != nil {
+		return nil, err
+	}
+	// The box corners lie beyond the radius.
+	if nearest == nil || haversineMeters(lat, lng, nearest.Lat, nearest.Lng) > localGeocoderMaxDistanceM {
+		return nil, nil
+	}
+	return &GeocodeResult{Address: nearest.addressLine(), City: nearest.City, PostalCode: nearest.Postcode}, nil
+}
+
+func (g *LocalGeocoder) Search(ctx context.Context, query string) ([]GeocodeSearchResult, error) {
+	tokens := addressSearchTokens(query)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	addresses, err := g.store.search(ctx, tokens, geocodeSearchLimit)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]GeocodeSearchResult, 0, len(addresses))
+	for _, address := range addresses {
+		label := address.addressLine()
+		if address.Postcode != "" || address.City != "" {
+			label = fmt.Sprintf("%s, %s", label, strings.TrimSpace(address.Postcode+" "+address.City))
+		}
+		results = append(results, GeocodeSearchResult{
+			Label:      label,
+			Address:    address.addressLine(),
+			City:       address.City,
+			PostalCode: address.Postcode,
+			Lat:        address.Lat,
+			Lng:        address.Lng,
+		})
+	}
+	return results, nil
+}
+
+type pgAddressStore struct {
+	db *sql.DB
+}
+
+func (s *pgAddressStore) nearest(ctx context.Context, box addressBox) (*localAddress, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT street, house_number, postcode, city, lat, lng
+		FROM addresses
+		WHERE lat BETWEEN $1 AND $2 AND lng BETWEEN $3 AND $4
+		ORDER BY (lat - $5) * (lat - $5) + ((lng - $6) * $7) * ((lng - $6) * $7)
+		LIMIT 1
+	`, box.MinLat, box.MaxLat, box.MinLng, box.MaxLng, box.Lat, box.Lng, box.LngScale)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	addresses, err := scanLocalAddresses(rows)
+	if err != nil || len(addresses) == 0 {
+		return nil, err
+	}
+	return &addresses[0], nil
+}
+
+func (s *pgAddressStore) search(ctx context.Context, tokens []string, limit int) ([]localAddress, error) {
+	conditions := make([]string, 0, len(tokens))
+	args := make([]any, 0, len(tokens)+1)
+	for _, token := range tokens {
+		args = append(args, "%"+escapeLikePattern(token)+"%")
+		conditions = append(conditions, fmt.Sprintf("search_text LIKE $%d", len(args)))
+	}
+	args = append(args, limit)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT street, house_number, postcode, city, lat, lng
+		FROM addresses
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY city, street, LENGTH(house_number), house_number
+		LIMIT $`+fmt.Sprint(len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanLocalAddresses(rows)
+}
+
+func scanLocalAddresses(rows *sql.Rows) ([]localAddress, error) {
+	addresses := make([]localAddress, 0)
+	for rows.Next() {
+		var address localAddress
+		if err := rows.Scan(&address.Street, &address.HouseNumber, &address.Postcode, &address.City, &address.Lat, &address.Lng); err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, rows.Err()
+}
+
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
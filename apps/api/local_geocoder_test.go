@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type memoryAddressStore struct {
+	addresses []localAddress
+}
+
+func (s *memoryAddressStore) nearest(ctx context.Context, box addressBox) (*localAddress, error) {
+	var nearest *localAddress
+	nearestDistance := math.Inf(1)
+	for i, address := range s.addresses {
+		if address.Lat < box.MinLat || address.Lat > box.MaxLat || address.Lng < box.MinLng || address.Lng > box.MaxLng {
+			continue
+		}
+		dLat, dLng := address.Lat-box.Lat, (address.Lng-box.Lng)*box.LngScale
+		if distance := dLat*dLat + dLng*dLng; distance < nearestDistance {
+			nearest = &s.addresses[i]
+			nearestDistance = distance
+		}
+	}
+	return nearest, nil
+}
+
+func (s *memoryAddressStore) search(ctx context.Context, tokens []string, limit int) ([]localAddress, error) {
+	found := make([]localAddress, 0)
+	for _, address := range s.addresses {
+		text := addressSearchText(address)
+		matches := true
+		for _, token := range tokens {
+			if !strings.Contains(text, token) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			found = append(found, address)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].HouseNumber < found[j].HouseNumber })
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func newTestLocalGeocoder() *LocalGeocoder {
+	return &LocalGeocoder{store: &memoryAddressStore{addresses: []localAddress{
+		{Street: "Oudegracht", HouseNumber: "1", Postcode: "3511 AA", City: "Utrecht", Lat: 52.09270, Lng: 5.11880},
+		{Street: "Oudegracht", HouseNumber: "3", Postcode: "3511 AA", City: "Utrecht", Lat: 52.09260, Lng: 5.11900},
+		{Street: "Damrak", HouseNumber: "1", Postcode: "1012 LG", City: "Amsterdam", Lat: 52.37620, Lng: 4.89630},
+		{Street: "Lange Viestraat", HouseNumber: "2", Postcode: "3511 BK", City: "Utrecht", Lat: 52.09000, Lng: 5.11600},
+	}}}
+}
+
+func TestLocalGeocoderReverseUsesNearestAddress(t *testing.T) {
+	geocoder := newTestLocalGeocoder()
+	ctx := context.Background()
+
+	result, err := geocoder.Geocode(ctx, 52.09258, 5.11905)
+	if err != nil || result == nil || result.Address != "Oudegracht 3" || result.PostalCode != "3511 AA" {
+		t.Fatalf("expected Oudegracht 3, got %#v, %v", result, err)
+	}
+
+	// About 11 m south of the address.
+	result, err = geocoder.Geocode(ctx, 52.08990, 5.11600)
+	if err != nil || result == nil || result.Address != "Lange Viestraat 2" {
+		t.Fatalf("expected Lange Viestraat 2, got %#v, %v", result, err)
+	}
+
+	// About 400 m from the nearest address.
+	if result, err := geocoder.Geocode(ctx, 52.0960, 5.1190); err != nil || result != nil {
+		t.Fatalf("expected no address beyond the maximum distance, got %#v, %v", result, err)
+	}
+}
+
+func TestAddressBoxAroundCoversTheRadius(t *testing.T) {
+	box := addressBoxAround(52.0926, 5.1188, localGeocoderMaxDistanceM)
+	// Points just inside the radius due north and due east.
+	north := 52.0926 + 249.0/111320
+	east := 5.1188 + 249.0/(111320*math.Cos(52.0926*math.Pi/180))
+	if haversineMeters(52.0926, 5.1188, north, 5.1188) > localGeocoderMaxDistanceM || haversineMeters(52.0926, 5.1188, 52.0926, east) > localGeocoderMaxDistanceM {
+		t.Fatalf("test points should lie within the radius")
+	}
+	if north > box.MaxLat || east > box.MaxLng || 2*52.0926-north < box.MinLat || 2*5.1188-east < box.MinLng {
+		t.Fatalf("expected the box to cover the radius, got %#v", box)
+	}
+	// Roughly 500 m by 500 m, not kilometres.
+	if height := haversineMeters(box.MinLat, box.Lng, box.MaxLat, box.Lng); height > 510 {
+		t.Fatalf("expected a box about 500 m high, got %.0f m", height)
+	}
+	if width := haversineMeters(box.Lat, box.MinLng, box.Lat, box.MaxLng); width > 510 {
+		t.Fatalf("expected a box about 500 m wide, got %.0f m", width)
+	}
+}
+
+func TestLocalGeocoderSearch(t *testing.T) {
+	geocoder := newTestLocalGeocoder()
+	ctx := context.Background()
+
+	results, err := geocoder.Search(ctx, "oudegracht, Utrecht")
+	if err != nil || len(results) != 2 {
+		t.Fatalf("expected two Oudegracht addresses, got %#v, %v", results, err)
+	}
+	if results[0].Label != "Oudegracht 1, 3511 AA Utrecht" || results[0].Lat != 52.09270 {
+		t.Fatalf("unexpected first result: %#v", results[0])
+	}
+
+	results, err = geocoder.Search(ctx, "1012 lg 1")
+	if err != nil || len(results) != 1 || results[0].City != "Amsterdam" {
+		t.Fatalf("expected a postcode search with a space to match, got %#v, %v", results, err)
+	}
+
+	if tokens := addressSearchTokens("Damrak 1, 1012 LG"); strings.Join(tokens, "|") != "damrak|1|1012lg" {
+		t.Fatalf("unexpected tokens: %v", tokens)
+	}
+}
@@ -92,9 +92,9 @@ func hasSharedTags(source, target []string) bool {
 	return false
 }
 
-func recencyScore(createdAt, now time.Time) float64 {
+func recencyScore(createdAt, now time.Time, lookbackDays int) float64 {
 	ageDays := now.Sub(createdAt).Hours() / 24
-	return clamp01(1 - ageDays/float64(dedupeLookbackDays))
+	return clamp01(1 - ageDays/float64(lookbackDays))
 }
 
 type dedupeCandidate struct {
@@ -103,18 +103,32 @@ type dedupeCandidate struct {
 	DistanceMeters float64
 }
 
-func scoreDuplicateCandidate(incoming Report, candidate Report, now time.Time) *dedupeCandidate {
+// scoreDuplicateCandidate weighs distance, tag overlap, recency and photo
+// similarity. Photos only count from photoSimilarityScoreFloor up; when they
+// are less similar, or photoSimilarity is nil because either report has no
+// hashed photos, the other components carry the full weight. Counted photos
+// can only raise that score, so the score never drops as similarity rises.
+func scoreDuplicateCandidate(incoming Report, candidate Report, photoSimilarity *float64, now time.Time, params SignalParams) *dedupeCandidate {
 	distanceMeters := haversineMeters(incoming.Location.Lat, incoming.Location.Lng, candidate.Location.Lat, candidate.Location.Lng)
-	if distanceMeters > dedupeRadiusMeters {
+	if distanceMeters > params.DedupeRadiusMeters {
 		return nil
 	}
 
-	distanceScore := clamp01(1 - distanceMeters/dedupeRadiusMeters)
+	distanceScore := clamp01(1 - distanceMeters/params.DedupeRadiusMeters)
 	overlap := tagOverlapRatio(incoming.Tags, candidate.Tags)
 	candidateCreated, _ := time.Parse(time.RFC3339, candidate.CreatedAt)
-	recency := recencyScore(candidateCreated, now)
+	recency := recencyScore(candidateCreated, now, params.DedupeLookbackDays)
 
-	score := distanceScore*distanceWeight + overlap*tagOverlapWeight + recency*recencyWeight
+	score := distanceScore*params.DistanceWeight + overlap*params.TagOverlapWeight + recency*params.RecencyWeight
+	withoutPhotos := score
+	if otherWeights := params.DistanceWeight + params.TagOverlapWeight + params.RecencyWeight; otherWeights > 0 {
+		withoutPhotos = score / otherWeights
+	}
+	if photoSimilarity != nil && *photoSimilarity >= photoSimilarityScoreFloor {
+		score = math.Max(withoutPhotos, score+*photoSimilarity*params.PhotoSimilarityWeight)
+	} else {
+		score = withoutPhotos
+	}
 	return &dedupeCandidate{
 		ReportID:       candidate.ID,
 		Score:          math.Round(score*10000) / 10000,
@@ -122,19 +136,19 @@ func scoreDuplicateCandidate(incoming Report, candidate Report, now time.Time) *
 	}
 }
 
-func scoreSignalGroupCandidate(incoming Report, candidate Report, now time.Time) *float64 {
+func scoreSignalGroupCandidate(incoming Report, candidate Report, now time.Time, params SignalParams) *float64 {
 	if !hasSharedTags(incoming.Tags, candidate.Tags) {
 		return nil
 	}
 	distanceMeters := haversineMeters(incoming.Location.Lat, incoming.Location.Lng, candidate.Location.Lat, candidate.Location.Lng)
-	if distanceMeters > signalMatchRadiusMeters {
+	if distanceMeters > params.SignalMatchRadiusMeters {
 		return nil
 	}
-	distanceScore := clamp01(1 - distanceMeters/signalMatchRadiusMeters)
+	distanceScore := clamp01(1 - distanceMeters/params.SignalMatchRadiusMeters)
 	overlap := tagOverlapRatio(incoming.Tags, candidate.Tags)
 	candidateCreated, _ := time.Parse(time.RFC3339, candidate.CreatedAt)
-	recency := recencyScore(candidateCreated, now)
-	score := distanceScore*distanceWeight + overlap*tagOverlapWeight + recency*recencyWeight
+	recency := recencyScore(candidateCreated, now, params.DedupeLookbackDays)
+	score := distanceScore*params.DistanceWeight + overlap*params.TagOverlapWeight + recency*params.RecencyWeight
 	rounded := math.Round(score*10000) / 10000
 	return &rounded
 }
@@ -150,19 +164,10 @@ func isSameUTCDay(leftISO, rightISO string) bool {
 	return ly == ry && lm == rm && ld == rd
 }
 
-func computeSignalStrength(summary ReportSignalSummary) string {
-	if summary.DistinctReporterReconfirmations > 0 && summary.UniqueReporters >= strongSignalMinReporters {
-		return "strong_distinct_reporters"
-	}
-	if summary.HasQualifyingReconfirmation {
-		return "weak_same_reporter"
-	}
-	return "none"
-}
-
 type reconfirmationComputation struct {
 	Summary                  ReportSignalSummary
 	SignalStrength           string
+	Score                    SignalScore
 	ClassificationByReportID map[int]string
 }
 
@@ -176,7 +181,7 @@ func effectiveReporterID(r Report) string {
 	return "anon:" + r.ReporterHash
 }
 
-func computeReconfirmation(reports []Report) reconfirmationComputation {
+func computeReconfirmation(reports []Report, params SignalParams) reconfirmationComputation {
 	sortedReports := append([]Report{}, reports...)
 	sort.Slice(sortedReports, func(i, j int) bool {
 		return sortedReports[i].CreatedAt < sortedReports[j].CreatedAt
@@ -213,7 +218,7 @@ func computeReconfirmation(reports []Report) reconfirmationComputation {
 		currentTime, _ := time.Parse(time.RFC3339, current.CreatedAt)
 		previousTime, _ := time.Parse(time.RFC3339, previous.CreatedAt)
 		gapDays := currentTime.Sub(previousTime).Hours() / 24
-		if gapDays < float64(signalReconfirmationGapDays) {
+		if gapDays < float64(params.SignalReconfirmationGapDays) {
 			classifications[current.ID] = "non_qualifying"
 			continue
 		}
@@ -244,9 +249,13 @@ func computeReconfirmation(reports []Report) reconfirmationComputation {
 		uniqueReportersSet[effectiveReporterID(report)] = struct{}{}
 	}
 
-	lastReportAt := time.Now().UTC().Format(time.RFC3339)
+	scoredAt := time.Now().UTC()
+	lastReportAt := scoredAt.Format(time.RFC3339)
 	if len(sortedReports) > 0 {
 		lastReportAt = sortedReports[len(sortedReports)-1].CreatedAt
+		if parsed, err := time.Parse(time.RFC3339, lastReportAt); err == nil {
+			scoredAt = parsed
+		}
 	}
 
 	summary := ReportSignalSummary{
@@ -260,14 +269,17 @@ func computeReconfirmation(reports []Report) reconfirmationComputation {
 		HasQualifyingReconfirmation:     sameReporterCount+distinctReporterCount > 0,
 	}
 
+	// Scored as of the last report, so the stored score is undecayed.
+	score := computeSignalScore(sortedReports, summary, params.TagWeights, scoredAt)
 	return reconfirmationComputation{
 		Summary:                  summary,
-		SignalStrength:           computeSignalStrength(summary),
+		SignalStrength:           computeSignalStrength(score.BaseScore, summary, params),
+		Score:                    score,
 		ClassificationByReportID: classifications,
 	}
 }
 
-func applySummaryToBikeGroup(group BikeGroup, summary ReportSignalSummary, signalStrength string) BikeGroup {
+func applySummaryToBikeGroup(group BikeGroup, summary ReportSignalSummary, signalStrength string, signalScore float64) BikeGroup {
 	now := time.Now().UTC().Format(time.RFC3339)
 	group.UpdatedAt = now
 	group.LastReportAt = summary.LastReportAt
@@ -278,6 +290,7 @@ func applySummaryToBikeGroup(group BikeGroup, summary ReportSignalSummary, signa
 	group.FirstQualifyingReconfirmationAt = summary.FirstQualifyingReconfirmationAt
 	group.LastQualifyingReconfirmationAt = summary.LastQualifyingReconfirmationAt
 	group.SignalStrength = signalStrength
+	group.SignalScore = signalScore
 	return group
 }
 
@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"embed"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
@@ -50,9 +52,10 @@ const (
 	strongSignalMinReporters    = 2
 	dedupeRadiusMeters          = 15.0
 	dedupeLookbackDays          = 30
-	distanceWeight              = 0.6
-	tagOverlapWeight            = 0.25
-	recencyWeight               = 0.15
+	distanceWeight              = 0.5
+	tagOverlapWeight            = 0.2
+	recencyWeight               = 0.1
+	photoSimilarityWeight       = 0.2
 	devCORSOriginLocalhost      = "http://localhost:5173"
 	devCORSOriginLoopback       = "http://127.0.0.1:5173"
 	adminCityFilterCacheTTL     = 30 * time.Minute
@@ -64,18 +67,18 @@ var (
 	reportStatuses       = []string{"new", "triaged", "forwarded", "resolved", "invalid"}
 	openReportStatuses   = []string{"new", "triaged", "forwarded"}
 	operatorRoles        = []string{"admin", "municipality_operator"}
-	allowedImageTypes    = map[string]struct{}{"image/jpeg": {}, "image/webp": {}}
+	allowedImageTypes    = map[string]struct{}{"image/jpeg": {}, "image/png": {}, "image/webp": {}, "image/heic": {}, "image/heif": {}}
 	defaultTagDictionary = []TagSeed{
-		{Code: "flat_tires", Label: "Flat tires", IsActive: true},
-		{Code: "rusted", Label: "Rusted", IsActive: true},
-		{Code: "missing_parts", Label: "Missing parts", IsActive: true},
-		{Code: "blocking_sidewalk", Label: "Blocking sidewalk", IsActive: true},
-		{Code: "damaged_frame", Label: "Damaged frame", IsActive: true},
-		{Code: "abandoned_long_time", Label: "Abandoned for long time", IsActive: true},
-		{Code: "no_chain", Label: "No chain", IsActive: true},
-		{Code: "wheel_missing", Label: "Missing wheel", IsActive: true},
-		{Code: "no_seat", Label: "No seat", IsActive: true},
-		{Code: "other_visibility_issue", Label: "Other visibility issue", IsActive: true},
+		{Code: "flat_tires", Label: "Flat tires", IsActive: true, Weight: 0.3},
+		{Code: "rusted", Label: "Rusted", IsActive: true, Weight: 0.3},
+		{Code: "missing_parts", Label: "Missing parts", IsActive: true, Weight: 0.5},
+		{Code: "blocking_sidewalk", Label: "Blocking sidewalk", IsActive: true, Weight: 1.0},
+		{Code: "damaged_frame", Label: "Damaged frame", IsActive: true, Weight: 0.6},
+		{Code: "abandoned_long_time", Label: "Abandoned for long time", IsActive: true, Weight: 0.8},
+		{Code: "no_chain", Label: "No chain", IsActive: true, Weight: 0.4},
+		{Code: "wheel_missing", Label: "Missing wheel", IsActive: true, Weight: 0.6},
+		{Code: "no_seat", Label: "No seat", IsActive: true, Weight: 0.4},
+		{Code: "other_visibility_issue", Label: "Other visibility issue", IsActive: true, Weight: 0.2},
 	}
 	statusTransitions = map[string][]string{
 		"new":       {"triaged", "invalid"},
@@ -95,6 +98,7 @@ type TagSeed struct {
 	Code     string
 	Label    string
 	IsActive bool
+	Weight   float64
 }
 
 type Config struct {
@@ -110,7 +114,33 @@ type Config struct {
 	BootstrapOperatorRole     string
 	MaxLocationAccuracyM      float64
 	MapboxAccessToken         string
-	GeocoderProvider          string
+	GeocoderProviders         []string
+	GeocoderTimeouts          map[string]time.Duration
+	GeocoderBreakerFailures   int
+	GeocoderBreakerCooldown   time.Duration
+	MetricsToken              string
+	MunicipalityBoundaries    string
+	ServiceAreaPath           string
+	ServiceAreaBorderMarginM  float64
+	GeocodeCacheTTL           time.Duration
+	GeocodeCacheNegativeTTL   time.Duration
+	PhotoLocationMismatchM    float64
+	PhotoMaxAge               time.Duration
+	PhotoDecodeConcurrency    int
+	PhotoReuseMinDistanceM    float64
+	PhotoRedactionRetention   time.Duration
+	RetentionPhotos           time.Duration
+	RetentionReporterEmail    time.Duration
+	RetentionFingerprint      time.Duration
+	RetentionExports          time.Duration
+	StorageBackend            string
+	S3Endpoint                string
+	S3Region                  string
+	S3Bucket                  string
+	S3AccessKeyID             string
+	S3SecretAccessKey         string
+	S3ForcePathStyle          bool
+	S3PresignTTL              time.Duration
 	ResendAPIKey              string
 	MailerFromAddresses       map[string]string
 }
@@ -120,8 +150,11 @@ type App struct {
 	db  *sql.DB
 	log *slog.Logger
 
-	geocoder Geocoder
-	mailer   *mailer.Mailer
+	geocoder       Geocoder
+	municipalities *MunicipalityResolver
+	serviceArea    *ServiceArea
+	mailer         *mailer.Mailer
+	blobs          BlobStore
 
 	rateLimiterMu sync.Mutex
 	rateBuckets   map[string]rateBucket
@@ -129,6 +162,9 @@ type App struct {
 	fingerprintMu sync.Mutex
 	fingerprints  map[string]fingerprintBucket
 
+	// decodeSlots limits concurrent photo decoding; nil means unlimited.
+	decodeSlots chan struct{}
+
 	adminTemplates  *adminTemplateRenderer
 	cityFilterMu    sync.Mutex
 	cityFilterCache map[string]cityFilterCacheEntry
@@ -140,6 +176,11 @@ type App struct {
 	adminGetReportByID        func(ctx context.Context, reportID int) (*Report, error)
 	adminGetReportDetails     func(ctx context.Context, reportID int) (*OperatorReportDetails, error)
 	adminUpdateReportStatus   func(ctx context.Context, reportID int, status string, session OperatorSession) (*Report, error)
+	adminEditReport           func(ctx context.Context, reportID int, edit ReportEdit, session OperatorSession) (*Report, error)
+	adminRedactPhoto          func(ctx context.Context, reportID, photoID int, redaction PhotoRedaction, session OperatorSession) error
+	adminListPhotoRedactions  func(ctx context.Context, photoID int) ([]PhotoRedactionRecord, error)
+	adminAddReportPhotos      func(ctx context.Context, reportID int, kind string, photos []PhotoUpload, session OperatorSession) error
+	adminSetReportLegalHold   func(ctx context.Context, reportID int, held bool, reason string, session OperatorSession) error
 	adminMergeDuplicates      func(ctx context.Context, canonicalReportID int, duplicateReportIDs []int, session OperatorSession) (*DedupeGroup, error)
 	adminListExports          func(ctx context.Context, session OperatorSession) ([]ExportBatch, error)
 	adminGenerateExport       func(ctx context.Context, input map[string]any, session OperatorSession) (*ExportBatch, error)
@@ -166,6 +207,25 @@ type App struct {
 	adminToggleReceivesReports             func(ctx context.Context, id int) (bool, error)
 	adminCreateOperatorMagicLinkToken      func(ctx context.Context, operatorID int, tokenHash string, expiresAt time.Time) error
 	adminVerifyOperatorMagicLinkToken      func(ctx context.Context, tokenHash string) (int, error)
+
+	adminListSignalParams   func(ctx context.Context) ([]SignalParams, error)
+	adminSaveSignalParams   func(ctx context.Context, params SignalParams, updatedBy string) error
+	adminDeleteSignalParams func(ctx context.Context, id int) error
+
+	adminListHotspots func(ctx context.Context, municipality *string) ([]Hotspot, error)
+
+	adminGeocodeCacheStats func(ctx context.Context) (GeocodeCacheStats, error)
+	adminPurgeGeocodeCache func(ctx context.Context, scope string) (int64, error)
+	adminGeocoderStatus    func() GeocoderChainStatus
+
+	// test hooks for citizen data requests
+	userLoadDataExport           func(ctx context.Context, userID int) (*UserDataExport, error)
+	userCreateDeletionToken      func(ctx context.Context, userID int, mode string) (string, error)
+	userConfirmDeletion          func(ctx context.Context, token string) (*AccountDeletionResult, error)
+	userLogDataSubjectRequest    func(ctx context.Context, entry DataSubjectRequest) error
+	adminListDataSubjectRequests func(ctx context.Context, emailHash string, limit int) ([]DataSubjectRequest, error)
+
+	writeMetrics func(w io.Writer) error
 }
 
 type rateBucket struct {
@@ -184,10 +244,11 @@ type cityFilterCacheEntry struct {
 }
 
 type Tag struct {
-	ID       int    `json:"id"`
-	Code     string `json:"code"`
-	Label    string `json:"label"`
-	IsActive bool   `json:"isActive"`
+	ID       int     `json:"id"`
+	Code     string  `json:"code"`
+	Label    string  `json:"label"`
+	IsActive bool    `json:"isActive"`
+	Weight   float64 `json:"weight"`
 }
 
 type ReportLocation struct {
@@ -216,6 +277,7 @@ type Report struct {
 	PostalCode       *string        `json:"postalCode,omitempty"`
 	Municipality     *string        `json:"municipality,omitempty"`
 	UserID           *int           `json:"userId,omitempty"`
+	LocationSource   string         `json:"locationSource"`
 }
 
 type BikeGroup struct {
@@ -232,6 +294,10 @@ type BikeGroup struct {
 	FirstQualifyingReconfirmationAt *string `json:"firstQualifyingReconfirmationAt"`
 	LastQualifyingReconfirmationAt  *string `json:"lastQualifyingReconfirmationAt"`
 	SignalStrength                  string  `json:"signalStrength"`
+	SignalScore                     float64 `json:"signalScore"`
+	State                           string  `json:"state"`
+	ClosedAt                        *string `json:"closedAt"`
+	PreviousGroupID                 *int    `json:"previousGroupId"`
 }
 
 type ReportSignalSummary struct {
@@ -253,15 +319,21 @@ type ReportPhoto struct {
 	Filename    string
 	SizeBytes   int64
 	CreatedAt   string
+	// Version starts at 1 and goes up with every redaction.
+	Version int
+	Kind    string
 }
 
 type OperatorReportPhotoView struct {
 	ID        int    `json:"id"`
 	URL       string `json:"url"`
+	ThumbURL  string `json:"thumb_url"`
+	MediumURL string `json:"medium_url"`
 	MimeType  string `json:"mime_type"`
 	Filename  string `json:"filename"`
 	SizeBytes int64  `json:"size_bytes"`
 	CreatedAt string `json:"created_at"`
+	Kind      string `json:"kind"`
 }
 
 type OperatorReportView struct {
@@ -269,6 +341,8 @@ type OperatorReportView struct {
 	BikeGroupID     int                 `json:"bike_group_id"`
 	SignalSummary   ReportSignalSummary `json:"signal_summary"`
 	SignalStrength  string              `json:"signal_strength"`
+	SignalScore     float64             `json:"signal_score"`
+	PriorityScore   float64             `json:"priority_score,omitempty"`
 	PreviewPhotoURL *string             `json:"preview_photo_url"`
 }
 
@@ -286,7 +360,10 @@ type SignalDetails struct {
 	BikeGroup      BikeGroup             `json:"bikeGroup"`
 	SignalSummary  ReportSignalSummary   `json:"signalSummary"`
 	SignalStrength string                `json:"signalStrength"`
+	SignalScore    SignalScore           `json:"signalScore"`
 	Timeline       []SignalTimelineEntry `json:"timeline"`
+	// RecurringHistory lists earlier, closed bike groups at the same location.
+	RecurringHistory []BikeGroup `json:"recurringHistory"`
 }
 
 type OperatorReportDetails struct {
@@ -294,6 +371,7 @@ type OperatorReportDetails struct {
 	Events        []ReportEvent             `json:"events"`
 	Photos        []OperatorReportPhotoView `json:"photos"`
 	SignalDetails SignalDetails             `json:"signal_details"`
+	LegalHold     *LegalHold                `json:"legal_hold,omitempty"`
 }
 
 type ReportEvent struct {
@@ -356,11 +434,21 @@ type PhotoUpload struct {
 	Name     string
 	MimeType string
 	Bytes    []byte
+	// OriginalMimeType is the uploaded format when the photo was converted.
+	OriginalMimeType string
+	// EXIF is read before the photo is re-encoded; nil when it had none.
+	EXIF *photoEXIF
+	// PerceptualHash is the difference hash of the stored photo.
+	PerceptualHash *uint64
+	// Kind is photoKindCitizen unless an operator added the photo later.
+	Kind string
 }
 
 type ReportCreatePayload struct {
 	Photos          []PhotoUpload
 	Location        ReportLocation
+	LocationSource  string
+	LocationToken   string
 	Tags            []string
 	Note            *string
 	ClientTS        *string
@@ -420,16 +508,39 @@ func main() {
 	var geocoder Geocoder
 	httpClient := &http.Client{Timeout: 10 * time.Second}
 
-	mapbox := &MapboxGeocoder{AccessToken: cfg.MapboxAccessToken, Client: httpClient}
-	nominatim := &NominatimGeocoder{UserAgent: "ZwerfFiets-API/1.0", Client: httpClient}
+	geocoderChain := newGeocoderChain(logger, cfg.GeocoderBreakerFailures, cfg.GeocoderBreakerCooldown)
+	for _, name := range cfg.GeocoderProviders {
+		switch name {
+		case "mapbox":
+			geocoderChain.add(name, &MapboxGeocoder{AccessToken: cfg.MapboxAccessToken, Client: httpClient}, cfg.GeocoderTimeouts[name])
+		case "nominatim":
+			geocoderChain.add(name, &NominatimGeocoder{UserAgent: "ZwerfFiets-API/1.0", Client: httpClient}, cfg.GeocoderTimeouts[name])
+		case "local":
+			geocoderChain.add(name, newLocalGeocoder(db), cfg.GeocoderTimeouts[name])
+		default:
+			panic(fmt.Sprintf("geocoder provider %q is accepted by GEOCODER_PROVIDER but not wired", name))
+		}
+	}
+	geocoder = geocoderChain
 
-	switch cfg.GeocoderProvider {
-	case "mapbox":
-		geocoder = mapbox
-	case "nominatim":
-		geocoder = nominatim
-	default:
-		geocoder = &FallbackGeocoder{Primary: mapbox, Secondary: nominatim}
+	// A TTL of zero days turns the cache off.
+	var geocodeCache *CachingGeocoder
+	if cfg.GeocodeCacheTTL > 0 {
+		geocodeCache = newCachingGeocoder(geocoder, db, cfg.GeocodeCacheTTL, cfg.GeocodeCacheNegativeTTL, logger)
+		geocoder = geocodeCache
+	}
+
+	municipalities, err := loadMunicipalityResolver(cfg.MunicipalityBoundaries)
+	if err != nil {
+		panic(err)
+	}
+	if municipalities == nil {
+		logger.Warn("no municipality boundaries available, falling back to the geocoder")
+	}
+
+	serviceArea, err := loadServiceArea(cfg.ServiceAreaPath)
+	if err != nil {
+		panic(err)
 	}
 
 	var mailProvider mailer.Provider
@@ -442,14 +553,24 @@ func main() {
 	}
 	mailClient := mailer.New(mailProvider, cfg.MailerFromAddresses[mailProvider.Name()])
 
+	blobs, err := newBlobStore(cfg, cfg.StorageBackend)
+	if err != nil {
+		panic(err)
+	}
+	logger.Info("blob storage initialized", "backend", blobs.Name())
+
 	app := &App{
 		cfg:             cfg,
 		db:              db,
 		log:             logger,
 		geocoder:        geocoder,
+		municipalities:  municipalities,
+		serviceArea:     serviceArea,
 		mailer:          mailClient,
+		blobs:           blobs,
 		rateBuckets:     make(map[string]rateBucket),
 		fingerprints:    make(map[string]fingerprintBucket),
+		decodeSlots:     make(chan struct{}, cfg.PhotoDecodeConcurrency),
 		adminTemplates:  newAdminTemplateRenderer(cfg.Env),
 		cityFilterCache: make(map[string]cityFilterCacheEntry),
 	}
@@ -480,6 +601,19 @@ func main() {
 	app.adminBulkDeleteUsers = app.storeBulkDeleteUsers
 	app.adminListReportCities = app.storeListReportCities
 
+	app.adminListSignalParams = app.storeListSignalParams
+	app.adminSaveSignalParams = app.storeSaveSignalParams
+	app.adminDeleteSignalParams = app.storeDeleteSignalParamsOverride
+
+	app.adminListHotspots = app.storeListHotspots
+
+	if geocodeCache != nil {
+		app.adminGeocodeCacheStats = geocodeCache.Stats
+		app.adminPurgeGeocodeCache = geocodeCache.Purge
+	}
+	app.adminGeocoderStatus = geocoderChain.Status
+	app.writeMetrics = geocoderChain.WritePrometheus
+
 	logger.Info(
 		"runtime configuration",
 		"env",
@@ -488,6 +622,8 @@ func main() {
 		cfg.Addr,
 		"max_location_accuracy_m",
 		cfg.MaxLocationAccuracyM,
+		"service_area_border_margin_m",
+		cfg.ServiceAreaBorderMarginM,
 	)
 
 	// Ensure migrations are run on startup
@@ -597,6 +733,190 @@ func main() {
 		return
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "detect-hotspots" {
+		if err := app.runMigrations(ctx); err != nil {
+			panic(err)
+		}
+		hotspots, err := app.runHotspotDetection(ctx, time.Now().UTC())
+		if err != nil {
+			logger.Error("failed to detect hotspots", "err", err)
+			os.Exit(1)
+		}
+		logger.Info("detect-hotspots completed", "hotspots", len(hotspots))
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate-municipalities" {
+		if len(os.Args) < 3 {
+			logger.Error("usage: migrate-municipalities <merger-file.json>")
+			os.Exit(2)
+		}
+		if err := app.runMigrations(ctx); err != nil {
+			panic(err)
+		}
+		if err := app.loadMunicipalityMergers(ctx); err != nil {
+			panic(err)
+		}
+		mergers, err := loadMunicipalityMergerFile(os.Args[2])
+		if err != nil {
+			logger.Error("invalid merger file", "path", os.Args[2], "err", err)
+			os.Exit(1)
+		}
+		result, err := app.migrateMunicipalities(ctx, mergers, time.Now().UTC())
+		if err != nil {
+			logger.Error("failed to migrate municipalities", "err", err)
+			os.Exit(1)
+		}
+		if result.Deferred > 0 {
+			logger.Warn("mergers not in effect yet were skipped; run again on or after their effective date", "deferred", result.Deferred)
+		}
+		logger.Info("migrate-municipalities completed", "mergers", result.Mergers, "deferred", result.Deferred, "reports", result.Reports, "operators", result.Operators, "signal_settings", result.SignalSettings)
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "import-addresses" {
+		if len(os.Args) < 3 {
+			logger.Error("usage: import-addresses <addresses.csv|addresses.geojson>")
+			os.Exit(2)
+		}
+		if err := app.runMigrations(ctx); err != nil {
+			panic(err)
+		}
+		result, err := app.importAddresses(ctx, os.Args[2])
+		if err != nil {
+			logger.Error("failed to import addresses", "path", os.Args[2], "err", err)
+			os.Exit(1)
+		}
+		logger.Info("import-addresses completed", "imported", result.Imported, "skipped", result.Skipped)
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "generate-photo-variants" {
+		if err := app.runMigrations(ctx); err != nil {
+			panic(err)
+		}
+		result, err := app.backfillPhotoVariants(ctx)
+		if err != nil {
+			logger.Error("failed to generate photo variants", "err", err)
+			os.Exit(1)
+		}
+		logger.Info("generate-photo-variants completed", "photos", result.Photos, "generated", result.Generated, "failed", result.Failed)
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "purge-redaction-originals" {
+		if err := app.runMigrations(ctx); err != nil {
+			panic(err)
+		}
+		purged, err := app.purgeExpiredRedactionOriginals(ctx, time.Now().UTC())
+		if err != nil {
+			logger.Error("failed to purge redaction originals", "err", err)
+			os.Exit(1)
+		}
+		logger.Info("purge-redaction-originals completed", "purged", purged)
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "purge-retention" {
+		dryRun := false
+		for _, arg := range os.Args[2:] {
+			if arg != "--dry-run" {
+				logger.Error("usage: purge-retention [--dry-run]")
+				os.Exit(2)
+			}
+			dryRun = true
+		}
+		if err := app.runMigrations(ctx); err != nil {
+			panic(err)
+		}
+		result, err := app.purgeRetention(ctx, time.Now().UTC(), dryRun)
+		if err != nil {
+			logger.Error("failed to purge retention", "err", err)
+			os.Exit(1)
+		}
+		logger.Info("purge-retention completed", "dry_run", result.DryRun, "photos", result.Photos, "photo_reports", result.PhotoReports, "reporter_emails", result.ReporterEmails, "fingerprints", result.Fingerprints, "exports", result.Exports, "legal_holds", result.LegalHolds)
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate-storage" {
+		if len(os.Args) < 4 {
+			logger.Error("usage: migrate-storage <filesystem|s3> <filesystem|s3>")
+			os.Exit(2)
+		}
+		from, err := newBlobStore(cfg, os.Args[2])
+		if err != nil {
+			logger.Error("invalid source storage backend", "backend", os.Args[2], "err", err)
+			os.Exit(2)
+		}
+		to, err := newBlobStore(cfg, os.Args[3])
+		if err != nil {
+			logger.Error("invalid destination storage backend", "backend", os.Args[3], "err", err)
+			os.Exit(2)
+		}
+		if from.Name() == to.Name() {
+			logger.Error("migrate-storage needs two different backends")
+			os.Exit(2)
+		}
+		result, err := app.migrateBlobs(ctx, from, to)
+		if err != nil {
+			logger.Error("failed to migrate storage", "err", err)
+			os.Exit(1)
+		}
+		logger.Info("migrate-storage completed", "blobs", result.Blobs, "copied", result.Copied, "skipped", result.Skipped, "failed", result.Failed)
+		if result.Failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "verify-storage" {
+		var options StorageVerifyOptions
+		outputPath := ""
+		for i := 2; i < len(os.Args); i++ {
+			switch os.Args[i] {
+			case "--checksums":
+				options.Checksums = true
+			case "--quarantine":
+				options.Quarantine = true
+			case "--output":
+				if i+1 >= len(os.Args) {
+					logger.Error("usage: verify-storage [--checksums] [--quarantine] [--output <report.json>]")
+					os.Exit(2)
+				}
+				i++
+				outputPath = os.Args[i]
+			default:
+				logger.Error("usage: verify-storage [--checksums] [--quarantine] [--output <report.json>]")
+				os.Exit(2)
+			}
+		}
+		if err := app.runMigrations(ctx); err != nil {
+			panic(err)
+		}
+		report, err := app.verifyStorageAgainstDatabase(ctx, options)
+		if err != nil {
+			logger.Error("failed to verify storage", "err", err)
+			os.Exit(1)
+		}
+		encoded, err := json.Marshal(report)
+		if err != nil {
+			panic(err)
+		}
+		if outputPath != "" {
+			if err := os.WriteFile(outputPath, append(encoded, '\n'), 0o644); err != nil {
+				logger.Error("failed to write storage report", "path", outputPath, "err", err)
+				os.Exit(1)
+			}
+		} else {
+			fmt.Println(string(encoded))
+		}
+		logger.Info("verify-storage completed", "references", report.References, "blobs", report.Blobs, "missing", report.Missing, "orphaned", report.Orphaned, "size_mismatches", report.SizeMismatches, "checksum_mismatches", report.ChecksumMismatches, "quarantined", report.Quarantined, "recent", report.Recent)
+		if report.HasIssues() {
+			os.Exit(1)
+		}
+		return
+	}
+
 	if len(os.Args) > 1 && os.Args[1] == "send-municipality-reports" {
 		if err := app.runMigrations(ctx); err != nil {
 			panic(err)
@@ -617,15 +937,21 @@ func main() {
 		panic(err)
 	}
 
+	if err := app.loadMunicipalityMergers(ctx); err != nil {
+		panic(err)
+	}
+
 	if err := InitContentCache(ctx, app.db); err != nil {
 		app.log.Error("failed to initialize content cache", "err", err)
 	}
 
-	if err := os.MkdirAll(filepath.Join(cfg.DataRoot, "uploads", "reports"), 0o755); err != nil {
-		panic(err)
-	}
-	if err := os.MkdirAll(filepath.Join(cfg.DataRoot, "exports"), 0o755); err != nil {
-		panic(err)
+	if cfg.StorageBackend == storageBackendFilesystem {
+		if err := os.MkdirAll(filepath.Join(cfg.DataRoot, "uploads", "reports"), 0o755); err != nil {
+			panic(err)
+		}
+		if err := os.MkdirAll(filepath.Join(cfg.DataRoot, "exports"), 0o755); err != nil {
+			panic(err)
+		}
 	}
 
 	r := gin.New()
@@ -639,6 +965,7 @@ func main() {
 	r.GET("/healthz", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
+	r.GET("/metrics", app.metricsHandler)
 
 	api := r.Group("/api/v1")
 	{
@@ -646,8 +973,10 @@ func main() {
 		api.GET("/reports/:public_id/status", app.reportStatusHandler)
 		api.GET("/tags", app.tagsHandler)
 		api.GET("/municipalities", app.municipalitiesHandler)
+		api.GET("/geocode/search", app.geocodeSearchHandler)
 		api.GET("/showcase", app.publicShowcaseItemsHandler)
 		api.GET("/showcase/:slot/photo", app.publicShowcasePhotoHandler)
+		api.GET("/showcase/:slot/photo/after", app.publicShowcaseAfterPhotoHandler)
 		api.GET("/blog", app.publicBlogListHandler)
 		api.GET("/blog/:slug", app.publicBlogPostHandler)
 		api.GET("/blog/media/:filename", app.blogMediaServeHandler)
@@ -661,10 +990,16 @@ func main() {
 			auth.GET("/session", app.userSessionHandler)
 		}
 
+		// The deletion link may be opened on another device, so confirming it
+		// needs the token rather than a session.
+		api.POST("/user/deletion/confirm", app.userDeletionConfirmHandler)
+
 		user := api.Group("/user")
 		user.Use(app.requireUserSession())
 		{
 			user.GET("/reports", app.userReportsHandler)
+			user.GET("/export", app.userDataExportHandler)
+			user.POST("/deletion", app.userDeletionRequestHandler)
 		}
 
 		opAuth := api.Group("/operator/auth")
@@ -680,6 +1015,7 @@ func main() {
 			op.GET("/reports", app.operatorReportsHandler)
 			op.GET("/reports/:id", app.operatorReportDetailsHandler)
 			op.GET("/reports/:id/events", app.operatorReportEventsHandler)
+			op.POST("/reports/:id/photos", app.operatorAddReportPhotosHandler)
 			op.GET("/reports/:id/photos/:photoID", app.operatorReportPhotoHandler)
 			op.POST("/reports/:id/status", app.requireRole("admin"), app.operatorUpdateStatusHandler)
 			op.POST("/dedupe/merge", app.requireRole("admin"), app.operatorMergeHandler)
@@ -758,7 +1094,31 @@ func loadConfig() (*Config, error) {
 		BootstrapOperatorRole:     valueOrDefault("BOOTSTRAP_OPERATOR_ROLE", "admin"),
 		MaxLocationAccuracyM:      3000,
 		MapboxAccessToken:         strings.TrimSpace(os.Getenv("MAPBOX_ACCESS_TOKEN")),
-		GeocoderProvider:          strings.TrimSpace(os.Getenv("GEOCODER_PROVIDER")),
+		GeocoderTimeouts:          map[string]time.Duration{},
+		GeocoderBreakerFailures:   defaultGeocoderBreakerFailures,
+		GeocoderBreakerCooldown:   defaultGeocoderBreakerCooldown,
+		MetricsToken:              strings.TrimSpace(os.Getenv("METRICS_TOKEN")),
+		MunicipalityBoundaries:    strings.TrimSpace(os.Getenv("MUNICIPALITY_BOUNDARIES_PATH")),
+		ServiceAreaPath:           strings.TrimSpace(os.Getenv("SERVICE_AREA_PATH")),
+		GeocodeCacheTTL:           defaultGeocodeCacheTTL,
+		GeocodeCacheNegativeTTL:   defaultGeocodeCacheNegativeTTL,
+		PhotoLocationMismatchM:    defaultPhotoLocationMismatchM,
+		PhotoMaxAge:               defaultPhotoMaxAge,
+		PhotoDecodeConcurrency:    defaultPhotoDecodeConcurrency,
+		PhotoReuseMinDistanceM:    defaultPhotoReuseMinDistanceM,
+		PhotoRedactionRetention:   defaultPhotoRedactionRetention,
+		RetentionPhotos:           defaultRetentionPhotos,
+		RetentionReporterEmail:    defaultRetentionReporterEmail,
+		RetentionFingerprint:      defaultRetentionFingerprint,
+		RetentionExports:          defaultRetentionExports,
+		StorageBackend:            strings.ToLower(valueOrDefault("STORAGE_BACKEND", storageBackendFilesystem)),
+		S3Endpoint:                strings.TrimSpace(os.Getenv("S3_ENDPOINT")),
+		S3Region:                  valueOrDefault("S3_REGION", defaultS3Region),
+		S3Bucket:                  strings.TrimSpace(os.Getenv("S3_BUCKET")),
+		S3AccessKeyID:             strings.TrimSpace(os.Getenv("S3_ACCESS_KEY_ID")),
+		S3SecretAccessKey:         strings.TrimSpace(os.Getenv("S3_SECRET_ACCESS_KEY")),
+		S3ForcePathStyle:          true,
+		S3PresignTTL:              defaultS3PresignTTL,
 		ResendAPIKey:              strings.TrimSpace(os.Getenv("RESEND_API_KEY")),
 		MailerFromAddresses: map[string]string{
 			"resend": valueOrDefault("MAILER_FROM_ADDRESS_RESEND", "noreply@mail1.zwerffiets.org"),
@@ -777,6 +1137,159 @@ func loadConfig() (*Config, error) {
 		cfg.MaxLocationAccuracyM = parsed
 	}
 
+	if rawBorderMargin := strings.TrimSpace(os.Getenv("SERVICE_AREA_BORDER_MARGIN_M")); rawBorderMargin != "" {
+		parsed, err := strconv.ParseFloat(rawBorderMargin, 64)
+		if err != nil {
+			return nil, fmt.Errorf("SERVICE_AREA_BORDER_MARGIN_M must be a valid number")
+		}
+		if parsed < 0 {
+			return nil, fmt.Errorf("SERVICE_AREA_BORDER_MARGIN_M must be >= 0")
+		}
+		cfg.ServiceAreaBorderMarginM = parsed
+	}
+
+	providers, err := parseGeocoderProviders(os.Getenv("GEOCODER_PROVIDER"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.GeocoderProviders = providers
+	for _, name := range providers {
+		cfg.GeocoderTimeouts[name] = defaultGeocoderTimeouts[name]
+		key := "GEOCODER_" + strings.ToUpper(name) + "_TIMEOUT_MS"
+		if rawTimeout := strings.TrimSpace(os.Getenv(key)); rawTimeout != "" {
+			parsed, err := strconv.Atoi(rawTimeout)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("%s must be a whole number > 0", key)
+			}
+			cfg.GeocoderTimeouts[name] = time.Duration(parsed) * time.Millisecond
+		}
+	}
+
+	if rawFailures := strings.TrimSpace(os.Getenv("GEOCODER_BREAKER_FAILURES")); rawFailures != "" {
+		parsed, err := strconv.Atoi(rawFailures)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("GEOCODER_BREAKER_FAILURES must be a whole number >= 0")
+		}
+		cfg.GeocoderBreakerFailures = parsed
+	}
+
+	if rawCooldown := strings.TrimSpace(os.Getenv("GEOCODER_BREAKER_COOLDOWN_SECONDS")); rawCooldown != "" {
+		parsed, err := strconv.Atoi(rawCooldown)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("GEOCODER_BREAKER_COOLDOWN_SECONDS must be a whole number > 0")
+		}
+		cfg.GeocoderBreakerCooldown = time.Duration(parsed) * time.Second
+	}
+
+	if rawCacheTTL := strings.TrimSpace(os.Getenv("GEOCODE_CACHE_TTL_DAYS")); rawCacheTTL != "" {
+		parsed, err := strconv.Atoi(rawCacheTTL)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("GEOCODE_CACHE_TTL_DAYS must be a whole number >= 0")
+		}
+		cfg.GeocodeCacheTTL = time.Duration(parsed) * 24 * time.Hour
+	}
+
+	if rawNegativeTTL := strings.TrimSpace(os.Getenv("GEOCODE_CACHE_NEGATIVE_TTL_HOURS")); rawNegativeTTL != "" {
+		parsed, err := strconv.Atoi(rawNegativeTTL)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("GEOCODE_CACHE_NEGATIVE_TTL_HOURS must be a whole number >= 0")
+		}
+		cfg.GeocodeCacheNegativeTTL = time.Duration(parsed) * time.Hour
+	}
+
+	if rawMismatch := strings.TrimSpace(os.Getenv("PHOTO_EXIF_MAX_DISTANCE_M")); rawMismatch != "" {
+		parsed, err := strconv.ParseFloat(rawMismatch, 64)
+		if err != nil {
+			return nil, fmt.Errorf("PHOTO_EXIF_MAX_DISTANCE_M must be a valid number")
+		}
+		if parsed < 0 {
+			return nil, fmt.Errorf("PHOTO_EXIF_MAX_DISTANCE_M must be >= 0")
+		}
+		cfg.PhotoLocationMismatchM = parsed
+	}
+
+	if rawMaxAge := strings.TrimSpace(os.Getenv("PHOTO_EXIF_MAX_AGE_DAYS")); rawMaxAge != "" {
+		parsed, err := strconv.Atoi(rawMaxAge)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("PHOTO_EXIF_MAX_AGE_DAYS must be a whole number >= 0")
+		}
+		cfg.PhotoMaxAge = time.Duration(parsed) * 24 * time.Hour
+	}
+
+	if rawConcurrency := strings.TrimSpace(os.Getenv("PHOTO_DECODE_CONCURRENCY")); rawConcurrency != "" {
+		parsed, err := strconv.Atoi(rawConcurrency)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("PHOTO_DECODE_CONCURRENCY must be a whole number > 0")
+		}
+		cfg.PhotoDecodeConcurrency = parsed
+	}
+
+	if rawReuse := strings.TrimSpace(os.Getenv("PHOTO_REUSE_MIN_DISTANCE_M")); rawReuse != "" {
+		parsed, err := strconv.ParseFloat(rawReuse, 64)
+		if err != nil {
+			return nil, fmt.Errorf("PHOTO_REUSE_MIN_DISTANCE_M must be a valid number")
+		}
+		if parsed < 0 {
+			return nil, fmt.Errorf("PHOTO_REUSE_MIN_DISTANCE_M must be >= 0")
+		}
+		cfg.PhotoReuseMinDistanceM = parsed
+	}
+
+	if rawRetention := strings.TrimSpace(os.Getenv("PHOTO_REDACTION_ORIGINAL_RETENTION_DAYS")); rawRetention != "" {
+		parsed, err := strconv.Atoi(rawRetention)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("PHOTO_REDACTION_ORIGINAL_RETENTION_DAYS must be a whole number >= 0")
+		}
+		cfg.PhotoRedactionRetention = time.Duration(parsed) * 24 * time.Hour
+	}
+
+	// A retention of zero days turns that policy off.
+	for _, policy := range []struct {
+		env    string
+		target *time.Duration
+	}{
+		{"RETENTION_PHOTOS_DAYS", &cfg.RetentionPhotos},
+		{"RETENTION_REPORTER_EMAIL_DAYS", &cfg.RetentionReporterEmail},
+		{"RETENTION_FINGERPRINT_DAYS", &cfg.RetentionFingerprint},
+		{"RETENTION_EXPORTS_DAYS", &cfg.RetentionExports},
+	} {
+		raw := strings.TrimSpace(os.Getenv(policy.env))
+		if raw == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("%s must be a whole number >= 0", policy.env)
+		}
+		*policy.target = time.Duration(parsed) * 24 * time.Hour
+	}
+
+	switch cfg.StorageBackend {
+	case storageBackendFilesystem:
+	case storageBackendS3:
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" || cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+			return nil, fmt.Errorf("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY must be set when STORAGE_BACKEND is 's3'")
+		}
+	default:
+		return nil, fmt.Errorf("STORAGE_BACKEND must be 'filesystem' or 's3'")
+	}
+
+	if rawPathStyle := strings.TrimSpace(os.Getenv("S3_FORCE_PATH_STYLE")); rawPathStyle != "" {
+		parsed, err := strconv.ParseBool(rawPathStyle)
+		if err != nil {
+			return nil, fmt.Errorf("S3_FORCE_PATH_STYLE must be true or false")
+		}
+		cfg.S3ForcePathStyle = parsed
+	}
+
+	if rawPresignTTL := strings.TrimSpace(os.Getenv("S3_PRESIGN_TTL_SECONDS")); rawPresignTTL != "" {
+		parsed, err := strconv.Atoi(rawPresignTTL)
+		if err != nil || parsed <= 0 || time.Duration(parsed)*time.Second > s3MaxPresignTTL {
+			return nil, fmt.Errorf("S3_PRESIGN_TTL_SECONDS must be a whole number between 1 and 604800")
+		}
+		cfg.S3PresignTTL = time.Duration(parsed) * time.Second
+	}
+
 	if cfg.BootstrapOperatorRole != "admin" {
 		return nil, fmt.Errorf("BOOTSTRAP_OPERATOR_ROLE must be 'admin'")
 	}
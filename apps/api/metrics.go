@@ -0,0 +1,30 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// metricsHandler serves Prometheus-style metrics to scrapers that send
+// METRICS_TOKEN as a bearer token. Without a configured token the endpoint
+// does not exist.
+func (a *App) metricsHandler(c *gin.Context) {
+	if a.cfg.MetricsToken == "" || a.writeMetrics == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+	if subtle.ConstantTimeCompare([]byte(token), []byte(a.cfg.MetricsToken)) != 1 {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := a.writeMetrics(c.Writer); err != nil {
+		a.log.Error("failed to write metrics", "err", err)
+	}
+}
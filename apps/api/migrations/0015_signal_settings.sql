@@ -0,0 +1,26 @@
+-- Tunable signal and dedupe parameters. The row without a municipality holds
+-- the global defaults; rows with a municipality override them for that area.
+CREATE TABLE IF NOT EXISTS signal_settings (
+  id SERIAL PRIMARY KEY,
+  municipality TEXT,
+  signal_match_radius_m DOUBLE PRECISION NOT NULL,
+  signal_lookback_days INTEGER NOT NULL,
+  signal_reconfirmation_gap_days INTEGER NOT NULL,
+  strong_signal_min_reporters INTEGER NOT NULL,
+  dedupe_radius_m DOUBLE PRECISION NOT NULL,
+  dedupe_lookback_days INTEGER NOT NULL,
+  distance_weight DOUBLE PRECISION NOT NULL,
+  tag_overlap_weight DOUBLE PRECISION NOT NULL,
+  recency_weight DOUBLE PRECISION NOT NULL,
+  updated_by TEXT,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signal_settings_scope ON signal_settings ((COALESCE(LOWER(municipality), '')));
+
+INSERT INTO signal_settings (
+  municipality, signal_match_radius_m, signal_lookback_days, signal_reconfirmation_gap_days,
+  strong_signal_min_reporters, dedupe_radius_m, dedupe_lookback_days,
+  distance_weight, tag_overlap_weight, recency_weight, updated_by
+) VALUES (NULL, 10, 180, 28, 2, 15, 30, 0.6, 0.25, 0.15, 'migration')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,16 @@
+-- Bike groups are open while they have unresolved reports and close once every
+-- report is resolved or invalid. Closed groups keep their history; a new group
+-- at the same spot links back to it as a recurring location.
+ALTER TABLE bike_groups ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT 'open' CHECK (state IN ('open', 'closed'));
+ALTER TABLE bike_groups ADD COLUMN IF NOT EXISTS closed_at TIMESTAMPTZ;
+ALTER TABLE bike_groups ADD COLUMN IF NOT EXISTS previous_group_id INTEGER REFERENCES bike_groups(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bike_groups_state ON bike_groups(state);
+
+UPDATE bike_groups bg
+SET state = 'closed', closed_at = NOW(), updated_at = NOW()
+WHERE EXISTS (SELECT 1 FROM reports r WHERE r.bike_group_id = bg.id)
+  AND NOT EXISTS (
+    SELECT 1 FROM reports r
+    WHERE r.bike_group_id = bg.id AND r.status NOT IN ('resolved', 'invalid')
+  );
@@ -0,0 +1,17 @@
+-- Chronic stray-bike hotspots, recomputed by the detect-hotspots command.
+CREATE TABLE IF NOT EXISTS hotspots (
+  id SERIAL PRIMARY KEY,
+  municipality TEXT NOT NULL,
+  center_lat DOUBLE PRECISION NOT NULL,
+  center_lng DOUBLE PRECISION NOT NULL,
+  radius_m DOUBLE PRECISION NOT NULL,
+  report_count INTEGER NOT NULL,
+  bike_group_count INTEGER NOT NULL,
+  recurrence_rate DOUBLE PRECISION NOT NULL,
+  median_resolution_hours DOUBLE PRECISION,
+  first_report_at TIMESTAMPTZ NOT NULL,
+  last_report_at TIMESTAMPTZ NOT NULL,
+  computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_hotspots_municipality ON hotspots(municipality);
@@ -0,0 +1,18 @@
+-- Numeric signal score per bike group. The stored value is the score as of
+-- last_report_at; readers apply the recency decay (half-life 90 days).
+ALTER TABLE bike_groups ADD COLUMN IF NOT EXISTS signal_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- Backfill from the stored reconfirmation counters so existing labels keep
+-- their meaning. Tag severity and persistence are added the next time a
+-- group is recomputed.
+UPDATE bike_groups
+SET signal_score = LEAST(
+  20 * LEAST(distinct_reporter_reconfirmations, 3)
+  + 8 * LEAST(same_reporter_reconfirmations, 2)
+  + CASE
+      WHEN same_reporter_reconfirmations + distinct_reporter_reconfirmations > 0
+      THEN 5 * LEAST(GREATEST(unique_reporters - 1, 0), 4)
+      ELSE 0
+    END,
+  100
+);
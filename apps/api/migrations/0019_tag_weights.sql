@@ -0,0 +1,18 @@
+-- Tag weights rate how urgent a tag makes a report, from 0 to 1. They scale
+-- the bike-group signal score and feed the triage priority.
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS weight DOUBLE PRECISION NOT NULL DEFAULT 0.3 CHECK (weight >= 0 AND weight <= 1);
+
+UPDATE tags SET weight = seed.weight, updated_at = NOW()
+FROM (VALUES
+  ('blocking_sidewalk', 1.0),
+  ('abandoned_long_time', 0.8),
+  ('damaged_frame', 0.6),
+  ('wheel_missing', 0.6),
+  ('missing_parts', 0.5),
+  ('no_chain', 0.4),
+  ('no_seat', 0.4),
+  ('flat_tires', 0.3),
+  ('rusted', 0.3),
+  ('other_visibility_issue', 0.2)
+) AS seed(code, weight)
+WHERE tags.code = seed.code;
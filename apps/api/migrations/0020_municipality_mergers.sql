@@ -0,0 +1,26 @@
+-- Herindelingen applied by the migrate-municipalities command. One row per
+-- merged gemeente; the API registers these on startup so successor names are
+-- valid from their effective date.
+CREATE TABLE IF NOT EXISTS municipality_mergers (
+  id SERIAL PRIMARY KEY,
+  from_municipality TEXT NOT NULL,
+  to_municipality TEXT NOT NULL,
+  effective_from DATE NOT NULL,
+  reports_reassigned INTEGER NOT NULL DEFAULT 0,
+  operators_reassigned INTEGER NOT NULL DEFAULT 0,
+  applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_municipality_mergers_from ON municipality_mergers ((LOWER(from_municipality)), effective_from);
+
+-- Audit trail for changes made to operator accounts by the system.
+CREATE TABLE IF NOT EXISTS operator_events (
+  id SERIAL PRIMARY KEY,
+  operator_id INTEGER NOT NULL REFERENCES operators(id) ON DELETE CASCADE,
+  type TEXT NOT NULL,
+  actor TEXT NOT NULL,
+  metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_operator_events_operator_id ON operator_events(operator_id);
@@ -0,0 +1,15 @@
+-- Reverse geocoding results keyed on coordinates rounded to 4 decimals
+-- (about 10 m). Rows with found = FALSE cache lookups that returned nothing.
+CREATE TABLE IF NOT EXISTS geocode_cache (
+  lat_key BIGINT NOT NULL,
+  lng_key BIGINT NOT NULL,
+  found BOOLEAN NOT NULL,
+  address TEXT NOT NULL DEFAULT '',
+  city TEXT NOT NULL DEFAULT '',
+  postal_code TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (lat_key, lng_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_geocode_cache_expires_at ON geocode_cache(expires_at);
@@ -0,0 +1,4 @@
+-- Where a report's coordinates came from: the device GPS or an address search.
+ALTER TABLE reports
+  ADD COLUMN IF NOT EXISTS location_source TEXT NOT NULL DEFAULT 'gps'
+  CHECK (location_source IN ('gps', 'address_search'));
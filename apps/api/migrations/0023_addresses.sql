@@ -0,0 +1,18 @@
+-- Address extract (BAG or OSM) for the offline geocoder, replaced as a whole
+-- by import-addresses. cell_lat/cell_lng bucket coordinates into 0.01 degree
+-- grid cells for nearest-address lookups; search_text holds the lowercased
+-- street, house number, compact postcode and city for address search.
+CREATE TABLE IF NOT EXISTS addresses (
+  id BIGSERIAL PRIMARY KEY,
+  street TEXT NOT NULL,
+  house_number TEXT NOT NULL,
+  postcode TEXT NOT NULL DEFAULT '',
+  city TEXT NOT NULL DEFAULT '',
+  lat DOUBLE PRECISION NOT NULL,
+  lng DOUBLE PRECISION NOT NULL,
+  cell_lat INTEGER NOT NULL,
+  cell_lng INTEGER NOT NULL,
+  search_text TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS addresses_cell_idx ON addresses (cell_lat, cell_lng);
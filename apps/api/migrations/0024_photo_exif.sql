@@ -0,0 +1,6 @@
+-- Capture position and time read from a photo's EXIF before it is stripped.
+-- Only used for review; never part of public responses.
+ALTER TABLE report_photos
+  ADD COLUMN IF NOT EXISTS exif_lat DOUBLE PRECISION,
+  ADD COLUMN IF NOT EXISTS exif_lng DOUBLE PRECISION,
+  ADD COLUMN IF NOT EXISTS exif_captured_at TIMESTAMPTZ;
@@ -0,0 +1,5 @@
+-- The format a photo was uploaded in; mime_type is the format it is stored in
+-- after conversion.
+ALTER TABLE report_photos ADD COLUMN IF NOT EXISTS original_mime_type TEXT;
+UPDATE report_photos SET original_mime_type = mime_type WHERE original_mime_type IS NULL;
+ALTER TABLE report_photos ALTER COLUMN original_mime_type SET NOT NULL;
@@ -0,0 +1,13 @@
+-- 64-bit difference hash of each stored photo, used in dedupe scoring and to
+-- spot the same photo reused on distant reports. Older photos have none.
+ALTER TABLE report_photos ADD COLUMN IF NOT EXISTS perceptual_hash BIGINT;
+
+ALTER TABLE signal_settings ADD COLUMN IF NOT EXISTS photo_similarity_weight DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- Rebalance the global row only while it still holds the seeded weights.
+-- Edited rows keep their weights, with photo similarity at 0.
+UPDATE signal_settings
+SET distance_weight = 0.5, tag_overlap_weight = 0.2, recency_weight = 0.1, photo_similarity_weight = 0.2
+WHERE municipality IS NULL
+  AND updated_by = 'migration'
+  AND distance_weight = 0.6 AND tag_overlap_weight = 0.25 AND recency_weight = 0.15;
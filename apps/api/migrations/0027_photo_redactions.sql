@@ -0,0 +1,19 @@
+-- Photo redactions. Each redaction stores a new version of the photo in
+-- report_photos; the version it replaced is kept for admins until
+-- retain_until, or deleted right away (previous_storage_path NULL).
+ALTER TABLE report_photos ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS report_photo_redactions (
+  id SERIAL PRIMARY KEY,
+  photo_id INTEGER NOT NULL REFERENCES report_photos(id) ON DELETE CASCADE,
+  version INTEGER NOT NULL,
+  mode TEXT NOT NULL,
+  regions JSONB NOT NULL,
+  previous_storage_path TEXT,
+  retain_until TIMESTAMPTZ,
+  created_by TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_photo_redactions_photo ON report_photo_redactions (photo_id, version);
+CREATE INDEX IF NOT EXISTS idx_report_photo_redactions_retain_until ON report_photo_redactions (retain_until) WHERE previous_storage_path IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Photos can be added to a report after it was created. kind tells citizen
+-- photos apart from operator field checks and after-removal shots;
+-- uploaded_by is the operator email and NULL for citizen uploads.
+ALTER TABLE report_photos
+  ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'citizen'
+    CHECK (kind IN ('citizen', 'field_check', 'after')),
+  ADD COLUMN IF NOT EXISTS uploaded_by TEXT;
+
+-- A showcase item can pair its photo with an after-removal photo of the
+-- same report.
+ALTER TABLE showcase_items
+  ADD COLUMN IF NOT EXISTS after_photo_id INTEGER REFERENCES report_photos(id) ON DELETE SET NULL;
@@ -0,0 +1,12 @@
+-- SHA-256 of each stored blob, hex encoded, so verify-storage --checksums can
+-- detect corrupted files. NULL for rows written before this migration.
+ALTER TABLE report_photos
+  ADD COLUMN IF NOT EXISTS sha256 TEXT;
+
+ALTER TABLE blog_media
+  ADD COLUMN IF NOT EXISTS sha256 TEXT;
+
+ALTER TABLE exports
+  ADD COLUMN IF NOT EXISTS csv_sha256 TEXT,
+  ADD COLUMN IF NOT EXISTS geojson_sha256 TEXT,
+  ADD COLUMN IF NOT EXISTS pdf_sha256 TEXT;
@@ -0,0 +1,22 @@
+-- A report under legal hold is skipped by every retention policy until an
+-- admin releases it.
+ALTER TABLE reports
+  ADD COLUMN IF NOT EXISTS legal_hold BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN IF NOT EXISTS legal_hold_reason TEXT,
+  ADD COLUMN IF NOT EXISTS legal_hold_by TEXT,
+  ADD COLUMN IF NOT EXISTS legal_hold_at TIMESTAMPTZ;
+
+-- One row per purged item. report_id and export_id are kept as plain values
+-- so the log outlives the rows it describes.
+CREATE TABLE IF NOT EXISTS retention_purges (
+  id BIGSERIAL PRIMARY KEY,
+  category TEXT NOT NULL CHECK (category IN ('photos', 'reporter_email', 'fingerprint', 'exports')),
+  action TEXT NOT NULL CHECK (action IN ('deleted', 'anonymized')),
+  report_id INTEGER,
+  export_id INTEGER,
+  detail JSONB NOT NULL DEFAULT '{}'::jsonb,
+  purged_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_purges_purged_at ON retention_purges(purged_at);
+CREATE INDEX IF NOT EXISTS idx_retention_purges_report_id ON retention_purges(report_id);
@@ -0,0 +1,29 @@
+-- Confirmation links for citizen account deletion. mode is what happens to
+-- the account's reports.
+CREATE TABLE IF NOT EXISTS account_deletion_tokens (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  token_hash TEXT NOT NULL UNIQUE,
+  mode TEXT NOT NULL CHECK (mode IN ('anonymize', 'delete')),
+  expires_at TIMESTAMPTZ NOT NULL,
+  used_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_deletion_tokens_user_id ON account_deletion_tokens(user_id);
+
+-- Processing log of citizen export and deletion requests. It outlives the
+-- account, so it keeps a keyed hash of the e-mail address instead of the
+-- address itself.
+CREATE TABLE IF NOT EXISTS data_subject_requests (
+  id BIGSERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL,
+  email_hash TEXT NOT NULL,
+  kind TEXT NOT NULL CHECK (kind IN ('export', 'deletion_requested', 'deletion_completed')),
+  mode TEXT CHECK (mode IN ('anonymize', 'delete')),
+  detail JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_subject_requests_email_hash ON data_subject_requests(email_hash);
+CREATE INDEX IF NOT EXISTS idx_data_subject_requests_created_at ON data_subject_requests(created_at);
@@ -0,0 +1,7 @@
+-- Address search matches search_text with LIKE '%token%', which a btree
+-- cannot serve. A trigram index keeps it off a sequential scan.
+-- import-addresses recreates this index on its staging table; keep the two
+-- in step.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS addresses_search_text_trgm_idx ON addresses USING gin (search_text gin_trgm_ops);
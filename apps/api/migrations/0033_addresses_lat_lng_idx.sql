@@ -0,0 +1,10 @@
+-- Reverse lookups in the offline geocoder now filter on a lat/lng box of the
+-- search radius and order by distance in SQL, instead of loading 3x3 grid
+-- cells into the API. The grid columns are no longer written or read.
+-- import-addresses recreates this index on its staging table; keep the two
+-- in step.
+DROP INDEX IF EXISTS addresses_cell_idx;
+ALTER TABLE addresses DROP COLUMN IF EXISTS cell_lat;
+ALTER TABLE addresses DROP COLUMN IF EXISTS cell_lng;
+
+CREATE INDEX IF NOT EXISTS addresses_lat_lng_idx ON addresses (lat, lng);
@@ -2,8 +2,8 @@ package main
 
 import (
 	"net/http"
-	"sort"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -359,26 +359,19 @@ func lookupMunicipality(placeName string) string {
 	return placeName
 }
 
-// isValidMunicipality reports whether name is a known Dutch gemeente, case-insensitively.
-func isValidMunicipality(name string) bool {
+// isValidMunicipality reports whether name is a Dutch gemeente at the given
+// time, case-insensitively. Gemeenten that were merged away before at are not
+// valid; their successors are.
+func isValidMunicipality(name string, at time.Time) bool {
 	if name == "" {
 		return false
 	}
-	lower := strings.ToLower(name)
-	for _, m := range dutchMunicipalities {
-		if strings.ToLower(m) == lower {
-			return true
-		}
-	}
-	return false
+	return containsMunicipality(municipalitiesAt(at), name)
 }
 
-// municipalityList returns a sorted copy of the Dutch municipality list.
+// municipalityList returns a sorted copy of the current Dutch municipality list.
 func municipalityList() []string {
-	out := make([]string, len(dutchMunicipalities))
-	copy(out, dutchMunicipalities)
-	sort.Strings(out)
-	return out
+	return municipalitiesAt(time.Now().UTC())
 }
 
 func (a *App) municipalitiesHandler(c *gin.Context) {
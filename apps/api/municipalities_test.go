@@ -3,6 +3,7 @@ package main
 import (
 	"sort"
 	"testing"
+	"time"
 )
 
 func TestLookupMunicipalityKnownPlace(t *testing.T) {
@@ -34,28 +35,28 @@ func TestLookupMunicipalityEmpty(t *testing.T) {
 }
 
 func TestIsValidMunicipalityKnown(t *testing.T) {
-	if !isValidMunicipality("Amsterdam") {
+	if !isValidMunicipality("Amsterdam", time.Now()) {
 		t.Fatal("expected Amsterdam to be a valid municipality")
 	}
 }
 
 func TestIsValidMunicipalityPlace(t *testing.T) {
-	if isValidMunicipality("IJmuiden") {
+	if isValidMunicipality("IJmuiden", time.Now()) {
 		t.Fatal("expected IJmuiden NOT to be a valid municipality")
 	}
 }
 
 func TestIsValidMunicipalityEmpty(t *testing.T) {
-	if isValidMunicipality("") {
+	if isValidMunicipality("", time.Now()) {
 		t.Fatal("expected empty string NOT to be a valid municipality")
 	}
 }
 
 func TestIsValidMunicipalityCaseInsensitive(t *testing.T) {
-	if !isValidMunicipality("amsterdam") {
+	if !isValidMunicipality("amsterdam", time.Now()) {
 		t.Fatal("expected lowercase amsterdam to be valid")
 	}
-	if !isValidMunicipality("AMSTERDAM") {
+	if !isValidMunicipality("AMSTERDAM", time.Now()) {
 		t.Fatal("expected uppercase AMSTERDAM to be valid")
 	}
 }
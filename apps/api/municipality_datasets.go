@@ -0,0 +1,418 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const municipalityDateLayout = "2006-01-02"
+
+// municipalityDataset is the full list of gemeenten as published for a given
+// year. Later changes are expressed as mergers on top of the newest dataset.
+type municipalityDataset struct {
+	Version        string
+	EffectiveFrom  time.Time
+	Municipalities []string
+}
+
+// municipalityMerger folds one or more gemeenten into a successor from a
+// given date (herindeling). The successor may be new or one of the merged
+// gemeenten.
+type municipalityMerger struct {
+	EffectiveFrom time.Time
+	From          []string
+	To            string
+}
+
+var municipalityDatasets = []municipalityDataset{
+	{Version: "2025", EffectiveFrom: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), Municipalities: dutchMunicipalities},
+}
+
+// municipalityMergers holds the herindelingen applied on top of the datasets:
+// the ones shipped with the code plus those recorded by migrate-municipalities
+// and loaded at startup.
+var (
+	municipalityMergersMu sync.RWMutex
+	municipalityMergers   []municipalityMerger
+)
+
+// registerMunicipalityMergers adds mergers to the registry, skipping ones that
+// are already known.
+func registerMunicipalityMergers(mergers []municipalityMerger) {
+	municipalityMergersMu.Lock()
+	defer municipalityMergersMu.Unlock()
+	for _, merger := range mergers {
+		known := false
+		for _, existing := range municipalityMergers {
+			if existing.EffectiveFrom.Equal(merger.EffectiveFrom) && strings.EqualFold(existing.To, merger.To) && sameMunicipalitySet(existing.From, merger.From) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			municipalityMergers = append(municipalityMergers, merger)
+		}
+	}
+	sort.SliceStable(municipalityMergers, func(i, j int) bool {
+		return municipalityMergers[i].EffectiveFrom.Before(municipalityMergers[j].EffectiveFrom)
+	})
+}
+
+func registeredMunicipalityMergers() []municipalityMerger {
+	municipalityMergersMu.RLock()
+	defer municipalityMergersMu.RUnlock()
+	return append([]municipalityMerger{}, municipalityMergers...)
+}
+
+func sameMunicipalitySet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, name := range a {
+		if !containsMunicipality(b, name) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsMunicipality(names []string, name string) bool {
+	for _, candidate := range names {
+		if strings.EqualFold(candidate, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// municipalityDatasetAt returns the newest dataset in effect at the given
+// time, or the oldest one for earlier dates.
+func municipalityDatasetAt(at time.Time) municipalityDataset {
+	dataset := municipalityDatasets[0]
+	for _, candidate := range municipalityDatasets[1:] {
+		if !candidate.EffectiveFrom.After(at) {
+			dataset = candidate
+		}
+	}
+	return dataset
+}
+
+// municipalitiesAt returns the sorted list of gemeenten that exist at the
+// given time: the dataset in effect with every later merger applied.
+func municipalitiesAt(at time.Time) []string {
+	dataset := municipalityDatasetAt(at)
+	names := append([]string{}, dataset.Municipalities...)
+	for _, merger := range registeredMunicipalityMergers() {
+		if !merger.EffectiveFrom.After(dataset.EffectiveFrom) || merger.EffectiveFrom.After(at) {
+			continue
+		}
+		kept := names[:0]
+		for _, name := range names {
+			if !containsMunicipality(merger.From, name) || strings.EqualFold(name, merger.To) {
+				kept = append(kept, name)
+			}
+		}
+		names = kept
+		if !containsMunicipality(names, merger.To) {
+			names = append(names, merger.To)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// successorMunicipality follows the mergers in effect at the given time and
+// returns the gemeente that name belongs to now. Unknown and unmerged names
+// are returned as they are.
+func successorMunicipality(name string, at time.Time) string {
+	current := name
+	for _, merger := range registeredMunicipalityMergers() {
+		if merger.EffectiveFrom.After(at) {
+			break
+		}
+		if containsMunicipality(merger.From, current) {
+			current = merger.To
+		}
+	}
+	return current
+}
+
+// municipalityMergerFile is the JSON format read by migrate-municipalities:
+//
+//	{"effective_from": "2027-01-01", "mergers": [{"from": ["A", "B"], "to": "C"}]}
+type municipalityMergerFile struct {
+	EffectiveFrom string `json:"effective_from"`
+	Mergers       []struct {
+		From []string `json:"from"`
+		To   string   `json:"to"`
+	} `json:"mergers"`
+}
+
+func loadMunicipalityMergerFile(path string) ([]municipalityMerger, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseMunicipalityMergerFile(data)
+}
+
+// parseMunicipalityMergerFile validates a merger file. Every merged gemeente
+// must exist the day before the merger takes effect; names are canonicalized
+// to the spelling of the dataset.
+func parseMunicipalityMergerFile(data []byte) ([]municipalityMerger, error) {
+	var file municipalityMergerFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decode merger file: %w", err)
+	}
+	effectiveFrom, err := time.Parse(municipalityDateLayout, strings.TrimSpace(file.EffectiveFrom))
+	if err != nil {
+		return nil, fmt.Errorf("effective_from must be a YYYY-MM-DD date: %w", err)
+	}
+	if len(file.Mergers) == 0 {
+		return nil, errors.New("merger file contains no mergers")
+	}
+
+	before := municipalitiesAt(effectiveFrom.AddDate(0, 0, -1))
+	seen := map[string]bool{}
+	mergers := make([]municipalityMerger, 0, len(file.Mergers))
+	for i, entry := range file.Mergers {
+		to := strings.TrimSpace(entry.To)
+		if to == "" || len(entry.From) == 0 {
+			return nil, fmt.Errorf("merger %d needs from and to", i)
+		}
+		merger := municipalityMerger{EffectiveFrom: effectiveFrom, To: canonicalMunicipalityName(before, to)}
+		for _, name := range entry.From {
+			canonical := canonicalMunicipalityName(before, strings.TrimSpace(name))
+			if !containsMunicipality(before, canonical) {
+				return nil, fmt.Errorf("merger %d: %q is not a municipality before %s", i, name, file.EffectiveFrom)
+			}
+			if seen[strings.ToLower(canonical)] {
+				return nil, fmt.Errorf("merger %d: %q is merged more than once", i, name)
+			}
+			seen[strings.ToLower(canonical)] = true
+			merger.From = append(merger.From, canonical)
+		}
+		mergers = append(mergers, merger)
+	}
+	return mergers, nil
+}
+
+func canonicalMunicipalityName(names []string, name string) string {
+	for _, known := range names {
+		if strings.EqualFold(known, name) {
+			return known
+		}
+	}
+	return name
+}
+
+// MunicipalityMigrationResult counts the rows reassigned by migrateMunicipalities.
+// Deferred counts mergers that are not in effect yet.
+type MunicipalityMigrationResult struct {
+	Mergers        int
+	Deferred       int
+	Reports        int
+	Operators      int
+	SignalSettings int
+}
+
+// dueMunicipalityMergers splits mergers into those in effect at now and
+// those that take effect later.
+func dueMunicipalityMergers(mergers []municipalityMerger, now time.Time) (due, deferred []municipalityMerger) {
+	for _, merger := range mergers {
+		if merger.EffectiveFrom.After(now) {
+			deferred = append(deferred, merger)
+		} else {
+			due = append(due, merger)
+		}
+	}
+	return due, deferred
+}
+
+// migrateMunicipalities reassigns reports, operators and signal overrides of
+// merged gemeenten to their successor and records each merger, so the next
+// startup knows the new names. Every reassigned report and operator gets an
+// audit event. Mergers that were already applied are skipped. Mergers that
+// take effect after now are left alone and not recorded, so running the same
+// file again on or after the date applies them.
+func (a *App) migrateMunicipalities(ctx context.Context, mergers []municipalityMerger, now time.Time) (MunicipalityMigrationResult, error) {
+	var result MunicipalityMigrationResult
+	mergers, deferred := dueMunicipalityMergers(mergers, now)
+	result.Deferred = len(deferred)
+	for _, merger := range mergers {
+		tx, err := a.db.BeginTx(ctx, nil)
+		if err != nil {
+			return result, err
+		}
+		applied, err := a.migrateMunicipalityMergerTx(ctx, tx, merger, &result)
+		if err != nil {
+			_ = tx.Rollback()
+			return result, fmt.Errorf("merge into %s: %w", merger.To, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return result, err
+		}
+		if applied {
+			result.Mergers++
+		}
+	}
+	registerMunicipalityMergers(mergers)
+	return result, nil
+}
+
+func (a *App) migrateMunicipalityMergerTx(ctx context.Context, tx *sql.Tx, merger municipalityMerger, result *MunicipalityMigrationResult) (bool, error) {
+	applied := false
+	metadata := map[string]any{
+		"to":             merger.To,
+		"effective_from": merger.EffectiveFrom.Format(municipalityDateLayout),
+	}
+	for _, from := range merger.From {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM municipality_mergers
+				WHERE LOWER(from_municipality) = LOWER($1) AND effective_from = $2
+			)
+		`, from, merger.EffectiveFrom).Scan(&exists); err != nil {
+			return false, err
+		}
+		if exists {
+			continue
+		}
+		applied = true
+		metadata["from"] = from
+
+		if strings.EqualFold(from, merger.To) {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO municipality_mergers (from_municipality, to_municipality, effective_from)
+				VALUES ($1, $2, $3)
+			`, from, merger.To, merger.EffectiveFrom); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		reportIDs, err := queryIDsTx(ctx, tx, `
+			UPDATE reports SET municipality = $1, updated_at = NOW()
+			WHERE LOWER(municipality) = LOWER($2)
+			RETURNING id
+		`, merger.To, from)
+		if err != nil {
+			return false, err
+		}
+		for _, id := range reportIDs {
+			if err := a.addEventTx(ctx, tx, id, "municipality_reassigned", "system", metadata); err != nil {
+				return false, err
+			}
+		}
+
+		operatorIDs, err := queryIDsTx(ctx, tx, `
+			UPDATE operators SET municipality = $1, updated_at = NOW()
+			WHERE LOWER(municipality) = LOWER($2)
+			RETURNING id
+		`, merger.To, from)
+		if err != nil {
+			return false, err
+		}
+		for _, id := range operatorIDs {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO operator_events (operator_id, type, actor, metadata)
+				VALUES ($1, 'municipality_reassigned', 'system', $2)
+			`, id, anyMapToJSON(metadata)); err != nil {
+				return false, err
+			}
+		}
+
+		// Only the first override of a merged gemeente moves over; the
+		// successor keeps its own when it already has one.
+		settings, err := tx.ExecContext(ctx, `
+			UPDATE signal_settings SET municipality = $1, updated_at = NOW()
+			WHERE LOWER(municipality) = LOWER($2)
+			  AND NOT EXISTS (SELECT 1 FROM signal_settings WHERE LOWER(municipality) = LOWER($1))
+		`, merger.To, from)
+		if err != nil {
+			return false, err
+		}
+		movedSettings, _ := settings.RowsAffected()
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO municipality_mergers (from_municipality, to_municipality, effective_from, reports_reassigned, operators_reassigned)
+			VALUES ($1, $2, $3, $4, $5)
+		`, from, merger.To, merger.EffectiveFrom, len(reportIDs), len(operatorIDs)); err != nil {
+			return false, err
+		}
+
+		a.log.Info("municipality merged", "from", from, "to", merger.To, "effective_from", metadata["effective_from"], "reports", len(reportIDs), "operators", len(operatorIDs))
+		result.Reports += len(reportIDs)
+		result.Operators += len(operatorIDs)
+		result.SignalSettings += int(movedSettings)
+	}
+	return applied, nil
+}
+
+func queryIDsTx(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// loadMunicipalityMergers registers the mergers recorded by earlier
+// migrate-municipalities runs.
+func (a *App) loadMunicipalityMergers(ctx context.Context) error {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT from_municipality, to_municipality, effective_from
+		FROM municipality_mergers
+		ORDER BY effective_from ASC, id ASC
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	byKey := map[string]*municipalityMerger{}
+	order := make([]string, 0)
+	for rows.Next() {
+		var from, to string
+		var effectiveFrom time.Time
+		if err := rows.Scan(&from, &to, &effectiveFrom); err != nil {
+			return err
+		}
+		key := effectiveFrom.Format(municipalityDateLayout) + "|" + strings.ToLower(to)
+		merger, ok := byKey[key]
+		if !ok {
+			merger = &municipalityMerger{EffectiveFrom: effectiveFrom.UTC(), To: to}
+			byKey[key] = merger
+			order = append(order, key)
+		}
+		merger.From = append(merger.From, from)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	mergers := make([]municipalityMerger, 0, len(order))
+	for _, key := range order {
+		mergers = append(mergers, *byKey[key])
+	}
+	registerMunicipalityMergers(mergers)
+	return nil
+}
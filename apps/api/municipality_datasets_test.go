@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func withMunicipalityMergers(t *testing.T, mergers []municipalityMerger) {
+	t.Helper()
+	municipalityMergersMu.Lock()
+	previous := municipalityMergers
+	municipalityMergers = nil
+	municipalityMergersMu.Unlock()
+	registerMunicipalityMergers(mergers)
+	t.Cleanup(func() {
+		municipalityMergersMu.Lock()
+		municipalityMergers = previous
+		municipalityMergersMu.Unlock()
+	})
+}
+
+func TestIsValidMunicipalityFollowsMergerDates(t *testing.T) {
+	effective := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+	withMunicipalityMergers(t, []municipalityMerger{
+		{EffectiveFrom: effective, From: []string{"Bergen (Noord-Holland)", "Castricum"}, To: "Kennemerduin"},
+		{EffectiveFrom: effective, From: []string{"Amsterdam", "Diemen"}, To: "Amsterdam"},
+	})
+
+	before := effective.AddDate(0, 0, -1)
+	if !isValidMunicipality("Castricum", before) || isValidMunicipality("Kennemerduin", before) {
+		t.Fatalf("expected the dataset list before the merger")
+	}
+	if isValidMunicipality("Castricum", effective) || !isValidMunicipality("kennemerduin", effective) {
+		t.Fatalf("expected the successor from the effective date")
+	}
+	if !isValidMunicipality("Amsterdam", effective) || isValidMunicipality("Diemen", effective) {
+		t.Fatalf("expected Amsterdam to absorb Diemen")
+	}
+
+	if got := successorMunicipality("castricum", effective); got != "Kennemerduin" {
+		t.Fatalf("expected Kennemerduin, got %q", got)
+	}
+	if got := successorMunicipality("Castricum", before); got != "Castricum" {
+		t.Fatalf("expected no successor before the merger, got %q", got)
+	}
+}
+
+func TestParseMunicipalityMergerFile(t *testing.T) {
+	withMunicipalityMergers(t, nil)
+
+	mergers, err := parseMunicipalityMergerFile([]byte(`{"effective_from":"2027-01-01","mergers":[{"from":["bergen (noord-holland)","Castricum"],"to":"Kennemerduin"}]}`))
+	if err != nil {
+		t.Fatalf("parse merger file: %v", err)
+	}
+	if len(mergers) != 1 || mergers[0].To != "Kennemerduin" || !mergers[0].EffectiveFrom.Equal(time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected mergers: %#v", mergers)
+	}
+	if mergers[0].From[0] != "Bergen (Noord-Holland)" {
+		t.Fatalf("expected canonical name, got %q", mergers[0].From[0])
+	}
+
+	invalid := []string{
+		`{"effective_from":"1 januari 2027","mergers":[{"from":["Castricum"],"to":"Kennemerduin"}]}`,
+		`{"effective_from":"2027-01-01","mergers":[]}`,
+		`{"effective_from":"2027-01-01","mergers":[{"from":["IJmuiden"],"to":"Kennemerduin"}]}`,
+		`{"effective_from":"2027-01-01","mergers":[{"from":["Castricum"],"to":""}]}`,
+		`{"effective_from":"2027-01-01","mergers":[{"from":["Castricum"],"to":"A"},{"from":["Castricum"],"to":"B"}]}`,
+	}
+	for _, data := range invalid {
+		if _, err := parseMunicipalityMergerFile([]byte(data)); err == nil {
+			t.Fatalf("expected %s to be rejected", data)
+		}
+	}
+}
+
+func TestDueMunicipalityMergersDefersFutureDates(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	mergers := []municipalityMerger{
+		{EffectiveFrom: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), From: []string{"Weesp"}, To: "Amsterdam"},
+		{EffectiveFrom: time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC), From: []string{"Diemen"}, To: "Amsterdam"},
+		{EffectiveFrom: time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC), From: []string{"Castricum"}, To: "Kennemerduin"},
+	}
+
+	due, deferred := dueMunicipalityMergers(mergers, now)
+	if len(due) != 2 || due[1].From[0] != "Diemen" {
+		t.Fatalf("expected mergers up to today to be due, got %#v", due)
+	}
+	if len(deferred) != 1 || deferred[0].To != "Kennemerduin" {
+		t.Fatalf("expected the 2027 merger to be deferred, got %#v", deferred)
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"strings"
+)
+
+const (
+	// municipalitySimplifyTolerance is the Douglas-Peucker tolerance in
+	// degrees (roughly 10-20 m in the Netherlands).
+	municipalitySimplifyTolerance = 0.0002
+	bundledMunicipalityBoundaries = "municipality_boundaries/gemeenten.geojson"
+)
+
+//go:embed municipality_boundaries
+var municipalityBoundariesFS embed.FS
+
+type geoRing [][2]float64
+
+type geoPolygon struct {
+	Outer geoRing
+	Holes []geoRing
+}
+
+// municipalityPolygon is one polygon of a municipality, kept at full
+// resolution and simplified. Points further than the simplify tolerance from
+// the simplified boundary are classified by the simplified polygon; closer
+// points fall back to the full geometry.
+type municipalityPolygon struct {
+	Municipality string
+	Full         geoPolygon
+	Simplified   geoPolygon
+	Box          bbox
+}
+
+// MunicipalityResolver assigns coordinates to a gemeente by point-in-polygon
+// over CBS boundary polygons, indexed by an R-tree on polygon bounding boxes.
+type MunicipalityResolver struct {
+	polygons []municipalityPolygon
+	index    *rtree
+}
+
+// loadMunicipalityResolver reads the boundaries from path, or from the
+// bundled dataset when path is empty. It returns nil without error when no
+// path is set and no dataset is bundled.
+func loadMunicipalityResolver(path string) (*MunicipalityResolver, error) {
+	var data []byte
+	var err error
+	if path != "" {
+		data, err = os.ReadFile(path)
+	} else {
+		data, err = municipalityBoundariesFS.ReadFile(bundledMunicipalityBoundaries)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseMunicipalityBoundaries(data)
+}
+
+// parseMunicipalityBoundaries builds a resolver from a GeoJSON
+// FeatureCollection of Polygon and MultiPolygon features in WGS84. The name
+// is read from the CBS "statnaam" property, or "gemeentenaam" or "name".
+func parseMunicipalityBoundaries(data []byte) (*MunicipalityResolver, error) {
+	var collection struct {
+		Features []struct {
+			Properties map[string]any `json:"properties"`
+			Geometry   struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("decode municipality boundaries: %w", err)
+	}
+
+	resolver := &MunicipalityResolver{}
+	entries := make([]rtreeEntry, 0, len(collection.Features))
+	for i, feature := range collection.Features {
+		name := municipalityFeatureName(feature.Properties)
+		if name == "" {
+			return nil, fmt.Errorf("municipality boundary feature %d has no name", i)
+		}
+
+		polygons, err := decodeGeoJSONPolygons(feature.Geometry.Type, feature.Geometry.Coordinates)
+		if err != nil {
+			return nil, fmt.Errorf("geometry of %s: %w", name, err)
+		}
+
+		for _, full := range polygons {
+			box, err := ringBBox(full.Outer)
+			if err != nil {
+				return nil, fmt.Errorf("polygon of %s: %w", name, err)
+			}
+
+			entries = append(entries, rtreeEntry{Box: box, Value: len(resolver.polygons)})
+			resolver.polygons = append(resolver.polygons, municipalityPolygon{
+				Municipality: name,
+				Full:         full,
+				Simplified:   simplifyPolygon(full, municipalitySimplifyTolerance),
+				Box:          box,
+			})
+		}
+	}
+	if len(resolver.polygons) == 0 {
+		return nil, errors.New("municipality boundaries contain no polygons")
+	}
+	resolver.index = newRTree(entries)
+	return resolver, nil
+}
+
+// decodeGeoJSONPolygons decodes the coordinates of a Polygon or MultiPolygon
+// geometry, skipping degenerate polygons.
+func decodeGeoJSONPolygons(geometryType string, coordinates json.RawMessage) ([]geoPolygon, error) {
+	var raw [][][][2]float64
+	switch geometryType {
+	case "Polygon":
+		var polygon [][][2]float64
+		if err := json.Unmarshal(coordinates, &polygon); err != nil {
+			return nil, fmt.Errorf("decode polygon: %w", err)
+		}
+		raw = append(raw, polygon)
+	case "MultiPolygon":
+		if err := json.Unmarshal(coordinates, &raw); err != nil {
+			return nil, fmt.Errorf("decode multipolygon: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported geometry %q", geometryType)
+	}
+
+	polygons := make([]geoPolygon, 0, len(raw))
+	for _, rings := range raw {
+		if len(rings) == 0 || len(rings[0]) < 4 {
+			continue
+		}
+		polygon := geoPolygon{Outer: rings[0]}
+		for _, hole := range rings[1:] {
+			polygon.Holes = append(polygon.Holes, hole)
+		}
+		polygons = append(polygons, polygon)
+	}
+	return polygons, nil
+}
+
+func municipalityFeatureName(properties map[string]any) string {
+	for _, key := range []string{"statnaam", "gemeentenaam", "name"} {
+		if value, ok := properties[key].(string); ok && strings.TrimSpace(value) != "" {
+			name := strings.TrimSpace(value)
+			for _, known := range dutchMunicipalities {
+				if strings.EqualFold(known, name) {
+					return known
+				}
+			}
+			return name
+		}
+	}
+	return ""
+}
+
+func ringBBox(ring geoRing) (bbox, error) {
+	box := emptyBBox()
+	for _, point := range ring {
+		lng, lat := point[0], point[1]
+		if lng < -180 || lng > 180 || lat < -90 || lat > 90 {
+			return bbox{}, errors.New("coordinates are not WGS84 longitude/latitude")
+		}
+		box = box.extend(bbox{MinX: lng, MinY: lat, MaxX: lng, MaxY: lat})
+	}
+	return box, nil
+}
+
+// Resolve returns the municipality containing the point, if any.
+func (r *MunicipalityResolver) Resolve(lat, lng float64) (string, bool) {
+	if r == nil || r.index == nil {
+		return "", false
+	}
+	for _, idx := range r.index.searchPoint(lng, lat) {
+		if r.polygons[idx].contains(lng, lat) {
+			return r.polygons[idx].Municipality, true
+		}
+	}
+	return "", false
+}
+
+func (p municipalityPolygon) contains(x, y float64) bool {
+	if !p.Box.containsPoint(x, y) {
+		return false
+	}
+	if polygonBoundaryDistance(p.Simplified, x, y) > municipalitySimplifyTolerance {
+		return polygonContains(p.Simplified, x, y)
+	}
+	return polygonContains(p.Full, x, y)
+}
+
+func polygonContains(polygon geoPolygon, x, y float64) bool {
+	if !ringContains(polygon.Outer, x, y) {
+		return false
+	}
+	for _, hole := range polygon.Holes {
+		if ringContains(hole, x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains is the even-odd ray casting test.
+func ringContains(ring geoRing, x, y float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func polygonBoundaryDistance(polygon geoPolygon, x, y float64) float64 {
+	distance := ringDistance(polygon.Outer, x, y)
+	for _, hole := range polygon.Holes {
+		distance = math.Min(distance, ringDistance(hole, x, y))
+	}
+	return distance
+}
+
+func ringDistance(ring geoRing, x, y float64) float64 {
+	distance := math.Inf(1)
+	for i := 1; i < len(ring); i++ {
+		distance = math.Min(distance, segmentDistance(x, y, ring[i-1], ring[i]))
+	}
+	return distance
+}
+
+func segmentDistance(x, y float64, a, b [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	if dx == 0 && dy == 0 {
+		return math.Hypot(x-a[0], y-a[1])
+	}
+	t := clamp01(((x-a[0])*dx + (y-a[1])*dy) / (dx*dx + dy*dy))
+	return math.Hypot(x-(a[0]+t*dx), y-(a[1]+t*dy))
+}
+
+func simplifyPolygon(polygon geoPolygon, tolerance float64) geoPolygon {
+	simplified := geoPolygon{Outer: simplifyRing(polygon.Outer, tolerance)}
+	for _, hole := range polygon.Holes {
+		simplified.Holes = append(simplified.Holes, simplifyRing(hole, tolerance))
+	}
+	return simplified
+}
+
+// simplifyRing applies Douglas-Peucker to a closed ring. Rings that would
+// collapse below a triangle are kept as they are.
+func simplifyRing(ring geoRing, tolerance float64) geoRing {
+	if len(ring) <= 4 {
+		return ring
+	}
+	keep := make([]bool, len(ring))
+	keep[0], keep[len(ring)-1] = true, true
+
+	// A closed ring starts and ends on the same point, so split it at the
+	// point furthest from the start to give Douglas-Peucker a real chord.
+	furthest, furthestDistance := 0, -1.0
+	for i := 1; i < len(ring)-1; i++ {
+		if d := math.Hypot(ring[i][0]-ring[0][0], ring[i][1]-ring[0][1]); d > furthestDistance {
+			furthest, furthestDistance = i, d
+		}
+	}
+	keep[furthest] = true
+	douglasPeucker(ring, 0, furthest, tolerance, keep)
+	douglasPeucker(ring, furthest, len(ring)-1, tolerance, keep)
+
+	simplified := make(geoRing, 0, len(ring))
+	for i, point := range ring {
+		if keep[i] {
+			simplified = append(simplified, point)
+		}
+	}
+	if len(simplified) < 4 {
+		return ring
+	}
+	return simplified
+}
+
+func douglasPeucker(ring geoRing, start, end int, tolerance float64, keep []bool) {
+	if end-start < 2 {
+		return
+	}
+	index, maxDistance := -1, tolerance
+	for i := start + 1; i < end; i++ {
+		if d := segmentDistance(ring[i][0], ring[i][1], ring[start], ring[end]); d > maxDistance {
+			index, maxDistance = i, d
+		}
+	}
+	if index < 0 {
+		return
+	}
+	keep[index] = true
+	douglasPeucker(ring, start, index, tolerance, keep)
+	douglasPeucker(ring, index, end, tolerance, keep)
+}
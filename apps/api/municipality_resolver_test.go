@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// jaggedEdge returns the points of a south-to-north edge at x from 52.0 to
+// 52.1 with a bump of the given offset between 52.050 and 52.051, finer than
+// the simplify tolerance.
+func jaggedEdge(x, bump float64) [][2]float64 {
+	points := [][2]float64{{x, 52.0}}
+	for y := 52.0500; y <= 52.0510+1e-9; y += 0.0002 {
+		points = append(points, [2]float64{x + bump, y})
+	}
+	return append(points, [2]float64{x, 52.1})
+}
+
+func testMunicipalityBoundaries(t *testing.T) []byte {
+	t.Helper()
+	// Utrecht bulges 15 m into De Bilt along their shared border.
+	utrecht := [][2]float64{{5.0, 52.0}, {5.1, 52.0}}
+	utrecht = append(utrecht, jaggedEdge(5.1, 0.00015)[1:]...)
+	utrecht = append(utrecht, [2]float64{5.0, 52.1}, [2]float64{5.0, 52.0})
+
+	deBilt := [][2]float64{{5.2, 52.0}, {5.2, 52.1}}
+	west := jaggedEdge(5.1, 0.00015)
+	for i := len(west) - 1; i >= 0; i-- {
+		deBilt = append(deBilt, west[i])
+	}
+	deBilt = append(deBilt, [2]float64{5.2, 52.0})
+
+	collection := map[string]any{
+		"type": "FeatureCollection",
+		"features": []any{
+			map[string]any{
+				"type":       "Feature",
+				"properties": map[string]any{"statnaam": "utrecht"},
+				"geometry":   map[string]any{"type": "Polygon", "coordinates": [][][2]float64{utrecht}},
+			},
+			map[string]any{
+				"type":       "Feature",
+				"properties": map[string]any{"statnaam": "De Bilt"},
+				"geometry":   map[string]any{"type": "Polygon", "coordinates": [][][2]float64{deBilt}},
+			},
+			map[string]any{
+				"type":       "Feature",
+				"properties": map[string]any{"statnaam": "Baarle-Nassau"},
+				"geometry": map[string]any{"type": "MultiPolygon", "coordinates": [][][][2]float64{
+					{
+						{{4.90, 51.40}, {4.95, 51.40}, {4.95, 51.45}, {4.90, 51.45}, {4.90, 51.40}},
+						{{4.92, 51.42}, {4.93, 51.42}, {4.93, 51.43}, {4.92, 51.43}, {4.92, 51.42}},
+					},
+					{
+						{{4.96, 51.40}, {4.97, 51.40}, {4.97, 51.41}, {4.96, 51.41}, {4.96, 51.40}},
+					},
+				}},
+			},
+		},
+	}
+	data, err := json.Marshal(collection)
+	if err != nil {
+		t.Fatalf("marshal boundaries: %v", err)
+	}
+	return data
+}
+
+func TestMunicipalityResolverResolvesPoints(t *testing.T) {
+	resolver, err := parseMunicipalityBoundaries(testMunicipalityBoundaries(t))
+	if err != nil {
+		t.Fatalf("parse boundaries: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		lat, lng float64
+		want     string
+	}{
+		{"inside Utrecht", 52.05, 5.05, "Utrecht"},
+		{"inside De Bilt", 52.05, 5.15, "De Bilt"},
+		{"inside border bulge", 52.0505, 5.1001, "Utrecht"},
+		{"next to border bulge", 52.0505, 5.1002, "De Bilt"},
+		{"second polygon of multipolygon", 51.405, 4.965, "Baarle-Nassau"},
+		{"inside hole", 51.425, 4.925, ""},
+		{"outside all boundaries", 53.0, 6.0, ""},
+	}
+	for _, tc := range cases {
+		got, ok := resolver.Resolve(tc.lat, tc.lng)
+		if got != tc.want || ok != (tc.want != "") {
+			t.Fatalf("%s: expected %q, got %q (ok=%v)", tc.name, tc.want, got, ok)
+		}
+	}
+}
+
+func TestSimplifyRingDropsDetailWithinTolerance(t *testing.T) {
+	ring := geoRing{{5.0, 52.0}, {5.1, 52.0}}
+	ring = append(ring, jaggedEdge(5.1, 0.00015)[1:]...)
+	ring = append(ring, [2]float64{5.0, 52.1}, [2]float64{5.0, 52.0})
+
+	simplified := simplifyRing(ring, municipalitySimplifyTolerance)
+	if len(simplified) != 5 {
+		t.Fatalf("expected the bulge to be simplified away, got %d points", len(simplified))
+	}
+	if !ringContains(simplified, 5.05, 52.05) {
+		t.Fatalf("expected simplified ring to keep its interior")
+	}
+}
+
+func TestRTreeSearchPoint(t *testing.T) {
+	entries := make([]rtreeEntry, 0, 400)
+	for x := 0; x < 20; x++ {
+		for y := 0; y < 20; y++ {
+			entries = append(entries, rtreeEntry{
+				Box:   bbox{MinX: float64(x), MinY: float64(y), MaxX: float64(x) + 0.9, MaxY: float64(y) + 0.9},
+				Value: x*20 + y,
+			})
+		}
+	}
+	tree := newRTree(entries)
+
+	got := tree.searchPoint(7.5, 13.5)
+	if len(got) != 1 || got[0] != 7*20+13 {
+		t.Fatalf("expected entry %d, got %v", 7*20+13, got)
+	}
+	if got := tree.searchPoint(7.95, 13.5); len(got) != 0 {
+		t.Fatalf("expected no entry in the gap, got %v", got)
+	}
+}
+
+func TestLoadMunicipalityResolver(t *testing.T) {
+	resolver, err := loadMunicipalityResolver("")
+	if err != nil {
+		t.Fatalf("load bundled boundaries: %v", err)
+	}
+	if _, err := municipalityBoundariesFS.ReadFile(bundledMunicipalityBoundaries); err != nil && resolver != nil {
+		t.Fatalf("expected no resolver without a bundled dataset")
+	}
+
+	path := filepath.Join(t.TempDir(), "gemeenten.geojson")
+	if _, err := loadMunicipalityResolver(path); err == nil {
+		t.Fatalf("expected a configured path that cannot be read to fail")
+	}
+	if err := os.WriteFile(path, testMunicipalityBoundaries(t), 0o600); err != nil {
+		t.Fatalf("write boundaries: %v", err)
+	}
+	resolver, err = loadMunicipalityResolver(path)
+	if err != nil || resolver == nil {
+		t.Fatalf("load boundaries from path: %v", err)
+	}
+	if name, _ := resolver.Resolve(52.05, 5.15); name != "De Bilt" {
+		t.Fatalf("expected De Bilt, got %q", name)
+	}
+
+	if _, err := parseMunicipalityBoundaries([]byte(`{"features":[{"properties":{"statnaam":"Utrecht"},"geometry":{"type":"Polygon","coordinates":[[[136000,455000],[137000,455000],[137000,456000],[136000,455000]]]}}]}`)); err == nil {
+		t.Fatalf("expected RD New coordinates to be rejected")
+	}
+}
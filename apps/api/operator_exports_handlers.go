@@ -10,7 +10,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"path/filepath"
+	"path"
 	"sort"
 	"strconv"
 	"strings"
@@ -97,6 +97,10 @@ func (a *App) updateReportStatus(ctx context.Context, reportID int, nextStatus s
 		_ = tx.Rollback()
 		return nil, err
 	}
+	if err := a.refreshBikeGroupStateTx(ctx, tx, current.BikeGroupID, reportID, session.Email); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -330,7 +334,16 @@ func (a *App) generateExportBatch(ctx context.Context, input map[string]any, ses
 	}
 	title := strings.Join(titleParts, " - ")
 
-	artifacts, err := buildExportArtifacts(filteredReports, periodStart, periodEnd, title)
+	var hotspotScope *string
+	if municipality != "" {
+		hotspotScope = &municipality
+	}
+	hotspots, err := a.storeListHotspots(ctx, hotspotScope)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts, err := buildExportArtifacts(filteredReports, hotspots, periodStart, periodEnd, title)
 	if err != nil {
 		return nil, err
 	}
@@ -360,40 +373,34 @@ func (a *App) generateExportBatch(ctx context.Context, input map[string]any, ses
 		return nil, err
 	}
 
-	exportDir := filepath.Join(a.cfg.DataRoot, "exports", strconv.Itoa(exportID))
-	if err := os.MkdirAll(exportDir, 0o755); err != nil {
-		return nil, err
-	}
-
+	exportDir := path.Join("exports", strconv.Itoa(exportID))
 	periodFilePart := sanitizeFileNamePart(periodStart)
 	baseName := fmt.Sprintf("zwerffiets-%s-%s", periodType, periodFilePart)
 	if municipality != "" {
 		baseName = fmt.Sprintf("zwerffiets-%s-%s-%s", strings.ToLower(sanitizeFileNamePart(municipality)), periodType, periodFilePart)
 	}
 
-	csvFile := filepath.Join(exportDir, baseName+".csv")
-	geoFile := filepath.Join(exportDir, baseName+".geojson")
-	pdfFile := filepath.Join(exportDir, baseName+".pdf")
+	relCSV := path.Join(exportDir, baseName+".csv")
+	relGeo := path.Join(exportDir, baseName+".geojson")
+	relPDF := path.Join(exportDir, baseName+".pdf")
 
-	if err := os.WriteFile(csvFile, []byte(artifacts.CSV), 0o644); err != nil {
+	store := a.blobStore()
+	if err := store.Put(ctx, relCSV, []byte(artifacts.CSV), "text/csv; charset=utf-8"); err != nil {
 		return nil, err
 	}
-	if err := os.WriteFile(geoFile, []byte(artifacts.GeoJSON), 0o644); err != nil {
+	if err := store.Put(ctx, relGeo, []byte(artifacts.GeoJSON), "application/geo+json; charset=utf-8"); err != nil {
 		return nil, err
 	}
-	if err := os.WriteFile(pdfFile, artifacts.PDF, 0o644); err != nil {
+	if err := store.Put(ctx, relPDF, artifacts.PDF, "application/pdf"); err != nil {
 		return nil, err
 	}
 
-	relCSV, _ := filepath.Rel(a.cfg.DataRoot, csvFile)
-	relGeo, _ := filepath.Rel(a.cfg.DataRoot, geoFile)
-	relPDF, _ := filepath.Rel(a.cfg.DataRoot, pdfFile)
-
 	if _, err := a.db.ExecContext(ctx, `
 		UPDATE exports
-		SET csv_path = $1, geojson_path = $2, pdf_path = $3
-		WHERE id = $4
-	`, relCSV, relGeo, relPDF, exportID); err != nil {
+		SET csv_path = $1, geojson_path = $2, pdf_path = $3,
+			csv_sha256 = $4, geojson_sha256 = $5, pdf_sha256 = $6
+		WHERE id = $7
+	`, relCSV, relGeo, relPDF, sha256Hex([]byte(artifacts.CSV)), sha256Hex([]byte(artifacts.GeoJSON)), sha256Hex(artifacts.PDF), exportID); err != nil {
 		return nil, err
 	}
 
@@ -466,7 +473,7 @@ func sanitizeFileNamePart(value string) string {
 	return value
 }
 
-func buildExportArtifacts(reports []Report, periodStart, periodEnd, title string) (ExportArtifacts, error) {
+func buildExportArtifacts(reports []Report, hotspots []Hotspot, periodStart, periodEnd, title string) (ExportArtifacts, error) {
 	sortedReports := append([]Report{}, reports...)
 	sort.Slice(sortedReports, func(i, j int) bool {
 		if sortedReports[i].CreatedAt != sortedReports[j].CreatedAt {
@@ -479,11 +486,11 @@ func buildExportArtifacts(reports []Report, periodStart, periodEnd, title string
 	if err != nil {
 		return ExportArtifacts{}, err
 	}
-	geoJSON, err := buildGeoJSON(sortedReports)
+	geoJSON, err := buildGeoJSON(sortedReports, hotspots)
 	if err != nil {
 		return ExportArtifacts{}, err
 	}
-	pdfData, err := buildPDF(sortedReports, periodStart, periodEnd, title)
+	pdfData, err := buildPDF(sortedReports, hotspots, periodStart, periodEnd, title)
 	if err != nil {
 		return ExportArtifacts{}, err
 	}
@@ -530,8 +537,10 @@ func buildCSV(reports []Report) (string, error) {
 	return buffer.String(), nil
 }
 
-func buildGeoJSON(reports []Report) (string, error) {
-	features := make([]map[string]any, 0, len(reports))
+// buildGeoJSON emits one Point feature per report and, after those, one per
+// hotspot with feature_type "hotspot".
+func buildGeoJSON(reports []Report, hotspots []Hotspot) (string, error) {
+	features := make([]map[string]any, 0, len(reports)+len(hotspots))
 	for _, report := range reports {
 		features = append(features, map[string]any{
 			"type": "Feature",
@@ -549,6 +558,27 @@ func buildGeoJSON(reports []Report) (string, error) {
 			},
 		})
 	}
+	for _, hotspot := range hotspots {
+		features = append(features, map[string]any{
+			"type": "Feature",
+			"geometry": map[string]any{
+				"type":        "Point",
+				"coordinates": []float64{hotspot.CenterLng, hotspot.CenterLat},
+			},
+			"properties": map[string]any{
+				"feature_type":            "hotspot",
+				"hotspot_id":              hotspot.ID,
+				"municipality":            hotspot.Municipality,
+				"radius_m":                hotspot.RadiusMeters,
+				"report_count":            hotspot.ReportCount,
+				"bike_group_count":        hotspot.BikeGroupCount,
+				"recurrence_rate":         hotspot.RecurrenceRate,
+				"median_resolution_hours": hotspot.MedianResolutionHours,
+				"first_report_at":         hotspot.FirstReportAt,
+				"last_report_at":          hotspot.LastReportAt,
+			},
+		})
+	}
 	payload := map[string]any{"type": "FeatureCollection", "features": features}
 	encoded, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
@@ -557,7 +587,7 @@ func buildGeoJSON(reports []Report) (string, error) {
 	return string(encoded), nil
 }
 
-func buildPDF(reports []Report, periodStart, periodEnd, title string) ([]byte, error) {
+func buildPDF(reports []Report, hotspots []Hotspot, periodStart, periodEnd, title string) ([]byte, error) {
 	pdf := fpdf.New("P", "mm", "A4", "")
 	pdf.AddPage()
 	pdf.SetFont("Helvetica", "", 16)
@@ -617,6 +647,24 @@ func buildPDF(reports []Report, periodStart, periodEnd, title string) ([]byte, e
 		pdf.Ln(6)
 	}
 
+	if len(hotspots) > 0 {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.Cell(0, 8, "Hotspots")
+		pdf.Ln(8)
+		pdf.SetFont("Helvetica", "", 10)
+		for _, hotspot := range hotspots {
+			median := "-"
+			if hotspot.MedianResolutionHours != nil {
+				median = formatResolutionHours(*hotspot.MedianResolutionHours)
+			}
+			pdf.Cell(0, 6, fmt.Sprintf("- %s (%.5f, %.5f, r=%.0f m): %d reports, %d bikes, %.2f bikes/30d, median resolution %s",
+				hotspot.Municipality, hotspot.CenterLat, hotspot.CenterLng, hotspot.RadiusMeters,
+				hotspot.ReportCount, hotspot.BikeGroupCount, hotspot.RecurrenceRate, median))
+			pdf.Ln(6)
+		}
+	}
+
 	buffer := bytes.NewBuffer(nil)
 	if err := pdf.Output(buffer); err != nil {
 		return nil, err
@@ -711,8 +759,7 @@ func (a *App) getExportAsset(ctx context.Context, exportID int, format string, s
 		selectedPath = csvPath.String
 	}
 
-	fullPath := filepath.Join(a.cfg.DataRoot, selectedPath)
-	bytes, err := os.ReadFile(fullPath)
+	bytes, err := a.blobStore().Get(ctx, selectedPath)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return "", nil, "", &apiError{Status: http.StatusNotFound, Code: "export_not_found", Message: "Export artifact not found"}
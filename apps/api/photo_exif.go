@@ -0,0 +1,411 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jdeng/goheif"
+)
+
+const (
+	defaultPhotoLocationMismatchM = 500
+	defaultPhotoMaxAge            = 7 * 24 * time.Hour
+
+	exifTagExifIFD           = 0x8769
+	exifTagGPSIFD            = 0x8825
+	exifTagOrientation       = 0x0112
+	exifTagDateTime          = 0x0132
+	exifTagDateTimeOriginal  = 0x9003
+	exifTagOffsetTimeOrig    = 0x9011
+	exifTagGPSLatitudeRef    = 0x0001
+	exifTagGPSLatitude       = 0x0002
+	exifTagGPSLongitudeRef   = 0x0003
+	exifTagGPSLongitude      = 0x0004
+	exifTypeASCII            = 2
+	exifTypeShort            = 3
+	exifTypeLong             = 4
+	exifTypeRational         = 5
+	exifMaxIFDEntries        = 512
+	exifDateTimeLayout       = "2006:01:02 15:04:05"
+	exifDateTimeOffsetLayout = "2006:01:02 15:04:05-07:00"
+)
+
+var errNoEXIF = errors.New("no exif data")
+
+// photoEXIF is the camera metadata read from an upload before it is stripped.
+// It is stored with the photo for review and never served publicly.
+type photoEXIF struct {
+	Lat        *float64
+	Lng        *float64
+	CapturedAt *time.Time
+	// Orientation is the EXIF orientation (1-8); 0 when the photo has none.
+	Orientation int
+}
+
+func (e photoEXIF) hasLocation() bool {
+	return e.Lat != nil && e.Lng != nil
+}
+
+// readPhotoEXIF returns the GPS position, capture time and orientation of a
+// JPEG, PNG, WebP or HEIC/HEIF photo. Missing or unreadable metadata is not an error for the upload; the
+// caller only gets errNoEXIF or a parse error to log.
+func readPhotoEXIF(mimeType string, data []byte) (photoEXIF, error) {
+	var tiff []byte
+	switch mimeType {
+	case "image/jpeg":
+		tiff = findJPEGEXIF(data)
+	case "image/png":
+		tiff = findPNGEXIF(data)
+	case "image/webp":
+		tiff = findWebPEXIF(data)
+	case "image/heic", "image/heif":
+		tiff = findHEIFEXIF(data)
+	}
+	if tiff == nil {
+		return photoEXIF{}, errNoEXIF
+	}
+	exif, err := parseEXIFTIFF(tiff)
+	if mimeType == "image/heic" || mimeType == "image/heif" {
+		// HEIF rotates with its irot property; decodeHEIF applies that, and
+		// the EXIF tag would turn the photo a second time.
+		exif.Orientation = 0
+	}
+	return exif, err
+}
+
+// findJPEGEXIF returns the TIFF payload of the APP1 Exif segment.
+func findJPEGEXIF(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+	offset := 2
+	for offset+4 <= len(data) {
+		if data[offset] != 0xFF {
+			return nil
+		}
+		marker := data[offset+1]
+		if marker == 0xFF {
+			// Fill byte before the real marker.
+			offset++
+			continue
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			offset += 2
+			continue
+		}
+		// Start of scan: the compressed image follows, metadata comes before it.
+		if marker == 0xDA || marker == 0xD9 {
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[offset+2 : offset+4]))
+		if length < 2 || offset+2+length > len(data) {
+			return nil
+		}
+		segment := data[offset+4 : offset+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:]
+		}
+		offset += 2 + length
+	}
+	return nil
+}
+
+// findHEIFEXIF returns the TIFF payload of the Exif item of a HEIC/HEIF file.
+// goheif slices the item without checking its length, so a malformed file
+// counts as having no EXIF instead of panicking.
+func findHEIFEXIF(data []byte) (tiff []byte) {
+	defer func() {
+		if recover() != nil {
+			tiff = nil
+		}
+	}()
+	payload, err := goheif.ExtractExif(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	// The item normally keeps the JPEG "Exif\0\0" prefix after its header.
+	return bytes.TrimPrefix(payload, []byte("Exif\x00\x00"))
+}
+
+// findWebPEXIF returns the payload of the EXIF chunk of a RIFF WebP file.
+func findWebPEXIF(data []byte) []byte {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil
+	}
+	offset := 12
+	for offset+8 <= len(data) {
+		fourCC := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		if size < 0 || offset+8+size > len(data) {
+			return nil
+		}
+		if fourCC == "EXIF" {
+			// Some encoders keep the JPEG "Exif\0\0" prefix in the chunk.
+			return bytes.TrimPrefix(data[offset+8:offset+8+size], []byte("Exif\x00\x00"))
+		}
+		offset += 8 + size + size%2
+	}
+	return nil
+}
+
+// findPNGEXIF returns the payload of the eXIf chunk, which must precede the
+// image data.
+func findPNGEXIF(data []byte) []byte {
+	if len(data) < 8 || string(data[:8]) != "\x89PNG\r\n\x1a\n" {
+		return nil
+	}
+	offset := 8
+	for offset+8 <= len(data) {
+		size := int(binary.BigEndian.Uint32(data[offset : offset+4]))
+		chunkType := string(data[offset+4 : offset+8])
+		if size < 0 || offset+12+size > len(data) {
+			return nil
+		}
+		switch chunkType {
+		case "eXIf":
+			return data[offset+8 : offset+8+size]
+		case "IDAT", "IEND":
+			return nil
+		}
+		offset += 12 + size
+	}
+	return nil
+}
+
+type exifReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+type exifEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+func parseEXIFTIFF(tiff []byte) (photoEXIF, error) {
+	if len(tiff) < 8 {
+		return photoEXIF{}, errors.New("exif header too short")
+	}
+	r := exifReader{data: tiff}
+	switch string(tiff[0:2]) {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return photoEXIF{}, errors.New("invalid exif byte order")
+	}
+	if r.order.Uint16(tiff[2:4]) != 42 {
+		return photoEXIF{}, errors.New("invalid exif header")
+	}
+
+	ifd0, err := r.readIFD(r.order.Uint32(tiff[4:8]))
+	if err != nil {
+		return photoEXIF{}, err
+	}
+
+	var result photoEXIF
+	if entry, ok := ifd0[exifTagOrientation]; ok {
+		if orientation := int(r.uint32Value(entry)); orientation >= 1 && orientation <= 8 {
+			result.Orientation = orientation
+		}
+	}
+	captured, offset := "", ""
+	if entry, ok := ifd0[exifTagDateTime]; ok {
+		captured = r.ascii(entry)
+	}
+	if entry, ok := ifd0[exifTagExifIFD]; ok {
+		if exifIFD, err := r.readIFD(r.uint32Value(entry)); err == nil {
+			if original, ok := exifIFD[exifTagDateTimeOriginal]; ok {
+				captured = r.ascii(original)
+			}
+			if zone, ok := exifIFD[exifTagOffsetTimeOrig]; ok {
+				offset = r.ascii(zone)
+			}
+		}
+	}
+	if capturedAt, ok := parseEXIFDateTime(captured, offset); ok {
+		result.CapturedAt = &capturedAt
+	}
+
+	if entry, ok := ifd0[exifTagGPSIFD]; ok {
+		if gps, err := r.readIFD(r.uint32Value(entry)); err == nil {
+			lat, latOK := r.coordinate(gps[exifTagGPSLatitude], gps[exifTagGPSLatitudeRef], "S")
+			lng, lngOK := r.coordinate(gps[exifTagGPSLongitude], gps[exifTagGPSLongitudeRef], "W")
+			if latOK && lngOK && math.Abs(lat) <= 90 && math.Abs(lng) <= 180 && (lat != 0 || lng != 0) {
+				result.Lat = &lat
+				result.Lng = &lng
+			}
+		}
+	}
+	return result, nil
+}
+
+func (r exifReader) readIFD(offset uint32) (map[uint16]exifEntry, error) {
+	start := int(offset)
+	if start < 8 || start+2 > len(r.data) {
+		return nil, errors.New("exif directory out of range")
+	}
+	count := int(r.order.Uint16(r.data[start : start+2]))
+	if count > exifMaxIFDEntries || start+2+count*12 > len(r.data) {
+		return nil, errors.New("exif directory out of range")
+	}
+
+	entries := make(map[uint16]exifEntry, count)
+	for i := 0; i < count; i++ {
+		raw := r.data[start+2+i*12 : start+2+(i+1)*12]
+		entry := exifEntry{tag: r.order.Uint16(raw[0:2]), typ: r.order.Uint16(raw[2:4]), count: r.order.Uint32(raw[4:8])}
+		var size uint64
+		switch entry.typ {
+		case exifTypeASCII:
+			size = uint64(entry.count)
+		case exifTypeShort:
+			size = uint64(entry.count) * 2
+		case exifTypeLong:
+			size = uint64(entry.count) * 4
+		case exifTypeRational:
+			size = uint64(entry.count) * 8
+		default:
+			continue
+		}
+		if size <= 4 {
+			entry.value = raw[8 : 8+size]
+		} else {
+			valueOffset := uint64(r.order.Uint32(raw[8:12]))
+			if valueOffset+size > uint64(len(r.data)) {
+				continue
+			}
+			entry.value = r.data[valueOffset : valueOffset+size]
+		}
+		entries[entry.tag] = entry
+	}
+	return entries, nil
+}
+
+func (r exifReader) ascii(entry exifEntry) string {
+	if entry.typ != exifTypeASCII {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(entry.value), "\x00"))
+}
+
+func (r exifReader) uint32Value(entry exifEntry) uint32 {
+	switch {
+	case entry.typ == exifTypeLong && len(entry.value) >= 4:
+		return r.order.Uint32(entry.value)
+	case entry.typ == exifTypeShort && len(entry.value) >= 2:
+		return uint32(r.order.Uint16(entry.value))
+	}
+	return 0
+}
+
+// coordinate converts degrees, minutes and seconds rationals to decimal
+// degrees, negated when the reference is negativeRef.
+func (r exifReader) coordinate(value, ref exifEntry, negativeRef string) (float64, bool) {
+	if value.typ != exifTypeRational || len(value.value) < 24 {
+		return 0, false
+	}
+	parts := [3]float64{}
+	for i := range parts {
+		numerator := r.order.Uint32(value.value[i*8 : i*8+4])
+		denominator := r.order.Uint32(value.value[i*8+4 : i*8+8])
+		if denominator == 0 {
+			return 0, false
+		}
+		parts[i] = float64(numerator) / float64(denominator)
+	}
+	degrees := parts[0] + parts[1]/60 + parts[2]/3600
+	if strings.EqualFold(r.ascii(ref), negativeRef) {
+		degrees = -degrees
+	}
+	return degrees, true
+}
+
+// parseEXIFDateTime reads an EXIF timestamp. Cameras usually write local time
+// without a zone; without OffsetTimeOriginal it is read as Dutch local time.
+func parseEXIFDateTime(value, offset string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if offset != "" {
+		if parsed, err := time.Parse(exifDateTimeOffsetLayout, value+offset); err == nil {
+			return parsed.UTC(), true
+		}
+	}
+	parsed, err := time.ParseInLocation(exifDateTimeLayout, value, adminTimeLocation())
+	if err != nil || parsed.Year() < 1990 {
+		return time.Time{}, false
+	}
+	return parsed.UTC(), true
+}
+
+// photoFinding is a suspicious property of an uploaded photo, recorded as a
+// report event.
+type photoFinding struct {
+	EventType string
+	Metadata  map[string]any
+}
+
+// comparePhotoEXIF checks a photo's capture position and time against the
+// submitted location and the client timestamp. A zero limit disables a check.
+func comparePhotoEXIF(photoIndex int, exif photoEXIF, location ReportLocation, reference time.Time, maxDistanceM float64, maxAge time.Duration) []photoFinding {
+	findings := make([]photoFinding, 0)
+	if maxDistanceM > 0 && exif.hasLocation() {
+		distance := haversineMeters(*exif.Lat, *exif.Lng, location.Lat, location.Lng)
+		if distance > maxDistanceM {
+			findings = append(findings, photoFinding{
+				EventType: "flagged_photo_location_mismatch",
+				Metadata: map[string]any{
+					"photo_index": photoIndex,
+					"distance_m":  math.Round(distance),
+					"threshold_m": maxDistanceM,
+					"accuracy_m":  location.AccuracyM,
+				},
+			})
+		}
+	}
+	if maxAge > 0 && exif.CapturedAt != nil {
+		age := reference.Sub(*exif.CapturedAt)
+		if age > maxAge {
+			findings = append(findings, photoFinding{
+				EventType: "flagged_photo_capture_too_old",
+				Metadata: map[string]any{
+					"photo_index":    photoIndex,
+					"captured_at":    exif.CapturedAt.Format(time.RFC3339),
+					"age_hours":      math.Round(age.Hours()),
+					"threshold_days": maxAge.Hours() / 24,
+				},
+			})
+		}
+	}
+	return findings
+}
+
+// photoEXIFClientTimeMaxLag is how far before now a client timestamp is
+// honoured. The web client drops queued offline reports after 24 hours, so an
+// older client_ts can only be a forged one.
+const photoEXIFClientTimeMaxLag = 24 * time.Hour
+
+// photoEXIFReferenceTime is the client timestamp when it is a valid RFC3339
+// time no later than now, and now otherwise. It never goes back further than
+// photoEXIFClientTimeMaxLag, so a backdated client_ts cannot hide a stale
+// photo.
+func photoEXIFReferenceTime(clientTS *string, now time.Time) time.Time {
+	if clientTS == nil {
+		return now
+	}
+	parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(*clientTS))
+	if err != nil || parsed.After(now) {
+		return now
+	}
+	if earliest := now.Add(-photoEXIFClientTimeMaxLag); parsed.Before(earliest) {
+		return earliest
+	}
+	return parsed.UTC()
+}
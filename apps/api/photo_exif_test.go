@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+// buildTestEXIF writes a little-endian TIFF block with DateTimeOriginal and a
+// GPS position in the northern and eastern hemispheres.
+func buildTestEXIF(lat, lng float64, captured string) []byte {
+	le := binary.LittleEndian
+	buf := make([]byte, 178)
+	copy(buf, "II")
+	le.PutUint16(buf[2:], 42)
+	le.PutUint32(buf[4:], 8)
+
+	entry := func(at int, tag, typ uint16, count, value uint32) {
+		le.PutUint16(buf[at:], tag)
+		le.PutUint16(buf[at+2:], typ)
+		le.PutUint32(buf[at+4:], count)
+		le.PutUint32(buf[at+8:], value)
+	}
+	rational := func(at int, degrees float64) {
+		whole := math.Floor(degrees)
+		minutes := math.Floor((degrees - whole) * 60)
+		seconds := ((degrees-whole)*60 - minutes) * 60
+		for i, part := range []uint32{uint32(whole), 1, uint32(minutes), 1, uint32(math.Round(seconds * 1000)), 1000} {
+			le.PutUint32(buf[at+i*4:], part)
+		}
+	}
+
+	// IFD0 at 8: pointers to the Exif and GPS directories.
+	le.PutUint16(buf[8:], 2)
+	entry(10, exifTagExifIFD, exifTypeLong, 1, 38)
+	entry(22, exifTagGPSIFD, exifTypeLong, 1, 76)
+	// Exif IFD at 38, with the timestamp string at 56.
+	le.PutUint16(buf[38:], 1)
+	entry(40, exifTagDateTimeOriginal, exifTypeASCII, 20, 56)
+	copy(buf[56:76], captured+"\x00")
+	// GPS IFD at 76, with the rationals at 130 and 154.
+	le.PutUint16(buf[76:], 4)
+	entry(78, exifTagGPSLatitudeRef, exifTypeASCII, 2, uint32('N'))
+	entry(90, exifTagGPSLatitude, exifTypeRational, 3, 130)
+	entry(102, exifTagGPSLongitudeRef, exifTypeASCII, 2, uint32('E'))
+	entry(114, exifTagGPSLongitude, exifTypeRational, 3, 154)
+	rational(130, lat)
+	rational(154, lng)
+	return buf
+}
+
+func buildTestJPEGWithEXIF(t *testing.T, tiff []byte) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, minPhotoDimension, minPhotoDimension))
+	img.Set(1, 1, color.RGBA{R: 200, A: 255})
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, img, nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	out := append([]byte{}, encoded.Bytes()[:2]...)
+	out = append(out, app1...)
+	out = append(out, segment...)
+	return append(out, encoded.Bytes()[2:]...)
+}
+
+// buildTestHEICWithEXIF returns testdata/bike.heic with its Exif item
+// replaced by tiff. The new item is appended to the trailing mdat box and the
+// item's iloc extent is pointed at it.
+func buildTestHEICWithEXIF(t *testing.T, tiff []byte) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/bike.heic")
+	if err != nil {
+		t.Fatalf("read heic sample: %v", err)
+	}
+	be := binary.BigEndian
+
+	iloc := bytes.Index(data, []byte("iloc"))
+	mdat := bytes.LastIndex(data, []byte("mdat")) - 4
+	// Version 0 iloc with 4-byte offset, length and base offset fields and
+	// no extent index, as the sample is written.
+	if iloc < 4 || mdat < 0 || data[iloc+4] != 0 || data[iloc+8] != 0x44 || data[iloc+9] != 0x40 {
+		t.Fatalf("unexpected heic sample layout")
+	}
+	exifEntry := -1
+	entry := iloc + 12
+	for i := 0; i < int(be.Uint16(data[iloc+10:])); i++ {
+		base := be.Uint32(data[entry+4:])
+		extents := int(be.Uint16(data[entry+8:]))
+		if extents == 1 && bytes.HasPrefix(data[base+be.Uint32(data[entry+10:])+4:], []byte("Exif\x00\x00")) {
+			exifEntry = entry
+		}
+		entry += 10 + extents*8
+	}
+	if exifEntry < 0 {
+		t.Fatalf("heic sample has no exif item")
+	}
+
+	// The item starts with the offset from its header to the TIFF block.
+	item := append([]byte{0, 0, 0, 6}, append([]byte("Exif\x00\x00"), tiff...)...)
+	out := append(append([]byte{}, data...), item...)
+	be.PutUint32(out[exifEntry+4:], uint32(len(data)))
+	be.PutUint32(out[exifEntry+10:], 0)
+	be.PutUint32(out[exifEntry+14:], uint32(len(item)))
+	be.PutUint32(out[mdat:], uint32(len(out)-mdat))
+	return out
+}
+
+func TestReadPhotoEXIFFromJPEG(t *testing.T) {
+	data := buildTestJPEGWithEXIF(t, buildTestEXIF(52.0907, 5.1214, "2026:10:01 14:30:00"))
+
+	exif, err := readPhotoEXIF("image/jpeg", data)
+	if err != nil {
+		t.Fatalf("readPhotoEXIF returned error: %v", err)
+	}
+	if !exif.hasLocation() || math.Abs(*exif.Lat-52.0907) > 1e-5 || math.Abs(*exif.Lng-5.1214) > 1e-5 {
+		t.Fatalf("unexpected position: %#v", exif)
+	}
+	// Without an offset the timestamp is Dutch local time (CEST in October).
+	if exif.CapturedAt == nil || !exif.CapturedAt.Equal(time.Date(2026, 10, 1, 12, 30, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected capture time: %v", exif.CapturedAt)
+	}
+
+	if _, err := readPhotoEXIF("image/jpeg", data[:40]); err == nil {
+		t.Fatalf("expected a truncated photo to have no readable exif")
+	}
+}
+
+func TestReadPhotoEXIFFromWebP(t *testing.T) {
+	tiff := buildTestEXIF(51.4416, 5.4697, "2026:09:30 08:00:00")
+	chunk := append([]byte("EXIF\x00\x00\x00\x00"), tiff...)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(tiff)))
+	data := append([]byte("RIFF\x00\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00\x08\x00\x00\x00\x00\x00\x00\x00\x00\x00"), chunk...)
+
+	exif, err := readPhotoEXIF("image/webp", data)
+	if err != nil || !exif.hasLocation() || math.Abs(*exif.Lat-51.4416) > 1e-5 {
+		t.Fatalf("expected webp exif position, got %#v, %v", exif, err)
+	}
+}
+
+func TestReadPhotoEXIFFromHEIC(t *testing.T) {
+	data := buildTestHEICWithEXIF(t, buildTestEXIF(52.0907, 5.1214, "2026:10:01 14:30:00"))
+
+	exif, err := readPhotoEXIF("image/heic", data)
+	if err != nil || !exif.hasLocation() || math.Abs(*exif.Lat-52.0907) > 1e-5 || math.Abs(*exif.Lng-5.1214) > 1e-5 {
+		t.Fatalf("expected heic exif position, got %#v, %v", exif, err)
+	}
+	if exif.CapturedAt == nil || !exif.CapturedAt.Equal(time.Date(2026, 10, 1, 12, 30, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected capture time: %v", exif.CapturedAt)
+	}
+
+	for _, truncated := range [][]byte{data[:40], data[:600]} {
+		if _, err := readPhotoEXIF("image/heic", truncated); err == nil {
+			t.Fatalf("expected a truncated heic to have no readable exif")
+		}
+	}
+}
+
+func TestSanitizeAndValidatePhotosReadsEXIFBeforeStripping(t *testing.T) {
+	data := buildTestJPEGWithEXIF(t, buildTestEXIF(52.0907, 5.1214, "2026:10:01 14:30:00"))
+
+	photos, err := sanitizeAndValidatePhotos([]PhotoUpload{{Name: "bike.jpg", MimeType: "image/jpeg", Bytes: data}})
+	if err != nil {
+		t.Fatalf("sanitizeAndValidatePhotos returned error: %v", err)
+	}
+	if photos[0].EXIF == nil || !photos[0].EXIF.hasLocation() {
+		t.Fatalf("expected exif to be kept on the upload")
+	}
+	if findJPEGEXIF(photos[0].Bytes) != nil {
+		t.Fatalf("expected exif to be stripped from the stored bytes")
+	}
+}
+
+func TestComparePhotoEXIF(t *testing.T) {
+	lat, lng := 52.0907, 5.1214
+	captured := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	exif := photoEXIF{Lat: &lat, Lng: &lng, CapturedAt: &captured}
+	reference := captured.Add(2 * time.Hour)
+
+	near := ReportLocation{Lat: 52.0910, Lng: 5.1214, AccuracyM: 20}
+	if findings := comparePhotoEXIF(0, exif, near, reference, 500, 7*24*time.Hour); len(findings) != 0 {
+		t.Fatalf("expected no findings, got %#v", findings)
+	}
+
+	far := ReportLocation{Lat: 52.3728, Lng: 4.8936, AccuracyM: 20}
+	findings := comparePhotoEXIF(1, exif, far, captured.Add(30*24*time.Hour), 500, 7*24*time.Hour)
+	if len(findings) != 2 || findings[0].EventType != "flagged_photo_location_mismatch" || findings[1].EventType != "flagged_photo_capture_too_old" {
+		t.Fatalf("expected location and age findings, got %#v", findings)
+	}
+	if findings[0].Metadata["photo_index"] != 1 || findings[1].Metadata["age_hours"] != float64(720) {
+		t.Fatalf("unexpected finding metadata: %#v", findings)
+	}
+
+	if findings := comparePhotoEXIF(0, exif, far, captured.Add(30*24*time.Hour), 0, 0); len(findings) != 0 {
+		t.Fatalf("expected zero limits to disable the checks, got %#v", findings)
+	}
+}
+
+func TestPhotoEXIFReferenceTime(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	earlier := "2026-10-18T11:00:00+02:00"
+	future := "2026-10-19T12:00:00Z"
+	invalid := "yesterday"
+
+	if got := photoEXIFReferenceTime(&earlier, now); !got.Equal(time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the client timestamp, got %v", got)
+	}
+	for _, raw := range []*string{nil, &future, &invalid} {
+		if got := photoEXIFReferenceTime(raw, now); !got.Equal(now) {
+			t.Fatalf("expected now for %v, got %v", raw, got)
+		}
+	}
+
+	backdated := "2025-01-01T00:00:00Z"
+	if got := photoEXIFReferenceTime(&backdated, now); !got.Equal(now.Add(-photoEXIFClientTimeMaxLag)) {
+		t.Fatalf("expected a backdated client timestamp to be bounded, got %v", got)
+	}
+	captured := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if findings := comparePhotoEXIF(0, photoEXIF{CapturedAt: &captured}, ReportLocation{}, photoEXIFReferenceTime(&backdated, now), 0, 7*24*time.Hour); len(findings) != 1 {
+		t.Fatalf("expected a backdated client timestamp to still flag the old photo, got %#v", findings)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+	"math/bits"
+)
+
+const (
+	// The difference hash compares neighbouring cells of a 9x8 grayscale
+	// grid, giving 64 bits.
+	photoHashGridWidth  = photoHashGridHeight + 1
+	photoHashGridHeight = 8
+
+	// photoHashSimilarBits is the Hamming distance at which two photos no
+	// longer count as similar for dedupe scoring. Unrelated photos differ in
+	// about 32 bits.
+	photoHashSimilarBits = 16
+	// photoHashReuseMaxBits is the distance up to which a photo counts as
+	// the same picture, allowing for re-encoding and small crops.
+	photoHashReuseMaxBits = 4
+	// photoSimilarityScoreFloor is the similarity from which photos count as
+	// evidence in dedupe scoring (8 differing bits or fewer). Below it the
+	// photos are treated like missing ones, so two different shots of the
+	// same bike do not push a candidate below one without hashes.
+	photoSimilarityScoreFloor = 0.5
+
+	defaultPhotoReuseMinDistanceM = 1000
+	maxPhotoReuseMatches          = 5
+)
+
+// photoDifferenceHash returns a 64-bit dHash of img: the image is averaged
+// down to a 9x8 grayscale grid and each bit records whether a cell is darker
+// than its right-hand neighbour. Similar pictures have hashes that differ in
+// few bits, regardless of resolution or compression.
+func photoDifferenceHash(img image.Image) uint64 {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return 0
+	}
+
+	luma := func(x, y int) float64 {
+		return float64(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+	}
+	// Decoded JPEG and WebP photos are YCbCr, whose Y plane is the luma.
+	switch src := img.(type) {
+	case *image.YCbCr:
+		luma = func(x, y int) float64 { return float64(src.Y[src.YOffset(x, y)]) }
+	case *image.NYCbCrA:
+		luma = func(x, y int) float64 { return float64(src.Y[src.YOffset(x, y)]) }
+	}
+
+	var sums, counts [photoHashGridHeight][photoHashGridWidth]float64
+	for y := 0; y < height; y++ {
+		row := y * photoHashGridHeight / height
+		for x := 0; x < width; x++ {
+			col := x * photoHashGridWidth / width
+			sums[row][col] += luma(bounds.Min.X+x, bounds.Min.Y+y)
+			counts[row][col]++
+		}
+	}
+
+	var hash uint64
+	for row := 0; row < photoHashGridHeight; row++ {
+		for col := 0; col < photoHashGridWidth-1; col++ {
+			hash <<= 1
+			if sums[row][col]/math.Max(counts[row][col], 1) < sums[row][col+1]/math.Max(counts[row][col+1], 1) {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+func photoHashDistance(left, right uint64) int {
+	return bits.OnesCount64(left ^ right)
+}
+
+// photoHashSimilarity scores the closest pair of photos between two reports
+// from 1 (identical) down to 0 at photoHashSimilarBits or more.
+func photoHashSimilarity(incoming, candidate []uint64) float64 {
+	best := 0.0
+	for _, left := range incoming {
+		for _, right := range candidate {
+			best = math.Max(best, clamp01(1-float64(photoHashDistance(left, right))/photoHashSimilarBits))
+		}
+	}
+	return best
+}
+
+// photoReuseMatch is a stored photo of another report that is nearly
+// identical to an uploaded one.
+type photoReuseMatch struct {
+	ReportID     int
+	HashDistance int
+	Lat          float64
+	Lng          float64
+}
+
+// photoReuseFindings turns nearly identical photos on reports further away
+// than minDistanceM into flagged_photo_reused events. The same bike cannot be
+// in two distant places, so this points at photos copied between reports.
+// A minDistanceM of 0 disables the check.
+func photoReuseFindings(photoIndex int, location ReportLocation, matches []photoReuseMatch, minDistanceM float64) []photoFinding {
+	if minDistanceM <= 0 {
+		return nil
+	}
+	findings := make([]photoFinding, 0)
+	for _, match := range matches {
+		distance := haversineMeters(location.Lat, location.Lng, match.Lat, match.Lng)
+		if distance <= minDistanceM {
+			continue
+		}
+		findings = append(findings, photoFinding{
+			EventType: "flagged_photo_reused",
+			Metadata: map[string]any{
+				"photo_index":     photoIndex,
+				"other_report_id": match.ReportID,
+				"hash_distance":   match.HashDistance,
+				"distance_m":      math.Round(distance),
+			},
+		})
+	}
+	return findings
+}
+
+// listPhotoHashesByReport returns the perceptual hashes of the photos of the
+// given reports. Photos stored before hashing was added have none.
+func (a *App) listPhotoHashesByReport(ctx context.Context, reportIDs []int) (map[int][]uint64, error) {
+	hashes := make(map[int][]uint64)
+	if len(reportIDs) == 0 {
+		return hashes, nil
+	}
+	ids := make([]int64, 0, len(reportIDs))
+	for _, id := range reportIDs {
+		ids = append(ids, int64(id))
+	}
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT report_id, perceptual_hash
+		FROM report_photos
+		WHERE report_id = ANY($1) AND perceptual_hash IS NOT NULL
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var reportID int
+		var hash int64
+		if err := rows.Scan(&reportID, &hash); err != nil {
+			return nil, err
+		}
+		hashes[reportID] = append(hashes[reportID], uint64(hash))
+	}
+	return hashes, rows.Err()
+}
+
+// findReusedPhotos returns photos of other reports whose hash is within
+// photoHashReuseMaxBits of hash, closest first.
+func (a *App) findReusedPhotos(ctx context.Context, reportID int, hash uint64) ([]photoReuseMatch, error) {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT report_id, hash_distance, lat, lng
+		FROM (
+			SELECT rp.report_id, r.lat, r.lng,
+				LENGTH(REPLACE(((rp.perceptual_hash # $2)::bit(64))::text, '0', '')) AS hash_distance
+			FROM report_photos rp
+			JOIN reports r ON r.id = rp.report_id
+			WHERE rp.perceptual_hash IS NOT NULL AND rp.report_id <> $1
+		) matches
+		WHERE hash_distance <= $3
+		ORDER BY hash_distance ASC, report_id DESC
+		LIMIT $4
+	`, reportID, int64(hash), photoHashReuseMaxBits, maxPhotoReuseMatches)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	matches := make([]photoReuseMatch, 0)
+	for rows.Next() {
+		var match photoReuseMatch
+		if err := rows.Scan(&match.ReportID, &match.HashDistance, &match.Lat, &match.Lng); err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, rows.Err()
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+	"time"
+)
+
+// buildTestGradientJPEG draws a diagonal gradient, optionally mirrored, so two
+// photos can share or differ in structure.
+func buildTestGradientJPEG(t *testing.T, width, height int, mirrored bool, quality int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			fx := x * 255 / width
+			if mirrored {
+				fx = 255 - fx
+			}
+			fy := y * 255 / height
+			img.Set(x, y, color.RGBA{R: uint8((fx + fy) / 2), G: uint8(fx), B: uint8(fy * fx / 255), A: 255})
+		}
+	}
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, img, &jpeg.Options{Quality: quality}); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	return encoded.Bytes()
+}
+
+func TestSanitizedPhotoHashMatchesResizedCopies(t *testing.T) {
+	hash := func(data []byte) uint64 {
+		t.Helper()
+		sanitized, err := sanitizePhotoBytes("image/jpeg", data, 0)
+		if err != nil || sanitized.Hash == nil {
+			t.Fatalf("sanitizePhotoBytes returned no hash: %v", err)
+		}
+		return *sanitized.Hash
+	}
+	original := hash(buildTestGradientJPEG(t, 800, 600, false, 90))
+	resized := hash(buildTestGradientJPEG(t, 400, 300, false, 40))
+	mirrored := hash(buildTestGradientJPEG(t, 800, 600, true, 90))
+
+	if distance := photoHashDistance(original, resized); distance > photoHashReuseMaxBits {
+		t.Fatalf("expected a resized copy to be near-identical, got distance %d", distance)
+	}
+	if distance := photoHashDistance(original, mirrored); distance < photoHashSimilarBits {
+		t.Fatalf("expected a mirrored photo to differ, got distance %d", distance)
+	}
+}
+
+func TestSanitizeAndValidatePhotosHashesStoredPhoto(t *testing.T) {
+	photos, err := sanitizeAndValidatePhotos([]PhotoUpload{{Name: "bike.jpg", MimeType: "image/jpeg", Bytes: buildTestGradientJPEG(t, 400, 300, false, 90)}})
+	if err != nil {
+		t.Fatalf("sanitizeAndValidatePhotos returned error: %v", err)
+	}
+	if photos[0].PerceptualHash == nil || *photos[0].PerceptualHash == 0 {
+		t.Fatalf("expected a perceptual hash on the upload")
+	}
+}
+
+func TestPhotoHashSimilarity(t *testing.T) {
+	base := uint64(0xF0F0_F0F0_F0F0_F0F0)
+	if got := photoHashSimilarity([]uint64{base}, []uint64{^base, base}); got != 1 {
+		t.Fatalf("expected the closest pair to count, got %v", got)
+	}
+	if got := photoHashSimilarity([]uint64{base}, []uint64{base ^ 0xFF}); got != 0.5 {
+		t.Fatalf("expected 8 differing bits to score 0.5, got %v", got)
+	}
+	if got := photoHashSimilarity([]uint64{base}, []uint64{^base}); got != 0 {
+		t.Fatalf("expected unrelated hashes to score 0, got %v", got)
+	}
+}
+
+func TestScoreDuplicateCandidateWeighsPhotoSimilarity(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	incoming := Report{Location: ReportLocation{Lat: 52.0, Lng: 5.0}, Tags: []string{"rusted"}}
+	candidate := Report{ID: 7, Location: ReportLocation{Lat: 52.0, Lng: 5.0}, Tags: []string{"rusted"}, CreatedAt: now.Format(time.RFC3339)}
+	params := defaultSignalParams()
+
+	same, similar, different := 1.0, photoSimilarityScoreFloor, 0.0
+	if scored := scoreDuplicateCandidate(incoming, candidate, &same, now, params); scored.Score != 1 {
+		t.Fatalf("expected a full score for identical photos, got %v", scored.Score)
+	}
+	if scored := scoreDuplicateCandidate(incoming, candidate, &similar, now, params); scored.Score != 1 {
+		t.Fatalf("expected photos at the floor not to lower a full score, got %v", scored.Score)
+	}
+	if scored := scoreDuplicateCandidate(incoming, candidate, &different, now, params); scored.Score != 1 {
+		t.Fatalf("expected unrelated photos to be left out rather than cap the score, got %v", scored.Score)
+	}
+	if scored := scoreDuplicateCandidate(incoming, candidate, nil, now, params); scored.Score != 1 {
+		t.Fatalf("expected missing hashes not to lower the score, got %v", scored.Score)
+	}
+}
+
+func TestScoreDuplicateCandidateIsMonotonicInPhotoSimilarity(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	params := defaultSignalParams()
+	// Half the dedupe radius away, one of two tags shared and a week old, so
+	// the score without photos is well below 1.
+	incoming := Report{Location: ReportLocation{Lat: 52.0, Lng: 5.0}, Tags: []string{"rusted", "flat_tires"}}
+	candidate := Report{
+		ID:        7,
+		Location:  ReportLocation{Lat: 52.0 + params.DedupeRadiusMeters/2/111_320, Lng: 5.0},
+		Tags:      []string{"rusted"},
+		CreatedAt: now.Add(-7 * 24 * time.Hour).Format(time.RFC3339),
+	}
+
+	withoutPhotos := scoreDuplicateCandidate(incoming, candidate, nil, now, params).Score
+	previous := withoutPhotos
+	for _, similarity := range []float64{0, 0.25, photoSimilarityScoreFloor - 0.01, photoSimilarityScoreFloor, photoSimilarityScoreFloor + 0.01, 0.75, 1} {
+		score := scoreDuplicateCandidate(incoming, candidate, &similarity, now, params).Score
+		if score < previous {
+			t.Fatalf("score dropped from %v to %v at similarity %v", previous, score, similarity)
+		}
+		previous = score
+	}
+	if previous <= withoutPhotos {
+		t.Fatalf("expected identical photos to raise the score above %v, got %v", withoutPhotos, previous)
+	}
+}
+
+func TestPhotoReuseFindings(t *testing.T) {
+	location := ReportLocation{Lat: 52.0907, Lng: 5.1214}
+	matches := []photoReuseMatch{
+		{ReportID: 11, HashDistance: 0, Lat: 52.0908, Lng: 5.1214},
+		{ReportID: 12, HashDistance: 2, Lat: 52.3728, Lng: 4.8936},
+	}
+
+	findings := photoReuseFindings(1, location, matches, 1000)
+	if len(findings) != 1 || findings[0].EventType != "flagged_photo_reused" {
+		t.Fatalf("expected one reuse finding for the distant report, got %#v", findings)
+	}
+	if findings[0].Metadata["other_report_id"] != 12 || findings[0].Metadata["hash_distance"] != 2 || findings[0].Metadata["photo_index"] != 1 {
+		t.Fatalf("unexpected finding metadata: %#v", findings[0].Metadata)
+	}
+	if findings := photoReuseFindings(1, location, matches, 0); len(findings) != 0 {
+		t.Fatalf("expected 0 to disable the check, got %#v", findings)
+	}
+}
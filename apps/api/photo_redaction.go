@@ -0,0 +1,416 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	photoRedactionBlur     = "blur"
+	photoRedactionPixelate = "pixelate"
+
+	maxPhotoRedactionRegions = 20
+	// photoRedactionCells is how many samples survive along the longest side
+	// of a region. Only these averages are kept, which is what makes a
+	// redaction irreversible: a 300 px wide plate keeps eight columns.
+	photoRedactionCells    = 8
+	minPhotoRedactionBlock = 4
+
+	defaultPhotoRedactionRetention = 30 * 24 * time.Hour
+)
+
+// PhotoRedactionRegion is a rectangle as fractions of the photo's width and
+// height, so it does not depend on the size the photo was drawn at.
+type PhotoRedactionRegion struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"w"`
+	Height float64 `json:"h"`
+}
+
+// PhotoRedaction is an admin request to blur or pixelate parts of a photo.
+type PhotoRedaction struct {
+	Mode    string
+	Regions []PhotoRedactionRegion
+}
+
+// PhotoRedactionRecord is a stored redaction. PreviousStoragePath is the
+// version it replaced, empty once that file is deleted.
+type PhotoRedactionRecord struct {
+	ID                  int
+	PhotoID             int
+	Version             int
+	Mode                string
+	RegionCount         int
+	PreviousStoragePath string
+	RetainUntil         *time.Time
+	CreatedBy           string
+	CreatedAt           time.Time
+}
+
+// parsePhotoRedactionRegions reads the regions drawn in the admin editor.
+// Regions are clipped to the photo; empty ones are rejected.
+func parsePhotoRedactionRegions(raw string) ([]PhotoRedactionRegion, error) {
+	var regions []PhotoRedactionRegion
+	if err := json.Unmarshal([]byte(raw), &regions); err != nil {
+		return nil, fmt.Errorf("invalid regions: %w", err)
+	}
+	if len(regions) == 0 {
+		return nil, errors.New("no regions")
+	}
+	if len(regions) > maxPhotoRedactionRegions {
+		return nil, fmt.Errorf("at most %d regions", maxPhotoRedactionRegions)
+	}
+	for i, region := range regions {
+		for _, value := range []float64{region.X, region.Y, region.Width, region.Height} {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				return nil, fmt.Errorf("region %d is not a number", i)
+			}
+		}
+		x0, y0 := math.Max(region.X, 0), math.Max(region.Y, 0)
+		x1, y1 := math.Min(region.X+region.Width, 1), math.Min(region.Y+region.Height, 1)
+		if x1 <= x0 || y1 <= y0 {
+			return nil, fmt.Errorf("region %d is empty", i)
+		}
+		regions[i] = PhotoRedactionRegion{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+	}
+	return regions, nil
+}
+
+func isValidPhotoRedactionMode(mode string) bool {
+	return mode == photoRedactionBlur || mode == photoRedactionPixelate
+}
+
+// redactImage returns a copy of img with every region reduced to a coarse
+// grid of average colours. Pixelate draws the grid as blocks; blur scales it
+// back up smoothly.
+func redactImage(img image.Image, regions []PhotoRedactionRegion, mode string) *image.RGBA {
+	bounds := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(out, out.Bounds(), img, bounds.Min, draw.Src)
+
+	width, height := float64(bounds.Dx()), float64(bounds.Dy())
+	for _, region := range regions {
+		rect := image.Rect(
+			int(math.Floor(region.X*width)), int(math.Floor(region.Y*height)),
+			int(math.Ceil((region.X+region.Width)*width)), int(math.Ceil((region.Y+region.Height)*height)),
+		).Intersect(out.Bounds())
+		if rect.Empty() {
+			continue
+		}
+
+		block := max(minPhotoRedactionBlock, (max(rect.Dx(), rect.Dy())+photoRedactionCells-1)/photoRedactionCells)
+		cells := image.NewRGBA(image.Rect(0, 0, (rect.Dx()+block-1)/block, (rect.Dy()+block-1)/block))
+		for cy := 0; cy < cells.Bounds().Dy(); cy++ {
+			for cx := 0; cx < cells.Bounds().Dx(); cx++ {
+				cell := image.Rect(rect.Min.X+cx*block, rect.Min.Y+cy*block, rect.Min.X+(cx+1)*block, rect.Min.Y+(cy+1)*block).Intersect(rect)
+				average := averageRGBA(out, cell)
+				cells.SetRGBA(cx, cy, average)
+				if mode == photoRedactionPixelate {
+					draw.Draw(out, cell, image.NewUniform(average), image.Point{}, draw.Src)
+				}
+			}
+		}
+		if mode == photoRedactionBlur {
+			draw.BiLinear.Scale(out, rect, cells, cells.Bounds(), draw.Src, nil)
+		}
+	}
+	return out
+}
+
+func averageRGBA(img *image.RGBA, rect image.Rectangle) color.RGBA {
+	var r, g, b, count int
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			pixel := img.RGBAAt(x, y)
+			r += int(pixel.R)
+			g += int(pixel.G)
+			b += int(pixel.B)
+			count++
+		}
+	}
+	if count == 0 {
+		return color.RGBA{A: 255}
+	}
+	return color.RGBA{R: uint8(r / count), G: uint8(g / count), B: uint8(b / count), A: 255}
+}
+
+func (a *App) adminApplyPhotoRedaction(ctx context.Context, reportID, photoID int, redaction PhotoRedaction, session OperatorSession) error {
+	if a.adminRedactPhoto != nil {
+		return a.adminRedactPhoto(ctx, reportID, photoID, redaction, session)
+	}
+	_, err := a.redactReportPhoto(ctx, reportID, photoID, redaction, session)
+	return err
+}
+
+// redactReportPhoto stores a redacted copy of a photo as its next version and
+// records a photo_redacted event. The version it replaces is moved out of the
+// uploads tree and kept for PHOTO_REDACTION_ORIGINAL_RETENTION_DAYS, or
+// deleted when that is 0. Cached variants are removed so they are rebuilt
+// from the redacted photo.
+func (a *App) redactReportPhoto(ctx context.Context, reportID, photoID int, redaction PhotoRedaction, session OperatorSession) (*ReportPhoto, error) {
+	if !isValidPhotoRedactionMode(redaction.Mode) || len(redaction.Regions) == 0 {
+		return nil, &apiError{Status: http.StatusBadRequest, Code: "invalid_redaction", Message: "Redaction needs a mode and at least one region"}
+	}
+	photo, err := a.getReportPhotoByID(ctx, reportID, photoID)
+	if err != nil {
+		return nil, err
+	}
+	if photo == nil {
+		return nil, &apiError{Status: http.StatusNotFound, Code: "photo_not_found", Message: "Photo not found"}
+	}
+
+	store := a.blobStore()
+	currentPath, err := a.resolveExistingPhotoStoragePath(ctx, photo.StoragePath)
+	if err != nil {
+		return nil, err
+	}
+	original, err := store.Get(ctx, currentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := a.acquirePhotoDecodeSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decodePhoto(photo.MimeType, original)
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("decode photo %d: %w", photo.ID, err)
+	}
+	redacted := redactImage(decoded, redaction.Regions, redaction.Mode)
+	buffer := bytes.NewBuffer(nil)
+	err = jpeg.Encode(buffer, redacted, &jpeg.Options{Quality: sanitizedJPEGQuality})
+	hash := photoDifferenceHash(redacted)
+	release()
+	if err != nil {
+		return nil, err
+	}
+
+	fileName, err := generatePhotoStorageFileName(".jpg")
+	if err != nil {
+		return nil, err
+	}
+	newPath := path.Join(path.Dir(currentPath), fileName)
+	if err := store.Put(ctx, newPath, buffer.Bytes(), "image/jpeg"); err != nil {
+		return nil, err
+	}
+
+	// The replaced version leaves the uploads tree before the new one is
+	// committed, so no code path can serve it by its old path afterwards.
+	var retainedPath string
+	var retainUntil *time.Time
+	if a.cfg.PhotoRedactionRetention > 0 {
+		// The random part keeps concurrent redactions of the same version
+		// from writing, and then cleaning up, each other's copy.
+		retainedName := strings.TrimSuffix(fileName, path.Ext(fileName))
+		retainedPath = path.Join("redaction-originals", "reports", strconv.Itoa(reportID), fmt.Sprintf("%d-v%d-%s%s", photo.ID, photo.Version, retainedName, path.Ext(currentPath)))
+		if err := store.Put(ctx, retainedPath, original, photo.MimeType); err != nil {
+			_ = store.Delete(ctx, newPath)
+			return nil, err
+		}
+		if err := store.Delete(ctx, currentPath); err != nil {
+			_ = store.Delete(ctx, retainedPath)
+			_ = store.Delete(ctx, newPath)
+			return nil, err
+		}
+		until := time.Now().UTC().Add(a.cfg.PhotoRedactionRetention)
+		retainUntil = &until
+	}
+	undoFiles := func() {
+		if retainedPath != "" {
+			if err := store.Put(ctx, currentPath, original, photo.MimeType); err == nil {
+				_ = store.Delete(ctx, retainedPath)
+			}
+		}
+		_ = store.Delete(ctx, newPath)
+	}
+
+	regionsJSON, err := json.Marshal(redaction.Regions)
+	if err != nil {
+		undoFiles()
+		return nil, err
+	}
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		undoFiles()
+		return nil, err
+	}
+	// Only the version that was read may be replaced. When another
+	// redaction committed first, its files are the current ones: this run
+	// removes only its own copies and leaves the original where that one
+	// put it.
+	var version int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE report_photos
+		SET storage_path = $1, mime_type = 'image/jpeg', size_bytes = $2, perceptual_hash = $3, sha256 = $4, version = version + 1
+		WHERE id = $5 AND version = $6
+		RETURNING version
+	`, newPath, buffer.Len(), int64(hash), sha256Hex(buffer.Bytes()), photo.ID, photo.Version).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = tx.Rollback()
+		_ = store.Delete(ctx, newPath)
+		if retainedPath != "" {
+			_ = store.Delete(ctx, retainedPath)
+		}
+		return nil, &apiError{Status: http.StatusConflict, Code: "photo_redaction_conflict", Message: "The photo was changed while it was being redacted; reload it and try again"}
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		undoFiles()
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO report_photo_redactions (photo_id, version, mode, regions, previous_storage_path, retain_until, created_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+	`, photo.ID, version, redaction.Mode, string(regionsJSON), retainedPath, retainUntil, session.Email); err != nil {
+		_ = tx.Rollback()
+		undoFiles()
+		return nil, err
+	}
+	metadata := map[string]any{
+		"photo_id":          photo.ID,
+		"version":           version,
+		"mode":              redaction.Mode,
+		"regions":           len(redaction.Regions),
+		"original_retained": retainedPath != "",
+	}
+	if retainUntil != nil {
+		metadata["retain_until"] = retainUntil.Format(time.RFC3339)
+	}
+	if err := a.addEventTx(ctx, tx, reportID, "photo_redacted", session.Email, metadata); err != nil {
+		_ = tx.Rollback()
+		undoFiles()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		undoFiles()
+		return nil, err
+	}
+
+	if retainedPath == "" {
+		if err := store.Delete(ctx, currentPath); err != nil {
+			a.log.Error("failed to delete redacted photo version", "photo_id", photo.ID, "path", currentPath, "err", err)
+		}
+	}
+	a.removePhotoVariants(ctx, *photo)
+
+	photo.StoragePath = newPath
+	photo.MimeType = "image/jpeg"
+	photo.SizeBytes = int64(buffer.Len())
+	photo.Version = version
+	return photo, nil
+}
+
+// removePhotoVariants deletes the cached thumb and medium variants of the
+// given version of a photo.
+func (a *App) removePhotoVariants(ctx context.Context, photo ReportPhoto) {
+	for size := range photoVariantMaxSides {
+		if err := a.blobStore().Delete(ctx, photoVariantStoragePath(photo.ReportID, photo.ID, photo.Version, size)); err != nil {
+			a.log.Error("failed to remove photo variant", "photo_id", photo.ID, "size", size, "err", err)
+		}
+	}
+}
+
+func (a *App) listPhotoRedactions(ctx context.Context, photoID int) ([]PhotoRedactionRecord, error) {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT id, photo_id, version, mode, jsonb_array_length(regions), COALESCE(previous_storage_path, ''), retain_until, created_by, created_at
+		FROM report_photo_redactions
+		WHERE photo_id = $1
+		ORDER BY version DESC
+	`, photoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]PhotoRedactionRecord, 0)
+	for rows.Next() {
+		var record PhotoRedactionRecord
+		var retainUntil sql.NullTime
+		if err := rows.Scan(&record.ID, &record.PhotoID, &record.Version, &record.Mode, &record.RegionCount, &record.PreviousStoragePath, &retainUntil, &record.CreatedBy, &record.CreatedAt); err != nil {
+			return nil, err
+		}
+		if retainUntil.Valid {
+			record.RetainUntil = &retainUntil.Time
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// getRetainedRedactionOriginal returns the storage path of the version that a
+// redaction replaced, if it is still retained.
+func (a *App) getRetainedRedactionOriginal(ctx context.Context, reportID, redactionID int) (string, error) {
+	var storagePath string
+	err := a.db.QueryRowContext(ctx, `
+		SELECT r.previous_storage_path
+		FROM report_photo_redactions r
+		JOIN report_photos p ON p.id = r.photo_id
+		WHERE r.id = $1 AND p.report_id = $2 AND r.previous_storage_path IS NOT NULL AND r.retain_until > NOW()
+	`, redactionID, reportID).Scan(&storagePath)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return storagePath, err
+}
+
+// purgeExpiredRedactionOriginals deletes retained photo versions whose
+// retention has passed. It returns how many files were removed.
+func (a *App) purgeExpiredRedactionOriginals(ctx context.Context, now time.Time) (int, error) {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT id, previous_storage_path
+		FROM report_photo_redactions
+		WHERE previous_storage_path IS NOT NULL AND retain_until <= $1
+	`, now)
+	if err != nil {
+		return 0, err
+	}
+	type expired struct {
+		id          int
+		storagePath string
+	}
+	list := make([]expired, 0)
+	for rows.Next() {
+		var item expired
+		if err := rows.Scan(&item.id, &item.storagePath); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		list = append(list, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, item := range list {
+		if err := a.blobStore().Delete(ctx, item.storagePath); err != nil {
+			return purged, err
+		}
+		if _, err := a.db.ExecContext(ctx, `
+			UPDATE report_photo_redactions SET previous_storage_path = NULL WHERE id = $1
+		`, item.id); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
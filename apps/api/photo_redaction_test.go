@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePhotoRedactionRegions(t *testing.T) {
+	regions, err := parsePhotoRedactionRegions(`[{"x":-0.1,"y":0.5,"w":0.3,"h":0.8}]`)
+	if err != nil {
+		t.Fatalf("parsePhotoRedactionRegions returned error: %v", err)
+	}
+	clipped := regions[0]
+	if clipped.X != 0 || clipped.Y != 0.5 || clipped.Height != 0.5 || clipped.Width < 0.199 || clipped.Width > 0.201 {
+		t.Fatalf("expected the region to be clipped to the photo, got %#v", clipped)
+	}
+
+	for _, raw := range []string{"", "[]", `[{"x":1.2,"y":0,"w":0.2,"h":0.2}]`, `[{"x":0.1,"y":0.1,"w":0,"h":0.2}]`, `{"x":0}`} {
+		if _, err := parsePhotoRedactionRegions(raw); err == nil {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+	tooMany := "[" + strings.TrimSuffix(strings.Repeat(`{"x":0,"y":0,"w":0.1,"h":0.1},`, maxPhotoRedactionRegions+1), ",") + "]"
+	if _, err := parsePhotoRedactionRegions(tooMany); err == nil {
+		t.Fatalf("expected more than %d regions to be rejected", maxPhotoRedactionRegions)
+	}
+}
+
+func TestRedactImageOnlyChangesRegions(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: uint8(x), G: uint8(y * 2), B: uint8((x * y) % 256), A: 255})
+		}
+	}
+	region := PhotoRedactionRegion{X: 0.5, Y: 0, Width: 0.5, Height: 1}
+
+	pixelated := redactImage(img, []PhotoRedactionRegion{region}, photoRedactionPixelate)
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			if pixelated.RGBAAt(x, y) != img.RGBAAt(x, y) {
+				t.Fatalf("expected pixel %d,%d outside the region to be unchanged", x, y)
+			}
+		}
+	}
+	// 100 px regions are averaged in 13 px blocks.
+	if pixelated.RGBAAt(100, 0) != pixelated.RGBAAt(112, 12) {
+		t.Fatalf("expected a pixelated block to be a single colour")
+	}
+	if pixelated.RGBAAt(150, 50) == img.RGBAAt(150, 50) && pixelated.RGBAAt(199, 99) == img.RGBAAt(199, 99) {
+		t.Fatalf("expected pixels inside the region to change")
+	}
+
+	blurred := redactImage(img, []PhotoRedactionRegion{region}, photoRedactionBlur)
+	if blurred.RGBAAt(10, 10) != img.RGBAAt(10, 10) {
+		t.Fatalf("expected blur to leave pixels outside the region unchanged")
+	}
+	if blurred.RGBAAt(199, 99) == img.RGBAAt(199, 99) {
+		t.Fatalf("expected blur to change pixels inside the region")
+	}
+}
+
+func TestWithPhotoVersion(t *testing.T) {
+	if got := withPhotoVersion("/api/v1/operator/reports/1/photos/2", 1); got != "/api/v1/operator/reports/1/photos/2" {
+		t.Fatalf("expected the first version to keep its URL, got %q", got)
+	}
+	if got := withPhotoVersion("/api/v1/operator/reports/1/photos/2?size=thumb", 3); got != "/api/v1/operator/reports/1/photos/2?size=thumb&v=3" {
+		t.Fatalf("unexpected versioned URL %q", got)
+	}
+}
+
+func TestAdminPhotoRedactSubmit(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	var applied PhotoRedaction
+	app.adminRedactPhoto = func(ctx context.Context, reportID, photoID int, redaction PhotoRedaction, session OperatorSession) error {
+		if reportID != 7 || photoID != 3 || session.Email != "operator@example.com" {
+			t.Fatalf("unexpected redaction target %d/%d by %q", reportID, photoID, session.Email)
+		}
+		applied = redaction
+		return nil
+	}
+
+	form := url.Values{"mode": {"blur"}, "regions": {`[{"x":0.1,"y":0.2,"w":0.3,"h":0.1}]`}}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodPost, "/bikeadmin/reports/7/photos/3/redact", form.Encode()))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", rec.Code)
+	}
+	if location := rec.Header().Get("Location"); !strings.HasPrefix(location, "/bikeadmin/reports/7?") || !strings.Contains(location, "notice=") {
+		t.Fatalf("expected a notice redirect to the report, got %q", location)
+	}
+	if applied.Mode != photoRedactionBlur || len(applied.Regions) != 1 {
+		t.Fatalf("unexpected redaction %#v", applied)
+	}
+
+	app.adminRedactPhoto = func(ctx context.Context, reportID, photoID int, redaction PhotoRedaction, session OperatorSession) error {
+		t.Fatalf("expected an invalid request not to be applied")
+		return nil
+	}
+	form.Set("regions", "[]")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodPost, "/bikeadmin/reports/7/photos/3/redact", form.Encode()))
+	if location := rec.Header().Get("Location"); !strings.HasPrefix(location, "/bikeadmin/reports/7/photos/3/redact?") || !strings.Contains(location, "error=") {
+		t.Fatalf("expected an error redirect to the editor, got %q", location)
+	}
+}
+
+func TestAdminPhotoRedactPageListsRetainedOriginals(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	app.cfg.PhotoRedactionRetention = defaultPhotoRedactionRetention
+	app.adminGetReportDetails = func(ctx context.Context, reportID int) (*OperatorReportDetails, error) {
+		return &OperatorReportDetails{
+			Report: Report{ID: reportID, PublicID: "PUB-7"},
+			Photos: []OperatorReportPhotoView{{ID: 3, URL: "/api/v1/operator/reports/7/photos/3?v=2"}},
+		}, nil
+	}
+	retainUntil := time.Now().UTC().Add(24 * time.Hour)
+	app.adminListPhotoRedactions = func(ctx context.Context, photoID int) ([]PhotoRedactionRecord, error) {
+		return []PhotoRedactionRecord{
+			{ID: 5, PhotoID: 3, Version: 2, Mode: photoRedactionBlur, RegionCount: 1, PreviousStoragePath: "redaction-originals/reports/7/3-v1.jpg", RetainUntil: &retainUntil, CreatedBy: "operator@example.com", CreatedAt: time.Now().UTC()},
+			{ID: 4, PhotoID: 3, Version: 1, Mode: photoRedactionPixelate, RegionCount: 2, CreatedBy: "operator@example.com", CreatedAt: time.Now().UTC()},
+		}, nil
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodGet, "/bikeadmin/reports/7/photos/3/redact", ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "/bikeadmin/reports/7/redactions/5/original") {
+		t.Fatalf("expected a link to the retained original")
+	}
+	if strings.Contains(body, "/bikeadmin/reports/7/redactions/4/original") {
+		t.Fatalf("expected no link once the original is deleted")
+	}
+}
+
+// photoRedactionTestConn is a database/sql driver holding one report_photos
+// row. The row is read at readVersion, but the conditional UPDATE sees
+// currentVersion, as if another redaction committed in between.
+type photoRedactionTestConn struct {
+	readVersion    int64
+	currentVersion int64
+}
+
+func (c *photoRedactionTestConn) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *photoRedactionTestConn) Driver() driver.Driver                        { return nil }
+func (c *photoRedactionTestConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+func (c *photoRedactionTestConn) Close() error              { return nil }
+func (c *photoRedactionTestConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *photoRedactionTestConn) Commit() error             { return errors.New("unexpected commit") }
+func (c *photoRedactionTestConn) Rollback() error           { return nil }
+
+func (c *photoRedactionTestConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	switch {
+	case strings.Contains(query, "UPDATE report_photos"):
+		if !strings.Contains(query, "AND version = $6") {
+			return nil, errors.New("expected the update to be conditional on the version read")
+		}
+		if args[5].Value.(int64) != c.currentVersion {
+			return &dataSubjectTestRows{}, nil
+		}
+		return &dataSubjectTestRows{rows: [][]driver.Value{{c.currentVersion + 1}}}, nil
+	case strings.Contains(query, "FROM report_photos"):
+		created := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+		return &dataSubjectTestRows{rows: [][]driver.Value{{int64(3), int64(7), "uploads/reports/7/a.jpg", "image/jpeg", "a.jpg", int64(100), created, c.readVersion, "citizen"}}}, nil
+	default:
+		return nil, errors.New("unexpected query")
+	}
+}
+
+func TestRedactReportPhotoRejectsAStaleVersion(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 40, 30)), nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	redaction := PhotoRedaction{Mode: photoRedactionBlur, Regions: []PhotoRedactionRegion{{X: 0, Y: 0, Width: 0.5, Height: 0.5}}}
+	session := OperatorSession{Email: "operator@example.com"}
+
+	for _, retention := range []time.Duration{0, defaultPhotoRedactionRetention} {
+		store := newFilesystemBlobStore(t.TempDir())
+		if err := store.Put(context.Background(), "uploads/reports/7/a.jpg", encoded.Bytes(), "image/jpeg"); err != nil {
+			t.Fatalf("put original: %v", err)
+		}
+		db := sql.OpenDB(&photoRedactionTestConn{readVersion: 1, currentVersion: 2})
+		app := &App{db: db, blobs: store, cfg: &Config{PhotoRedactionRetention: retention}}
+
+		_, err := app.redactReportPhoto(context.Background(), 7, 3, redaction, session)
+		db.Close()
+		var apiErr *apiError
+		if !errors.As(err, &apiErr) || apiErr.Status != http.StatusConflict || apiErr.Code != "photo_redaction_conflict" {
+			t.Fatalf("retention %v: expected a 409 conflict, got %v", retention, err)
+		}
+
+		uploads, _ := store.List(context.Background(), "uploads/")
+		if retention == 0 && (len(uploads) != 1 || uploads[0].Key != "uploads/reports/7/a.jpg") {
+			t.Fatalf("expected only the original upload to remain, got %#v", uploads)
+		}
+		// With retention the original left the uploads tree before the
+		// update, as it did for the redaction that won, and is not put back.
+		if retention > 0 && len(uploads) != 0 {
+			t.Fatalf("expected the redacted copy to be removed, got %#v", uploads)
+		}
+		if retained, _ := store.List(context.Background(), "redaction-originals/"); len(retained) != 0 {
+			t.Fatalf("expected this run's retained copy to be removed, got %#v", retained)
+		}
+	}
+}
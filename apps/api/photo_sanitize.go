@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"time"
+
+	"github.com/jdeng/goheif"
+	"github.com/jdeng/goheif/heif"
+	"golang.org/x/image/webp"
+)
+
+const (
+	sanitizedJPEGQuality = 88
+
+	// Limits checked from the image header before decoding. A decoded photo
+	// takes about four bytes per pixel, so maxPhotoPixels caps one decode at
+	// roughly 200 MB.
+	maxPhotoDimension = 12000
+	maxPhotoPixels    = 50_000_000
+	minPhotoDimension = 200
+
+	defaultPhotoDecodeConcurrency = 4
+	photoDecodeSlotWait           = 10 * time.Second
+
+	// VP8X feature flags for the metadata chunks that are removed.
+	webpFlagICC  = 0x20
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+// webpImageChunks are the chunks needed to render a WebP. Everything else,
+// including EXIF, XMP and ICCP, is dropped.
+var webpImageChunks = map[string]struct{}{
+	"VP8 ": {},
+	"VP8L": {},
+	"VP8X": {},
+	"ALPH": {},
+	"ANIM": {},
+	"ANMF": {},
+}
+
+// checkPhotoDimensions reads only the image header, so decompression bombs
+// are rejected before any pixel buffer is allocated.
+func checkPhotoDimensions(mimeType string, data []byte) error {
+	var config image.Config
+	var err error
+	switch mimeType {
+	case "image/jpeg":
+		config, err = jpeg.DecodeConfig(bytes.NewReader(data))
+	case "image/png":
+		config, err = png.DecodeConfig(bytes.NewReader(data))
+	case "image/webp":
+		config, err = webp.DecodeConfig(bytes.NewReader(data))
+	case "image/heic", "image/heif":
+		config, err = decodeHEIFConfig(data)
+	default:
+		err = fmt.Errorf("no decoder for %s", mimeType)
+	}
+
+	switch {
+	case err != nil:
+		return &apiError{Status: http.StatusBadRequest, Code: "invalid_photo", Message: "Photo could not be read"}
+	case config.Width > maxPhotoDimension || config.Height > maxPhotoDimension:
+		return &apiError{Status: http.StatusBadRequest, Code: "photo_dimensions_too_large", Message: fmt.Sprintf("Photo sides may be at most %d pixels", maxPhotoDimension)}
+	case config.Width*config.Height > maxPhotoPixels:
+		return &apiError{Status: http.StatusBadRequest, Code: "photo_too_many_pixels", Message: fmt.Sprintf("Photo may have at most %d megapixels", maxPhotoPixels/1_000_000)}
+	case config.Width < minPhotoDimension || config.Height < minPhotoDimension:
+		return &apiError{Status: http.StatusBadRequest, Code: "photo_too_small", Message: fmt.Sprintf("Photo sides must be at least %d pixels", minPhotoDimension)}
+	}
+	return nil
+}
+
+// acquirePhotoDecodeSlot bounds how many requests decode photos at once, so a
+// burst of uploads cannot exhaust memory. The returned func releases the slot.
+func (a *App) acquirePhotoDecodeSlot(ctx context.Context) (func(), error) {
+	if a.decodeSlots == nil {
+		return func() {}, nil
+	}
+	timer := time.NewTimer(photoDecodeSlotWait)
+	defer timer.Stop()
+	select {
+	case a.decodeSlots <- struct{}{}:
+		return func() { <-a.decodeSlots }, nil
+	case <-timer.C:
+		return nil, &apiError{Status: http.StatusServiceUnavailable, Code: "photo_processing_busy", Message: "Too many photos are being processed. Please retry later."}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// sanitizedPhoto is an upload after sanitizePhotoBytes.
+type sanitizedPhoto struct {
+	Bytes    []byte
+	MimeType string
+	// Hash is the difference hash of the decoded image, taken while it is in
+	// memory anyway; nil when a WebP that keeps its chunks cannot be decoded.
+	Hash *uint64
+}
+
+// sanitizePhotoBytes returns the photo without any embedded metadata, upright
+// according to its EXIF orientation, and the MIME type it is stored as. JPEG
+// is the canonical format: PNGs and HEIC/HEIF are converted to it, as are
+// WebPs that need rotating. Other WebPs keep their image data and lose all other chunks.
+// Each photo is decoded once.
+func sanitizePhotoBytes(mimeType string, data []byte, orientation int) (sanitizedPhoto, error) {
+	if mimeType == "image/webp" && orientation <= 1 {
+		stripped, err := stripWebPMetadata(data)
+		if err != nil {
+			return sanitizedPhoto{}, err
+		}
+		photo := sanitizedPhoto{Bytes: stripped, MimeType: mimeType}
+		if decoded, err := decodePhoto(mimeType, stripped); err == nil {
+			hash := photoDifferenceHash(decoded)
+			photo.Hash = &hash
+		}
+		return photo, nil
+	}
+	decoded, err := decodePhoto(mimeType, data)
+	if err != nil {
+		return sanitizedPhoto{}, err
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	oriented := applyEXIFOrientation(flattenOnWhite(decoded), orientation)
+	if err := jpeg.Encode(buffer, oriented, &jpeg.Options{Quality: sanitizedJPEGQuality}); err != nil {
+		return sanitizedPhoto{}, err
+	}
+	hash := photoDifferenceHash(oriented)
+	return sanitizedPhoto{Bytes: buffer.Bytes(), MimeType: "image/jpeg", Hash: &hash}, nil
+}
+
+// decodePhoto decodes an image of one of the allowed types.
+func decodePhoto(mimeType string, data []byte) (image.Image, error) {
+	switch mimeType {
+	case "image/jpeg":
+		return jpeg.Decode(bytes.NewReader(data))
+	case "image/png":
+		return png.Decode(bytes.NewReader(data))
+	case "image/webp":
+		return webp.Decode(bytes.NewReader(data))
+	case "image/heic", "image/heif":
+		return decodeHEIF(data)
+	default:
+		return nil, fmt.Errorf("no decoder for %s", mimeType)
+	}
+}
+
+func init() {
+	// Without it goheif returns pixel planes that point into libde265 memory,
+	// which is freed when Decode returns.
+	goheif.SafeEncoding = true
+}
+
+// heifRotationOrientations maps the irot property, in 90 degree
+// counter-clockwise steps, to the EXIF orientation that undoes it.
+var heifRotationOrientations = [4]int{1, 8, 3, 6}
+
+// decodeHEIF decodes the primary image of a HEIC/HEIF file and turns it by
+// its irot property. HEIF carries the rotation there rather than in the EXIF
+// orientation tag, which readPhotoEXIF ignores for these files.
+func decodeHEIF(data []byte) (img image.Image, err error) {
+	defer recoverHEIF(&err)
+	item, err := heif.Open(bytes.NewReader(data)).PrimaryItem()
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := goheif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return applyEXIFOrientation(decoded, heifRotationOrientations[item.Rotations()%4]), nil
+}
+
+func decodeHEIFConfig(data []byte) (config image.Config, err error) {
+	defer recoverHEIF(&err)
+	return goheif.DecodeConfig(bytes.NewReader(data))
+}
+
+// recoverHEIF turns a panic in the HEIF box parser into an error. The parser
+// does not bounds-check every box, and uploads are untrusted.
+func recoverHEIF(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("malformed heif file: %v", r)
+	}
+}
+
+// heifBrands are the ftyp major brands of still HEIC/HEIF images. Image
+// sequences (hevc, msf1) are not accepted.
+var heifBrands = map[string]string{
+	"heic": "image/heic",
+	"heix": "image/heic",
+	"heim": "image/heic",
+	"heis": "image/heic",
+	"mif1": "image/heif",
+}
+
+// sniffPhotoMimeType detects the type of an upload that came without one.
+// http.DetectContentType does not know HEIF, so its ftyp brand is checked
+// first.
+func sniffPhotoMimeType(data []byte) string {
+	if len(data) >= 12 && string(data[4:8]) == "ftyp" {
+		if mimeType, ok := heifBrands[string(data[8:12])]; ok {
+			return mimeType
+		}
+	}
+	return http.DetectContentType(data)
+}
+
+// flattenOnWhite composites images with transparency onto white, since JPEG
+// has no alpha channel and would otherwise turn transparent areas black.
+func flattenOnWhite(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+	bounds := img.Bounds()
+	flat := image.NewRGBA(bounds)
+	draw.Draw(flat, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, bounds, img, bounds.Min, draw.Over)
+	return flat
+}
+
+// applyEXIFOrientation rotates and mirrors img so it displays upright once the
+// orientation tag is gone. Orientation 0 or 1 returns img unchanged.
+func applyEXIFOrientation(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	outWidth, outHeight := width, height
+	if orientation >= 5 {
+		outWidth, outHeight = height, width
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, outWidth, outHeight))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = width-1-x, y
+			case 3: // rotated 180
+				dx, dy = width-1-x, height-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, height-1-y
+			case 5: // mirrored along the top-left diagonal
+				dx, dy = y, x
+			case 6: // needs a 90 degree clockwise turn
+				dx, dy = height-1-y, x
+			case 7: // mirrored along the top-right diagonal
+				dx, dy = height-1-y, width-1-x
+			case 8: // needs a 90 degree counter-clockwise turn
+				dx, dy = y, width-1-x
+			}
+			out.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return out
+}
+
+// stripWebPMetadata rewrites a RIFF WebP with only the image chunks and
+// clears the metadata flags in the VP8X header.
+func stripWebPMetadata(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errors.New("not a webp file")
+	}
+
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+	hasImage := false
+	offset := 12
+	for offset < len(data) {
+		if offset+8 > len(data) {
+			return nil, errors.New("truncated webp chunk header")
+		}
+		fourCC := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		end := offset + 8 + size
+		if size < 0 || end > len(data) {
+			return nil, fmt.Errorf("truncated webp %q chunk", fourCC)
+		}
+		padded := end + size%2
+		if padded > len(data) {
+			padded = end
+		}
+
+		if _, ok := webpImageChunks[fourCC]; ok {
+			start := len(out)
+			out = append(out, data[offset:end]...)
+			if size%2 == 1 {
+				out = append(out, 0)
+			}
+			switch fourCC {
+			case "VP8X":
+				if size < 10 {
+					return nil, errors.New("invalid webp VP8X chunk")
+				}
+				out[start+8] &^= webpFlagICC | webpFlagEXIF | webpFlagXMP
+			case "VP8 ", "VP8L", "ANMF":
+				hasImage = true
+			}
+		}
+		offset = padded
+	}
+	if !hasImage {
+		return nil, errors.New("webp has no image data")
+	}
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, nil
+}
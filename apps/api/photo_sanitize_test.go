@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func webpChunk(fourCC string, payload []byte) []byte {
+	chunk := make([]byte, 8, 8+len(payload)+1)
+	copy(chunk, fourCC)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(payload)))
+	chunk = append(chunk, payload...)
+	if len(payload)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func buildTestWebP(chunks ...[]byte) []byte {
+	body := bytes.Join(chunks, nil)
+	out := []byte("RIFF\x00\x00\x00\x00WEBP")
+	binary.LittleEndian.PutUint32(out[4:], uint32(4+len(body)))
+	return append(out, body...)
+}
+
+// testVP8XHeader is a VP8X payload for a 640x480 canvas with the given flags.
+func testVP8XHeader(flags byte) []byte {
+	header := []byte{flags, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	copy(header[4:7], []byte{0x7f, 0x02, 0x00})  // width - 1 = 639
+	copy(header[7:10], []byte{0xdf, 0x01, 0x00}) // height - 1 = 479
+	return header
+}
+
+// buildTestWebPWithMetadata returns an extended WebP carrying an ICC profile,
+// EXIF with a GPS position and an XMP packet next to the image data.
+func buildTestWebPWithMetadata() []byte {
+	vp8x := testVP8XHeader(webpFlagICC | webpFlagEXIF | webpFlagXMP)
+	return buildTestWebP(
+		webpChunk("VP8X", vp8x),
+		webpChunk("ICCP", []byte("device-profile")),
+		webpChunk("VP8 ", []byte("fake-vp8-bitstream")),
+		webpChunk("EXIF", buildTestEXIF(52.0907, 5.1214, "2026:10:01 14:30:00")),
+		webpChunk("XMP ", []byte(`<x:xmpmeta><tiff:Model>Phone</tiff:Model></x:xmpmeta>`)),
+	)
+}
+
+func pngChunk(chunkType string, payload []byte) []byte {
+	chunk := make([]byte, 8, 12+len(payload))
+	binary.BigEndian.PutUint32(chunk, uint32(len(payload)))
+	copy(chunk[4:], chunkType)
+	chunk = append(chunk, payload...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// buildTestPNGWithMetadata returns a PNG with a transparent pixel and eXIf and
+// tEXt chunks inserted after the header.
+func buildTestPNGWithMetadata(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, minPhotoDimension, minPhotoDimension))
+	for y := 0; y < minPhotoDimension; y++ {
+		for x := 0; x < minPhotoDimension; x++ {
+			img.Set(x, y, color.NRGBA{R: 20, G: 120, B: 200, A: 255})
+		}
+	}
+	img.Set(0, 0, color.NRGBA{})
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+
+	// The signature and IHDR chunk take the first 33 bytes.
+	raw := encoded.Bytes()
+	out := append([]byte{}, raw[:33]...)
+	out = append(out, pngChunk("eXIf", buildTestEXIF(52.0907, 5.1214, "2026:10:01 14:30:00"))...)
+	out = append(out, pngChunk("tEXt", []byte("Author\x00Reporter"))...)
+	return append(out, raw[33:]...)
+}
+
+// photoMetadataLeaks lists the metadata containers left in a sanitized photo.
+func photoMetadataLeaks(t *testing.T, mimeType string, data []byte) []string {
+	t.Helper()
+	leaks := make([]string, 0)
+	switch mimeType {
+	case "image/jpeg":
+		offset := 2
+		for offset+4 <= len(data) && data[offset] == 0xFF && data[offset+1] != 0xDA {
+			marker := data[offset+1]
+			if (marker >= 0xE1 && marker <= 0xEF) || marker == 0xFE {
+				leaks = append(leaks, fmt.Sprintf("marker 0x%X", marker))
+			}
+			offset += 2 + int(binary.BigEndian.Uint16(data[offset+2:offset+4]))
+		}
+	case "image/webp":
+		for offset := 12; offset+8 <= len(data); {
+			fourCC := string(data[offset : offset+4])
+			if _, ok := webpImageChunks[fourCC]; !ok {
+				leaks = append(leaks, fourCC)
+			}
+			if fourCC == "VP8X" && data[offset+8]&(webpFlagICC|webpFlagEXIF|webpFlagXMP) != 0 {
+				leaks = append(leaks, "VP8X flags")
+			}
+			size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+			offset += 8 + size + size%2
+		}
+	case "image/heic", "image/heif":
+		// Only the Exif item is checked, below; the stored type is JPEG.
+	case "image/png":
+		for offset := 8; offset+8 <= len(data); {
+			size := int(binary.BigEndian.Uint32(data[offset : offset+4]))
+			chunkType := string(data[offset+4 : offset+8])
+			switch chunkType {
+			case "IHDR", "PLTE", "tRNS", "IDAT", "IEND":
+			default:
+				leaks = append(leaks, chunkType)
+			}
+			offset += 12 + size
+		}
+	default:
+		t.Fatalf("no leak check for %s", mimeType)
+	}
+	if _, err := readPhotoEXIF(mimeType, data); err == nil {
+		leaks = append(leaks, "exif")
+	}
+	return leaks
+}
+
+func TestSanitizeAndValidatePhotosStripsMetadataForEveryAllowedType(t *testing.T) {
+	samples := map[string][]byte{
+		"image/jpeg": buildTestJPEGWithEXIF(t, buildTestEXIF(52.0907, 5.1214, "2026:10:01 14:30:00")),
+		"image/png":  buildTestPNGWithMetadata(t),
+		"image/webp": buildTestWebPWithMetadata(),
+		"image/heic": buildTestHEICWithEXIF(t, buildTestEXIF(52.0907, 5.1214, "2026:10:01 14:30:00")),
+		"image/heif": buildTestHEICWithEXIF(t, buildTestEXIF(52.0907, 5.1214, "2026:10:01 14:30:00")),
+	}
+	for mimeType := range allowedImageTypes {
+		sample, ok := samples[mimeType]
+		if !ok {
+			t.Fatalf("no metadata sample for allowed type %s", mimeType)
+		}
+		if leaks := photoMetadataLeaks(t, mimeType, sample); len(leaks) == 0 {
+			t.Fatalf("%s sample carries no metadata to strip", mimeType)
+		}
+
+		photos, err := sanitizeAndValidatePhotos([]PhotoUpload{{Name: "bike", MimeType: mimeType, Bytes: sample}})
+		if err != nil {
+			t.Fatalf("%s: sanitizeAndValidatePhotos returned error: %v", mimeType, err)
+		}
+		stored := photos[0]
+		if _, ok := allowedImageTypes[stored.MimeType]; !ok || stored.OriginalMimeType != mimeType {
+			t.Fatalf("%s: unexpected stored type %q (original %q)", mimeType, stored.MimeType, stored.OriginalMimeType)
+		}
+		if leaks := photoMetadataLeaks(t, stored.MimeType, stored.Bytes); len(leaks) != 0 {
+			t.Fatalf("%s: metadata survived sanitizing: %v", mimeType, leaks)
+		}
+	}
+}
+
+func TestStripWebPMetadataKeepsImageData(t *testing.T) {
+	stripped, err := stripWebPMetadata(buildTestWebPWithMetadata())
+	if err != nil {
+		t.Fatalf("stripWebPMetadata returned error: %v", err)
+	}
+
+	vp8x := testVP8XHeader(0)
+	want := buildTestWebP(webpChunk("VP8X", vp8x), webpChunk("VP8 ", []byte("fake-vp8-bitstream")))
+	if !bytes.Equal(stripped, want) {
+		t.Fatalf("unexpected stripped webp:\n got %q\nwant %q", stripped, want)
+	}
+	if bytes.Contains(stripped, []byte("Phone")) || bytes.Contains(stripped, []byte("device-profile")) {
+		t.Fatalf("expected metadata payloads to be gone")
+	}
+}
+
+func TestSanitizePhotoBytesRejectsUnreadablePhotos(t *testing.T) {
+	cases := []struct {
+		mimeType string
+		data     []byte
+	}{
+		{"image/jpeg", []byte("not a jpeg")},
+		{"image/webp", []byte("RIFF\x04\x00\x00\x00WEBP")},
+		{"image/webp", buildTestWebP(webpChunk("EXIF", []byte("only metadata")))},
+		{"image/webp", buildTestWebPWithMetadata()[:40]},
+	}
+	for _, tc := range cases {
+		if _, err := sanitizePhotoBytes(tc.mimeType, tc.data, 0); err == nil {
+			t.Fatalf("expected %s %q to be rejected", tc.mimeType, tc.data)
+		}
+	}
+
+	_, err := sanitizeAndValidatePhotos([]PhotoUpload{{Name: "bike.jpg", MimeType: "image/jpeg", Bytes: []byte("not a jpeg")}})
+	if apiErr, ok := err.(*apiError); !ok || apiErr.Code != "invalid_photo" {
+		t.Fatalf("expected invalid_photo, got %v", err)
+	}
+}
+
+func TestSanitizeAndValidatePhotosConvertsHEICToJPEG(t *testing.T) {
+	data := buildTestHEICWithEXIF(t, buildTestEXIF(52.0907, 5.1214, "2026:10:01 14:30:00"))
+
+	photos, err := sanitizeAndValidatePhotos([]PhotoUpload{{Name: "bike.heic", MimeType: "image/heic", Bytes: data}})
+	if err != nil {
+		t.Fatalf("sanitizeAndValidatePhotos returned error: %v", err)
+	}
+	if photos[0].MimeType != "image/jpeg" || photos[0].OriginalMimeType != "image/heic" {
+		t.Fatalf("expected heic stored as jpeg, got %q from %q", photos[0].MimeType, photos[0].OriginalMimeType)
+	}
+	if photos[0].EXIF == nil || !photos[0].EXIF.hasLocation() || photos[0].PerceptualHash == nil {
+		t.Fatalf("expected the heic exif item to be read and the photo hashed")
+	}
+
+	decoded, err := jpeg.Decode(bytes.NewReader(photos[0].Bytes))
+	if err != nil {
+		t.Fatalf("decode converted photo: %v", err)
+	}
+	if bounds := decoded.Bounds(); bounds.Dx() != 512 || bounds.Dy() != 512 {
+		t.Fatalf("unexpected converted size %v", bounds)
+	}
+
+	_, err = sanitizeAndValidatePhotos([]PhotoUpload{{Name: "bike.heic", MimeType: "image/heic", Bytes: data[:600]}})
+	if apiErr, ok := err.(*apiError); !ok || apiErr.Code != "invalid_photo" {
+		t.Fatalf("expected a truncated heic to be invalid_photo, got %v", err)
+	}
+}
+
+func TestSniffPhotoMimeType(t *testing.T) {
+	heic := buildTestHEICWithEXIF(t, buildTestEXIF(52.0907, 5.1214, "2026:10:01 14:30:00"))
+	mif1 := append([]byte{}, heic...)
+	copy(mif1[8:12], "mif1")
+	sequence := append([]byte{}, heic...)
+	copy(sequence[8:12], "msf1")
+
+	cases := map[string][]byte{
+		"image/heic": heic,
+		"image/heif": mif1,
+		"image/jpeg": buildTestJPEGWithEXIF(t, buildTestEXIF(52.0907, 5.1214, "2026:10:01 14:30:00")),
+	}
+	for want, data := range cases {
+		if got := sniffPhotoMimeType(data); got != want {
+			t.Fatalf("expected %s, got %s", want, got)
+		}
+	}
+	if got := sniffPhotoMimeType(sequence); got == "image/heic" || got == "image/heif" {
+		t.Fatalf("expected an image sequence not to sniff as a still image, got %s", got)
+	}
+}
+
+func TestSanitizeAndValidatePhotosConvertsPNGToJPEG(t *testing.T) {
+	photos, err := sanitizeAndValidatePhotos([]PhotoUpload{{Name: "bike.png", MimeType: "image/png", Bytes: buildTestPNGWithMetadata(t)}})
+	if err != nil {
+		t.Fatalf("sanitizeAndValidatePhotos returned error: %v", err)
+	}
+	if photos[0].MimeType != "image/jpeg" || photos[0].OriginalMimeType != "image/png" {
+		t.Fatalf("expected png stored as jpeg, got %q from %q", photos[0].MimeType, photos[0].OriginalMimeType)
+	}
+	if photos[0].EXIF == nil || !photos[0].EXIF.hasLocation() {
+		t.Fatalf("expected the png eXIf chunk to be read")
+	}
+
+	decoded, err := jpeg.Decode(bytes.NewReader(photos[0].Bytes))
+	if err != nil {
+		t.Fatalf("decode converted photo: %v", err)
+	}
+	// The transparent pixel is flattened onto white rather than black.
+	if r, g, b, _ := decoded.At(0, 0).RGBA(); r>>8 < 128 || g>>8 < 128 || b>>8 < 128 {
+		t.Fatalf("expected a light pixel where the png was transparent, got %d %d %d", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestApplyEXIFOrientation(t *testing.T) {
+	// A 3x2 image with a marked top-left pixel.
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	marked := color.RGBA{R: 255, A: 255}
+	src.Set(0, 0, marked)
+
+	cases := []struct {
+		orientation   int
+		width, height int
+		markX, markY  int
+	}{
+		{1, 3, 2, 0, 0},
+		{2, 3, 2, 2, 0},
+		{3, 3, 2, 2, 1},
+		{4, 3, 2, 0, 1},
+		{5, 2, 3, 0, 0},
+		{6, 2, 3, 1, 0},
+		{7, 2, 3, 1, 2},
+		{8, 2, 3, 0, 2},
+	}
+	for _, tc := range cases {
+		out := applyEXIFOrientation(src, tc.orientation)
+		if out.Bounds().Dx() != tc.width || out.Bounds().Dy() != tc.height {
+			t.Fatalf("orientation %d: expected %dx%d, got %v", tc.orientation, tc.width, tc.height, out.Bounds())
+		}
+		if out.At(tc.markX, tc.markY) != marked {
+			t.Fatalf("orientation %d: expected the marked pixel at %d,%d", tc.orientation, tc.markX, tc.markY)
+		}
+	}
+}
+
+func TestReadPhotoEXIFOrientation(t *testing.T) {
+	tiff := make([]byte, 26)
+	copy(tiff, "MM\x00\x2a\x00\x00\x00\x08")
+	binary.BigEndian.PutUint16(tiff[8:], 1)
+	binary.BigEndian.PutUint16(tiff[10:], exifTagOrientation)
+	binary.BigEndian.PutUint16(tiff[12:], exifTypeShort)
+	binary.BigEndian.PutUint32(tiff[14:], 1)
+	binary.BigEndian.PutUint16(tiff[18:], 6)
+
+	exif, err := parseEXIFTIFF(tiff)
+	if err != nil || exif.Orientation != 6 || exif.hasLocation() || exif.CapturedAt != nil {
+		t.Fatalf("expected orientation 6 only, got %#v, %v", exif, err)
+	}
+}
+
+func testPNGHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], width)
+	binary.BigEndian.PutUint32(ihdr[4:], height)
+	ihdr[8], ihdr[9] = 8, 6 // 8-bit RGBA
+	return append([]byte("\x89PNG\r\n\x1a\n"), pngChunk("IHDR", ihdr)...)
+}
+
+func TestCheckPhotoDimensions(t *testing.T) {
+	cases := []struct {
+		name     string
+		mimeType string
+		data     []byte
+		wantCode string
+	}{
+		{"bomb", "image/png", testPNGHeader(30000, 30000), "photo_dimensions_too_large"},
+		{"too many pixels", "image/png", testPNGHeader(10000, 9000), "photo_too_many_pixels"},
+		{"too small", "image/png", testPNGHeader(640, 100), "photo_too_small"},
+		{"unreadable header", "image/jpeg", []byte("not a jpeg"), "invalid_photo"},
+		{"webp bomb", "image/webp", buildTestWebP(webpChunk("VP8X", []byte{0, 0, 0, 0, 0xff, 0xff, 0x00, 0xff, 0xff, 0x00})), "photo_dimensions_too_large"},
+		{"ok", "image/png", testPNGHeader(4000, 3000), ""},
+	}
+	for _, tc := range cases {
+		err := checkPhotoDimensions(tc.mimeType, tc.data)
+		if tc.wantCode == "" {
+			if err != nil {
+				t.Fatalf("%s: expected no error, got %v", tc.name, err)
+			}
+			continue
+		}
+		if apiErr, ok := err.(*apiError); !ok || apiErr.Code != tc.wantCode {
+			t.Fatalf("%s: expected %s, got %v", tc.name, tc.wantCode, err)
+		}
+	}
+
+	// The header check runs before decoding, so a bomb never gets allocated.
+	_, err := sanitizeAndValidatePhotos([]PhotoUpload{{Name: "bomb.png", MimeType: "image/png", Bytes: testPNGHeader(30000, 30000)}})
+	if apiErr, ok := err.(*apiError); !ok || apiErr.Code != "photo_dimensions_too_large" {
+		t.Fatalf("expected photo_dimensions_too_large, got %v", err)
+	}
+}
+
+func TestAcquirePhotoDecodeSlot(t *testing.T) {
+	app := &App{decodeSlots: make(chan struct{}, 1)}
+	release, err := app.acquirePhotoDecodeSlot(context.Background())
+	if err != nil {
+		t.Fatalf("expected a free slot, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := app.acquirePhotoDecodeSlot(ctx); err == nil {
+		t.Fatalf("expected a full limiter to wait and give up with the request")
+	}
+
+	release()
+	release, err = app.acquirePhotoDecodeSlot(context.Background())
+	if err != nil {
+		t.Fatalf("expected the released slot to be reusable, got %v", err)
+	}
+	release()
+
+	if release, err := (&App{}).acquirePhotoDecodeSlot(context.Background()); err != nil || release == nil {
+		t.Fatalf("expected no limit without slots, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"os"
+	"path"
+	"strconv"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	photoVariantThumb  = "thumb"
+	photoVariantMedium = "medium"
+	photoVariantFull   = "full"
+
+	photoVariantJPEGQuality = 80
+)
+
+// photoVariantMaxSides is the longest side of each derived variant. The full
+// variant is the stored photo itself.
+var photoVariantMaxSides = map[string]int{
+	photoVariantThumb:  320,
+	photoVariantMedium: 1024,
+}
+
+// parsePhotoVariantSize maps the ?size= parameter to a variant; empty is full.
+func parsePhotoVariantSize(raw string) (string, bool) {
+	switch raw {
+	case "", photoVariantFull:
+		return photoVariantFull, true
+	case photoVariantThumb, photoVariantMedium:
+		return raw, true
+	default:
+		return "", false
+	}
+}
+
+// photoVariantStoragePath is the blob key a derived variant is cached under.
+// The key includes the photo version, so a redaction can never be answered
+// with a variant rendered from the unredacted version. Version 1 keeps the
+// unversioned key variants were cached under before redactions existed.
+func photoVariantStoragePath(reportID, photoID, version int, size string) string {
+	name := fmt.Sprintf("%d-%s.jpg", photoID, size)
+	if version > 1 {
+		name = fmt.Sprintf("%d-v%d-%s.jpg", photoID, version, size)
+	}
+	return path.Join("variants", "reports", strconv.Itoa(reportID), name)
+}
+
+// ensurePhotoVariant returns the storage path of the requested variant,
+// generating and caching it on first use. The full variant is the original.
+func (a *App) ensurePhotoVariant(ctx context.Context, photo ReportPhoto, size string) (string, error) {
+	originalPath, err := a.resolveExistingPhotoStoragePath(ctx, photo.StoragePath)
+	if err != nil || size == photoVariantFull {
+		return originalPath, err
+	}
+	maxSide, ok := photoVariantMaxSides[size]
+	if !ok {
+		return "", fmt.Errorf("unknown photo variant %q", size)
+	}
+
+	variantPath := photoVariantStoragePath(photo.ReportID, photo.ID, photo.Version, size)
+	store := a.blobStore()
+	if _, err := store.Stat(ctx, variantPath); err == nil {
+		return variantPath, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	release, err := a.acquirePhotoDecodeSlot(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	original, err := store.Get(ctx, originalPath)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := renderPhotoVariant(photo.MimeType, original, maxSide)
+	if err != nil {
+		return "", fmt.Errorf("render %s variant of photo %d: %w", size, photo.ID, err)
+	}
+	// Stores write atomically, so a concurrent request never serves a
+	// partially written variant.
+	if err := store.Put(ctx, variantPath, encoded, "image/jpeg"); err != nil {
+		return "", err
+	}
+	return variantPath, nil
+}
+
+// renderPhotoVariant scales a stored photo down so its longest side is at most
+// maxSide and encodes it as JPEG. Smaller photos are re-encoded as they are.
+func renderPhotoVariant(mimeType string, data []byte, maxSide int) ([]byte, error) {
+	decoded, err := decodePhoto(mimeType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := decoded.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if longest := max(width, height); longest > maxSide {
+		width = max(1, width*maxSide/longest)
+		height = max(1, height*maxSide/longest)
+		scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.BiLinear.Scale(scaled, scaled.Bounds(), decoded, bounds, draw.Src, nil)
+		decoded = scaled
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	if err := jpeg.Encode(buffer, flattenOnWhite(decoded), &jpeg.Options{Quality: photoVariantJPEGQuality}); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// PhotoVariantBackfillResult counts the outcome of generate-photo-variants.
+type PhotoVariantBackfillResult struct {
+	Photos    int
+	Generated int
+	Failed    int
+}
+
+// backfillPhotoVariants generates the missing derived variants of every
+// stored report photo. Failures are logged and counted, not fatal.
+func (a *App) backfillPhotoVariants(ctx context.Context) (PhotoVariantBackfillResult, error) {
+	var result PhotoVariantBackfillResult
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT id, report_id, storage_path, mime_type, filename, size_bytes, version
+		FROM report_photos
+		ORDER BY id ASC
+	`)
+	if err != nil {
+		return result, err
+	}
+	photos := make([]ReportPhoto, 0)
+	for rows.Next() {
+		var photo ReportPhoto
+		if err := rows.Scan(&photo.ID, &photo.ReportID, &photo.StoragePath, &photo.MimeType, &photo.Filename, &photo.SizeBytes, &photo.Version); err != nil {
+			rows.Close()
+			return result, err
+		}
+		photos = append(photos, photo)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	for _, photo := range photos {
+		result.Photos++
+		for _, size := range []string{photoVariantThumb, photoVariantMedium} {
+			existed := a.photoVariantExists(ctx, photo, size)
+			if _, err := a.ensurePhotoVariant(ctx, photo, size); err != nil {
+				if errors.Is(err, context.Canceled) {
+					return result, err
+				}
+				a.log.Error("failed to generate photo variant", "photo_id", photo.ID, "size", size, "err", err)
+				result.Failed++
+				continue
+			}
+			if !existed {
+				result.Generated++
+			}
+		}
+	}
+	return result, nil
+}
+
+func (a *App) photoVariantExists(ctx context.Context, photo ReportPhoto, size string) bool {
+	_, err := a.blobStore().Stat(ctx, photoVariantStoragePath(photo.ReportID, photo.ID, photo.Version, size))
+	return err == nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func buildTestJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, img, nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	return encoded.Bytes()
+}
+
+func TestParsePhotoVariantSize(t *testing.T) {
+	for raw, expected := range map[string]string{"": photoVariantFull, "full": photoVariantFull, "thumb": photoVariantThumb, "medium": photoVariantMedium} {
+		if got, ok := parsePhotoVariantSize(raw); !ok || got != expected {
+			t.Fatalf("parsePhotoVariantSize(%q) = %q, %v", raw, got, ok)
+		}
+	}
+	if _, ok := parsePhotoVariantSize("large"); ok {
+		t.Fatalf("expected an unknown size to be rejected")
+	}
+}
+
+func TestRenderPhotoVariantScalesLongestSide(t *testing.T) {
+	encoded, err := renderPhotoVariant("image/jpeg", buildTestJPEG(t, 1200, 600), 320)
+	if err != nil {
+		t.Fatalf("renderPhotoVariant returned error: %v", err)
+	}
+	config, err := jpeg.DecodeConfig(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("variant is not a jpeg: %v", err)
+	}
+	if config.Width != 320 || config.Height != 160 {
+		t.Fatalf("expected 320x160, got %dx%d", config.Width, config.Height)
+	}
+
+	small, err := renderPhotoVariant("image/jpeg", buildTestJPEG(t, 240, 200), 320)
+	if err != nil {
+		t.Fatalf("renderPhotoVariant returned error: %v", err)
+	}
+	if config, _ := jpeg.DecodeConfig(bytes.NewReader(small)); config.Width != 240 || config.Height != 200 {
+		t.Fatalf("expected small photos not to be upscaled, got %dx%d", config.Width, config.Height)
+	}
+}
+
+func TestEnsurePhotoVariantCachesUnderDataRoot(t *testing.T) {
+	dataRoot := t.TempDir()
+	app := &App{cfg: &Config{DataRoot: dataRoot}}
+
+	originalPath := filepath.ToSlash(filepath.Join("uploads", "reports", "7", "a.jpg"))
+	fullOriginalPath := filepath.Join(dataRoot, filepath.FromSlash(originalPath))
+	if err := os.MkdirAll(filepath.Dir(fullOriginalPath), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err := os.WriteFile(fullOriginalPath, buildTestJPEG(t, 1600, 1200), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	photo := ReportPhoto{ID: 3, ReportID: 7, StoragePath: originalPath, MimeType: "image/jpeg"}
+
+	full, err := app.ensurePhotoVariant(context.Background(), photo, photoVariantFull)
+	if err != nil || full != originalPath {
+		t.Fatalf("expected the original for the full size, got %q, %v", full, err)
+	}
+
+	medium, err := app.ensurePhotoVariant(context.Background(), photo, photoVariantMedium)
+	if err != nil {
+		t.Fatalf("ensurePhotoVariant returned error: %v", err)
+	}
+	if medium != photoVariantStoragePath(7, 3, 1, photoVariantMedium) {
+		t.Fatalf("unexpected variant path %q", medium)
+	}
+	fullMediumPath := filepath.Join(dataRoot, medium)
+	contents, err := os.ReadFile(fullMediumPath)
+	if err != nil {
+		t.Fatalf("variant was not written: %v", err)
+	}
+	if config, _ := jpeg.DecodeConfig(bytes.NewReader(contents)); config.Width != 1024 || config.Height != 768 {
+		t.Fatalf("expected 1024x768, got %dx%d", config.Width, config.Height)
+	}
+
+	// A cached variant is served as is, even when the original has changed.
+	if err := os.WriteFile(fullMediumPath, []byte("cached"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, err := app.ensurePhotoVariant(context.Background(), photo, photoVariantMedium); err != nil {
+		t.Fatalf("ensurePhotoVariant returned error: %v", err)
+	}
+	if contents, _ := os.ReadFile(fullMediumPath); string(contents) != "cached" {
+		t.Fatalf("expected the cached variant to be reused")
+	}
+
+	// A redacted version renders its own variant instead of reusing the
+	// cached one of the version before.
+	redacted := photo
+	redacted.Version = 2
+	redactedMedium, err := app.ensurePhotoVariant(context.Background(), redacted, photoVariantMedium)
+	if err != nil {
+		t.Fatalf("ensurePhotoVariant returned error: %v", err)
+	}
+	if redactedMedium == medium || redactedMedium != photoVariantStoragePath(7, 3, 2, photoVariantMedium) {
+		t.Fatalf("expected a versioned variant path, got %q", redactedMedium)
+	}
+	if contents, _ := os.ReadFile(filepath.Join(dataRoot, redactedMedium)); string(contents) == "cached" {
+		t.Fatalf("expected the redacted version to be rendered from its own original")
+	}
+
+	missing := ReportPhoto{ID: 4, ReportID: 7, StoragePath: "uploads/reports/7/missing.jpg", MimeType: "image/jpeg"}
+	if _, err := app.ensurePhotoVariant(context.Background(), missing, photoVariantThumb); !os.IsNotExist(err) {
+		t.Fatalf("expected not-exist for a missing original, got %v", err)
+	}
+}
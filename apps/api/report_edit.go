@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"zwerffiets/libs/mailer"
+)
+
+// ReportEdit is an admin correction of a report's location and address.
+// Regeocode replaces the typed address, postcode and municipality with a fresh
+// lookup for the new coordinates; Rematch moves the report to the bike group
+// that best fits its new location.
+type ReportEdit struct {
+	Municipality string
+	Address      string
+	Postcode     string
+	Lat          float64
+	Lng          float64
+	Regeocode    bool
+	Rematch      bool
+}
+
+// reportEditFields are the report columns an edit can change.
+type reportEditFields struct {
+	Lat          float64
+	Lng          float64
+	Address      string
+	City         string
+	Postcode     string
+	Municipality string
+	BikeGroupID  int
+}
+
+func reportEditFieldsOf(report Report) reportEditFields {
+	return reportEditFields{
+		Lat:          report.Location.Lat,
+		Lng:          report.Location.Lng,
+		Address:      stringValue(report.Address),
+		City:         stringValue(report.City),
+		Postcode:     stringValue(report.PostalCode),
+		Municipality: stringValue(report.Municipality),
+		BikeGroupID:  report.BikeGroupID,
+	}
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// diffReportEditFields returns {"field": {"from": old, "to": new}} for every
+// field that changed. Coordinates are compared at the stored precision.
+func diffReportEditFields(before, after reportEditFields) map[string]any {
+	diff := map[string]any{}
+	change := func(field string, from, to any) {
+		diff[field] = map[string]any{"from": from, "to": to}
+	}
+	if !sameCoordinate(before.Lat, after.Lat) {
+		change("lat", before.Lat, after.Lat)
+	}
+	if !sameCoordinate(before.Lng, after.Lng) {
+		change("lng", before.Lng, after.Lng)
+	}
+	if before.Address != after.Address {
+		change("address", before.Address, after.Address)
+	}
+	if before.City != after.City {
+		change("city", before.City, after.City)
+	}
+	if before.Postcode != after.Postcode {
+		change("postcode", before.Postcode, after.Postcode)
+	}
+	if before.Municipality != after.Municipality {
+		change("municipality", before.Municipality, after.Municipality)
+	}
+	if before.BikeGroupID != after.BikeGroupID {
+		change("bike_group_id", before.BikeGroupID, after.BikeGroupID)
+	}
+	return diff
+}
+
+// regeocodedMunicipality picks the municipality for a re-geocoded location:
+// the boundary match, else the geocoded place name. A name that is not a
+// current gemeente falls back to the one the admin entered.
+func regeocodedMunicipality(res *GeocodeResult, resolved *string, fallback string, now time.Time) string {
+	candidate := ""
+	if res != nil {
+		candidate = successorMunicipality(lookupMunicipality(res.City), now)
+	}
+	if resolved != nil {
+		candidate = *resolved
+	}
+	if isValidMunicipality(candidate, now) {
+		return candidate
+	}
+	return fallback
+}
+
+func sameCoordinate(a, b float64) bool {
+	return math.Abs(a-b) < 1e-7
+}
+
+func (a *App) adminApplyReportEdit(ctx context.Context, reportID int, edit ReportEdit, session OperatorSession) (*Report, error) {
+	if a.adminEditReport != nil {
+		return a.adminEditReport(ctx, reportID, edit, session)
+	}
+	return a.editReport(ctx, reportID, edit, session)
+}
+
+// editReport applies an admin edit, records an edited event with the diff and
+// tells the operators of both municipalities when the report changed hands.
+func (a *App) editReport(ctx context.Context, reportID int, edit ReportEdit, session OperatorSession) (*Report, error) {
+	current, err := a.getReportByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, &apiError{Status: http.StatusNotFound, Code: "report_not_found", Message: "Report not found"}
+	}
+
+	before := reportEditFieldsOf(*current)
+	after := before
+	after.Lat = edit.Lat
+	after.Lng = edit.Lng
+	after.Address = edit.Address
+	after.Postcode = edit.Postcode
+	after.Municipality = edit.Municipality
+	location := ReportLocation{Lat: edit.Lat, Lng: edit.Lng, AccuracyM: current.Location.AccuracyM}
+
+	now := time.Now().UTC()
+	if edit.Regeocode {
+		res, err := a.geocoder.Geocode(ctx, edit.Lat, edit.Lng)
+		if err != nil {
+			a.log.Error("failed to geocode edited report", "id", reportID, "err", err)
+			return nil, &apiError{Status: http.StatusBadGateway, Code: "geocoder_unavailable", Message: "Geocoding failed"}
+		}
+		if res != nil {
+			after.Address = res.Address
+			after.City = res.City
+			after.Postcode = res.PostalCode
+		}
+		after.Municipality = regeocodedMunicipality(res, a.resolveMunicipality(location), edit.Municipality, now)
+	}
+	if after.Municipality != "" && !isValidMunicipality(after.Municipality, now) {
+		return nil, &apiError{Status: http.StatusBadRequest, Code: "invalid_municipality", Message: "Unknown municipality"}
+	}
+
+	var municipality *string
+	if after.Municipality != "" {
+		municipality = &after.Municipality
+	}
+	params, err := a.loadSignalParams(ctx, municipality)
+	if err != nil {
+		return nil, err
+	}
+
+	// A report that is alone in its group and matches nothing else keeps the
+	// group and moves its anchor along. A new group is only created inside
+	// the transaction, so a failed edit leaves no empty group behind.
+	moveAnchor := false
+	newGroup := false
+	var previousGroupID *int
+	if edit.Rematch {
+		incoming := *current
+		incoming.Location = location
+		open, recurring, err := a.matchBikeGroup(ctx, incoming, now, params)
+		if err != nil {
+			return nil, err
+		}
+		groupReports, err := a.listReportsByBikeGroupID(ctx, current.BikeGroupID)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case open != nil:
+			after.BikeGroupID = open.ID
+		case len(groupReports) > 1:
+			newGroup = true
+			if recurring != nil {
+				previousGroupID = &recurring.ID
+			}
+		default:
+			moveAnchor = true
+		}
+	}
+
+	if !newGroup && len(diffReportEditFields(before, after)) == 0 {
+		return current, nil
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if newGroup {
+		groupID, err := a.createBikeGroupTx(ctx, tx, location, previousGroupID)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		after.BikeGroupID = groupID
+	}
+	diff := diffReportEditFields(before, after)
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE reports
+		SET lat = $1, lng = $2, address = $3, city = $4, postcode = $5, municipality = $6, bike_group_id = $7, updated_at = NOW()
+		WHERE id = $8
+	`, after.Lat, after.Lng, after.Address, after.City, after.Postcode, after.Municipality, after.BikeGroupID, reportID); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if moveAnchor {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE bike_groups SET anchor_lat = $1, anchor_lng = $2, updated_at = NOW() WHERE id = $3
+		`, after.Lat, after.Lng, after.BikeGroupID); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := a.addEventTx(ctx, tx, reportID, "edited", session.Email, map[string]any{
+		"changes":   diff,
+		"regeocode": edit.Regeocode,
+		"rematch":   edit.Rematch,
+	}); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if after.BikeGroupID != before.BikeGroupID {
+		for _, groupID := range []int{before.BikeGroupID, after.BikeGroupID} {
+			if err := a.recomputeBikeGroup(ctx, groupID, params); err != nil {
+				a.log.Error("failed to recompute bike group", "bike_group_id", groupID, "err", err)
+			}
+			if err := a.refreshBikeGroupState(ctx, groupID, reportID, session.Email); err != nil {
+				a.log.Error("failed to refresh bike group state", "bike_group_id", groupID, "err", err)
+			}
+		}
+	}
+
+	if !strings.EqualFold(before.Municipality, after.Municipality) {
+		a.notifyMunicipalityReassignment(ctx, *current, before.Municipality, after.Municipality)
+	}
+	return a.getReportByID(ctx, reportID)
+}
+
+// recomputeBikeGroup refreshes the signal summary of a group after its
+// membership changed.
+func (a *App) recomputeBikeGroup(ctx context.Context, groupID int, params SignalParams) error {
+	group, err := a.getBikeGroupByID(ctx, groupID)
+	if err != nil || group == nil {
+		return err
+	}
+	reports, err := a.listReportsByBikeGroupID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	recomputation := computeReconfirmation(reports, params)
+	return a.updateBikeGroup(ctx, applySummaryToBikeGroup(*group, recomputation.Summary, recomputation.SignalStrength, recomputation.Score.BaseScore))
+}
+
+// notifyMunicipalityReassignment mails the report recipients of the old and
+// the new municipality. Failures are logged; the edit itself already succeeded.
+func (a *App) notifyMunicipalityReassignment(ctx context.Context, report Report, from, to string) {
+	if a.mailer == nil || a.adminListReportRecipientOperators == nil {
+		return
+	}
+	operators, err := a.adminListReportRecipientOperators(ctx)
+	if err != nil {
+		a.log.Error("failed to list operators for municipality change", "id", report.ID, "err", err)
+		return
+	}
+	for _, op := range operators {
+		if op.Municipality == nil {
+			continue
+		}
+		var msg mailer.Message
+		switch {
+		case from != "" && strings.EqualFold(*op.Municipality, from):
+			msg = a.buildMunicipalityReassignmentEmail(op, report, from, to, false)
+		case to != "" && strings.EqualFold(*op.Municipality, to):
+			msg = a.buildMunicipalityReassignmentEmail(op, report, from, to, true)
+		default:
+			continue
+		}
+		if _, err := a.mailer.Send(msg); err != nil {
+			a.log.Error("failed to send municipality change email", "email", op.Email, "id", report.ID, "err", err)
+			continue
+		}
+		a.log.Info("sent municipality change email", "email", op.Email, "id", report.ID, "from", from, "to", to)
+	}
+}
+
+func (a *App) buildMunicipalityReassignmentEmail(op Operator, report Report, from, to string, incoming bool) mailer.Message {
+	if from == "" {
+		from = "onbekend"
+	}
+	if to == "" {
+		to = "onbekend"
+	}
+	detailURL := buildPublicURL(a.cfg.PublicBaseURL, fmt.Sprintf("/bikeadmin/reports/%d", report.ID))
+
+	subject := fmt.Sprintf("Melding %s valt niet meer onder %s", report.PublicID, from)
+	summary := fmt.Sprintf("Een beheerder heeft melding %s verplaatst van %s naar %s. De melding valt niet meer onder uw gemeente.", report.PublicID, from, to)
+	if incoming {
+		subject = fmt.Sprintf("Melding %s valt nu onder %s", report.PublicID, to)
+		summary = fmt.Sprintf("Een beheerder heeft melding %s verplaatst van %s naar %s. De melding valt nu onder uw gemeente.", report.PublicID, from, to)
+	}
+
+	html := fmt.Sprintf(`
+		<div style="font-family: sans-serif; max-width: 600px; margin: 0 auto; line-height: 1.6; color: #333;">
+			<p>%s</p>
+			<p><a href="%s">Bekijk de melding</a></p>
+		</div>
+	`, summary, detailURL)
+	text := fmt.Sprintf("%s\n\nBekijk de melding: %s", summary, detailURL)
+
+	return mailer.Message{
+		To:      []string{op.Email},
+		Subject: subject,
+		HTML:    html,
+		Text:    text,
+	}
+}
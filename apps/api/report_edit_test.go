@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"zwerffiets/libs/mailer"
+)
+
+func TestDiffReportEditFields(t *testing.T) {
+	before := reportEditFields{Lat: 52.0907, Lng: 5.1214, Address: "Oudegracht 1", Municipality: "Utrecht", BikeGroupID: 4}
+	after := before
+	after.Lat = 52.0907000001
+	if diff := diffReportEditFields(before, after); len(diff) != 0 {
+		t.Fatalf("expected no diff below coordinate precision, got %#v", diff)
+	}
+
+	after.Lng = 5.2
+	after.Municipality = "De Bilt"
+	after.BikeGroupID = 9
+	diff := diffReportEditFields(before, after)
+	if len(diff) != 3 {
+		t.Fatalf("expected lng, municipality and bike group changes, got %#v", diff)
+	}
+	municipality, ok := diff["municipality"].(map[string]any)
+	if !ok || municipality["from"] != "Utrecht" || municipality["to"] != "De Bilt" {
+		t.Fatalf("unexpected municipality change: %#v", diff["municipality"])
+	}
+}
+
+func TestRegeocodedMunicipalityIsValidated(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	if got := regeocodedMunicipality(&GeocodeResult{City: "Utrecht"}, nil, "De Bilt", now); got != "Utrecht" {
+		t.Fatalf("expected the geocoded municipality, got %q", got)
+	}
+	resolved := "Zeist"
+	if got := regeocodedMunicipality(&GeocodeResult{City: "Utrecht"}, &resolved, "De Bilt", now); got != "Zeist" {
+		t.Fatalf("expected the boundary match to win, got %q", got)
+	}
+	if got := regeocodedMunicipality(&GeocodeResult{City: "Atlantis"}, nil, "De Bilt", now); got != "De Bilt" {
+		t.Fatalf("expected an unknown place to fall back to the entered municipality, got %q", got)
+	}
+	if got := regeocodedMunicipality(nil, nil, "", now); got != "" {
+		t.Fatalf("expected no municipality without a lookup, got %q", got)
+	}
+}
+
+func TestAdminReportEditSubmitPassesEditOptions(t *testing.T) {
+	app, router := newAdminTestServer(t)
+
+	var got ReportEdit
+	var gotID int
+	app.adminEditReport = func(ctx context.Context, reportID int, edit ReportEdit, session OperatorSession) (*Report, error) {
+		gotID = reportID
+		got = edit
+		return &Report{ID: reportID}, nil
+	}
+
+	rec := httptest.NewRecorder()
+	form := "municipality=Utrecht&address=Oudegracht+1&postcode=3511+AA&lat=52.0907&lng=5.1214&regeocode=1&rematch=1"
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodPost, "/bikeadmin/reports/7/edit", form))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d", rec.Code)
+	}
+	if gotID != 7 || !got.Regeocode || !got.Rematch || got.Municipality != "Utrecht" || got.Lat != 52.0907 {
+		t.Fatalf("unexpected edit for report %d: %#v", gotID, got)
+	}
+	if location := rec.Header().Get("Location"); !strings.Contains(location, "notice=") {
+		t.Fatalf("expected notice redirect, got %q", location)
+	}
+
+	app.adminEditReport = func(ctx context.Context, reportID int, edit ReportEdit, session OperatorSession) (*Report, error) {
+		return nil, &apiError{Status: http.StatusBadGateway, Code: "geocoder_unavailable", Message: "Geocoding failed"}
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodPost, "/bikeadmin/reports/7/edit", form))
+	if location := rec.Header().Get("Location"); !strings.HasPrefix(location, "/bikeadmin/reports/7/edit?") || !strings.Contains(location, "error=") {
+		t.Fatalf("expected error redirect back to the edit page, got %q", location)
+	}
+}
+
+func TestNotifyMunicipalityReassignment(t *testing.T) {
+	app, _ := newAdminTestServer(t)
+	provider := &mockProvider{}
+	app.mailer = mailer.New(provider, "test@example.com")
+
+	utrecht := "Utrecht"
+	deBilt := "De Bilt"
+	amsterdam := "Amsterdam"
+	app.adminListReportRecipientOperators = func(ctx context.Context) ([]Operator, error) {
+		return []Operator{
+			{ID: 1, Email: "utrecht@example.com", Municipality: &utrecht},
+			{ID: 2, Email: "debilt@example.com", Municipality: &deBilt},
+			{ID: 3, Email: "amsterdam@example.com", Municipality: &amsterdam},
+		}, nil
+	}
+
+	app.notifyMunicipalityReassignment(context.Background(), Report{ID: 7, PublicID: "ZF-ABC123"}, "Utrecht", "De Bilt")
+	if len(provider.SentMessages) != 2 {
+		t.Fatalf("expected mails to the old and new municipality, got %d", len(provider.SentMessages))
+	}
+	for _, msg := range provider.SentMessages {
+		switch msg.To[0] {
+		case "utrecht@example.com":
+			if !strings.Contains(msg.Subject, "niet meer onder Utrecht") {
+				t.Fatalf("unexpected subject for the old municipality: %q", msg.Subject)
+			}
+		case "debilt@example.com":
+			if !strings.Contains(msg.Subject, "nu onder De Bilt") {
+				t.Fatalf("unexpected subject for the new municipality: %q", msg.Subject)
+			}
+		default:
+			t.Fatalf("unexpected recipient %q", msg.To[0])
+		}
+	}
+}
+
+func TestAdminReportEditPageSearchesAndPrefillsAddress(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	app.geocoder = &countingGeocoder{results: []GeocodeSearchResult{
+		{Label: "Oudegracht 10, 3511 AL Utrecht", Address: "Oudegracht 10", PostalCode: "3511 AL", City: "Utrecht", Lat: 52.0912, Lng: 5.1201},
+	}}
+	app.adminGetReportDetails = func(ctx context.Context, reportID int) (*OperatorReportDetails, error) {
+		return &OperatorReportDetails{Report: Report{ID: reportID, Location: ReportLocation{Lat: 52.0907, Lng: 5.1214}}}, nil
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodGet, "/bikeadmin/reports/7/edit?q=Oudegracht+10", ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Oudegracht 10, 3511 AL Utrecht") || !strings.Contains(body, "lat=52.0912") {
+		t.Fatalf("expected the search result with a link carrying its coordinates, got %s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodGet, "/bikeadmin/reports/7/edit?lat=52.0912&lng=5.1201&address=Oudegracht+10&postcode=3511+AL", ""))
+	body = rec.Body.String()
+	if !strings.Contains(body, `name="lat" value="52.0912"`) || !strings.Contains(body, `name="address" value="Oudegracht 10"`) {
+		t.Fatalf("expected the picked result to prefill the form, got %s", body)
+	}
+
+	app.geocoder = &countingGeocoder{err: errors.New("upstream down")}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodGet, "/bikeadmin/reports/7/edit?q=Oudegracht+10", ""))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), adminText("nl", "error_address_search_failed")) {
+		t.Fatalf("expected the form to render with a search error, got %d", rec.Code)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -19,7 +20,7 @@ func TestResolveExistingPhotoStoragePath_ExactPath(t *testing.T) {
 		t.Fatalf("write failed: %v", err)
 	}
 
-	got, err := app.resolveExistingPhotoStoragePath(relPath)
+	got, err := app.resolveExistingPhotoStoragePath(context.Background(), relPath)
 	if err != nil {
 		t.Fatalf("resolveExistingPhotoStoragePath returned error: %v", err)
 	}
@@ -41,7 +42,7 @@ func TestResolveExistingPhotoStoragePath_FallbackToAnyExtension(t *testing.T) {
 		t.Fatalf("write failed: %v", err)
 	}
 
-	got, err := app.resolveExistingPhotoStoragePath(relPathNoExt)
+	got, err := app.resolveExistingPhotoStoragePath(context.Background(), relPathNoExt)
 	if err != nil {
 		t.Fatalf("resolveExistingPhotoStoragePath returned error: %v", err)
 	}
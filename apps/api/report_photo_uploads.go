@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	photoKindCitizen    = "citizen"
+	photoKindFieldCheck = "field_check"
+	photoKindAfter      = "after"
+)
+
+var reportPhotoKinds = []string{photoKindCitizen, photoKindFieldCheck, photoKindAfter}
+
+// addReportPhotos sanitizes photos an operator adds to an existing report and
+// stores them with the given kind. Each photo gets a photo_added event so it
+// shows up in the report timeline. It returns the new photo IDs.
+func (a *App) addReportPhotos(ctx context.Context, reportID int, kind string, photos []PhotoUpload, session OperatorSession) ([]int, error) {
+	if !containsString(reportPhotoKinds, kind) {
+		return nil, &apiError{Status: http.StatusBadRequest, Code: "invalid_photo_kind", Message: "Kind must be citizen, field_check or after"}
+	}
+
+	releaseDecodeSlot, err := a.acquirePhotoDecodeSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sanitized, err := sanitizeAndValidatePhotos(photos)
+	releaseDecodeSlot()
+	if err != nil {
+		return nil, err
+	}
+	for i := range sanitized {
+		sanitized[i].Kind = kind
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	photoIDs, err := a.saveReportPhotosTx(ctx, tx, reportID, sanitized, &session.Email)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	for i, photoID := range photoIDs {
+		if err := a.addEventTx(ctx, tx, reportID, "photo_added", session.Email, map[string]any{
+			"photo_id": photoID,
+			"kind":     kind,
+			"filename": sanitized[i].Name,
+		}); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE reports SET updated_at = NOW() WHERE id = $1`, reportID); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return photoIDs, nil
+}
+
+func (a *App) operatorAddReportPhotosHandler(c *gin.Context) {
+	session, err := getOperatorSession(c)
+	if err != nil {
+		writeAPIError(c, &apiError{Status: http.StatusUnauthorized, Code: "unauthorized", Message: "Operator session required"})
+		return
+	}
+	reportID := a.parseOperatorReportID(c)
+	if reportID == 0 {
+		writeAPIError(c, &apiError{Status: http.StatusBadRequest, Code: "invalid_id", Message: "Invalid report ID"})
+		return
+	}
+
+	report, err := a.getReportByID(c.Request.Context(), reportID)
+	if err != nil {
+		writeAPIError(c, err)
+		return
+	}
+	if report == nil {
+		writeAPIError(c, &apiError{Status: http.StatusNotFound, Code: "report_not_found", Message: "Report not found"})
+		return
+	}
+	if err := a.checkMunicipalityScope(c, report.Municipality); err != nil {
+		writeAPIError(c, err)
+		return
+	}
+
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		writeAPIError(c, &apiError{Status: http.StatusBadRequest, Code: "invalid_multipart", Message: "Invalid multipart form"})
+		return
+	}
+	photos, err := readMultipartPhotos(c.Request.MultipartForm.File["photos"])
+	if err != nil {
+		writeAPIError(c, err)
+		return
+	}
+
+	photoIDs, err := a.addReportPhotos(c.Request.Context(), reportID, strings.TrimSpace(c.PostForm("kind")), photos, session)
+	if err != nil {
+		writeAPIError(c, err)
+		return
+	}
+
+	stored, err := a.listReportPhotos(c.Request.Context(), reportID)
+	if err != nil {
+		writeAPIError(c, err)
+		return
+	}
+	isAdded := make(map[int]bool, len(photoIDs))
+	for _, photoID := range photoIDs {
+		isAdded[photoID] = true
+	}
+	added := make([]ReportPhoto, 0, len(photoIDs))
+	for _, photo := range stored {
+		if isAdded[photo.ID] {
+			added = append(added, photo)
+		}
+	}
+	c.JSON(http.StatusCreated, a.toOperatorReportPhotoViews(reportID, added))
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+func buildPhotoUploadRequest(t *testing.T, app *App, target, kind string, session OperatorSession) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("kind", kind); err != nil {
+		t.Fatalf("write kind: %v", err)
+	}
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="photos"; filename="after.jpg"`)
+	header.Set("Content-Type", "image/jpeg")
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		t.Fatalf("create part: %v", err)
+	}
+	if _, err := part.Write(buildTestJPEG(t, 64, 48)); err != nil {
+		t.Fatalf("write photo: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close writer: %v", err)
+	}
+
+	req := authenticatedRequestWithSession(t, app, http.MethodPost, target, "", session)
+	req.Body = io.NopCloser(&body)
+	req.ContentLength = int64(body.Len())
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestAdminReportPhotosSubmitAddsPhotos(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	var gotKind string
+	var gotPhotos []PhotoUpload
+	app.adminAddReportPhotos = func(ctx context.Context, reportID int, kind string, photos []PhotoUpload, session OperatorSession) error {
+		if reportID != 4 || session.Email != "operator@example.com" {
+			t.Fatalf("unexpected upload for report %d by %q", reportID, session.Email)
+		}
+		gotKind, gotPhotos = kind, photos
+		return nil
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, buildPhotoUploadRequest(t, app, "/bikeadmin/reports/4/photos", photoKindAfter, OperatorSession{Email: "operator@example.com", Role: "admin"}))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", rec.Code)
+	}
+	if location := rec.Header().Get("Location"); !strings.HasPrefix(location, "/bikeadmin/reports/4?") || !strings.Contains(location, "notice=") {
+		t.Fatalf("expected a notice redirect to the report, got %q", location)
+	}
+	if gotKind != photoKindAfter || len(gotPhotos) != 1 || gotPhotos[0].MimeType != "image/jpeg" || gotPhotos[0].Name != "after.jpg" {
+		t.Fatalf("unexpected upload: kind %q, %d photos", gotKind, len(gotPhotos))
+	}
+}
+
+func TestAdminReportPhotosSubmitChecksMunicipalityScope(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	other := "Utrecht"
+	app.adminGetReportByID = func(ctx context.Context, reportID int) (*Report, error) {
+		return &Report{ID: reportID, Municipality: &other}, nil
+	}
+	app.adminAddReportPhotos = func(ctx context.Context, reportID int, kind string, photos []PhotoUpload, session OperatorSession) error {
+		t.Fatalf("expected photos outside the operator's municipality to be refused")
+		return nil
+	}
+
+	municipality := "Amsterdam"
+	session := OperatorSession{Email: "crew@example.com", Role: "municipality_operator", Municipality: &municipality}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, buildPhotoUploadRequest(t, app, "/bikeadmin/reports/4/photos", photoKindFieldCheck, session))
+	if location := rec.Header().Get("Location"); !strings.Contains(location, "error=") {
+		t.Fatalf("expected an error redirect, got %q", location)
+	}
+}
+
+func TestAddReportPhotosRejectsUnknownKind(t *testing.T) {
+	app := &App{cfg: &Config{}}
+	_, err := app.addReportPhotos(context.Background(), 1, "before", []PhotoUpload{{Name: "a.jpg", MimeType: "image/jpeg"}}, OperatorSession{Email: "operator@example.com"})
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.Code != "invalid_photo_kind" {
+		t.Fatalf("expected invalid_photo_kind, got %v", err)
+	}
+}
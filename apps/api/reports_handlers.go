@@ -1,18 +1,18 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"image"
-	"image/jpeg"
 	"io"
+	"math"
+	"mime/multipart"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -132,7 +132,7 @@ func (a *App) tagsHandler(c *gin.Context) {
 
 func (a *App) getTags(ctx context.Context) ([]Tag, error) {
 	rows, err := a.db.QueryContext(ctx, `
-		SELECT id, code, label, is_active
+		SELECT id, code, label, is_active, weight
 		FROM tags
 		ORDER BY code ASC
 	`)
@@ -144,7 +144,7 @@ func (a *App) getTags(ctx context.Context) ([]Tag, error) {
 	var tags []Tag
 	for rows.Next() {
 		var tag Tag
-		if err := rows.Scan(&tag.ID, &tag.Code, &tag.Label, &tag.IsActive); err != nil {
+		if err := rows.Scan(&tag.ID, &tag.Code, &tag.Label, &tag.IsActive, &tag.Weight); err != nil {
 			return nil, err
 		}
 		tags = append(tags, tag)
@@ -158,23 +158,23 @@ func (a *App) getTags(ctx context.Context) ([]Tag, error) {
 
 	for _, seed := range defaultTagDictionary {
 		_, err := a.db.ExecContext(ctx, `
-			INSERT INTO tags (code, label, is_active)
-			VALUES ($1, $2, $3)
+			INSERT INTO tags (code, label, is_active, weight)
+			VALUES ($1, $2, $3, $4)
 			ON CONFLICT (code) DO NOTHING
-		`, seed.Code, seed.Label, seed.IsActive)
+		`, seed.Code, seed.Label, seed.IsActive, seed.Weight)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	rows, err = a.db.QueryContext(ctx, `SELECT id, code, label, is_active FROM tags ORDER BY code ASC`)
+	rows, err = a.db.QueryContext(ctx, `SELECT id, code, label, is_active, weight FROM tags ORDER BY code ASC`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	for rows.Next() {
 		var tag Tag
-		if err := rows.Scan(&tag.ID, &tag.Code, &tag.Label, &tag.IsActive); err != nil {
+		if err := rows.Scan(&tag.ID, &tag.Code, &tag.Label, &tag.IsActive, &tag.Weight); err != nil {
 			return nil, err
 		}
 		tags = append(tags, tag)
@@ -196,16 +196,27 @@ func sanitizeAndValidatePhotos(photos []PhotoUpload) ([]PhotoUpload, error) {
 			return nil, &apiError{Status: http.StatusBadRequest, Code: "invalid_photo_type", Message: "Photo mime type is not supported"}
 		}
 
-		if photo.MimeType == "image/jpeg" {
-			decoded, _, err := image.Decode(bytes.NewReader(photo.Bytes))
-			if err == nil {
-				buffer := bytes.NewBuffer(nil)
-				if encodeErr := jpeg.Encode(buffer, decoded, &jpeg.Options{Quality: 88}); encodeErr == nil {
-					photo.Bytes = buffer.Bytes()
-				}
-			}
+		if err := checkPhotoDimensions(photo.MimeType, photo.Bytes); err != nil {
+			return nil, err
+		}
+
+		if exif, err := readPhotoEXIF(photo.MimeType, photo.Bytes); err == nil {
+			photo.EXIF = &exif
 		}
 
+		orientation := 0
+		if photo.EXIF != nil {
+			orientation = photo.EXIF.Orientation
+		}
+		sanitized, err := sanitizePhotoBytes(photo.MimeType, photo.Bytes, orientation)
+		if err != nil {
+			return nil, &apiError{Status: http.StatusBadRequest, Code: "invalid_photo", Message: "Photo could not be read"}
+		}
+		photo.OriginalMimeType = photo.MimeType
+		photo.MimeType = sanitized.MimeType
+		photo.Bytes = sanitized.Bytes
+		photo.PerceptualHash = sanitized.Hash
+
 		out = append(out, photo)
 	}
 
@@ -233,6 +244,42 @@ func parseDataURLPhoto(dataURL string, fallbackName string) (PhotoUpload, error)
 	return PhotoUpload{Name: fallbackName, MimeType: mimeType, Bytes: decoded}, nil
 }
 
+// readMultipartPhotos reads uploaded photo files, rejecting oversized files
+// and unsupported types before anything is decoded.
+func readMultipartPhotos(files []*multipart.FileHeader) ([]PhotoUpload, error) {
+	photos := make([]PhotoUpload, 0, len(files))
+	for idx, fileHeader := range files {
+		opened, err := fileHeader.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, readErr := io.ReadAll(io.LimitReader(opened, maxUploadBytes+1))
+		_ = opened.Close()
+		if readErr != nil {
+			return nil, readErr
+		}
+		if len(data) > maxUploadBytes {
+			return nil, &apiError{Status: http.StatusBadRequest, Code: "photo_too_large", Message: "Photo exceeds upload size limit"}
+		}
+
+		mimeType := fileHeader.Header.Get("Content-Type")
+		if mimeType == "" {
+			mimeType = sniffPhotoMimeType(data)
+		}
+		mimeType = strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0]))
+		if _, ok := allowedImageTypes[mimeType]; !ok {
+			return nil, &apiError{Status: http.StatusBadRequest, Code: "invalid_photo_type", Message: "Photo mime type is not supported"}
+		}
+
+		name := strings.TrimSpace(fileHeader.Filename)
+		if name == "" {
+			name = fmt.Sprintf("photo-%d.jpg", idx+1)
+		}
+		photos = append(photos, PhotoUpload{Name: name, MimeType: mimeType, Bytes: data})
+	}
+	return photos, nil
+}
+
 func normalizeNote(value string) *string {
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
@@ -274,11 +321,13 @@ func parseReportCreatePayload(c *gin.Context) (ReportCreatePayload, error) {
 				Lng       float64 `json:"lng"`
 				AccuracyM float64 `json:"accuracy_m"`
 			} `json:"location"`
-			Tags          []string `json:"tags"`
-			Note          *string  `json:"note"`
-			ClientTS      *string  `json:"client_ts"`
-			ReporterEmail string   `json:"reporter_email"`
-			UILanguage    string   `json:"ui_language"`
+			LocationSource string   `json:"location_source"`
+			LocationToken  string   `json:"location_token"`
+			Tags           []string `json:"tags"`
+			Note           *string  `json:"note"`
+			ClientTS       *string  `json:"client_ts"`
+			ReporterEmail  string   `json:"reporter_email"`
+			UILanguage     string   `json:"ui_language"`
 		}
 		if err := c.ShouldBindJSON(&body); err != nil {
 			return payload, &apiError{Status: http.StatusBadRequest, Code: "invalid_payload", Message: "Invalid JSON body"}
@@ -293,6 +342,12 @@ func parseReportCreatePayload(c *gin.Context) (ReportCreatePayload, error) {
 		}
 		payload.Photos = photos
 		payload.Location = ReportLocation{Lat: body.Location.Lat, Lng: body.Location.Lng, AccuracyM: body.Location.AccuracyM}
+		locationSource, ok := normalizeLocationSource(body.LocationSource)
+		if !ok {
+			return payload, &apiError{Status: http.StatusBadRequest, Code: "invalid_location", Message: "Invalid location source"}
+		}
+		payload.LocationSource = locationSource
+		payload.LocationToken = body.LocationToken
 		payload.Tags = body.Tags
 		if body.Note != nil {
 			payload.Note = normalizeNote(*body.Note)
@@ -319,6 +374,10 @@ func parseReportCreatePayload(c *gin.Context) (ReportCreatePayload, error) {
 	if err != nil {
 		return payload, &apiError{Status: http.StatusBadRequest, Code: "invalid_location", Message: "Invalid accuracy"}
 	}
+	locationSource, ok := normalizeLocationSource(c.PostForm("location_source"))
+	if !ok {
+		return payload, &apiError{Status: http.StatusBadRequest, Code: "invalid_location", Message: "Invalid location source"}
+	}
 
 	tagsRaw := c.PostForm("tags")
 	var tags []string
@@ -331,40 +390,15 @@ func parseReportCreatePayload(c *gin.Context) (ReportCreatePayload, error) {
 		payload.ClientTS = &clientTS
 	}
 
-	files := c.Request.MultipartForm.File["photos"]
-	photos := make([]PhotoUpload, 0, len(files))
-	for idx, fileHeader := range files {
-		opened, err := fileHeader.Open()
-		if err != nil {
-			return payload, err
-		}
-		data, readErr := io.ReadAll(io.LimitReader(opened, maxUploadBytes+1))
-		_ = opened.Close()
-		if readErr != nil {
-			return payload, readErr
-		}
-		if len(data) > maxUploadBytes {
-			return payload, &apiError{Status: http.StatusBadRequest, Code: "photo_too_large", Message: "Photo exceeds upload size limit"}
-		}
-
-		mimeType := fileHeader.Header.Get("Content-Type")
-		if mimeType == "" {
-			mimeType = http.DetectContentType(data)
-		}
-		mimeType = strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0]))
-		if _, ok := allowedImageTypes[mimeType]; !ok {
-			return payload, &apiError{Status: http.StatusBadRequest, Code: "invalid_photo_type", Message: "Photo mime type is not supported"}
-		}
-
-		name := strings.TrimSpace(fileHeader.Filename)
-		if name == "" {
-			name = fmt.Sprintf("photo-%d.jpg", idx+1)
-		}
-		photos = append(photos, PhotoUpload{Name: name, MimeType: mimeType, Bytes: data})
+	photos, err := readMultipartPhotos(c.Request.MultipartForm.File["photos"])
+	if err != nil {
+		return payload, err
 	}
 
 	payload.Photos = photos
 	payload.Location = ReportLocation{Lat: lat, Lng: lng, AccuracyM: accuracy}
+	payload.LocationSource = locationSource
+	payload.LocationToken = c.PostForm("location_token")
 	payload.Tags = tags
 	payload.Note = normalizeNote(c.PostForm("note"))
 	payload.ReporterEmail = normalizeReporterEmail(c.PostForm("reporter_email"))
@@ -376,7 +410,12 @@ func validateReportCreatePayload(payload ReportCreatePayload, maxLocationAccurac
 	if payload.Location.Lat < -90 || payload.Location.Lat > 90 || payload.Location.Lng < -180 || payload.Location.Lng > 180 {
 		return &apiError{Status: http.StatusBadRequest, Code: "invalid_location", Message: "Location is invalid"}
 	}
-	if payload.Location.AccuracyM < 0 || payload.Location.AccuracyM > maxLocationAccuracyM {
+	if _, ok := normalizeLocationSource(payload.LocationSource); !ok {
+		return &apiError{Status: http.StatusBadRequest, Code: "invalid_location", Message: "Location source is invalid"}
+	}
+	// An address search has no GPS fix, so its accuracy is not bounded;
+	// createReport checks its location token instead.
+	if payload.Location.AccuracyM < 0 || (payload.LocationSource != locationSourceAddressSearch && payload.Location.AccuracyM > maxLocationAccuracyM) {
 		return &apiError{Status: http.StatusBadRequest, Code: "invalid_location", Message: "Location accuracy is invalid"}
 	}
 	if len(payload.Tags) < minTagCount || len(payload.Tags) > maxTagCount {
@@ -441,10 +480,38 @@ func (a *App) createReportHandler(c *gin.Context) {
 	c.JSON(http.StatusCreated, created)
 }
 
+// reportCreatedMetadata describes a new report for its created event.
+// retention_days is left out while the photo retention policy is off.
+func reportCreatedMetadata(source, locationSource string, bikeGroup BikeGroup, params SignalParams, photoRetention time.Duration) map[string]any {
+	metadata := map[string]any{
+		"source":          source,
+		"location_source": locationSource,
+		"bike_group_id":   bikeGroup.ID,
+		"signal_params":   params.eventMetadata(),
+	}
+	if photoRetention > 0 {
+		metadata["retention_days"] = retentionDays(photoRetention)
+	}
+	if bikeGroup.PreviousGroupID != nil {
+		metadata["recurring_bike_group_id"] = *bikeGroup.PreviousGroupID
+	}
+	return metadata
+}
+
 func (a *App) createReport(ctx context.Context, payload ReportCreatePayload) (ReportCreateResponse, error) {
 	if err := validateReportCreatePayload(payload, a.cfg.MaxLocationAccuracyM); err != nil {
 		return ReportCreateResponse{}, err
 	}
+	locationSource, _ := normalizeLocationSource(payload.LocationSource)
+	if locationSource == locationSourceAddressSearch {
+		if err := a.verifyLocationToken(payload.LocationToken, payload.Location); err != nil {
+			return ReportCreateResponse{}, err
+		}
+	}
+	nearBorder, err := a.checkServiceArea(payload.Location)
+	if err != nil {
+		return ReportCreateResponse{}, err
+	}
 
 	now := time.Now().UTC()
 	allowed := a.checkRateLimit("report:"+payload.IP, reportRateLimitRequests, reportRateLimitWindow, now)
@@ -452,7 +519,12 @@ func (a *App) createReport(ctx context.Context, payload ReportCreatePayload) (Re
 		return ReportCreateResponse{}, &apiError{Status: http.StatusTooManyRequests, Code: "rate_limited", Message: "Too many reports from this IP. Please retry later."}
 	}
 
+	releaseDecodeSlot, err := a.acquirePhotoDecodeSlot(ctx)
+	if err != nil {
+		return ReportCreateResponse{}, err
+	}
 	sanitizedPhotos, err := sanitizeAndValidatePhotos(payload.Photos)
+	releaseDecodeSlot()
 	if err != nil {
 		return ReportCreateResponse{}, err
 	}
@@ -473,13 +545,30 @@ func (a *App) createReport(ctx context.Context, payload ReportCreatePayload) (Re
 		}
 	}
 
-	matchedGroup, err := a.selectBikeGroupForReport(ctx, payload, now)
+	reportMunicipality := a.resolveMunicipality(payload.Location)
+	signalMunicipality := reportMunicipality
+	if signalMunicipality == nil {
+		signalMunicipality, err = a.municipalityNearLocation(ctx, payload.Location)
+		if err != nil {
+			return ReportCreateResponse{}, err
+		}
+	}
+	params, err := a.loadSignalParams(ctx, signalMunicipality)
+	if err != nil {
+		return ReportCreateResponse{}, err
+	}
+
+	matchedGroup, recurringGroup, err := a.selectBikeGroupForReport(ctx, payload, now, params)
 	if err != nil {
 		return ReportCreateResponse{}, err
 	}
 	bikeGroup := matchedGroup
 	if bikeGroup == nil {
-		created, err := a.createBikeGroup(ctx, payload.Location)
+		var previousGroupID *int
+		if recurringGroup != nil {
+			previousGroupID = &recurringGroup.ID
+		}
+		created, err := a.createBikeGroup(ctx, payload.Location, previousGroupID)
 		if err != nil {
 			return ReportCreateResponse{}, err
 		}
@@ -504,29 +593,26 @@ func (a *App) createReport(ctx context.Context, payload ReportCreatePayload) (Re
 			public_id, status, lat, lng, accuracy_m, tags, note,
 			dedupe_group_id, source, fingerprint_hash, reporter_hash,
 			flagged_for_review, bike_group_id, user_id, reporter_email, reporter_email_confirmed,
-			created_at, updated_at
+			municipality, location_source, created_at, updated_at
 		) VALUES (
 			$1, 'new', $2, $3, $4, $5, $6,
 			NULL, $7, $8, $9,
 			FALSE, $10, $11, $12, FALSE,
-			NOW(), NOW()
+			$13, $14, NOW(), NOW()
 		)
 		RETURNING id
-	`, publicID, payload.Location.Lat, payload.Location.Lng, payload.Location.AccuracyM, tagsToJSON(payload.Tags), payload.Note, payload.Source, payload.FingerprintHash, payload.ReporterHash, bikeGroup.ID, payload.UserID, payload.ReporterEmail).Scan(&reportID); err != nil {
+	`, publicID, payload.Location.Lat, payload.Location.Lng, payload.Location.AccuracyM, tagsToJSON(payload.Tags), payload.Note, payload.Source, payload.FingerprintHash, payload.ReporterHash, bikeGroup.ID, payload.UserID, payload.ReporterEmail, reportMunicipality, locationSource).Scan(&reportID); err != nil {
 		_ = tx.Rollback()
 		return ReportCreateResponse{}, err
 	}
 
-	if err := a.saveReportPhotosTx(ctx, tx, reportID, sanitizedPhotos); err != nil {
+	if _, err := a.saveReportPhotosTx(ctx, tx, reportID, sanitizedPhotos, nil); err != nil {
 		_ = tx.Rollback()
 		return ReportCreateResponse{}, err
 	}
 
-	if err := a.addEventTx(ctx, tx, reportID, "created", actor, map[string]any{
-		"source":         payload.Source,
-		"retention_days": 365,
-		"bike_group_id":  bikeGroup.ID,
-	}); err != nil {
+	createdMetadata := reportCreatedMetadata(payload.Source, locationSource, *bikeGroup, params, a.cfg.RetentionPhotos)
+	if err := a.addEventTx(ctx, tx, reportID, "created", actor, createdMetadata); err != nil {
 		_ = tx.Rollback()
 		return ReportCreateResponse{}, err
 	}
@@ -547,9 +633,9 @@ func (a *App) createReport(ctx context.Context, payload ReportCreatePayload) (Re
 	if err != nil {
 		return ReportCreateResponse{}, err
 	}
-	recomputation := computeReconfirmation(groupReports)
+	recomputation := computeReconfirmation(groupReports, params)
 	previousStrength := bikeGroup.SignalStrength
-	updatedGroup := applySummaryToBikeGroup(*bikeGroup, recomputation.Summary, recomputation.SignalStrength)
+	updatedGroup := applySummaryToBikeGroup(*bikeGroup, recomputation.Summary, recomputation.SignalStrength, recomputation.Score.BaseScore)
 	if err := a.updateBikeGroup(ctx, updatedGroup); err != nil {
 		return ReportCreateResponse{}, err
 	}
@@ -573,20 +659,47 @@ func (a *App) createReport(ctx context.Context, payload ReportCreatePayload) (Re
 			"previous_signal_strength": previousStrength,
 			"signal_strength":          recomputation.SignalStrength,
 			"bike_group_id":            bikeGroup.ID,
+			"signal_params_id":         params.ID,
 		})
 	}
 
-	openSince := now.AddDate(0, 0, -dedupeLookbackDays).Format(time.RFC3339)
+	openSince := now.AddDate(0, 0, -params.DedupeLookbackDays).Format(time.RFC3339)
 	openReports, err := a.listOpenReportsSince(ctx, openSince)
 	if err != nil {
 		return ReportCreateResponse{}, err
 	}
+	incomingHashes := make([]uint64, 0, len(sanitizedPhotos))
+	for _, photo := range sanitizedPhotos {
+		if photo.PerceptualHash != nil {
+			incomingHashes = append(incomingHashes, *photo.PerceptualHash)
+		}
+	}
+	// Only reports inside the dedupe radius can score, so only their photo
+	// hashes are loaded.
+	nearbyIDs := make([]int, 0)
+	for _, candidate := range openReports {
+		if candidate.ID != report.ID && haversineMeters(report.Location.Lat, report.Location.Lng, candidate.Location.Lat, candidate.Location.Lng) <= params.DedupeRadiusMeters {
+			nearbyIDs = append(nearbyIDs, candidate.ID)
+		}
+	}
+	candidateHashes := map[int][]uint64{}
+	if len(incomingHashes) > 0 {
+		candidateHashes, err = a.listPhotoHashesByReport(ctx, nearbyIDs)
+		if err != nil {
+			return ReportCreateResponse{}, err
+		}
+	}
 	candidates := make([]dedupeCandidate, 0)
 	for _, candidate := range openReports {
 		if candidate.ID == report.ID {
 			continue
 		}
-		scored := scoreDuplicateCandidate(*report, candidate, now)
+		var photoSimilarity *float64
+		if hashes := candidateHashes[candidate.ID]; len(hashes) > 0 {
+			similarity := photoHashSimilarity(incomingHashes, hashes)
+			photoSimilarity = &similarity
+		}
+		scored := scoreDuplicateCandidate(*report, candidate, photoSimilarity, now, params)
 		if scored != nil {
 			candidates = append(candidates, *scored)
 		}
@@ -602,6 +715,34 @@ func (a *App) createReport(ctx context.Context, payload ReportCreatePayload) (Re
 	}
 
 	flagged := a.applyFingerprintHeuristic(payload.FingerprintHash, now)
+	if nearBorder {
+		flagged = true
+		if err := a.addEvent(ctx, report.ID, "flagged_near_service_area_border", "system", map[string]any{
+			"distance_m": math.Round(a.serviceArea.DistanceMeters(payload.Location.Lat, payload.Location.Lng)),
+		}); err != nil {
+			return ReportCreateResponse{}, err
+		}
+	}
+	exifReference := photoEXIFReferenceTime(payload.ClientTS, now)
+	for i, photo := range sanitizedPhotos {
+		findings := make([]photoFinding, 0)
+		if photo.EXIF != nil {
+			findings = append(findings, comparePhotoEXIF(i, *photo.EXIF, payload.Location, exifReference, a.cfg.PhotoLocationMismatchM, a.cfg.PhotoMaxAge)...)
+		}
+		if photo.PerceptualHash != nil && a.cfg.PhotoReuseMinDistanceM > 0 {
+			matches, err := a.findReusedPhotos(ctx, report.ID, *photo.PerceptualHash)
+			if err != nil {
+				return ReportCreateResponse{}, err
+			}
+			findings = append(findings, photoReuseFindings(i, payload.Location, matches, a.cfg.PhotoReuseMinDistanceM)...)
+		}
+		for _, finding := range findings {
+			flagged = true
+			if err := a.addEvent(ctx, report.ID, finding.EventType, "system", finding.Metadata); err != nil {
+				return ReportCreateResponse{}, err
+			}
+		}
+	}
 	if flagged {
 		if _, err := a.db.ExecContext(ctx, `UPDATE reports SET flagged_for_review = TRUE, updated_at = NOW() WHERE id = $1`, report.ID); err != nil {
 			return ReportCreateResponse{}, err
@@ -756,6 +897,7 @@ func (a *App) listOperatorReports(ctx context.Context, filters map[string]any) (
 		}
 	}
 
+	now := time.Now().UTC()
 	views := make([]OperatorReportView, 0, len(reports))
 	for _, report := range reports {
 		group, ok := groups[report.BikeGroupID]
@@ -770,15 +912,17 @@ func (a *App) listOperatorReports(ctx context.Context, filters map[string]any) (
 
 		var previewPhotoURL *string
 		if len(photos) > 0 {
-			previewURL := a.buildOperatorReportPhotoURL(report.ID, photos[0].ID)
+			previewURL := withPhotoVersion(a.buildOperatorReportPhotoVariantURL(report.ID, photos[0].ID, photoVariantThumb), photos[0].Version)
 			previewPhotoURL = &previewURL
 		}
 
+		score := decayedGroupSignalScore(group, now)
 		view := OperatorReportView{
 			Report:          report,
 			BikeGroupID:     group.ID,
 			SignalSummary:   bikeGroupToSignalSummary(group),
-			SignalStrength:  group.SignalStrength,
+			SignalStrength:  decayedSignalStrength(group.SignalStrength, score),
+			SignalScore:     score,
 			PreviewPhotoURL: previewPhotoURL,
 		}
 
@@ -799,10 +943,8 @@ func (a *App) listOperatorReports(ctx context.Context, filters map[string]any) (
 
 	if sortBy, ok := filters["sort"].(string); ok && sortBy == "signal" {
 		sort.Slice(views, func(i, j int) bool {
-			left := signalStrengthPriority[views[i].SignalStrength]
-			right := signalStrengthPriority[views[j].SignalStrength]
-			if left != right {
-				return left > right
+			if views[i].SignalScore != views[j].SignalScore {
+				return views[i].SignalScore > views[j].SignalScore
 			}
 			return views[i].CreatedAt > views[j].CreatedAt
 		})
@@ -867,6 +1009,11 @@ func (a *App) operatorReportPhotoHandler(c *gin.Context) {
 		writeAPIError(c, &apiError{Status: http.StatusBadRequest, Code: "invalid_id", Message: "Invalid ID"})
 		return
 	}
+	size, ok := parsePhotoVariantSize(c.Query("size"))
+	if !ok {
+		writeAPIError(c, &apiError{Status: http.StatusBadRequest, Code: "invalid_size", Message: "Size must be thumb, medium or full"})
+		return
+	}
 
 	photo, err := a.getReportPhotoByID(c.Request.Context(), reportID, photoID)
 	if err != nil {
@@ -890,33 +1037,67 @@ func (a *App) operatorReportPhotoHandler(c *gin.Context) {
 		}
 	}
 
-	relativePath, err := a.resolveExistingPhotoStoragePath(photo.StoragePath)
+	relativePath, err := a.ensurePhotoVariant(c.Request.Context(), *photo, size)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
+		var apiErr *apiError
+		switch {
+		case errors.Is(err, os.ErrNotExist):
 			writeAPIError(c, &apiError{Status: http.StatusNotFound, Code: "photo_not_found", Message: "Photo not found"})
+		case errors.As(err, &apiErr):
+			writeAPIError(c, apiErr)
+		case size != photoVariantFull:
+			a.log.Error("failed to generate photo variant", "photo_id", photo.ID, "size", size, "err", err)
+			writeAPIError(c, &apiError{Status: http.StatusInternalServerError, Code: "photo_variant_failed", Message: "Photo variant could not be generated"})
+		default:
+			writeAPIError(c, &apiError{Status: http.StatusInternalServerError, Code: "invalid_photo_path", Message: "Photo path is invalid"})
+		}
+		return
+	}
+
+	contentType, filename := photo.MimeType, photo.Filename
+	if size != photoVariantFull {
+		contentType = "image/jpeg"
+		filename = strings.TrimSuffix(filename, filepath.Ext(filename)) + "-" + size + ".jpg"
+	}
+	a.writeProtectedPhoto(c, relativePath, contentType, filename, fmt.Sprintf("private, max-age=%d", operatorPhotoCacheMaxAgeSeconds))
+}
+
+// writeProtectedPhoto sends a stored blob after the caller checked access:
+// through a presigned URL when the store supports it, X-Accel-Redirect in
+// production, directly otherwise.
+func (a *App) writeProtectedPhoto(c *gin.Context, relativePath, contentType, filename, cacheControl string) {
+	disposition := fmt.Sprintf("inline; filename=%q", filename)
+	store := a.blobStore()
+	if presigner, ok := store.(blobPresigner); ok {
+		target, err := presigner.PresignGet(relativePath, a.cfg.S3PresignTTL, BlobResponseHeaders{
+			ContentType:        contentType,
+			ContentDisposition: disposition,
+			CacheControl:       cacheControl,
+		})
+		if err != nil {
+			writeAPIError(c, err)
 			return
 		}
-		writeAPIError(c, &apiError{Status: http.StatusInternalServerError, Code: "invalid_photo_path", Message: "Photo path is invalid"})
+		// The URL expires, so the redirect itself must not be cached.
+		c.Header("Cache-Control", "no-store")
+		c.Redirect(http.StatusFound, target)
 		return
 	}
 
-	if photo.MimeType != "" {
-		c.Header("Content-Type", photo.MimeType)
+	if contentType != "" {
+		c.Header("Content-Type", contentType)
+	}
+	c.Header("Content-Disposition", disposition)
+	if cacheControl != "" {
+		c.Header("Cache-Control", cacheControl)
 	}
-	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", photo.Filename))
-	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", operatorPhotoCacheMaxAgeSeconds))
 	if a.shouldUseInternalMediaRedirect() {
 		c.Header("X-Accel-Redirect", buildOperatorMediaInternalPath(relativePath))
 		c.Status(http.StatusOK)
 		return
 	}
 
-	fullPath, err := a.resolveDataRootStoragePath(relativePath)
-	if err != nil {
-		writeAPIError(c, err)
-		return
-	}
-	contents, err := os.ReadFile(fullPath)
+	contents, err := store.Get(c.Request.Context(), relativePath)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			writeAPIError(c, &apiError{Status: http.StatusNotFound, Code: "photo_not_found", Message: "Photo not found"})
@@ -925,7 +1106,7 @@ func (a *App) operatorReportPhotoHandler(c *gin.Context) {
 		writeAPIError(c, err)
 		return
 	}
-	c.Data(http.StatusOK, photo.MimeType, contents)
+	c.Data(http.StatusOK, contentType, contents)
 }
 
 func (a *App) getReportDetails(ctx context.Context, reportID int) (*OperatorReportDetails, error) {
@@ -960,72 +1141,59 @@ func (a *App) getReportDetails(ctx context.Context, reportID int) (*OperatorRepo
 		return nil, err
 	}
 
-	signalDetails := buildSignalDetails(groupReports, *group)
+	params, err := a.loadSignalParams(ctx, report.Municipality)
+	if err != nil {
+		return nil, err
+	}
+
+	signalDetails := buildSignalDetails(groupReports, *group, params)
+	signalDetails.RecurringHistory, err = a.listRecurringLocationHistory(ctx, *group)
+	if err != nil {
+		return nil, err
+	}
+	legalHold, err := a.getLegalHold(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
 	return &OperatorReportDetails{
 		Report:        *report,
 		Events:        events,
 		Photos:        a.toOperatorReportPhotoViews(reportID, photos),
 		SignalDetails: signalDetails,
+		LegalHold:     legalHold,
 	}, nil
 }
 
-func (a *App) resolveDataRootStoragePath(storagePath string) (string, error) {
-	cleanStoragePath := filepath.Clean(strings.TrimSpace(storagePath))
-	if cleanStoragePath == "" || cleanStoragePath == "." || filepath.IsAbs(cleanStoragePath) {
-		return "", fmt.Errorf("invalid storage path")
-	}
-
-	root := filepath.Clean(a.cfg.DataRoot)
-	resolved := filepath.Clean(filepath.Join(root, cleanStoragePath))
-	relative, err := filepath.Rel(root, resolved)
+// resolveExistingPhotoStoragePath returns the key a photo is stored under.
+// Legacy rows may lack the file extension; the first stored blob with that
+// name and any extension is used then.
+func (a *App) resolveExistingPhotoStoragePath(ctx context.Context, storagePath string) (string, error) {
+	key, err := cleanBlobKey(storagePath)
 	if err != nil {
 		return "", err
 	}
-	if relative == ".." || strings.HasPrefix(relative, ".."+string(os.PathSeparator)) {
-		return "", fmt.Errorf("resolved path escapes data root")
-	}
-
-	return resolved, nil
-}
-
-func (a *App) resolveExistingPhotoStoragePath(storagePath string) (string, error) {
-	resolvedPath, err := a.resolveDataRootStoragePath(storagePath)
-	if err != nil {
+	store := a.blobStore()
+	if _, err := store.Stat(ctx, key); err == nil {
+		return key, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
 		return "", err
 	}
-	if fileExists(resolvedPath) {
-		return a.relativeDataRootPath(resolvedPath)
-	}
-	if filepath.Ext(resolvedPath) != "" {
+	if path.Ext(key) != "" {
 		return "", os.ErrNotExist
 	}
 
-	matches, err := filepath.Glob(resolvedPath + ".*")
+	candidates, err := store.List(ctx, key+".")
 	if err != nil {
 		return "", err
 	}
-	sort.Strings(matches)
-	for _, candidatePath := range matches {
-		if !fileExists(candidatePath) {
-			continue
+	for _, candidate := range candidates {
+		if !strings.Contains(strings.TrimPrefix(candidate.Key, key+"."), "/") {
+			return candidate.Key, nil
 		}
-		return a.relativeDataRootPath(candidatePath)
 	}
 	return "", os.ErrNotExist
 }
 
-func (a *App) relativeDataRootPath(fullPath string) (string, error) {
-	root := filepath.Clean(a.cfg.DataRoot)
-	relativePath, err := filepath.Rel(root, filepath.Clean(fullPath))
-	if err != nil {
-		return "", err
-	}
-	if relativePath == "." || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(os.PathSeparator)) {
-		return "", fmt.Errorf("path escapes data root")
-	}
-	return filepath.ToSlash(relativePath), nil
-}
-
 func buildOperatorMediaInternalPath(relativeStoragePath string) string {
 	normalized := filepath.ToSlash(filepath.Clean(strings.TrimSpace(relativeStoragePath)))
 	normalized = strings.TrimPrefix(normalized, "/")
@@ -1036,18 +1204,13 @@ func (a *App) shouldUseInternalMediaRedirect() bool {
 	return strings.EqualFold(a.cfg.Env, "production")
 }
 
-func fileExists(path string) bool {
-	info, err := os.Stat(path)
-	return err == nil && !info.IsDir()
-}
-
-func buildSignalDetails(reports []Report, group BikeGroup) SignalDetails {
+func buildSignalDetails(reports []Report, group BikeGroup, params SignalParams) SignalDetails {
 	sortedReports := append([]Report{}, reports...)
 	sort.Slice(sortedReports, func(i, j int) bool {
 		return sortedReports[i].CreatedAt < sortedReports[j].CreatedAt
 	})
 
-	recomputation := computeReconfirmation(sortedReports)
+	recomputation := computeReconfirmation(sortedReports, params)
 	reporterOrder := make([]string, 0)
 	reporterSeen := make(map[string]struct{})
 	for _, report := range sortedReports {
@@ -1093,10 +1256,12 @@ func buildSignalDetails(reports []Report, group BikeGroup) SignalDetails {
 		})
 	}
 
+	score := computeSignalScore(sortedReports, bikeGroupToSignalSummary(group), params.TagWeights, time.Now().UTC())
 	return SignalDetails{
 		BikeGroup:      group,
 		SignalSummary:  bikeGroupToSignalSummary(group),
-		SignalStrength: group.SignalStrength,
+		SignalStrength: decayedSignalStrength(group.SignalStrength, score.Score),
+		SignalScore:    score,
 		Timeline:       timeline,
 	}
 }
@@ -1118,11 +1283,31 @@ func (a *App) geocodeReport(ctx context.Context, reportID int) error {
 		return nil // No address found
 	}
 
-	municipality := lookupMunicipality(res.City)
+	municipality := successorMunicipality(lookupMunicipality(res.City), time.Now().UTC())
+	// The boundary polygons are authoritative; the geocoder only fills in
+	// the municipality when the point lies outside every known boundary.
+	if resolved := a.resolveMunicipality(report.Location); resolved != nil {
+		if !strings.EqualFold(*resolved, municipality) {
+			a.log.Warn("geocoder municipality disagrees with boundaries", "id", reportID, "city", res.City, "geocoder_municipality", municipality, "boundary_municipality", *resolved)
+		}
+		municipality = *resolved
+	}
 	a.log.Info("geocoded report", "id", reportID, "address", res.Address, "city", res.City, "municipality", municipality)
 	return a.updateReportAddress(ctx, reportID, res.Address, res.City, res.PostalCode, municipality)
 }
 
+// resolveMunicipality returns the municipality whose boundary contains loc,
+// or nil when no boundaries are loaded or loc lies outside all of them.
+func (a *App) resolveMunicipality(loc ReportLocation) *string {
+	name, ok := a.municipalities.Resolve(loc.Lat, loc.Lng)
+	if !ok {
+		return nil
+	}
+	// Boundary files can predate a herindeling.
+	name = successorMunicipality(name, time.Now().UTC())
+	return &name
+}
+
 func (a *App) updateReportAddress(ctx context.Context, reportID int, address, city, postcode, municipality string) error {
 	_, err := a.db.ExecContext(ctx, `
 		UPDATE reports
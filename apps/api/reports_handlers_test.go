@@ -3,6 +3,7 @@ package main
 import (
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -32,3 +33,19 @@ func TestTrackingTokenFromRequest_UsesBearerHeader(t *testing.T) {
 		t.Fatalf("expected bearer token, got %q", token)
 	}
 }
+
+func TestReportCreatedMetadataOmitsDisabledPhotoRetention(t *testing.T) {
+	group := BikeGroup{ID: 4}
+	enabled := reportCreatedMetadata("web", "gps", group, defaultSignalParams(), 90*24*time.Hour)
+	if enabled["retention_days"] != 90 {
+		t.Fatalf("expected retention_days 90, got %#v", enabled["retention_days"])
+	}
+
+	disabled := reportCreatedMetadata("web", "gps", group, defaultSignalParams(), 0)
+	if _, ok := disabled["retention_days"]; ok {
+		t.Fatalf("expected no retention_days while photo retention is off, got %#v", disabled)
+	}
+	if disabled["bike_group_id"] != 4 {
+		t.Fatalf("unexpected metadata %#v", disabled)
+	}
+}
@@ -0,0 +1,461 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	retentionCategoryPhotos        = "photos"
+	retentionCategoryReporterEmail = "reporter_email"
+	retentionCategoryFingerprint   = "fingerprint"
+	retentionCategoryExports       = "exports"
+
+	retentionActionDeleted    = "deleted"
+	retentionActionAnonymized = "anonymized"
+
+	defaultRetentionPhotos        = 365 * 24 * time.Hour
+	defaultRetentionReporterEmail = 365 * 24 * time.Hour
+	defaultRetentionFingerprint   = 30 * 24 * time.Hour
+	defaultRetentionExports       = 365 * 24 * time.Hour
+
+	legalHoldReasonMaxLength = 500
+)
+
+// RetentionPurgeResult counts what one purge-retention run removed, or would
+// remove on a dry run. LegalHolds is the number of reports under legal hold,
+// which every policy skipped.
+type RetentionPurgeResult struct {
+	DryRun         bool
+	Photos         int
+	PhotoReports   int
+	ReporterEmails int
+	Fingerprints   int
+	Exports        int
+	LegalHolds     int
+}
+
+// LegalHold exempts a report from every retention policy.
+type LegalHold struct {
+	Reason string `json:"reason"`
+	SetBy  string `json:"setBy"`
+	SetAt  string `json:"setAt"`
+}
+
+// purgedPhoto is a report photo removed by the photo policy, with the blobs
+// that belong to it.
+type purgedPhoto struct {
+	ID                int
+	ReportID          int
+	StoragePath       string
+	Version           int
+	RedactionOriginal []string
+}
+
+func retentionDays(d time.Duration) int {
+	return int(d / (24 * time.Hour))
+}
+
+// purgeRetention applies every enabled retention policy as of now:
+//   - photos of resolved and invalid reports, counted from the first status
+//     change to either, are deleted with their variants and retained
+//     redaction originals;
+//   - reporter e-mail addresses and fingerprint hashes are anonymized, since
+//     the reports themselves stay for statistics;
+//   - exports are deleted.
+//
+// Reports under legal hold are skipped. Each purged item is written to
+// retention_purges, and report-bound purges also get a retention_purged
+// event. Rows go first; a blob that fails to delete afterwards is logged and
+// left for verify-storage to report as an orphan.
+func (a *App) purgeRetention(ctx context.Context, now time.Time, dryRun bool) (RetentionPurgeResult, error) {
+	result := RetentionPurgeResult{DryRun: dryRun}
+	if err := a.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM reports WHERE legal_hold`).Scan(&result.LegalHolds); err != nil {
+		return result, err
+	}
+
+	if a.cfg.RetentionPhotos > 0 {
+		if err := a.purgeResolvedReportPhotos(ctx, now, dryRun, &result); err != nil {
+			return result, fmt.Errorf("purge photos: %w", err)
+		}
+	}
+	if a.cfg.RetentionReporterEmail > 0 {
+		count, err := a.anonymizeReportColumn(ctx, now, a.cfg.RetentionReporterEmail, retentionCategoryReporterEmail, dryRun)
+		if err != nil {
+			return result, fmt.Errorf("anonymize reporter e-mail: %w", err)
+		}
+		result.ReporterEmails = count
+	}
+	if a.cfg.RetentionFingerprint > 0 {
+		count, err := a.anonymizeReportColumn(ctx, now, a.cfg.RetentionFingerprint, retentionCategoryFingerprint, dryRun)
+		if err != nil {
+			return result, fmt.Errorf("anonymize fingerprints: %w", err)
+		}
+		result.Fingerprints = count
+	}
+	if a.cfg.RetentionExports > 0 {
+		count, err := a.purgeExpiredExports(ctx, now, dryRun)
+		if err != nil {
+			return result, fmt.Errorf("purge exports: %w", err)
+		}
+		result.Exports = count
+	}
+	return result, nil
+}
+
+func (a *App) purgeResolvedReportPhotos(ctx context.Context, now time.Time, dryRun bool, result *RetentionPurgeResult) error {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT p.id, p.report_id, p.storage_path, p.version
+		FROM report_photos p
+		JOIN reports r ON r.id = p.report_id
+		LEFT JOIN LATERAL (
+			SELECT MIN(e.created_at) AS resolved_at
+			FROM report_events e
+			WHERE e.report_id = r.id AND e.type = 'status_changed' AND e.metadata->>'status' IN ('resolved', 'invalid')
+		) resolution ON TRUE
+		WHERE r.status IN ('resolved', 'invalid')
+		  AND NOT r.legal_hold
+		  AND COALESCE(resolution.resolved_at, r.updated_at) <= $1
+		ORDER BY p.report_id ASC, p.id ASC
+	`, now.Add(-a.cfg.RetentionPhotos))
+	if err != nil {
+		return err
+	}
+	byReport := make(map[int][]purgedPhoto)
+	reportIDs := make([]int, 0)
+	for rows.Next() {
+		var photo purgedPhoto
+		if err := rows.Scan(&photo.ID, &photo.ReportID, &photo.StoragePath, &photo.Version); err != nil {
+			rows.Close()
+			return err
+		}
+		if _, ok := byReport[photo.ReportID]; !ok {
+			reportIDs = append(reportIDs, photo.ReportID)
+		}
+		byReport[photo.ReportID] = append(byReport[photo.ReportID], photo)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, reportID := range reportIDs {
+		photos := byReport[reportID]
+		if dryRun {
+			result.Photos += len(photos)
+			result.PhotoReports++
+			continue
+		}
+		purged, err := a.deleteReportPhotosForRetention(ctx, reportID, photos, now)
+		if err != nil {
+			return fmt.Errorf("report %d: %w", reportID, err)
+		}
+		if len(purged) == 0 {
+			continue
+		}
+		a.deletePurgedPhotoBlobs(ctx, purged)
+		result.Photos += len(purged)
+		result.PhotoReports++
+	}
+	return nil
+}
+
+// deleteReportPhotosForRetention deletes a report's photo rows and logs the
+// purge in one transaction. It returns nothing when the report was put under
+// legal hold in the meantime.
+func (a *App) deleteReportPhotosForRetention(ctx context.Context, reportID int, photos []purgedPhoto, now time.Time) ([]purgedPhoto, error) {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var held bool
+	if err := tx.QueryRowContext(ctx, `SELECT legal_hold FROM reports WHERE id = $1 FOR UPDATE`, reportID).Scan(&held); err != nil {
+		return nil, err
+	}
+	if held {
+		return nil, nil
+	}
+
+	photoIDs := make([]int64, 0, len(photos))
+	for _, photo := range photos {
+		photoIDs = append(photoIDs, int64(photo.ID))
+	}
+	if err := loadRedactionOriginalsTx(ctx, tx, photos); err != nil {
+		return nil, err
+	}
+
+	// Showcase items and redaction records cascade with the photo rows.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM report_photos WHERE report_id = $1 AND id = ANY($2)`, reportID, photoIDs); err != nil {
+		return nil, err
+	}
+	detail := map[string]any{
+		"photo_ids":      photoIDs,
+		"retention_days": retentionDays(a.cfg.RetentionPhotos),
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO retention_purges (category, action, report_id, detail, purged_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, retentionCategoryPhotos, retentionActionDeleted, reportID, anyMapToJSON(detail), now); err != nil {
+		return nil, err
+	}
+	if err := a.addEventTx(ctx, tx, reportID, "retention_purged", "system", map[string]any{
+		"category":       retentionCategoryPhotos,
+		"action":         retentionActionDeleted,
+		"photo_count":    len(photos),
+		"retention_days": retentionDays(a.cfg.RetentionPhotos),
+	}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return photos, nil
+}
+
+// loadRedactionOriginalsTx fills in the retained redaction originals of
+// photos that are about to be deleted; their rows go with the photo rows.
+func loadRedactionOriginalsTx(ctx context.Context, tx *sql.Tx, photos []purgedPhoto) error {
+	photoIDs := make([]int64, 0, len(photos))
+	for _, photo := range photos {
+		photoIDs = append(photoIDs, int64(photo.ID))
+	}
+	rows, err := tx.QueryContext(ctx, `
+		SELECT photo_id, previous_storage_path
+		FROM report_photo_redactions
+		WHERE photo_id = ANY($1) AND previous_storage_path IS NOT NULL
+	`, photoIDs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	originals := make(map[int][]string)
+	for rows.Next() {
+		var photoID int
+		var storagePath string
+		if err := rows.Scan(&photoID, &storagePath); err != nil {
+			return err
+		}
+		originals[photoID] = append(originals[photoID], storagePath)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range photos {
+		photos[i].RedactionOriginal = originals[photos[i].ID]
+	}
+	return nil
+}
+
+// deletePurgedPhotoBlobs removes the stored photo, its cached variants and any
+// retained redaction originals.
+func (a *App) deletePurgedPhotoBlobs(ctx context.Context, photos []purgedPhoto) {
+	store := a.blobStore()
+	for _, photo := range photos {
+		keys := append([]string{photo.StoragePath}, photo.RedactionOriginal...)
+		if resolved, err := a.resolveExistingPhotoStoragePath(ctx, photo.StoragePath); err == nil {
+			keys[0] = resolved
+		}
+		for _, key := range keys {
+			if err := store.Delete(ctx, key); err != nil {
+				a.log.Error("failed to delete purged photo", "photo_id", photo.ID, "key", key, "err", err)
+			}
+		}
+		a.removePhotoVariants(ctx, ReportPhoto{ID: photo.ID, ReportID: photo.ReportID, Version: photo.Version})
+	}
+}
+
+// anonymizeReportColumn clears the reporter e-mail address or fingerprint
+// hash of reports created before the category's cutoff. updated_at is left
+// alone so the purge does not count as an edit.
+func (a *App) anonymizeReportColumn(ctx context.Context, now time.Time, retention time.Duration, category string, dryRun bool) (int, error) {
+	var set, pending string
+	switch category {
+	case retentionCategoryReporterEmail:
+		set, pending = "reporter_email = NULL", "reporter_email IS NOT NULL"
+	case retentionCategoryFingerprint:
+		set, pending = "fingerprint_hash = ''", "fingerprint_hash <> ''"
+	default:
+		return 0, fmt.Errorf("unknown retention category %q", category)
+	}
+	where := pending + " AND NOT legal_hold AND created_at <= $1"
+	cutoff := now.Add(-retention)
+
+	var count int
+	if dryRun {
+		err := a.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM reports WHERE `+where, cutoff).Scan(&count)
+		return count, err
+	}
+	err := a.db.QueryRowContext(ctx, `
+		WITH purged AS (
+			UPDATE reports SET `+set+`
+			WHERE `+where+`
+			RETURNING id
+		), logged AS (
+			INSERT INTO retention_purges (category, action, report_id, detail, purged_at)
+			SELECT $2::text, $3::text, id, jsonb_build_object('retention_days', $4::int), $5::timestamptz
+			FROM purged
+		), events AS (
+			INSERT INTO report_events (report_id, type, actor, metadata)
+			SELECT id, 'retention_purged', 'system', jsonb_build_object('category', $2::text, 'action', $3::text, 'retention_days', $4::int)
+			FROM purged
+		)
+		SELECT COUNT(*) FROM purged
+	`, cutoff, category, retentionActionAnonymized, retentionDays(retention), now).Scan(&count)
+	return count, err
+}
+
+// purgeExpiredExports deletes exports older than the export policy. Exports
+// span many reports, so a legal hold does not keep them.
+func (a *App) purgeExpiredExports(ctx context.Context, now time.Time, dryRun bool) (int, error) {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT id, csv_path, geojson_path, pdf_path
+		FROM exports
+		WHERE created_at <= $1
+		ORDER BY id ASC
+	`, now.Add(-a.cfg.RetentionExports))
+	if err != nil {
+		return 0, err
+	}
+	type expired struct {
+		id    int
+		paths [3]sql.NullString
+	}
+	list := make([]expired, 0)
+	for rows.Next() {
+		var item expired
+		if err := rows.Scan(&item.id, &item.paths[0], &item.paths[1], &item.paths[2]); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		list = append(list, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if dryRun {
+		return len(list), nil
+	}
+
+	purged := 0
+	for _, item := range list {
+		files := make([]string, 0, len(item.paths))
+		for _, artifact := range item.paths {
+			if artifact.Valid && strings.TrimSpace(artifact.String) != "" {
+				files = append(files, artifact.String)
+			}
+		}
+		tx, err := a.db.BeginTx(ctx, nil)
+		if err != nil {
+			return purged, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM exports WHERE id = $1`, item.id); err != nil {
+			_ = tx.Rollback()
+			return purged, err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO retention_purges (category, action, export_id, detail, purged_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, retentionCategoryExports, retentionActionDeleted, item.id, anyMapToJSON(map[string]any{
+			"files":          files,
+			"retention_days": retentionDays(a.cfg.RetentionExports),
+		}), now); err != nil {
+			_ = tx.Rollback()
+			return purged, err
+		}
+		if err := tx.Commit(); err != nil {
+			return purged, err
+		}
+		for _, key := range files {
+			if err := a.blobStore().Delete(ctx, key); err != nil {
+				a.log.Error("failed to delete purged export", "export_id", item.id, "key", key, "err", err)
+			}
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (a *App) getLegalHold(ctx context.Context, reportID int) (*LegalHold, error) {
+	var held bool
+	var reason, setBy sql.NullString
+	var setAt sql.NullTime
+	err := a.db.QueryRowContext(ctx, `
+		SELECT legal_hold, legal_hold_reason, legal_hold_by, legal_hold_at
+		FROM reports
+		WHERE id = $1
+	`, reportID).Scan(&held, &reason, &setBy, &setAt)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !held) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	hold := &LegalHold{Reason: reason.String, SetBy: setBy.String}
+	if setAt.Valid {
+		hold.SetAt = setAt.Time.UTC().Format(time.RFC3339)
+	}
+	return hold, nil
+}
+
+// setLegalHold places a report under legal hold, or releases it when held is
+// false, and records a legal_hold_set or legal_hold_released event. A reason
+// is required to place a hold.
+func (a *App) setLegalHold(ctx context.Context, reportID int, held bool, reason string, session OperatorSession) error {
+	reason = strings.TrimSpace(reason)
+	if held && reason == "" {
+		return &apiError{Status: http.StatusBadRequest, Code: "legal_hold_reason_required", Message: "A reason is required for a legal hold"}
+	}
+	if len(reason) > legalHoldReasonMaxLength {
+		return &apiError{Status: http.StatusBadRequest, Code: "legal_hold_reason_too_long", Message: fmt.Sprintf("The reason may be at most %d characters", legalHoldReasonMaxLength)}
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var current bool
+	if err := tx.QueryRowContext(ctx, `SELECT legal_hold FROM reports WHERE id = $1 FOR UPDATE`, reportID).Scan(&current); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &apiError{Status: http.StatusNotFound, Code: "not_found", Message: "Report not found"}
+		}
+		return err
+	}
+	if current == held {
+		return nil
+	}
+
+	eventType := "legal_hold_released"
+	metadata := map[string]any{}
+	if held {
+		eventType = "legal_hold_set"
+		metadata["reason"] = reason
+		_, err = tx.ExecContext(ctx, `
+			UPDATE reports
+			SET legal_hold = TRUE, legal_hold_reason = $2, legal_hold_by = $3, legal_hold_at = NOW()
+			WHERE id = $1
+		`, reportID, reason, session.Email)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE reports
+			SET legal_hold = FALSE, legal_hold_reason = NULL, legal_hold_by = NULL, legal_hold_at = NULL
+			WHERE id = $1
+		`, reportID)
+	}
+	if err != nil {
+		return err
+	}
+	if err := a.addEventTx(ctx, tx, reportID, eventType, session.Email, metadata); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
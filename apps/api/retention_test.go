@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAdminLegalHoldSubmit(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	type change struct {
+		held   bool
+		reason string
+	}
+	var changes []change
+	app.adminSetReportLegalHold = func(ctx context.Context, reportID int, held bool, reason string, session OperatorSession) error {
+		if reportID != 7 || session.Email != "operator@example.com" {
+			t.Fatalf("unexpected legal hold target %d by %q", reportID, session.Email)
+		}
+		changes = append(changes, change{held, reason})
+		return nil
+	}
+
+	form := url.Values{"action": {"set"}, "reason": {"Police investigation 2026-114"}}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodPost, "/bikeadmin/reports/7/legal-hold", form.Encode()))
+	if location := rec.Header().Get("Location"); !strings.HasPrefix(location, "/bikeadmin/reports/7?") || !strings.Contains(location, "notice=") {
+		t.Fatalf("expected a notice redirect to the report, got %q", location)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodPost, "/bikeadmin/reports/7/legal-hold", url.Values{"action": {"release"}}.Encode()))
+	if len(changes) != 2 || changes[0] != (change{true, "Police investigation 2026-114"}) || changes[1].held {
+		t.Fatalf("unexpected legal hold changes %#v", changes)
+	}
+
+	app.adminSetReportLegalHold = func(ctx context.Context, reportID int, held bool, reason string, session OperatorSession) error {
+		return &apiError{Status: http.StatusBadRequest, Code: "legal_hold_reason_required", Message: "A reason is required for a legal hold"}
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodPost, "/bikeadmin/reports/7/legal-hold", url.Values{"action": {"set"}}.Encode()))
+	if location := rec.Header().Get("Location"); !strings.Contains(location, "error=") {
+		t.Fatalf("expected an error redirect, got %q", location)
+	}
+}
+
+func TestAdminLegalHoldSubmitRequiresAdmin(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	app.adminSetReportLegalHold = func(ctx context.Context, reportID int, held bool, reason string, session OperatorSession) error {
+		t.Fatalf("expected operators not to change legal holds")
+		return nil
+	}
+
+	municipality := "Amsterdam"
+	session := OperatorSession{Email: "crew@example.com", Role: "municipality_operator", Municipality: &municipality}
+	form := url.Values{"action": {"release"}}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequestWithSession(t, app, http.MethodPost, "/bikeadmin/reports/7/legal-hold", form.Encode(), session))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+}
+
+func TestAdminReportDetailShowsLegalHold(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	app.adminGetReportDetails = func(ctx context.Context, reportID int) (*OperatorReportDetails, error) {
+		return &OperatorReportDetails{
+			Report:        Report{ID: reportID, PublicID: "PUB-7", Status: "resolved"},
+			Photos:        []OperatorReportPhotoView{},
+			SignalDetails: SignalDetails{BikeGroup: BikeGroup{ID: 10}},
+			LegalHold:     &LegalHold{Reason: "Police investigation 2026-114", SetBy: "operator@example.com", SetAt: "2026-10-18T09:30:00Z"},
+			Events: []ReportEvent{
+				{Type: "retention_purged", Actor: "system", CreatedAt: "2026-10-18T09:30:00Z", Metadata: map[string]any{"category": retentionCategoryReporterEmail}},
+			},
+		}, nil
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, authenticatedRequest(t, app, http.MethodGet, "/bikeadmin/reports/7", ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Police investigation 2026-114") || !strings.Contains(body, `name="action" value="release"`) {
+		t.Fatalf("expected the legal hold and a release button")
+	}
+	if !strings.Contains(body, adminText(adminDefaultLanguage, "event_retention_purged")+": "+adminText(adminDefaultLanguage, "retention_category_reporter_email")) {
+		t.Fatalf("expected the purge event to name its category")
+	}
+}
+
+func TestDeletePurgedPhotoBlobsRemovesVariantsAndOriginals(t *testing.T) {
+	ctx := context.Background()
+	dataRoot := t.TempDir()
+	app := &App{cfg: &Config{DataRoot: dataRoot}, log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	store := app.blobStore()
+	keys := []string{
+		"uploads/reports/7/a.jpg",
+		"uploads/reports/7/legacy.webp",
+		"redaction-originals/reports/7/3-v1.jpg",
+		photoVariantStoragePath(7, 3, 1, photoVariantThumb),
+		photoVariantStoragePath(7, 3, 1, photoVariantMedium),
+		"uploads/reports/7/kept.jpg",
+	}
+	for _, key := range keys {
+		if err := store.Put(ctx, key, []byte(key), ""); err != nil {
+			t.Fatalf("Put returned error: %v", err)
+		}
+	}
+
+	app.deletePurgedPhotoBlobs(ctx, []purgedPhoto{
+		{ID: 3, ReportID: 7, StoragePath: "uploads/reports/7/a.jpg", RedactionOriginal: []string{"redaction-originals/reports/7/3-v1.jpg"}},
+		{ID: 4, ReportID: 7, StoragePath: "uploads/reports/7/legacy"},
+	})
+
+	for _, key := range keys[:5] {
+		if _, err := store.Stat(ctx, key); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected %s to be deleted, got %v", key, err)
+		}
+	}
+	if _, err := store.Stat(ctx, "uploads/reports/7/kept.jpg"); err != nil {
+		t.Fatalf("expected other photos to stay: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+const rtreeNodeCapacity = 16
+
+type bbox struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+func emptyBBox() bbox {
+	return bbox{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+}
+
+func (b bbox) extend(other bbox) bbox {
+	return bbox{
+		MinX: math.Min(b.MinX, other.MinX),
+		MinY: math.Min(b.MinY, other.MinY),
+		MaxX: math.Max(b.MaxX, other.MaxX),
+		MaxY: math.Max(b.MaxY, other.MaxY),
+	}
+}
+
+func (b bbox) containsPoint(x, y float64) bool {
+	return x >= b.MinX && x <= b.MaxX && y >= b.MinY && y <= b.MaxY
+}
+
+func (b bbox) centerX() float64 { return (b.MinX + b.MaxX) / 2 }
+func (b bbox) centerY() float64 { return (b.MinY + b.MaxY) / 2 }
+
+type rtreeEntry struct {
+	Box   bbox
+	Value int
+}
+
+type rtreeNode struct {
+	box      bbox
+	children []*rtreeNode
+	entries  []rtreeEntry
+}
+
+// rtree is a static R-tree, bulk-loaded with Sort-Tile-Recursive packing.
+// It is built once and only answers point queries.
+type rtree struct {
+	root *rtreeNode
+}
+
+func newRTree(entries []rtreeEntry) *rtree {
+	if len(entries) == 0 {
+		return &rtree{}
+	}
+
+	leaves := make([]*rtreeNode, 0, len(entries)/rtreeNodeCapacity+1)
+	for _, group := range strPartition(entries, func(e rtreeEntry) bbox { return e.Box }) {
+		node := &rtreeNode{box: emptyBBox(), entries: group}
+		for _, entry := range group {
+			node.box = node.box.extend(entry.Box)
+		}
+		leaves = append(leaves, node)
+	}
+
+	level := leaves
+	for len(level) > 1 {
+		parents := make([]*rtreeNode, 0, len(level)/rtreeNodeCapacity+1)
+		for _, group := range strPartition(level, func(n *rtreeNode) bbox { return n.box }) {
+			node := &rtreeNode{box: emptyBBox(), children: group}
+			for _, child := range group {
+				node.box = node.box.extend(child.box)
+			}
+			parents = append(parents, node)
+		}
+		level = parents
+	}
+	return &rtree{root: level[0]}
+}
+
+// strPartition sorts items into vertical slices by x and then packs each
+// slice by y into groups of at most rtreeNodeCapacity.
+func strPartition[T any](items []T, boxOf func(T) bbox) [][]T {
+	sorted := append([]T{}, items...)
+	sort.Slice(sorted, func(i, j int) bool { return boxOf(sorted[i]).centerX() < boxOf(sorted[j]).centerX() })
+
+	nodeCount := int(math.Ceil(float64(len(sorted)) / rtreeNodeCapacity))
+	sliceCount := int(math.Ceil(math.Sqrt(float64(nodeCount))))
+	sliceSize := sliceCount * rtreeNodeCapacity
+
+	groups := make([][]T, 0, nodeCount)
+	for start := 0; start < len(sorted); start += sliceSize {
+		end := min(start+sliceSize, len(sorted))
+		slice := sorted[start:end]
+		sort.Slice(slice, func(i, j int) bool { return boxOf(slice[i]).centerY() < boxOf(slice[j]).centerY() })
+		for groupStart := 0; groupStart < len(slice); groupStart += rtreeNodeCapacity {
+			groupEnd := min(groupStart+rtreeNodeCapacity, len(slice))
+			groups = append(groups, slice[groupStart:groupEnd])
+		}
+	}
+	return groups
+}
+
+// searchPoint returns the values of all entries whose box contains the point.
+func (t *rtree) searchPoint(x, y float64) []int {
+	if t.root == nil {
+		return nil
+	}
+	result := make([]int, 0, 2)
+	stack := []*rtreeNode{t.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !node.box.containsPoint(x, y) {
+			continue
+		}
+		for _, entry := range node.entries {
+			if entry.Box.containsPoint(x, y) {
+				result = append(result, entry.Value)
+			}
+		}
+		stack = append(stack, node.children...)
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+)
+
+const defaultServiceArea = "service_area/netherlands.geojson"
+
+// The default service area is a coarse outline of the European Netherlands,
+// including the Wadden islands and a strip of coastal water. It follows the
+// land borders to within a kilometre or two.
+//
+//go:embed service_area
+var serviceAreaFS embed.FS
+
+// ServiceArea is the set of polygons reports may be filed in.
+type ServiceArea struct {
+	polygons []geoPolygon
+}
+
+// loadServiceArea returns the bundled Netherlands outline, extended with the
+// polygons of the GeoJSON file at extraPath when it is set.
+func loadServiceArea(extraPath string) (*ServiceArea, error) {
+	data, err := serviceAreaFS.ReadFile(defaultServiceArea)
+	if err != nil {
+		return nil, err
+	}
+	polygons, err := parseServiceAreaPolygons(data)
+	if err != nil {
+		return nil, fmt.Errorf("default service area: %w", err)
+	}
+
+	if extraPath != "" {
+		extra, err := os.ReadFile(extraPath)
+		if err != nil {
+			return nil, err
+		}
+		extraPolygons, err := parseServiceAreaPolygons(extra)
+		if err != nil {
+			return nil, fmt.Errorf("service area %s: %w", extraPath, err)
+		}
+		polygons = append(polygons, extraPolygons...)
+	}
+	return &ServiceArea{polygons: polygons}, nil
+}
+
+// parseServiceAreaPolygons reads the Polygon and MultiPolygon features of a
+// GeoJSON FeatureCollection in WGS84.
+func parseServiceAreaPolygons(data []byte) ([]geoPolygon, error) {
+	var collection struct {
+		Features []struct {
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("decode service area: %w", err)
+	}
+
+	polygons := make([]geoPolygon, 0, len(collection.Features))
+	for i, feature := range collection.Features {
+		decoded, err := decodeGeoJSONPolygons(feature.Geometry.Type, feature.Geometry.Coordinates)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i, err)
+		}
+		for _, polygon := range decoded {
+			if _, err := ringBBox(polygon.Outer); err != nil {
+				return nil, fmt.Errorf("feature %d: %w", i, err)
+			}
+			polygons = append(polygons, polygon)
+		}
+	}
+	if len(polygons) == 0 {
+		return nil, errors.New("service area contains no polygons")
+	}
+	return polygons, nil
+}
+
+// Contains reports whether the point lies inside one of the polygons.
+func (s *ServiceArea) Contains(lat, lng float64) bool {
+	for _, polygon := range s.polygons {
+		if polygonContains(polygon, lng, lat) {
+			return true
+		}
+	}
+	return false
+}
+
+// DistanceMeters returns the approximate distance from the point to the
+// nearest service area boundary, on a local equirectangular projection.
+func (s *ServiceArea) DistanceMeters(lat, lng float64) float64 {
+	scaleX := metersPerDegreeLat * math.Cos(lat*math.Pi/180)
+	project := func(point [2]float64) [2]float64 {
+		return [2]float64{(point[0] - lng) * scaleX, (point[1] - lat) * metersPerDegreeLat}
+	}
+
+	distance := math.Inf(1)
+	for _, polygon := range s.polygons {
+		for _, ring := range append([]geoRing{polygon.Outer}, polygon.Holes...) {
+			for i := 1; i < len(ring); i++ {
+				distance = math.Min(distance, segmentDistance(0, 0, project(ring[i-1]), project(ring[i])))
+			}
+		}
+	}
+	return distance
+}
+
+// checkServiceArea rejects locations outside the service area. With a border
+// margin configured, locations outside but within the margin are accepted and
+// reported as near the border so the report is flagged for review.
+func (a *App) checkServiceArea(loc ReportLocation) (bool, error) {
+	if a.serviceArea == nil || a.serviceArea.Contains(loc.Lat, loc.Lng) {
+		return false, nil
+	}
+	if margin := a.cfg.ServiceAreaBorderMarginM; margin > 0 && a.serviceArea.DistanceMeters(loc.Lat, loc.Lng) <= margin {
+		return true, nil
+	}
+	return false, &apiError{Status: http.StatusBadRequest, Code: "out_of_service_area", Message: "Location is outside the service area"}
+}
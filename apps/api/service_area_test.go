@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultServiceAreaCoversTheNetherlands(t *testing.T) {
+	area, err := loadServiceArea("")
+	if err != nil {
+		t.Fatalf("load service area: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		lat, lng float64
+		inside   bool
+	}{
+		{"Amsterdam", 52.3728, 4.8936, true},
+		{"Maastricht", 50.8514, 5.6910, true},
+		{"Vaals", 50.7700, 6.0180, true},
+		{"Groningen", 53.2194, 6.5665, true},
+		{"Den Burg, Texel", 53.0547, 4.7977, true},
+		{"Cadzand", 51.3700, 3.3900, true},
+		{"'s-Heerenberg", 51.8760, 6.2500, true},
+		{"Enschede", 52.2215, 6.8937, true},
+		{"Brussels", 50.8503, 4.3517, false},
+		{"Antwerp", 51.2194, 4.4025, false},
+		{"Aachen", 50.7753, 6.0839, false},
+		{"Emmerich", 51.8300, 6.2500, false},
+		{"North Sea", 52.5000, 3.0000, false},
+	}
+	for _, tc := range cases {
+		if got := area.Contains(tc.lat, tc.lng); got != tc.inside {
+			t.Fatalf("%s: expected inside=%v, got %v", tc.name, tc.inside, got)
+		}
+	}
+}
+
+func TestLoadServiceAreaExtendsDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bonaire.geojson")
+	bonaire := `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":[[[-68.45,12.00],[-68.18,12.00],[-68.18,12.32],[-68.45,12.32],[-68.45,12.00]]]}}]}`
+	if err := os.WriteFile(path, []byte(bonaire), 0o600); err != nil {
+		t.Fatalf("write service area: %v", err)
+	}
+	area, err := loadServiceArea(path)
+	if err != nil {
+		t.Fatalf("load service area: %v", err)
+	}
+	if !area.Contains(12.15, -68.27) || !area.Contains(52.37, 4.89) {
+		t.Fatalf("expected both Bonaire and the Netherlands in the service area")
+	}
+
+	if _, err := parseServiceAreaPolygons([]byte(`{"features":[]}`)); err == nil {
+		t.Fatalf("expected an empty service area to be rejected")
+	}
+}
+
+func TestCheckServiceAreaBorderMargin(t *testing.T) {
+	area, err := loadServiceArea("")
+	if err != nil {
+		t.Fatalf("load service area: %v", err)
+	}
+	app := &App{cfg: &Config{}, serviceArea: area}
+
+	// Just across the border near Vaals, roughly 700 m outside the outline.
+	aachenOutskirts := ReportLocation{Lat: 50.7700, Lng: 6.0350}
+	_, err = app.checkServiceArea(aachenOutskirts)
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.Code != "out_of_service_area" {
+		t.Fatalf("expected out_of_service_area without a margin, got %v", err)
+	}
+
+	app.cfg.ServiceAreaBorderMarginM = 2000
+	nearBorder, err := app.checkServiceArea(aachenOutskirts)
+	if err != nil || !nearBorder {
+		t.Fatalf("expected the report to be accepted and flagged within the margin, got %v, %v", nearBorder, err)
+	}
+
+	nearBorder, err = app.checkServiceArea(ReportLocation{Lat: 52.3728, Lng: 4.8936})
+	if err != nil || nearBorder {
+		t.Fatalf("expected a report inside the area not to be flagged, got %v, %v", nearBorder, err)
+	}
+	if _, err := app.checkServiceArea(ReportLocation{Lat: 50.8503, Lng: 4.3517}); err == nil {
+		t.Fatalf("expected Brussels to be rejected despite the margin")
+	}
+}
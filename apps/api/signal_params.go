@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	signalParamsScopeGlobal  = "global"
+	maxSignalParamsRadiusM   = 500.0
+	maxSignalParamsDays      = 3650
+	maxSignalParamsReporters = 100
+	signalParamsWeightSumEps = 0.001
+)
+
+// SignalParams holds the tunable parameters used for bike-group matching,
+// reconfirmation counting and dedupe scoring. A nil Municipality marks the
+// global defaults; otherwise the row overrides the defaults for that municipality.
+type SignalParams struct {
+	ID                          int     `json:"id"`
+	Municipality                *string `json:"municipality"`
+	SignalMatchRadiusMeters     float64 `json:"signalMatchRadiusM"`
+	SignalLookbackDays          int     `json:"signalLookbackDays"`
+	SignalReconfirmationGapDays int     `json:"signalReconfirmationGapDays"`
+	StrongSignalMinReporters    int     `json:"strongSignalMinReporters"`
+	DedupeRadiusMeters          float64 `json:"dedupeRadiusM"`
+	DedupeLookbackDays          int     `json:"dedupeLookbackDays"`
+	DistanceWeight              float64 `json:"distanceWeight"`
+	TagOverlapWeight            float64 `json:"tagOverlapWeight"`
+	RecencyWeight               float64 `json:"recencyWeight"`
+	PhotoSimilarityWeight       float64 `json:"photoSimilarityWeight"`
+	UpdatedBy                   *string `json:"updatedBy"`
+	UpdatedAt                   string  `json:"updatedAt"`
+	// TagWeights comes from the tags table, not from signal_settings.
+	TagWeights map[string]float64 `json:"-"`
+}
+
+// defaultSignalParams returns the built-in parameters, used when the
+// signal_settings table has no usable row.
+func defaultSignalParams() SignalParams {
+	return SignalParams{
+		SignalMatchRadiusMeters:     signalMatchRadiusMeters,
+		SignalLookbackDays:          signalLookbackDays,
+		SignalReconfirmationGapDays: signalReconfirmationGapDays,
+		StrongSignalMinReporters:    strongSignalMinReporters,
+		DedupeRadiusMeters:          dedupeRadiusMeters,
+		DedupeLookbackDays:          dedupeLookbackDays,
+		DistanceWeight:              distanceWeight,
+		TagOverlapWeight:            tagOverlapWeight,
+		RecencyWeight:               recencyWeight,
+		PhotoSimilarityWeight:       photoSimilarityWeight,
+		TagWeights:                  defaultTagWeights(),
+	}
+}
+
+func (p SignalParams) Scope() string {
+	if p.Municipality == nil || *p.Municipality == "" {
+		return signalParamsScopeGlobal
+	}
+	return *p.Municipality
+}
+
+// eventMetadata describes the parameter set for report events, so that
+// scoring decisions stay explainable after the settings change.
+func (p SignalParams) eventMetadata() map[string]any {
+	return map[string]any{
+		"id":                             p.ID,
+		"scope":                          p.Scope(),
+		"updated_at":                     p.UpdatedAt,
+		"signal_match_radius_m":          p.SignalMatchRadiusMeters,
+		"signal_lookback_days":           p.SignalLookbackDays,
+		"signal_reconfirmation_gap_days": p.SignalReconfirmationGapDays,
+		"strong_signal_min_reporters":    p.StrongSignalMinReporters,
+		"dedupe_radius_m":                p.DedupeRadiusMeters,
+		"dedupe_lookback_days":           p.DedupeLookbackDays,
+		"distance_weight":                p.DistanceWeight,
+		"tag_overlap_weight":             p.TagOverlapWeight,
+		"recency_weight":                 p.RecencyWeight,
+		"photo_similarity_weight":        p.PhotoSimilarityWeight,
+	}
+}
+
+func validateSignalParams(p SignalParams) error {
+	if p.Municipality != nil && !isValidMunicipality(*p.Municipality, time.Now().UTC()) {
+		return fmt.Errorf("unknown municipality: %s", *p.Municipality)
+	}
+	if p.SignalMatchRadiusMeters <= 0 || p.SignalMatchRadiusMeters > maxSignalParamsRadiusM {
+		return fmt.Errorf("signal match radius must be between 0 and %.0f m", maxSignalParamsRadiusM)
+	}
+	if p.DedupeRadiusMeters <= 0 || p.DedupeRadiusMeters > maxSignalParamsRadiusM {
+		return fmt.Errorf("dedupe radius must be between 0 and %.0f m", maxSignalParamsRadiusM)
+	}
+	if p.SignalLookbackDays < 1 || p.SignalLookbackDays > maxSignalParamsDays {
+		return fmt.Errorf("signal lookback must be between 1 and %d days", maxSignalParamsDays)
+	}
+	if p.DedupeLookbackDays < 1 || p.DedupeLookbackDays > maxSignalParamsDays {
+		return fmt.Errorf("dedupe lookback must be between 1 and %d days", maxSignalParamsDays)
+	}
+	if p.SignalReconfirmationGapDays < 0 || p.SignalReconfirmationGapDays > p.SignalLookbackDays {
+		return fmt.Errorf("reconfirmation gap must be between 0 and the signal lookback")
+	}
+	if p.StrongSignalMinReporters < 1 || p.StrongSignalMinReporters > maxSignalParamsReporters {
+		return fmt.Errorf("strong signal reporters must be between 1 and %d", maxSignalParamsReporters)
+	}
+	for _, weight := range []float64{p.DistanceWeight, p.TagOverlapWeight, p.RecencyWeight, p.PhotoSimilarityWeight} {
+		if weight < 0 || weight > 1 || math.IsNaN(weight) {
+			return fmt.Errorf("weights must be between 0 and 1")
+		}
+	}
+	if sum := p.DistanceWeight + p.TagOverlapWeight + p.RecencyWeight + p.PhotoSimilarityWeight; math.Abs(sum-1) > signalParamsWeightSumEps {
+		return fmt.Errorf("weights must add up to 1 (got %.3f)", sum)
+	}
+	return nil
+}
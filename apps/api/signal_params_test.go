@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateSignalParams(t *testing.T) {
+	if err := validateSignalParams(defaultSignalParams()); err != nil {
+		t.Fatalf("expected defaults to be valid, got %v", err)
+	}
+
+	unknown := "Nergenshuizen"
+	cases := map[string]func(p *SignalParams){
+		"zero radius":          func(p *SignalParams) { p.SignalMatchRadiusMeters = 0 },
+		"radius too large":     func(p *SignalParams) { p.DedupeRadiusMeters = 1000 },
+		"zero lookback":        func(p *SignalParams) { p.DedupeLookbackDays = 0 },
+		"gap beyond lookback":  func(p *SignalParams) { p.SignalReconfirmationGapDays = p.SignalLookbackDays + 1 },
+		"no strong reporters":  func(p *SignalParams) { p.StrongSignalMinReporters = 0 },
+		"negative weight":      func(p *SignalParams) { p.DistanceWeight = -0.1; p.TagOverlapWeight = 0.95 },
+		"weights not summing":  func(p *SignalParams) { p.RecencyWeight = 0.5 },
+		"unknown municipality": func(p *SignalParams) { p.Municipality = &unknown },
+	}
+	for name, mutate := range cases {
+		params := defaultSignalParams()
+		mutate(&params)
+		if err := validateSignalParams(params); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestScoringUsesSignalParams(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	incoming := Report{Location: ReportLocation{Lat: 52.0, Lng: 5.0}, Tags: []string{"rusted"}}
+	// ~22 m north of the incoming report.
+	candidate := Report{ID: 7, Location: ReportLocation{Lat: 52.0002, Lng: 5.0}, Tags: []string{"rusted"}, CreatedAt: now.AddDate(0, 0, -2).Format(time.RFC3339)}
+
+	params := defaultSignalParams()
+	if scoreSignalGroupCandidate(incoming, candidate, now, params) != nil {
+		t.Fatalf("expected candidate outside default match radius")
+	}
+	if scoreDuplicateCandidate(incoming, candidate, nil, now, params) != nil {
+		t.Fatalf("expected candidate outside default dedupe radius")
+	}
+
+	params.SignalMatchRadiusMeters = 50
+	params.DedupeRadiusMeters = 50
+	if scoreSignalGroupCandidate(incoming, candidate, now, params) == nil {
+		t.Fatalf("expected candidate inside widened match radius")
+	}
+	scored := scoreDuplicateCandidate(incoming, candidate, nil, now, params)
+	if scored == nil || scored.ReportID != 7 {
+		t.Fatalf("expected dedupe candidate inside widened radius, got %#v", scored)
+	}
+}
+
+func TestComputeReconfirmationUsesSignalParams(t *testing.T) {
+	reports := []Report{
+		{ID: 1, ReporterHash: "a", CreatedAt: "2026-01-01T10:00:00Z"},
+		{ID: 2, ReporterHash: "b", CreatedAt: "2026-01-11T10:00:00Z"},
+	}
+
+	defaults := computeReconfirmation(reports, defaultSignalParams())
+	if defaults.ClassificationByReportID[2] != "non_qualifying" || defaults.SignalStrength != "none" {
+		t.Fatalf("expected 10 day gap to be non-qualifying by default, got %#v", defaults)
+	}
+
+	params := defaultSignalParams()
+	params.SignalReconfirmationGapDays = 7
+	tuned := computeReconfirmation(reports, params)
+	if tuned.ClassificationByReportID[2] != "counted_distinct_reporter" {
+		t.Fatalf("expected distinct reconfirmation with 7 day gap, got %s", tuned.ClassificationByReportID[2])
+	}
+	if tuned.SignalStrength != "strong_distinct_reporters" {
+		t.Fatalf("expected strong signal, got %s", tuned.SignalStrength)
+	}
+
+	params.StrongSignalMinReporters = 3
+	if got := computeReconfirmation(reports, params).SignalStrength; got == "strong_distinct_reporters" {
+		t.Fatalf("expected no strong signal with 3 required reporters, got %s", got)
+	}
+}
+
+func signalSettingsForm(municipality string) url.Values {
+	form := url.Values{}
+	form.Set("municipality", municipality)
+	form.Set("signal_match_radius_m", "12.5")
+	form.Set("signal_lookback_days", "120")
+	form.Set("signal_reconfirmation_gap_days", "14")
+	form.Set("strong_signal_min_reporters", "2")
+	form.Set("dedupe_radius_m", "20")
+	form.Set("dedupe_lookback_days", "30")
+	form.Set("distance_weight", "0.4")
+	form.Set("tag_overlap_weight", "0.3")
+	form.Set("recency_weight", "0.2")
+	form.Set("photo_similarity_weight", "0.1")
+	return form
+}
+
+func TestAdminSignalSettingsSubmit(t *testing.T) {
+	app, router := newAdminTestServer(t)
+
+	var saved *SignalParams
+	var savedBy string
+	app.adminSaveSignalParams = func(ctx context.Context, params SignalParams, updatedBy string) error {
+		saved = &params
+		savedBy = updatedBy
+		return nil
+	}
+
+	rec := httptest.NewRecorder()
+	req := authenticatedRequest(t, app, http.MethodPost, "/bikeadmin/settings/signal", signalSettingsForm("Utrecht").Encode())
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect %d, got %d", http.StatusSeeOther, rec.Code)
+	}
+	if !strings.Contains(rec.Header().Get("Location"), "notice=") {
+		t.Fatalf("expected notice redirect, got %s", rec.Header().Get("Location"))
+	}
+	if saved == nil || saved.Scope() != "Utrecht" || saved.SignalMatchRadiusMeters != 12.5 || saved.SignalReconfirmationGapDays != 14 {
+		t.Fatalf("unexpected saved params: %#v", saved)
+	}
+	if savedBy != "operator@example.com" {
+		t.Fatalf("expected updated_by from session, got %s", savedBy)
+	}
+
+	saved = nil
+	invalid := signalSettingsForm("")
+	invalid.Set("recency_weight", "0.9")
+	rec = httptest.NewRecorder()
+	req = authenticatedRequest(t, app, http.MethodPost, "/bikeadmin/settings/signal", invalid.Encode())
+	router.ServeHTTP(rec, req)
+
+	if saved != nil {
+		t.Fatalf("expected invalid params not to be saved")
+	}
+	if !strings.Contains(rec.Header().Get("Location"), "error=") {
+		t.Fatalf("expected error redirect, got %s", rec.Header().Get("Location"))
+	}
+}
+
+func TestAdminSignalSettingsRequiresAdmin(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	app.adminListSignalParams = func(ctx context.Context) ([]SignalParams, error) {
+		t.Fatalf("settings must not load for non-admin operators")
+		return nil, nil
+	}
+
+	municipality := "Utrecht"
+	rec := httptest.NewRecorder()
+	req := authenticatedRequestWithSession(t, app, http.MethodGet, "/bikeadmin/settings/signal", "", OperatorSession{Email: "muni@example.com", Role: "municipality_operator", Municipality: &municipality})
+	router.ServeHTTP(rec, req)
+
+	if rec.Code == http.StatusOK {
+		t.Fatalf("expected non-admin request to be rejected")
+	}
+}
+
+func TestAdminSignalSettingsPageRenders(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	municipality := "Utrecht"
+	app.adminListSignalParams = func(ctx context.Context) ([]SignalParams, error) {
+		global := defaultSignalParams()
+		global.ID = 1
+		override := defaultSignalParams()
+		override.ID = 2
+		override.Municipality = &municipality
+		override.SignalMatchRadiusMeters = 25
+		return []SignalParams{global, override}, nil
+	}
+
+	rec := httptest.NewRecorder()
+	req := authenticatedRequest(t, app, http.MethodGet, "/bikeadmin/settings/signal", "")
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `/bikeadmin/settings/signal/2/delete`) || !strings.Contains(body, `value="25"`) {
+		t.Fatalf("expected override form in page")
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	signalScoreMax = 100.0
+	// The thresholds are half the lowest base score that earns each label
+	// (one distinct reconfirmation plus a second reporter, or one
+	// same-reporter reconfirmation), so a label lasts at least one half-life
+	// without new reports.
+	signalScoreStrongThreshold = 12.5
+	signalScoreWeakThreshold   = 4.0
+	signalScoreHalfLifeDays    = 90.0
+
+	signalScoreDistinctPoints  = 20.0
+	signalScoreDistinctCap     = 3
+	signalScoreSamePoints      = 8.0
+	signalScoreSameCap         = 2
+	signalScoreReporterPoints  = 5.0
+	signalScoreReporterCap     = 4
+	signalScorePersistenceMax  = 10.0
+	signalScorePersistenceDays = 90.0
+	signalScoreSeverityBoost   = 0.5
+)
+
+// defaultTagWeights returns the built-in tag weights, used when the tags
+// table cannot be read. Weights rate how urgent a tag makes a report, from
+// 0 to 1; unknown tags count as 0.
+func defaultTagWeights() map[string]float64 {
+	weights := make(map[string]float64, len(defaultTagDictionary))
+	for _, seed := range defaultTagDictionary {
+		weights[seed.Code] = seed.Weight
+	}
+	return weights
+}
+
+// SignalScore is the numeric signal of a bike group and how it was built.
+// BaseScore is the score at the last report; Score applies the recency decay.
+type SignalScore struct {
+	Score                        float64 `json:"score"`
+	BaseScore                    float64 `json:"baseScore"`
+	DistinctReconfirmationPoints float64 `json:"distinctReconfirmationPoints"`
+	SameReconfirmationPoints     float64 `json:"sameReconfirmationPoints"`
+	ReporterPoints               float64 `json:"reporterPoints"`
+	PersistencePoints            float64 `json:"persistencePoints"`
+	TagSeverity                  float64 `json:"tagSeverity"`
+	SeverityMultiplier           float64 `json:"severityMultiplier"`
+	AgeDays                      float64 `json:"ageDays"`
+	DecayFactor                  float64 `json:"decayFactor"`
+}
+
+// computeSignalScore scores a bike group from its reports and signal summary.
+// Qualifying reconfirmations carry most of the weight, distinct reporters and
+// the time span between the first report and the last qualifying
+// reconfirmation add to it, and the highest tag weight scales the sum. The
+// result halves every signalScoreHalfLifeDays without new reports.
+func computeSignalScore(reports []Report, summary ReportSignalSummary, tagWeights map[string]float64, now time.Time) SignalScore {
+	score := SignalScore{
+		DistinctReconfirmationPoints: signalScoreDistinctPoints * float64(min(summary.DistinctReporterReconfirmations, signalScoreDistinctCap)),
+		SameReconfirmationPoints:     signalScoreSamePoints * float64(min(summary.SameReporterReconfirmations, signalScoreSameCap)),
+	}
+	if summary.HasQualifyingReconfirmation && summary.UniqueReporters > 1 {
+		score.ReporterPoints = signalScoreReporterPoints * float64(min(summary.UniqueReporters-1, signalScoreReporterCap))
+	}
+
+	var firstReport time.Time
+	for _, report := range reports {
+		created, err := time.Parse(time.RFC3339, report.CreatedAt)
+		if err == nil && (firstReport.IsZero() || created.Before(firstReport)) {
+			firstReport = created
+		}
+		for _, tag := range report.Tags {
+			score.TagSeverity = math.Max(score.TagSeverity, tagWeights[tag])
+		}
+	}
+	if summary.LastQualifyingReconfirmationAt != nil && !firstReport.IsZero() {
+		if lastQualifying, err := time.Parse(time.RFC3339, *summary.LastQualifyingReconfirmationAt); err == nil {
+			spanDays := lastQualifying.Sub(firstReport).Hours() / 24
+			score.PersistencePoints = roundScore(signalScorePersistenceMax * clamp01(spanDays/signalScorePersistenceDays))
+		}
+	}
+
+	score.SeverityMultiplier = 1 + signalScoreSeverityBoost*score.TagSeverity
+	sum := score.DistinctReconfirmationPoints + score.SameReconfirmationPoints + score.ReporterPoints + score.PersistencePoints
+	score.BaseScore = roundScore(math.Min(sum*score.SeverityMultiplier, signalScoreMax))
+
+	if lastReport, err := time.Parse(time.RFC3339, summary.LastReportAt); err == nil {
+		score.AgeDays = math.Max(now.Sub(lastReport).Hours()/24, 0)
+	}
+	score.DecayFactor = signalScoreDecay(score.AgeDays)
+	score.Score = roundScore(score.BaseScore * score.DecayFactor)
+	score.AgeDays = math.Round(score.AgeDays*10) / 10
+	score.DecayFactor = math.Round(score.DecayFactor*1000) / 1000
+	return score
+}
+
+func signalScoreDecay(ageDays float64) float64 {
+	return math.Pow(0.5, math.Max(ageDays, 0)/signalScoreHalfLifeDays)
+}
+
+// decayedGroupSignalScore applies the recency decay to the stored group score.
+func decayedGroupSignalScore(group BikeGroup, now time.Time) float64 {
+	lastReport, err := time.Parse(time.RFC3339, group.LastReportAt)
+	if err != nil {
+		return group.SignalScore
+	}
+	return roundScore(group.SignalScore * signalScoreDecay(now.Sub(lastReport).Hours()/24))
+}
+
+// computeSignalStrength derives the signal label from a freshly computed
+// score. A strong signal also needs the configured number of reporters and a
+// qualifying reconfirmation by someone other than an earlier reporter, so one
+// reporter coming back often cannot reach it alone.
+func computeSignalStrength(score float64, summary ReportSignalSummary, params SignalParams) string {
+	if score >= signalScoreStrongThreshold && summary.UniqueReporters >= params.StrongSignalMinReporters && summary.DistinctReporterReconfirmations > 0 {
+		return "strong_distinct_reporters"
+	}
+	if score >= signalScoreWeakThreshold {
+		return "weak_same_reporter"
+	}
+	return "none"
+}
+
+// decayedSignalStrength lowers the stored label when the decayed score has
+// dropped below its threshold. Decay never raises a label.
+func decayedSignalStrength(stored string, score float64) string {
+	derived := "none"
+	if score >= signalScoreStrongThreshold {
+		derived = "strong_distinct_reporters"
+	} else if score >= signalScoreWeakThreshold {
+		derived = "weak_same_reporter"
+	}
+	if signalStrengthPriority[derived] < signalStrengthPriority[stored] {
+		return derived
+	}
+	return stored
+}
+
+// signalScoreSQL is the decayed bike group score as a SQL expression, the
+// counterpart of decayedGroupSignalScore.
+var signalScoreSQL = fmt.Sprintf(
+	"(bg.signal_score * POWER(0.5, GREATEST(EXTRACT(EPOCH FROM (NOW() - bg.last_report_at)) / 86400.0, 0) / %g))",
+	signalScoreHalfLifeDays,
+)
+
+// signalStrengthSQL is the decayed signal label as a SQL expression, the
+// counterpart of decayedSignalStrength. The score is rounded like roundScore,
+// so the filter and the displayed label agree at the threshold.
+var signalStrengthSQL = fmt.Sprintf(`(CASE
+		WHEN bg.signal_strength = 'strong_distinct_reporters' AND ROUND(%[1]s::numeric, 2) >= %[2]g THEN 'strong_distinct_reporters'
+		WHEN bg.signal_strength <> 'none' AND ROUND(%[1]s::numeric, 2) >= %[3]g THEN 'weak_same_reporter'
+		ELSE 'none'
+	END)`, signalScoreSQL, signalScoreStrongThreshold, signalScoreWeakThreshold)
+
+func roundScore(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeSignalScoreBreakdown(t *testing.T) {
+	reports := []Report{
+		{ID: 1, ReporterHash: "a", CreatedAt: "2026-01-01T10:00:00Z", Tags: []string{"rusted", "blocking_sidewalk"}},
+		{ID: 2, ReporterHash: "b", CreatedAt: "2026-01-31T10:00:00Z", Tags: []string{"rusted"}},
+		{ID: 3, ReporterHash: "a", CreatedAt: "2026-04-01T10:00:00Z"},
+	}
+	recomputation := computeReconfirmation(reports, defaultSignalParams())
+	lastReport := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	score := computeSignalScore(reports, recomputation.Summary, defaultTagWeights(), lastReport.AddDate(0, 0, 90))
+	if score.DistinctReconfirmationPoints != 20 || score.SameReconfirmationPoints != 8 || score.ReporterPoints != 5 || score.PersistencePoints != 10 {
+		t.Fatalf("unexpected components: %#v", score)
+	}
+	if score.SeverityMultiplier != 1.5 || score.BaseScore != 64.5 {
+		t.Fatalf("expected blocking sidewalk to scale 43 to 64.5, got %#v", score)
+	}
+	if score.AgeDays != 90 || score.DecayFactor != 0.5 || score.Score != 32.25 {
+		t.Fatalf("expected one half-life of decay, got %#v", score)
+	}
+
+	if recomputation.Score.BaseScore != 64.5 || recomputation.Score.Score != 64.5 {
+		t.Fatalf("expected recomputation to be scored at the last report, got %#v", recomputation.Score)
+	}
+	if recomputation.SignalStrength != "strong_distinct_reporters" {
+		t.Fatalf("expected strong signal, got %s", recomputation.SignalStrength)
+	}
+}
+
+func TestSignalScoreWithoutReconfirmationIsZero(t *testing.T) {
+	reports := []Report{
+		{ID: 1, ReporterHash: "a", CreatedAt: "2026-01-01T10:00:00Z", Tags: []string{"blocking_sidewalk"}},
+		{ID: 2, ReporterHash: "b", CreatedAt: "2026-01-02T10:00:00Z"},
+	}
+	recomputation := computeReconfirmation(reports, defaultSignalParams())
+	if recomputation.Score.BaseScore != 0 || recomputation.SignalStrength != "none" {
+		t.Fatalf("expected no signal without qualifying reconfirmation, got %#v", recomputation.Score)
+	}
+}
+
+func TestSignalStrengthNeedsDistinctReconfirmation(t *testing.T) {
+	// b reports within the reconfirmation gap, so only a's returns qualify.
+	reports := []Report{
+		{ID: 1, ReporterHash: "a", CreatedAt: "2026-01-01T10:00:00Z"},
+		{ID: 2, ReporterHash: "b", CreatedAt: "2026-01-01T12:00:00Z"},
+		{ID: 3, ReporterHash: "a", CreatedAt: "2026-02-01T10:00:00Z"},
+		{ID: 4, ReporterHash: "a", CreatedAt: "2026-03-01T10:00:00Z"},
+		{ID: 5, ReporterHash: "a", CreatedAt: "2026-04-01T10:00:00Z"},
+		{ID: 6, ReporterHash: "a", CreatedAt: "2026-05-01T10:00:00Z"},
+	}
+	recomputation := computeReconfirmation(reports, defaultSignalParams())
+	if recomputation.Summary.DistinctReporterReconfirmations != 0 || recomputation.Summary.UniqueReporters != 2 {
+		t.Fatalf("unexpected summary %#v", recomputation.Summary)
+	}
+	if recomputation.Score.BaseScore < signalScoreStrongThreshold {
+		t.Fatalf("expected the score alone to reach the strong threshold, got %v", recomputation.Score.BaseScore)
+	}
+	if recomputation.SignalStrength != "weak_same_reporter" {
+		t.Fatalf("expected one returning reporter to stay weak, got %s", recomputation.SignalStrength)
+	}
+}
+
+func TestDecayedSignalStrength(t *testing.T) {
+	group := BikeGroup{SignalStrength: "strong_distinct_reporters", SignalScore: 64.5, LastReportAt: "2026-04-01T10:00:00Z"}
+	lastReport := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		days int
+		want string
+	}{
+		{0, "strong_distinct_reporters"},
+		{180, "strong_distinct_reporters"},
+		{270, "weak_same_reporter"},
+		{400, "none"},
+	}
+	for _, tc := range cases {
+		score := decayedGroupSignalScore(group, lastReport.AddDate(0, 0, tc.days))
+		if got := decayedSignalStrength(group.SignalStrength, score); got != tc.want {
+			t.Fatalf("after %d days (score %v): expected %s, got %s", tc.days, score, tc.want, got)
+		}
+	}
+
+	// The lowest scores that earn a label keep it for one half-life.
+	lowest := []BikeGroup{
+		{SignalStrength: "weak_same_reporter", SignalScore: signalScoreSamePoints, LastReportAt: group.LastReportAt},
+		{SignalStrength: "strong_distinct_reporters", SignalScore: signalScoreDistinctPoints + signalScoreReporterPoints, LastReportAt: group.LastReportAt},
+	}
+	for _, minimal := range lowest {
+		kept := decayedGroupSignalScore(minimal, lastReport.AddDate(0, 0, 89))
+		if got := decayedSignalStrength(minimal.SignalStrength, kept); got != minimal.SignalStrength {
+			t.Fatalf("expected score %v to keep %s after 89 days, got %s (score %v)", minimal.SignalScore, minimal.SignalStrength, got, kept)
+		}
+		dropped := decayedGroupSignalScore(minimal, lastReport.AddDate(0, 0, 91))
+		if got := decayedSignalStrength(minimal.SignalStrength, dropped); got == minimal.SignalStrength {
+			t.Fatalf("expected score %v to lose %s after 91 days (score %v)", minimal.SignalScore, minimal.SignalStrength, dropped)
+		}
+	}
+
+	if got := decayedSignalStrength("weak_same_reporter", 80); got != "weak_same_reporter" {
+		t.Fatalf("expected decay never to raise a label, got %s", got)
+	}
+}
+
+func TestAdminReportDetailShowsSignalScoreBreakdown(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	app.adminGetReportDetails = func(ctx context.Context, reportID int) (*OperatorReportDetails, error) {
+		return &OperatorReportDetails{
+			Report: Report{ID: 1, PublicID: "PUB-1", Status: "new"},
+			Photos: []OperatorReportPhotoView{},
+			SignalDetails: SignalDetails{
+				BikeGroup: BikeGroup{ID: 10},
+				SignalScore: SignalScore{
+					Score: 32.25, BaseScore: 64.5, DistinctReconfirmationPoints: 20,
+					SeverityMultiplier: 1.5, AgeDays: 90, DecayFactor: 0.5,
+				},
+			},
+		}, nil
+	}
+
+	rec := httptest.NewRecorder()
+	req := authenticatedRequest(t, app, http.MethodGet, "/bikeadmin/reports/1", "")
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"32.2", "64.5", "× 1.50", "× 0.500"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in score breakdown", want)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	storageIssueMissing          = "missing"
+	storageIssueOrphan           = "orphan"
+	storageIssueSizeMismatch     = "size_mismatch"
+	storageIssueChecksumMismatch = "checksum_mismatch"
+
+	// storageQuarantinePrefix sits outside blobStorePrefixes, so quarantined
+	// blobs are neither served, verified again nor migrated.
+	storageQuarantinePrefix = "quarantine"
+
+	// storageOrphanGrace keeps freshly written blobs out of the orphan
+	// check. Photo uploads, redactions and exports write the blob before
+	// the row that points at it commits, and references are read before
+	// the store is listed.
+	storageOrphanGrace = 24 * time.Hour
+)
+
+// storageReference is a blob the database points at. Optional references,
+// such as cached variants, may be missing; they only keep a blob from being
+// reported as an orphan.
+type storageReference struct {
+	Table    string
+	RowID    int
+	Key      string
+	Size     *int64
+	SHA256   string
+	Optional bool
+}
+
+type StorageVerifyOptions struct {
+	Checksums  bool
+	Quarantine bool
+	Now        time.Time
+}
+
+type StorageIssue struct {
+	Kind          string `json:"kind"`
+	Key           string `json:"key"`
+	Table         string `json:"table,omitempty"`
+	RowID         int    `json:"rowId,omitempty"`
+	ExpectedSize  *int64 `json:"expectedSize,omitempty"`
+	ActualSize    *int64 `json:"actualSize,omitempty"`
+	QuarantinedTo string `json:"quarantinedTo,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// StorageVerifyReport is the JSON document printed by verify-storage.
+type StorageVerifyReport struct {
+	Backend            string         `json:"backend"`
+	CheckedAt          time.Time      `json:"checkedAt"`
+	Checksums          bool           `json:"checksums"`
+	References         int            `json:"references"`
+	Blobs              int            `json:"blobs"`
+	Missing            int            `json:"missing"`
+	Orphaned           int            `json:"orphaned"`
+	SizeMismatches     int            `json:"sizeMismatches"`
+	ChecksumMismatches int            `json:"checksumMismatches"`
+	Unhashed           int            `json:"unhashed"`
+	Quarantined        int            `json:"quarantined"`
+	Recent             int            `json:"recent"`
+	Issues             []StorageIssue `json:"issues"`
+}
+
+// HasIssues reports whether anything needs attention.
+func (r StorageVerifyReport) HasIssues() bool {
+	return len(r.Issues) > 0
+}
+
+// verifyStorageAgainstDatabase compares report_photos, retained redaction
+// originals, blog_media and exports with the blobs in the configured store.
+func (a *App) verifyStorageAgainstDatabase(ctx context.Context, options StorageVerifyOptions) (StorageVerifyReport, error) {
+	references, err := a.listStorageReferences(ctx)
+	if err != nil {
+		return StorageVerifyReport{}, err
+	}
+	return verifyStorage(ctx, a.blobStore(), references, options)
+}
+
+// verifyStorage checks every reference against the store: missing blobs,
+// size mismatches and, with options.Checksums, SHA-256 mismatches for
+// references that have a stored hash. Blobs under blobStorePrefixes that no
+// reference points at are orphans; with options.Quarantine they are moved to
+// quarantine/<timestamp>/<key>. Unreferenced blobs written within
+// storageOrphanGrace are only counted as recent.
+func verifyStorage(ctx context.Context, store BlobStore, references []storageReference, options StorageVerifyOptions) (StorageVerifyReport, error) {
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+	report := StorageVerifyReport{
+		Backend:   store.Name(),
+		CheckedAt: now,
+		Checksums: options.Checksums,
+		Issues:    make([]StorageIssue, 0),
+	}
+
+	blobs := make([]BlobInfo, 0)
+	for _, prefix := range blobStorePrefixes {
+		listed, err := store.List(ctx, prefix)
+		if err != nil {
+			return report, fmt.Errorf("list %s: %w", prefix, err)
+		}
+		blobs = append(blobs, listed...)
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].Key < blobs[j].Key })
+	report.Blobs = len(blobs)
+	byKey := make(map[string]BlobInfo, len(blobs))
+	for _, blob := range blobs {
+		byKey[blob.Key] = blob
+	}
+
+	referenced := make(map[string]bool, len(references))
+	for _, reference := range references {
+		if !reference.Optional {
+			report.References++
+		}
+		blob, found := findReferencedBlob(reference.Key, byKey, blobs)
+		if !found {
+			if !reference.Optional {
+				report.Missing++
+				report.Issues = append(report.Issues, StorageIssue{Kind: storageIssueMissing, Key: reference.Key, Table: reference.Table, RowID: reference.RowID})
+			}
+			continue
+		}
+		referenced[blob.Key] = true
+		if reference.Optional {
+			continue
+		}
+
+		if reference.Size != nil && *reference.Size != blob.Size {
+			actual := blob.Size
+			report.SizeMismatches++
+			report.Issues = append(report.Issues, StorageIssue{Kind: storageIssueSizeMismatch, Key: blob.Key, Table: reference.Table, RowID: reference.RowID, ExpectedSize: reference.Size, ActualSize: &actual})
+		}
+		if !options.Checksums {
+			continue
+		}
+		if reference.SHA256 == "" {
+			report.Unhashed++
+			continue
+		}
+		data, err := store.Get(ctx, blob.Key)
+		if err != nil {
+			return report, fmt.Errorf("read %s: %w", blob.Key, err)
+		}
+		if !strings.EqualFold(sha256Hex(data), reference.SHA256) {
+			report.ChecksumMismatches++
+			report.Issues = append(report.Issues, StorageIssue{Kind: storageIssueChecksumMismatch, Key: blob.Key, Table: reference.Table, RowID: reference.RowID})
+		}
+	}
+
+	quarantineRoot := path.Join(storageQuarantinePrefix, now.UTC().Format("20060102T150405Z"))
+	for _, blob := range blobs {
+		if referenced[blob.Key] {
+			continue
+		}
+		if !blob.ModTime.IsZero() && blob.ModTime.After(now.Add(-storageOrphanGrace)) {
+			report.Recent++
+			continue
+		}
+		size := blob.Size
+		issue := StorageIssue{Kind: storageIssueOrphan, Key: blob.Key, ActualSize: &size}
+		report.Orphaned++
+		if options.Quarantine {
+			target := path.Join(quarantineRoot, blob.Key)
+			if err := moveBlob(ctx, store, blob.Key, target); err != nil {
+				issue.Error = err.Error()
+			} else {
+				issue.QuarantinedTo = target
+				report.Quarantined++
+			}
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+	return report, nil
+}
+
+// findReferencedBlob looks a reference up in the listing. Like
+// resolveExistingPhotoStoragePath, an extensionless legacy key matches the
+// first blob with that name and any extension.
+func findReferencedBlob(key string, byKey map[string]BlobInfo, sorted []BlobInfo) (BlobInfo, bool) {
+	cleaned, err := cleanBlobKey(key)
+	if err != nil {
+		return BlobInfo{}, false
+	}
+	if blob, ok := byKey[cleaned]; ok {
+		return blob, true
+	}
+	if path.Ext(cleaned) != "" {
+		return BlobInfo{}, false
+	}
+	prefix := cleaned + "."
+	start := sort.Search(len(sorted), func(i int) bool { return sorted[i].Key >= prefix })
+	for i := start; i < len(sorted) && strings.HasPrefix(sorted[i].Key, prefix); i++ {
+		if !strings.Contains(strings.TrimPrefix(sorted[i].Key, prefix), "/") {
+			return sorted[i], true
+		}
+	}
+	return BlobInfo{}, false
+}
+
+// moveBlob copies a blob to a new key and deletes the original.
+func moveBlob(ctx context.Context, store BlobStore, from, to string) error {
+	data, err := store.Get(ctx, from)
+	if err != nil {
+		return err
+	}
+	if err := store.Put(ctx, to, data, blobContentType(from)); err != nil {
+		return err
+	}
+	return store.Delete(ctx, from)
+}
+
+// listStorageReferences collects every blob key the database points at.
+func (a *App) listStorageReferences(ctx context.Context) ([]storageReference, error) {
+	references := make([]storageReference, 0)
+
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT id, report_id, storage_path, size_bytes, COALESCE(sha256, ''), version
+		FROM report_photos
+		ORDER BY id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var photoID, reportID, version int
+		var storagePath, hash string
+		var size int64
+		if err := rows.Scan(&photoID, &reportID, &storagePath, &size, &hash, &version); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		references = append(references, storageReference{Table: "report_photos", RowID: photoID, Key: storagePath, Size: &size, SHA256: hash})
+		for variant := range photoVariantMaxSides {
+			references = append(references, storageReference{Table: "report_photos", RowID: photoID, Key: photoVariantStoragePath(reportID, photoID, version, variant), Optional: true})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = a.db.QueryContext(ctx, `
+		SELECT id, previous_storage_path
+		FROM report_photo_redactions
+		WHERE previous_storage_path IS NOT NULL
+		ORDER BY id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var redactionID int
+		var storagePath string
+		if err := rows.Scan(&redactionID, &storagePath); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		references = append(references, storageReference{Table: "report_photo_redactions", RowID: redactionID, Key: storagePath})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = a.db.QueryContext(ctx, `
+		SELECT id, filename, storage_path, size_bytes, COALESCE(sha256, '')
+		FROM blog_media
+		ORDER BY id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var media BlogMedia
+		if err := rows.Scan(&media.ID, &media.Filename, &media.StoragePath, &media.SizeBytes, &media.SHA256); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		size := media.SizeBytes
+		references = append(references, storageReference{Table: "blog_media", RowID: media.ID, Key: blogMediaStorageKey(&media), Size: &size, SHA256: media.SHA256})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = a.db.QueryContext(ctx, `
+		SELECT id, csv_path, geojson_path, pdf_path,
+			COALESCE(csv_sha256, ''), COALESCE(geojson_sha256, ''), COALESCE(pdf_sha256, '')
+		FROM exports
+		ORDER BY id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var exportID int
+		var paths, hashes [3]sql.NullString
+		if err := rows.Scan(&exportID, &paths[0], &paths[1], &paths[2], &hashes[0], &hashes[1], &hashes[2]); err != nil {
+			return nil, err
+		}
+		for i, artifact := range paths {
+			// Exports that failed half way keep an empty path.
+			if !artifact.Valid || strings.TrimSpace(artifact.String) == "" {
+				continue
+			}
+			references = append(references, storageReference{Table: "exports", RowID: exportID, Key: artifact.String, SHA256: hashes[i].String})
+		}
+	}
+	return references, rows.Err()
+}
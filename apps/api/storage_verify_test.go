@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyStorageReportsAndQuarantinesIssues(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := newFilesystemBlobStore(root)
+	now := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	blobs := map[string]string{
+		"uploads/reports/1/a.jpg":        "photo a",
+		"uploads/reports/1/b.jpg":        "photo b",
+		"uploads/reports/1/c.jpg":        "photo c",
+		"uploads/reports/2/legacy.webp":  "legacy",
+		"variants/reports/1/1-thumb.jpg": "thumb",
+		"variants/reports/1/9-thumb.jpg": "stale thumb",
+		"blog/unused.png":                "unused",
+		"uploads/reports/3/pending.jpg":  "not committed yet",
+	}
+	for key, data := range blobs {
+		if err := store.Put(ctx, key, []byte(data), ""); err != nil {
+			t.Fatalf("Put returned error: %v", err)
+		}
+		written := now.Add(-48 * time.Hour)
+		if key == "uploads/reports/3/pending.jpg" {
+			written = now.Add(-time.Minute)
+		}
+		if err := os.Chtimes(filepath.Join(root, filepath.FromSlash(key)), written, written); err != nil {
+			t.Fatalf("Chtimes returned error: %v", err)
+		}
+	}
+	size := func(n int) *int64 {
+		value := int64(n)
+		return &value
+	}
+	references := []storageReference{
+		{Table: "report_photos", RowID: 1, Key: "uploads/reports/1/a.jpg", Size: size(7), SHA256: sha256Hex([]byte("photo a"))},
+		{Table: "report_photos", RowID: 2, Key: "uploads/reports/1/b.jpg", Size: size(99)},
+		{Table: "report_photos", RowID: 3, Key: "uploads/reports/1/c.jpg", Size: size(7), SHA256: sha256Hex([]byte("tampered"))},
+		{Table: "report_photos", RowID: 4, Key: "uploads/reports/2/legacy", Size: size(6)},
+		{Table: "report_photos", RowID: 1, Key: "variants/reports/1/1-thumb.jpg", Optional: true},
+		{Table: "report_photos", RowID: 1, Key: "variants/reports/1/1-medium.jpg", Optional: true},
+		{Table: "exports", RowID: 3, Key: "exports/3/zwerffiets-weekly.csv"},
+	}
+
+	report, err := verifyStorage(ctx, store, references, StorageVerifyOptions{Checksums: true, Quarantine: true, Now: now})
+	if err != nil {
+		t.Fatalf("verifyStorage returned error: %v", err)
+	}
+	if report.References != 5 || report.Blobs != 8 {
+		t.Fatalf("expected 5 references and 8 blobs, got %d and %d", report.References, report.Blobs)
+	}
+	if report.Missing != 1 || report.SizeMismatches != 1 || report.ChecksumMismatches != 1 || report.Unhashed != 2 {
+		t.Fatalf("unexpected counts %#v", report)
+	}
+	if report.Orphaned != 2 || report.Quarantined != 2 {
+		t.Fatalf("expected both orphans to be quarantined, got %#v", report)
+	}
+	if report.Recent != 1 {
+		t.Fatalf("expected the fresh upload to be counted as recent, got %#v", report)
+	}
+	if _, err := store.Stat(ctx, "uploads/reports/3/pending.jpg"); err != nil {
+		t.Fatalf("expected the fresh upload to stay in place: %v", err)
+	}
+
+	kinds := map[string]string{}
+	for _, issue := range report.Issues {
+		kinds[issue.Key] = issue.Kind
+	}
+	expected := map[string]string{
+		"exports/3/zwerffiets-weekly.csv": storageIssueMissing,
+		"uploads/reports/1/b.jpg":         storageIssueSizeMismatch,
+		"uploads/reports/1/c.jpg":         storageIssueChecksumMismatch,
+		"variants/reports/1/9-thumb.jpg":  storageIssueOrphan,
+		"blog/unused.png":                 storageIssueOrphan,
+	}
+	if len(kinds) != len(expected) {
+		t.Fatalf("unexpected issues %#v", report.Issues)
+	}
+	for key, kind := range expected {
+		if kinds[key] != kind {
+			t.Fatalf("expected %s to be reported as %s, got %q", key, kind, kinds[key])
+		}
+	}
+
+	if _, err := store.Stat(ctx, "blog/unused.png"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the orphan to leave its place, got %v", err)
+	}
+	if _, err := store.Stat(ctx, "quarantine/20261018T093000Z/blog/unused.png"); err != nil {
+		t.Fatalf("expected the orphan in quarantine: %v", err)
+	}
+
+	encoded, err := json.Marshal(report)
+	if err != nil || !strings.Contains(string(encoded), `"quarantinedTo":"quarantine/20261018T093000Z/blog/unused.png"`) {
+		t.Fatalf("unexpected JSON report %s, %v", encoded, err)
+	}
+
+	again, err := verifyStorage(ctx, store, references, StorageVerifyOptions{Now: now})
+	if err != nil {
+		t.Fatalf("verifyStorage returned error: %v", err)
+	}
+	if again.Orphaned != 0 || again.Blobs != 6 || again.ChecksumMismatches != 0 {
+		t.Fatalf("expected quarantined blobs to be out of scope, got %#v", again)
+	}
+}
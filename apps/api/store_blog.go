@@ -24,6 +24,7 @@ type BlogMedia struct {
 	StoragePath string    `json:"storage_path"`
 	MimeType    string    `json:"mime_type"`
 	SizeBytes   int64     `json:"size_bytes"`
+	SHA256      string    `json:"sha256,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
@@ -149,11 +150,11 @@ func (a *App) storePublicGetBlogPostBySlug(ctx context.Context, slug string) (*B
 
 func (a *App) storeSaveBlogMedia(ctx context.Context, m *BlogMedia) error {
 	return a.db.QueryRowContext(ctx, `
-		INSERT INTO blog_media (filename, storage_path, mime_type, size_bytes)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO blog_media (filename, storage_path, mime_type, size_bytes, sha256)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
 		ON CONFLICT (filename) DO UPDATE SET updated_at = NOW() -- Should not happen if named uniquely
 		RETURNING id, created_at
-	`, m.Filename, m.StoragePath, m.MimeType, m.SizeBytes).Scan(&m.ID, &m.CreatedAt)
+	`, m.Filename, m.StoragePath, m.MimeType, m.SizeBytes, m.SHA256).Scan(&m.ID, &m.CreatedAt)
 }
 
 func (a *App) storeGetBlogMedia(ctx context.Context, filename string) (*BlogMedia, error) {
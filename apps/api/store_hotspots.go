@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// listHotspotPoints loads the reports used for hotspot detection, grouped by
+// municipality. Invalid reports are skipped; resolved_at is taken from the
+// status_changed event that resolved the report.
+func (a *App) listHotspotPoints(ctx context.Context, since time.Time) (map[string][]hotspotPoint, error) {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT r.id, r.bike_group_id, r.lat, r.lng, r.created_at, r.municipality, resolved.resolved_at
+		FROM reports r
+		LEFT JOIN LATERAL (
+			SELECT MIN(e.created_at) AS resolved_at
+			FROM report_events e
+			WHERE e.report_id = r.id AND e.type = 'status_changed' AND e.metadata->>'status' = 'resolved'
+		) resolved ON TRUE
+		WHERE r.municipality IS NOT NULL AND r.municipality <> ''
+			AND r.status <> 'invalid'
+			AND r.created_at >= $1
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pointsByMunicipality := make(map[string][]hotspotPoint)
+	for rows.Next() {
+		var point hotspotPoint
+		var municipality string
+		var resolvedAt sql.NullTime
+		if err := rows.Scan(&point.ReportID, &point.BikeGroupID, &point.Lat, &point.Lng, &point.CreatedAt, &municipality, &resolvedAt); err != nil {
+			return nil, err
+		}
+		if resolvedAt.Valid {
+			value := resolvedAt.Time
+			point.ResolvedAt = &value
+		}
+		pointsByMunicipality[municipality] = append(pointsByMunicipality[municipality], point)
+	}
+	return pointsByMunicipality, rows.Err()
+}
+
+// replaceHotspots swaps the stored hotspots for a freshly computed set.
+func (a *App) replaceHotspots(ctx context.Context, hotspots []Hotspot) error {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM hotspots`); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	for _, hotspot := range hotspots {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO hotspots (
+				municipality, center_lat, center_lng, radius_m, report_count, bike_group_count,
+				recurrence_rate, median_resolution_hours, first_report_at, last_report_at, computed_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		`, hotspot.Municipality, hotspot.CenterLat, hotspot.CenterLng, hotspot.RadiusMeters, hotspot.ReportCount, hotspot.BikeGroupCount,
+			hotspot.RecurrenceRate, hotspot.MedianResolutionHours, hotspot.FirstReportAt, hotspot.LastReportAt); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// storeListHotspots returns stored hotspots, optionally limited to one
+// municipality, with the largest clusters first.
+func (a *App) storeListHotspots(ctx context.Context, municipality *string) ([]Hotspot, error) {
+	var scope any
+	if municipality != nil && *municipality != "" {
+		scope = *municipality
+	}
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT id, municipality, center_lat, center_lng, radius_m, report_count, bike_group_count,
+			recurrence_rate, median_resolution_hours, first_report_at, last_report_at, computed_at
+		FROM hotspots
+		WHERE $1::text IS NULL OR LOWER(municipality) = LOWER($1::text)
+		ORDER BY report_count DESC, id ASC
+	`, scope)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hotspots := make([]Hotspot, 0)
+	for rows.Next() {
+		var hotspot Hotspot
+		var medianHours sql.NullFloat64
+		var firstReportAt, lastReportAt, computedAt time.Time
+		if err := rows.Scan(&hotspot.ID, &hotspot.Municipality, &hotspot.CenterLat, &hotspot.CenterLng, &hotspot.RadiusMeters,
+			&hotspot.ReportCount, &hotspot.BikeGroupCount, &hotspot.RecurrenceRate, &medianHours,
+			&firstReportAt, &lastReportAt, &computedAt); err != nil {
+			return nil, err
+		}
+		if medianHours.Valid {
+			value := medianHours.Float64
+			hotspot.MedianResolutionHours = &value
+		}
+		hotspot.FirstReportAt = firstReportAt.UTC().Format(time.RFC3339)
+		hotspot.LastReportAt = lastReportAt.UTC().Format(time.RFC3339)
+		hotspot.ComputedAt = computedAt.UTC().Format(time.RFC3339)
+		hotspots = append(hotspots, hotspot)
+	}
+	return hotspots, rows.Err()
+}
+
+// runHotspotDetection recomputes all hotspots from the reports of the last
+// hotspotLookbackDays days.
+func (a *App) runHotspotDetection(ctx context.Context, now time.Time) ([]Hotspot, error) {
+	points, err := a.listHotspotPoints(ctx, now.AddDate(0, 0, -hotspotLookbackDays))
+	if err != nil {
+		return nil, err
+	}
+	hotspots := detectHotspots(points, hotspotEpsMeters, hotspotMinReports, hotspotLookbackDays)
+	if err := a.replaceHotspots(ctx, hotspots); err != nil {
+		return nil, err
+	}
+	return hotspots, nil
+}
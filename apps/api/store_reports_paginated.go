@@ -47,15 +47,19 @@ func (a *App) listOperatorReportsPaginated(ctx context.Context, filters map[stri
 		var bgCreatedAt, bgUpdatedAt time.Time
 		var bgLastReportAt time.Time
 		var bgFirstQual, bgLastQual sql.NullTime
+		var signalScore float64
+		var signalStrength string
+		var priorityScore float64
 
 		err := rows.Scan(
 			&r.ID, &r.PublicID, &rCreatedAt, &rUpdatedAt, &r.Status,
 			&r.Location.Lat, &r.Location.Lng, &r.Location.AccuracyM, &tagsRaw, &note,
 			&r.Source, &dedupeGroupID, &r.BikeGroupID, &r.FingerprintHash, &r.ReporterHash,
-			&r.FlaggedForReview, &addr, &city, &post, &muni, &userID,
+			&r.FlaggedForReview, &addr, &city, &post, &muni, &userID, &r.LocationSource,
 			&bg.ID, &bg.AnchorLat, &bg.AnchorLng, &bgLastReportAt, &bg.TotalReports,
 			&bg.UniqueReporters, &bg.SameReporterReconfirmations, &bg.DistinctReporterReconfirmations,
-			&bgFirstQual, &bgLastQual, &bg.SignalStrength, &bgCreatedAt, &bgUpdatedAt,
+			&bgFirstQual, &bgLastQual, &bg.SignalStrength, &bg.SignalScore, &bgCreatedAt, &bgUpdatedAt,
+			&signalScore, &signalStrength, &priorityScore,
 			&totalCount,
 		)
 		if err != nil {
@@ -107,7 +111,7 @@ func (a *App) listOperatorReportsPaginated(ctx context.Context, filters map[stri
 		photos, _ := a.listReportPhotos(ctx, r.ID)
 		var previewPhotoURL *string
 		if len(photos) > 0 {
-			url := a.buildOperatorReportPhotoURL(r.ID, photos[0].ID)
+			url := withPhotoVersion(a.buildOperatorReportPhotoVariantURL(r.ID, photos[0].ID, photoVariantThumb), photos[0].Version)
 			previewPhotoURL = &url
 		}
 
@@ -115,7 +119,9 @@ func (a *App) listOperatorReportsPaginated(ctx context.Context, filters map[stri
 			Report:          r,
 			BikeGroupID:     bg.ID,
 			SignalSummary:   bikeGroupToSignalSummary(bg),
-			SignalStrength:  bg.SignalStrength,
+			SignalStrength:  signalStrength,
+			SignalScore:     roundScore(signalScore),
+			PriorityScore:   roundScore(priorityScore),
 			PreviewPhotoURL: previewPhotoURL,
 		})
 	}
@@ -141,10 +147,13 @@ func buildOperatorReportsQuery(filters map[string]any, page, pageSize int) (stri
 			reports.id, reports.public_id, reports.created_at, reports.updated_at, reports.status,
 			reports.lat, reports.lng, reports.accuracy_m, reports.tags, reports.note,
 			reports.source, reports.dedupe_group_id, reports.bike_group_id, reports.fingerprint_hash, reports.reporter_hash,
-			reports.flagged_for_review, reports.address, reports.city, reports.postcode, reports.municipality, reports.user_id,
+			reports.flagged_for_review, reports.address, reports.city, reports.postcode, reports.municipality, reports.user_id, reports.location_source,
 			bg.id, bg.anchor_lat, bg.anchor_lng, bg.last_report_at, bg.total_reports,
 			bg.unique_reporters, bg.same_reporter_reconfirmations, bg.distinct_reporter_reconfirmations,
-			bg.first_qualifying_reconfirmation_at, bg.last_qualifying_reconfirmation_at, bg.signal_strength, bg.created_at, bg.updated_at,
+			bg.first_qualifying_reconfirmation_at, bg.last_qualifying_reconfirmation_at, bg.signal_strength, bg.signal_score, bg.created_at, bg.updated_at,
+			` + signalScoreSQL + ` AS decayed_signal_score,
+			` + signalStrengthSQL + ` AS decayed_signal_strength,
+			` + triagePrioritySQL + ` AS priority_score,
 			COUNT(*) OVER() as total_count
 		FROM reports
 		JOIN bike_groups bg ON reports.bike_group_id = bg.id
@@ -155,7 +164,7 @@ func buildOperatorReportsQuery(filters map[string]any, page, pageSize int) (stri
 	argIndex := len(args) + 1
 
 	if signalStrength, ok := filters["signal_strength"].(string); ok && signalStrength != "" {
-		query += fmt.Sprintf(" AND bg.signal_strength = $%d", argIndex)
+		query += fmt.Sprintf(" AND %s = $%d", signalStrengthSQL, argIndex)
 		args = append(args, signalStrength)
 		argIndex++
 	}
@@ -165,24 +174,21 @@ func buildOperatorReportsQuery(filters map[string]any, page, pageSize int) (stri
 		query += " AND (bg.same_reporter_reconfirmations = 0 AND bg.distinct_reporter_reconfirmations = 0)"
 	}
 	if strongOnly, ok := filters["strong_only"].(bool); ok && strongOnly {
-		query += " AND bg.signal_strength = 'strong_distinct_reporters'"
+		query += " AND " + signalStrengthSQL + " = 'strong_distinct_reporters'"
+	}
+	if minPriority, ok := filters["min_priority"].(float64); ok && minPriority > 0 {
+		query += fmt.Sprintf(" AND %s >= $%d", triagePrioritySQL, argIndex)
+		args = append(args, minPriority)
+		argIndex++
 	}
 
 	// Apply Sorting
 	sortBy, _ := filters["sort"].(string)
 	if sortBy == "signal" {
-		// Custom sort for signal strength priority
-		// We can't easily map string enum to integer in pure SQL without a CASE statement or lookup table
-		// Priority: strong_distinct_reporters (2) > weak_same_reporter (1) > none (0)
-		query += `
-			ORDER BY
-			CASE bg.signal_strength
-				WHEN 'strong_distinct_reporters' THEN 2
-				WHEN 'weak_same_reporter' THEN 1
-				ELSE 0
-			END DESC,
-			reports.created_at DESC
-		`
+		// Strongest first by the decayed signal score, so stale signals sink.
+		query += " ORDER BY decayed_signal_score DESC, reports.created_at DESC"
+	} else if sortBy == "priority" {
+		query += " ORDER BY priority_score DESC, reports.created_at DESC"
 	} else {
 		// Default: newest first
 		query += " ORDER BY reports.created_at DESC"
@@ -200,10 +206,8 @@ func buildOperatorReportsQuery(filters map[string]any, page, pageSize int) (stri
 func (a *App) sortReports(reports []OperatorReportView, by string) {
 	if by == "signal" {
 		sort.Slice(reports, func(i, j int) bool {
-			left := signalStrengthPriority[reports[i].SignalStrength]
-			right := signalStrengthPriority[reports[j].SignalStrength]
-			if left != right {
-				return left > right
+			if reports[i].SignalScore != reports[j].SignalScore {
+				return reports[i].SignalScore > reports[j].SignalScore
 			}
 			return reports[i].CreatedAt > reports[j].CreatedAt
 		})
@@ -67,12 +67,49 @@ func TestBuildOperatorReportsQuery(t *testing.T) {
 			page:     1,
 			pageSize: 10,
 			wantSql: []string{
-				"CASE bg.signal_strength",
-				"WHEN 'strong_distinct_reporters' THEN 2",
+				"AS decayed_signal_score",
+				"ORDER BY decayed_signal_score DESC, reports.created_at DESC",
 				"LIMIT $1 OFFSET $2",
 			},
 			wantArgs: []any{10, 0},
 		},
+		{
+			name:     "Signal Strength Filter Uses Decayed Label",
+			filters:  map[string]any{"signal_strength": "weak_same_reporter"},
+			page:     1,
+			pageSize: 10,
+			wantSql: []string{
+				"WHEN bg.signal_strength = 'strong_distinct_reporters' AND ROUND((bg.signal_score * POWER(0.5,",
+				"END) = $1",
+				"LIMIT $2 OFFSET $3",
+			},
+			wantArgs: []any{"weak_same_reporter", 10, 0},
+		},
+		{
+			name:     "Sort Priority",
+			filters:  map[string]any{"sort": "priority"},
+			page:     1,
+			pageSize: 10,
+			wantSql: []string{
+				"SELECT MAX(t.weight) FROM tags t WHERE reports.tags::jsonb ? t.code",
+				"SELECT 1 FROM hotspots h",
+				"AS priority_score",
+				"ORDER BY priority_score DESC, reports.created_at DESC",
+			},
+			wantArgs: []any{10, 0},
+		},
+		{
+			name:     "Min Priority Filter",
+			filters:  map[string]any{"min_priority": 40.0, "status": "new"},
+			page:     1,
+			pageSize: 10,
+			wantSql: []string{
+				"AND reports.status = $1",
+				") >= $2",
+				"LIMIT $3 OFFSET $4",
+			},
+			wantArgs: []any{"new", 40.0, 10, 0},
+		},
 		{
 			name:     "Pagination Page 2",
 			filters:  map[string]any{},
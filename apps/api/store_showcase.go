@@ -15,13 +15,19 @@ type ShowcaseItem struct {
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	StoragePath   string
+	// AfterPhotoID pairs the photo with an after-removal photo of the same
+	// report; nil for a single photo.
+	AfterPhotoID     *int
+	AfterStoragePath *string
 }
 
 func (a *App) storeGetShowcaseItems(ctx context.Context) ([]ShowcaseItem, error) {
 	query := `
-		SELECT s.slot, s.report_photo_id, s.subtitle, s.focal_x, s.focal_y, s.scale_percent, s.created_at, s.updated_at, p.storage_path
+		SELECT s.slot, s.report_photo_id, s.subtitle, s.focal_x, s.focal_y, s.scale_percent, s.created_at, s.updated_at, p.storage_path,
+			s.after_photo_id, ap.storage_path
 		FROM showcase_items s
 		JOIN report_photos p ON s.report_photo_id = p.id
+		LEFT JOIN report_photos ap ON s.after_photo_id = ap.id
 		ORDER BY s.slot ASC
 	`
 	rows, err := a.db.QueryContext(ctx, query)
@@ -36,7 +42,7 @@ func (a *App) storeGetShowcaseItems(ctx context.Context) ([]ShowcaseItem, error)
 		if err := rows.Scan(
 			&item.Slot, &item.ReportPhotoID, &item.Subtitle,
 			&item.FocalX, &item.FocalY, &item.ScalePercent, &item.CreatedAt, &item.UpdatedAt,
-			&item.StoragePath,
+			&item.StoragePath, &item.AfterPhotoID, &item.AfterStoragePath,
 		); err != nil {
 			return nil, err
 		}
@@ -45,18 +51,34 @@ func (a *App) storeGetShowcaseItems(ctx context.Context) ([]ShowcaseItem, error)
 	return items, rows.Err()
 }
 
-func (a *App) storeUpsertShowcaseItem(ctx context.Context, slot, reportPhotoID, focalX, focalY, scalePercent int, subtitle string) error {
+func (a *App) storeUpsertShowcaseItem(ctx context.Context, slot, reportPhotoID, focalX, focalY, scalePercent int, subtitle string, afterPhotoID *int) error {
 	query := `
-		INSERT INTO showcase_items (slot, report_photo_id, subtitle, focal_x, focal_y, scale_percent, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		INSERT INTO showcase_items (slot, report_photo_id, subtitle, focal_x, focal_y, scale_percent, after_photo_id, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
 		ON CONFLICT (slot) DO UPDATE SET
 			report_photo_id = EXCLUDED.report_photo_id,
 			subtitle = EXCLUDED.subtitle,
 			focal_x = EXCLUDED.focal_x,
 			focal_y = EXCLUDED.focal_y,
 			scale_percent = EXCLUDED.scale_percent,
+			after_photo_id = EXCLUDED.after_photo_id,
 			updated_at = NOW();
 	`
-	_, err := a.db.ExecContext(ctx, query, slot, reportPhotoID, subtitle, focalX, focalY, scalePercent)
+	_, err := a.db.ExecContext(ctx, query, slot, reportPhotoID, subtitle, focalX, focalY, scalePercent, afterPhotoID)
 	return err
 }
+
+// storeIsAfterPhotoOf reports whether afterPhotoID is an after-removal photo
+// of the same report as photoID, so the two can be shown as a pair.
+func (a *App) storeIsAfterPhotoOf(ctx context.Context, photoID, afterPhotoID int) (bool, error) {
+	var ok bool
+	err := a.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM report_photos bp
+			JOIN report_photos ap ON ap.report_id = bp.report_id
+			WHERE bp.id = $1 AND ap.id = $2 AND ap.kind = $3
+		)
+	`, photoID, afterPhotoID, photoKindAfter).Scan(&ok)
+	return ok, err
+}
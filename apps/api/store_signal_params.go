@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const signalParamsSelect = `
+	SELECT id, municipality, signal_match_radius_m, signal_lookback_days, signal_reconfirmation_gap_days,
+		strong_signal_min_reporters, dedupe_radius_m, dedupe_lookback_days,
+		distance_weight, tag_overlap_weight, recency_weight, photo_similarity_weight, updated_by, updated_at
+	FROM signal_settings
+`
+
+// municipalityLookupRadiusDegrees bounds the search for a nearby geocoded
+// report when resolving the municipality of an incoming location (~1 km).
+const municipalityLookupRadiusDegrees = 0.01
+
+func scanSignalParams(scanner rowScanner) (SignalParams, error) {
+	var params SignalParams
+	var municipality sql.NullString
+	var updatedBy sql.NullString
+	var updatedAt time.Time
+	if err := scanner.Scan(
+		&params.ID, &municipality, &params.SignalMatchRadiusMeters, &params.SignalLookbackDays, &params.SignalReconfirmationGapDays,
+		&params.StrongSignalMinReporters, &params.DedupeRadiusMeters, &params.DedupeLookbackDays,
+		&params.DistanceWeight, &params.TagOverlapWeight, &params.RecencyWeight, &params.PhotoSimilarityWeight, &updatedBy, &updatedAt,
+	); err != nil {
+		return SignalParams{}, err
+	}
+	if municipality.Valid {
+		params.Municipality = &municipality.String
+	}
+	if updatedBy.Valid {
+		params.UpdatedBy = &updatedBy.String
+	}
+	params.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+	return params, nil
+}
+
+// storeListSignalParams returns the global row first, followed by the
+// municipality overrides in alphabetical order.
+func (a *App) storeListSignalParams(ctx context.Context) ([]SignalParams, error) {
+	rows, err := a.db.QueryContext(ctx, signalParamsSelect+` ORDER BY municipality IS NOT NULL, LOWER(municipality)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]SignalParams, 0)
+	for rows.Next() {
+		params, err := scanSignalParams(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, params)
+	}
+	return list, rows.Err()
+}
+
+// storeSaveSignalParams inserts or replaces the row for the scope of params.
+func (a *App) storeSaveSignalParams(ctx context.Context, params SignalParams, updatedBy string) error {
+	_, err := a.db.ExecContext(ctx, `
+		INSERT INTO signal_settings (
+			municipality, signal_match_radius_m, signal_lookback_days, signal_reconfirmation_gap_days,
+			strong_signal_min_reporters, dedupe_radius_m, dedupe_lookback_days,
+			distance_weight, tag_overlap_weight, recency_weight, photo_similarity_weight, updated_by, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW())
+		ON CONFLICT ((COALESCE(LOWER(municipality), ''))) DO UPDATE SET
+			signal_match_radius_m = EXCLUDED.signal_match_radius_m,
+			signal_lookback_days = EXCLUDED.signal_lookback_days,
+			signal_reconfirmation_gap_days = EXCLUDED.signal_reconfirmation_gap_days,
+			strong_signal_min_reporters = EXCLUDED.strong_signal_min_reporters,
+			dedupe_radius_m = EXCLUDED.dedupe_radius_m,
+			dedupe_lookback_days = EXCLUDED.dedupe_lookback_days,
+			distance_weight = EXCLUDED.distance_weight,
+			tag_overlap_weight = EXCLUDED.tag_overlap_weight,
+			recency_weight = EXCLUDED.recency_weight,
+			photo_similarity_weight = EXCLUDED.photo_similarity_weight,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+	`, params.Municipality, params.SignalMatchRadiusMeters, params.SignalLookbackDays, params.SignalReconfirmationGapDays,
+		params.StrongSignalMinReporters, params.DedupeRadiusMeters, params.DedupeLookbackDays,
+		params.DistanceWeight, params.TagOverlapWeight, params.RecencyWeight, params.PhotoSimilarityWeight, updatedBy)
+	return err
+}
+
+// storeDeleteSignalParamsOverride removes a municipality override. The global
+// row cannot be deleted.
+func (a *App) storeDeleteSignalParamsOverride(ctx context.Context, id int) error {
+	_, err := a.db.ExecContext(ctx, `DELETE FROM signal_settings WHERE id = $1 AND municipality IS NOT NULL`, id)
+	return err
+}
+
+// loadSignalParams resolves the parameters for a municipality: its override
+// when present, otherwise the global row, otherwise the built-in defaults.
+func (a *App) loadSignalParams(ctx context.Context, municipality *string) (SignalParams, error) {
+	var scope any
+	if municipality != nil && *municipality != "" {
+		scope = *municipality
+	}
+	row := a.db.QueryRowContext(ctx, signalParamsSelect+`
+		WHERE municipality IS NULL OR LOWER(municipality) = LOWER($1)
+		ORDER BY municipality IS NULL
+		LIMIT 1
+	`, scope)
+	params, err := scanSignalParams(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		params, err = defaultSignalParams(), nil
+	}
+	if err != nil {
+		return SignalParams{}, err
+	}
+	params.TagWeights, err = a.loadTagWeights(ctx)
+	if err != nil {
+		return SignalParams{}, err
+	}
+	return params, nil
+}
+
+// loadTagWeights returns the weight of every tag by code. Tags missing from
+// the table fall back to their built-in weight.
+func (a *App) loadTagWeights(ctx context.Context) (map[string]float64, error) {
+	rows, err := a.db.QueryContext(ctx, `SELECT code, weight FROM tags`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weights := defaultTagWeights()
+	for rows.Next() {
+		var code string
+		var weight float64
+		if err := rows.Scan(&code, &weight); err != nil {
+			return nil, err
+		}
+		weights[code] = weight
+	}
+	return weights, rows.Err()
+}
+
+// municipalityNearLocation returns the municipality of the closest geocoded
+// report around loc. Geocoding of new reports happens asynchronously, so this
+// is the best available hint at intake time.
+func (a *App) municipalityNearLocation(ctx context.Context, loc ReportLocation) (*string, error) {
+	var municipality string
+	err := a.db.QueryRowContext(ctx, `
+		SELECT municipality
+		FROM reports
+		WHERE municipality IS NOT NULL AND municipality <> ''
+			AND lat BETWEEN $1 - $3 AND $1 + $3
+			AND lng BETWEEN $2 - $3 AND $2 + $3
+		ORDER BY (lat - $1) * (lat - $1) + (lng - $2) * (lng - $2)
+		LIMIT 1
+	`, loc.Lat, loc.Lng, municipalityLookupRadiusDegrees).Scan(&municipality)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &municipality, nil
+}
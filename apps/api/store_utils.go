@@ -9,8 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"mime"
-	"net/http"
-	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -22,45 +21,55 @@ import (
 
 const photoStorageNameRandomBytes = 16
 
-func (a *App) saveReportPhotosTx(ctx context.Context, tx *sql.Tx, reportID int, photos []PhotoUpload) error {
-	reportDir := filepath.Join(a.cfg.DataRoot, "uploads", "reports", strconv.Itoa(reportID))
-	if err := os.MkdirAll(reportDir, 0o755); err != nil {
-		return err
-	}
+// saveReportPhotosTx stores photos for a report and returns their IDs in
+// order. uploadedBy is the operator email, nil for citizen uploads.
+func (a *App) saveReportPhotosTx(ctx context.Context, tx *sql.Tx, reportID int, photos []PhotoUpload, uploadedBy *string) ([]int, error) {
+	reportDir := path.Join("uploads", "reports", strconv.Itoa(reportID))
+	photoIDs := make([]int, 0, len(photos))
 	for _, photo := range photos {
 		ext := extensionFromMime(photo.MimeType, photo.Name)
+		var exifLat, exifLng *float64
+		var exifCapturedAt *time.Time
+		if photo.EXIF != nil {
+			exifLat, exifLng, exifCapturedAt = photo.EXIF.Lat, photo.EXIF.Lng, photo.EXIF.CapturedAt
+		}
+		// BIGINT is signed; the hash is stored with the same 64 bits.
+		var perceptualHash *int64
+		if photo.PerceptualHash != nil {
+			hash := int64(*photo.PerceptualHash)
+			perceptualHash = &hash
+		}
 		var photoID int
 		if err := tx.QueryRowContext(ctx, `
-			INSERT INTO report_photos (report_id, storage_path, mime_type, filename, size_bytes)
-			VALUES ($1, '', $2, $3, $4)
+			INSERT INTO report_photos (report_id, storage_path, mime_type, original_mime_type, filename, size_bytes, exif_lat, exif_lng, exif_captured_at, perceptual_hash, kind, uploaded_by)
+			VALUES ($1, '', $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 			RETURNING id
-		`, reportID, photo.MimeType, photo.Name, len(photo.Bytes)).Scan(&photoID); err != nil {
-			return err
+		`, reportID, photo.MimeType, valueOrDefaultString(photo.OriginalMimeType, photo.MimeType), photo.Name, len(photo.Bytes), exifLat, exifLng, exifCapturedAt, perceptualHash, valueOrDefaultString(photo.Kind, photoKindCitizen), uploadedBy).Scan(&photoID); err != nil {
+			return nil, err
 		}
 
 		fileName, err := generatePhotoStorageFileName(ext)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		fullPath := filepath.Join(reportDir, fileName)
-		relPath, _ := filepath.Rel(a.cfg.DataRoot, fullPath)
-
-		if err := os.WriteFile(fullPath, photo.Bytes, 0o644); err != nil {
-			return err
+		storagePath := path.Join(reportDir, fileName)
+		if err := a.blobStore().Put(ctx, storagePath, photo.Bytes, photo.MimeType); err != nil {
+			return nil, err
 		}
 
 		if _, err := tx.ExecContext(ctx, `
-			UPDATE report_photos SET storage_path = $1 WHERE id = $2
-		`, relPath, photoID); err != nil {
-			return err
+			UPDATE report_photos SET storage_path = $1, sha256 = $2 WHERE id = $3
+		`, storagePath, sha256Hex(photo.Bytes), photoID); err != nil {
+			return nil, err
 		}
+		photoIDs = append(photoIDs, photoID)
 	}
-	return nil
+	return photoIDs, nil
 }
 
 func (a *App) listReportPhotos(ctx context.Context, reportID int) ([]ReportPhoto, error) {
 	rows, err := a.db.QueryContext(ctx, `
-		SELECT id, report_id, storage_path, mime_type, filename, size_bytes, created_at
+		SELECT id, report_id, storage_path, mime_type, filename, size_bytes, created_at, version, kind
 		FROM report_photos
 		WHERE report_id = $1
 		ORDER BY created_at ASC
@@ -82,6 +91,8 @@ func (a *App) listReportPhotos(ctx context.Context, reportID int) ([]ReportPhoto
 			&photo.Filename,
 			&photo.SizeBytes,
 			&createdAt,
+			&photo.Version,
+			&photo.Kind,
 		); err != nil {
 			return nil, err
 		}
@@ -97,7 +108,7 @@ func (a *App) getReportPhotoByID(ctx context.Context, reportID int, photoID int)
 	var photo ReportPhoto
 	var createdAt time.Time
 	err := a.db.QueryRowContext(ctx, `
-		SELECT id, report_id, storage_path, mime_type, filename, size_bytes, created_at
+		SELECT id, report_id, storage_path, mime_type, filename, size_bytes, created_at, version, kind
 		FROM report_photos
 		WHERE id = $1 AND report_id = $2
 	`, photoID, reportID).Scan(
@@ -108,6 +119,8 @@ func (a *App) getReportPhotoByID(ctx context.Context, reportID int, photoID int)
 		&photo.Filename,
 		&photo.SizeBytes,
 		&createdAt,
+		&photo.Version,
+		&photo.Kind,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -124,16 +137,36 @@ func (a *App) buildOperatorReportPhotoURL(reportID int, photoID int) string {
 	return fmt.Sprintf("/api/v1/operator/reports/%d/photos/%d", reportID, photoID)
 }
 
+func (a *App) buildOperatorReportPhotoVariantURL(reportID int, photoID int, size string) string {
+	return fmt.Sprintf("%s?size=%s", a.buildOperatorReportPhotoURL(reportID, photoID), size)
+}
+
+// withPhotoVersion adds the photo version to a photo URL once it has been
+// redacted, so browsers do not keep showing a cached earlier version.
+func withPhotoVersion(photoURL string, version int) string {
+	if version <= 1 {
+		return photoURL
+	}
+	separator := "?"
+	if strings.Contains(photoURL, "?") {
+		separator = "&"
+	}
+	return fmt.Sprintf("%s%sv=%d", photoURL, separator, version)
+}
+
 func (a *App) toOperatorReportPhotoViews(reportID int, photos []ReportPhoto) []OperatorReportPhotoView {
 	views := make([]OperatorReportPhotoView, 0, len(photos))
 	for _, photo := range photos {
 		views = append(views, OperatorReportPhotoView{
 			ID:        photo.ID,
-			URL:       a.buildOperatorReportPhotoURL(reportID, photo.ID),
+			URL:       withPhotoVersion(a.buildOperatorReportPhotoURL(reportID, photo.ID), photo.Version),
+			ThumbURL:  withPhotoVersion(a.buildOperatorReportPhotoVariantURL(reportID, photo.ID, photoVariantThumb), photo.Version),
+			MediumURL: withPhotoVersion(a.buildOperatorReportPhotoVariantURL(reportID, photo.ID, photoVariantMedium), photo.Version),
 			MimeType:  photo.MimeType,
 			Filename:  photo.Filename,
 			SizeBytes: photo.SizeBytes,
 			CreatedAt: photo.CreatedAt,
+			Kind:      photo.Kind,
 		})
 	}
 	return views
@@ -202,20 +235,28 @@ func (a *App) listEvents(ctx context.Context, reportID int) ([]ReportEvent, erro
 	return events, rows.Err()
 }
 
-func (a *App) selectBikeGroupForReport(ctx context.Context, payload ReportCreatePayload, now time.Time) (*BikeGroup, error) {
-	since := now.AddDate(0, 0, -signalLookbackDays).Format(time.RFC3339)
+// selectBikeGroupForReport returns the best-scoring open bike group for the
+// incoming report. When the best match nearby is a closed group it is returned
+// as the recurring location instead, so a new group can link back to it.
+func (a *App) selectBikeGroupForReport(ctx context.Context, payload ReportCreatePayload, now time.Time, params SignalParams) (*BikeGroup, *BikeGroup, error) {
+	return a.matchBikeGroup(ctx, Report{Location: payload.Location, Tags: payload.Tags}, now, params)
+}
+
+// matchBikeGroup scores incoming against recent reports like
+// selectBikeGroupForReport. An existing report is never matched against itself.
+func (a *App) matchBikeGroup(ctx context.Context, incoming Report, now time.Time, params SignalParams) (*BikeGroup, *BikeGroup, error) {
+	since := now.AddDate(0, 0, -params.SignalLookbackDays).Format(time.RFC3339)
 	reports, err := a.listReportsSince(ctx, since)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	bestScoreByGroup := make(map[int]float64)
 
-	incoming := Report{Location: payload.Location, Tags: payload.Tags}
 	for _, candidate := range reports {
-		if candidate.Status == "invalid" {
+		if candidate.Status == "invalid" || (incoming.ID != 0 && candidate.ID == incoming.ID) {
 			continue
 		}
-		score := scoreSignalGroupCandidate(incoming, candidate, now)
+		score := scoreSignalGroupCandidate(incoming, candidate, now, params)
 		if score == nil {
 			continue
 		}
@@ -224,7 +265,7 @@ func (a *App) selectBikeGroupForReport(ctx context.Context, payload ReportCreate
 		}
 	}
 	if len(bestScoreByGroup) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	type candidate struct {
@@ -236,24 +277,43 @@ func (a *App) selectBikeGroupForReport(ctx context.Context, payload ReportCreate
 		candidates = append(candidates, candidate{id: id, score: score})
 	}
 	sort.Slice(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
-	return a.getBikeGroupByID(ctx, candidates[0].id)
+
+	var recurring *BikeGroup
+	for _, candidate := range candidates {
+		group, err := a.getBikeGroupByID(ctx, candidate.id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if group == nil {
+			continue
+		}
+		if group.State == bikeGroupStateOpen {
+			return group, nil, nil
+		}
+		if recurring == nil {
+			recurring = group
+		}
+	}
+	return nil, recurring, nil
 }
 
-func (a *App) createBikeGroup(ctx context.Context, anchor ReportLocation) (BikeGroup, error) {
+const insertBikeGroupQuery = `
+	INSERT INTO bike_groups (
+		anchor_lat, anchor_lng, last_report_at, total_reports,
+		unique_reporters, same_reporter_reconfirmations,
+		distinct_reporter_reconfirmations,
+		first_qualifying_reconfirmation_at,
+		last_qualifying_reconfirmation_at,
+		signal_strength, state, previous_group_id
+	)
+	VALUES ($1, $2, $3, 0, 0, 0, 0, NULL, NULL, 'none', 'open', $4)
+	RETURNING id
+`
+
+func (a *App) createBikeGroup(ctx context.Context, anchor ReportLocation, previousGroupID *int) (BikeGroup, error) {
 	now := time.Now().UTC().Format(time.RFC3339)
 	var groupID int
-	err := a.db.QueryRowContext(ctx, `
-		INSERT INTO bike_groups (
-			anchor_lat, anchor_lng, last_report_at, total_reports,
-			unique_reporters, same_reporter_reconfirmations,
-			distinct_reporter_reconfirmations,
-			first_qualifying_reconfirmation_at,
-			last_qualifying_reconfirmation_at,
-			signal_strength
-		)
-		VALUES ($1, $2, $3, 0, 0, 0, 0, NULL, NULL, 'none')
-		RETURNING id
-	`, anchor.Lat, anchor.Lng, now).Scan(&groupID)
+	err := a.db.QueryRowContext(ctx, insertBikeGroupQuery, anchor.Lat, anchor.Lng, now, previousGroupID).Scan(&groupID)
 	if err != nil {
 		return BikeGroup{}, err
 	}
@@ -267,6 +327,14 @@ func (a *App) createBikeGroup(ctx context.Context, anchor ReportLocation) (BikeG
 	return *group, nil
 }
 
+// createBikeGroupTx inserts an empty open group inside tx and returns its id.
+func (a *App) createBikeGroupTx(ctx context.Context, tx *sql.Tx, anchor ReportLocation, previousGroupID *int) (int, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	var groupID int
+	err := tx.QueryRowContext(ctx, insertBikeGroupQuery, anchor.Lat, anchor.Lng, now, previousGroupID).Scan(&groupID)
+	return groupID, err
+}
+
 func (a *App) getBikeGroupByID(ctx context.Context, groupID int) (*BikeGroup, error) {
 	var group BikeGroup
 	var createdAt time.Time
@@ -274,6 +342,8 @@ func (a *App) getBikeGroupByID(ctx context.Context, groupID int) (*BikeGroup, er
 	var lastReportAt time.Time
 	var firstQual sql.NullTime
 	var lastQual sql.NullTime
+	var closedAt sql.NullTime
+	var previousGroupID sql.NullInt64
 	err := a.db.QueryRowContext(ctx, `
 		SELECT
 			id, anchor_lat, anchor_lng,
@@ -284,6 +354,10 @@ func (a *App) getBikeGroupByID(ctx context.Context, groupID int) (*BikeGroup, er
 			first_qualifying_reconfirmation_at,
 			last_qualifying_reconfirmation_at,
 			signal_strength,
+			signal_score,
+			state,
+			closed_at,
+			previous_group_id,
 			created_at,
 			updated_at
 		FROM bike_groups
@@ -300,6 +374,10 @@ func (a *App) getBikeGroupByID(ctx context.Context, groupID int) (*BikeGroup, er
 		&firstQual,
 		&lastQual,
 		&group.SignalStrength,
+		&group.SignalScore,
+		&group.State,
+		&closedAt,
+		&previousGroupID,
 		&createdAt,
 		&updatedAt,
 	)
@@ -320,6 +398,14 @@ func (a *App) getBikeGroupByID(ctx context.Context, groupID int) (*BikeGroup, er
 		value := lastQual.Time.UTC().Format(time.RFC3339)
 		group.LastQualifyingReconfirmationAt = &value
 	}
+	if closedAt.Valid {
+		value := closedAt.Time.UTC().Format(time.RFC3339)
+		group.ClosedAt = &value
+	}
+	if previousGroupID.Valid {
+		value := int(previousGroupID.Int64)
+		group.PreviousGroupID = &value
+	}
 	return &group, nil
 }
 
@@ -337,9 +423,10 @@ func (a *App) updateBikeGroup(ctx context.Context, group BikeGroup) error {
 			first_qualifying_reconfirmation_at = $8,
 			last_qualifying_reconfirmation_at = $9,
 			signal_strength = $10,
+			signal_score = $11,
 			updated_at = NOW()
-		WHERE id = $11
-	`, group.AnchorLat, group.AnchorLng, group.LastReportAt, group.TotalReports, group.UniqueReporters, group.SameReporterReconfirmations, group.DistinctReporterReconfirmations, group.FirstQualifyingReconfirmationAt, group.LastQualifyingReconfirmationAt, group.SignalStrength, group.ID)
+		WHERE id = $12
+	`, group.AnchorLat, group.AnchorLng, group.LastReportAt, group.TotalReports, group.UniqueReporters, group.SameReporterReconfirmations, group.DistinctReporterReconfirmations, group.FirstQualifyingReconfirmationAt, group.LastQualifyingReconfirmationAt, group.SignalStrength, group.SignalScore, group.ID)
 	return err
 }
 
@@ -397,7 +484,8 @@ const reportSelect = `
 		city,
 		postcode,
 		municipality,
-		user_id
+		user_id,
+		location_source
 	FROM reports
 `
 
@@ -436,6 +524,7 @@ func scanReport(scanner rowScanner) (Report, error) {
 		&post,
 		&muni,
 		&userID,
+		&report.LocationSource,
 	); err != nil {
 		return Report{}, err
 	}
@@ -558,10 +647,6 @@ func (a *App) buildStatusFilter(status string) bool {
 	return containsString(reportStatuses, status)
 }
 
-func (a *App) isProduction() bool {
-	return strings.EqualFold(a.cfg.Env, "production")
-}
-
 func (a *App) parseOperatorReportsFilters(c *gin.Context) map[string]any {
 	_ = c
 	return map[string]any{}
@@ -569,10 +654,8 @@ func (a *App) parseOperatorReportsFilters(c *gin.Context) map[string]any {
 
 func (a *App) operatorReportsSortSignal(reports []OperatorReportView) {
 	sort.Slice(reports, func(i, j int) bool {
-		left := signalStrengthPriority[reports[i].SignalStrength]
-		right := signalStrengthPriority[reports[j].SignalStrength]
-		if left != right {
-			return left > right
+		if reports[i].SignalScore != reports[j].SignalScore {
+			return reports[i].SignalScore > reports[j].SignalScore
 		}
 		return reports[i].CreatedAt > reports[j].CreatedAt
 	})
@@ -607,7 +690,7 @@ func (a *App) detectMimeType(data []byte, fallback string) string {
 			return mimeType
 		}
 	}
-	mimeType := a.cleanMimeType(http.DetectContentType(data))
+	mimeType := a.cleanMimeType(sniffPhotoMimeType(data))
 	if _, ok := allowedImageTypes[mimeType]; ok {
 		return mimeType
 	}
@@ -679,12 +762,3 @@ func (a *App) parseClientTimestamp(raw string) *string {
 }
 
 func (a *App) noop() {}
-
-func (a *App) updateReportDetails(ctx context.Context, reportID int, municipality, address, postcode string, lat, lng float64) error {
-	_, err := a.db.ExecContext(ctx, `
-		UPDATE reports
-		SET municipality = $1, address = $2, postcode = $3, lat = $4, lng = $5, updated_at = NOW()
-		WHERE id = $6
-	`, municipality, address, postcode, lat, lng, reportID)
-	return err
-}
@@ -0,0 +1,40 @@
+package main
+
+import "fmt"
+
+// Triage priority is a 0-100 score computed in SQL so that sorting and
+// filtering by it stay correct across pages. The weights below add up to 100.
+const (
+	priorityTagWeight      = 35.0
+	prioritySignalWeight   = 30.0
+	priorityAgeWeight      = 15.0
+	priorityFlaggedWeight  = 10.0
+	priorityHotspotWeight  = 10.0
+	prioritySignalScoreCap = 50.0
+	priorityAgeFullDays    = 30.0
+)
+
+// triagePrioritySQL scores a report row joined with its bike group as bg:
+//   - the highest weight among the report's tags (tags.weight)
+//   - the decayed signal score, full at prioritySignalScoreCap
+//   - the report's age, full at priorityAgeFullDays
+//   - whether the report is flagged for review
+//   - whether the report lies within a hotspot of its municipality, including
+//     a margin of hotspotEpsMeters around the hotspot radius
+var triagePrioritySQL = fmt.Sprintf(`(
+		%[1]g * COALESCE((SELECT MAX(t.weight) FROM tags t WHERE reports.tags::jsonb ? t.code), 0)
+		+ %[2]g * LEAST(%[3]s / %[4]g, 1)
+		+ %[5]g * LEAST(GREATEST(EXTRACT(EPOCH FROM (NOW() - reports.created_at)) / 86400.0, 0) / %[6]g, 1)
+		+ CASE WHEN reports.flagged_for_review THEN %[7]g ELSE 0 END
+		+ CASE WHEN EXISTS (
+			SELECT 1 FROM hotspots h
+			WHERE LOWER(h.municipality) = LOWER(reports.municipality)
+				AND %[9]g * SQRT(POWER(reports.lat - h.center_lat, 2) + POWER((reports.lng - h.center_lng) * COS(RADIANS(h.center_lat)), 2)) <= h.radius_m + %[10]g
+		) THEN %[8]g ELSE 0 END
+	)`,
+	priorityTagWeight,
+	prioritySignalWeight, signalScoreSQL, prioritySignalScoreCap,
+	priorityAgeWeight, priorityAgeFullDays,
+	priorityFlaggedWeight,
+	priorityHotspotWeight, metersPerDegreeLat, hotspotEpsMeters,
+)
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminTriagePrioritySortAndThreshold(t *testing.T) {
+	app, router := newAdminTestServer(t)
+	var capturedFilters map[string]any
+	app.adminListPaginatedReports = func(ctx context.Context, filters map[string]any, page, pageSize int) (*PaginatedOperatorReports, error) {
+		capturedFilters = filters
+		return &PaginatedOperatorReports{
+			Reports:     []OperatorReportView{{Report: Report{ID: 4, PublicID: "PUB-4", Status: "new"}, PriorityScore: 72.456}},
+			TotalCount:  1,
+			TotalPages:  1,
+			CurrentPage: page,
+			PageSize:    pageSize,
+		}, nil
+	}
+
+	rec := httptest.NewRecorder()
+	req := authenticatedRequest(t, app, http.MethodGet, "/bikeadmin?sort=priority&min_priority=40", "")
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rec.Code)
+	}
+	if capturedFilters["sort"] != "priority" {
+		t.Fatalf("expected sort priority, got %#v", capturedFilters["sort"])
+	}
+	if capturedFilters["min_priority"] != 40.0 {
+		t.Fatalf("expected min_priority 40, got %#v", capturedFilters["min_priority"])
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "<td>72.5</td>") {
+		t.Fatalf("expected priority score column in page")
+	}
+	if !strings.Contains(body, `name="min_priority" value="40"`) {
+		t.Fatalf("expected threshold to be kept in the filter form")
+	}
+
+	for _, threshold := range []string{"250", "NaN"} {
+		rec = httptest.NewRecorder()
+		req = authenticatedRequest(t, app, http.MethodGet, "/bikeadmin?min_priority="+threshold, "")
+		router.ServeHTTP(rec, req)
+		if _, ok := capturedFilters["min_priority"]; ok {
+			t.Fatalf("expected threshold %s to be ignored, got %#v", threshold, capturedFilters["min_priority"])
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"zwerffiets/libs/mailer"
+
+	"github.com/gin-gonic/gin"
+)
+
+const adminTemplatePrivacyPath = "templates/admin/privacy.tmpl"
+
+// accountDeletionContent holds per-language email content for the account
+// deletion confirmation link.
+var accountDeletionContent = map[string]struct {
+	Subject  string
+	BodyHTML string // format args: mode description, confirmURL
+	BodyText string // format args: mode description, confirmURL
+	Modes    map[string]string
+}{
+	"nl": {
+		Subject:  "Bevestig het verwijderen van je ZwerfFiets account",
+		BodyHTML: `<p>Je hebt gevraagd je ZwerfFiets account te verwijderen. %s</p><p><a href="%s">Account definitief verwijderen</a></p><p>De link is 1 uur geldig. Heb je dit niet zelf gevraagd, dan kun je deze e-mail negeren.</p>`,
+		BodyText: "Je hebt gevraagd je ZwerfFiets account te verwijderen. %s\n\nBevestig via: %s\n\nDe link is 1 uur geldig. Heb je dit niet zelf gevraagd, dan kun je deze e-mail negeren.",
+		Modes: map[string]string{
+			accountDeletionAnonymize: "Je meldingen blijven bestaan, maar zonder je e-mailadres of andere gegevens die naar jou verwijzen.",
+			accountDeletionDelete:    "Je meldingen en foto's worden ook verwijderd, behalve meldingen die we wettelijk moeten bewaren; die worden geanonimiseerd.",
+		},
+	},
+	"en": {
+		Subject:  "Confirm deleting your ZwerfFiets account",
+		BodyHTML: `<p>You asked us to delete your ZwerfFiets account. %s</p><p><a href="%s">Delete my account</a></p><p>This link is valid for 1 hour. If you did not ask for this, you can ignore this email.</p>`,
+		BodyText: "You asked us to delete your ZwerfFiets account. %s\n\nConfirm at: %s\n\nThis link is valid for 1 hour. If you did not ask for this, you can ignore this email.",
+		Modes: map[string]string{
+			accountDeletionAnonymize: "Your reports are kept, but without your email address or anything else that points to you.",
+			accountDeletionDelete:    "Your reports and photos are deleted too, except reports we are legally required to keep; those are anonymized.",
+		},
+	},
+}
+
+// userDataExportHandler streams a zip archive of everything linked to the
+// signed-in account.
+func (a *App) userDataExportHandler(c *gin.Context) {
+	session, err := getUserSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User session required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	export, err := a.userDataExport(ctx, session.UserID)
+	if err != nil {
+		a.log.Error("failed to load user data export", "user_id", session.UserID, "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to export data"})
+		return
+	}
+	if export == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Account not found"})
+		return
+	}
+
+	// Build the archive before writing headers, so a storage error still
+	// turns into a JSON error instead of a truncated download.
+	now := time.Now().UTC()
+	var archive bytes.Buffer
+	if err := a.writeUserDataArchive(ctx, &archive, export, now); err != nil {
+		a.log.Error("failed to write user data archive", "user_id", session.UserID, "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to export data"})
+		return
+	}
+
+	if err := a.logDataSubjectRequest(ctx, DataSubjectRequest{
+		UserID:    export.User.ID,
+		EmailHash: a.hashDataSubjectEmail(export.User.Email),
+		Kind:      dataSubjectRequestExport,
+		Detail:    map[string]any{"reports": len(export.Reports)},
+	}); err != nil {
+		a.log.Error("failed to log data export", "user_id", session.UserID, "err", err)
+	}
+
+	filename := fmt.Sprintf("zwerffiets-data-%s.zip", now.Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", archive.Bytes())
+}
+
+// userDeletionRequestHandler mails a confirmation link; nothing is deleted
+// until that link is used.
+func (a *App) userDeletionRequestHandler(c *gin.Context) {
+	session, err := getUserSession(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "User session required"})
+		return
+	}
+
+	var payload struct {
+		Mode     string `json:"mode"`
+		Language string `json:"language"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_payload", "message": "Invalid request payload"})
+		return
+	}
+	if !isValidAccountDeletionMode(payload.Mode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_mode", "message": "Mode must be anonymize or delete"})
+		return
+	}
+	content, ok := accountDeletionContent[payload.Language]
+	if !ok {
+		content = accountDeletionContent["nl"]
+	}
+
+	ctx := c.Request.Context()
+	token, err := a.accountDeletionToken(ctx, session.UserID, payload.Mode)
+	if err != nil {
+		a.log.Error("failed to create account deletion token", "user_id", session.UserID, "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to process request"})
+		return
+	}
+
+	confirmURL := fmt.Sprintf("%s/account/delete?token=%s", a.cfg.PublicBaseURL, token)
+	msg := mailer.Message{
+		To:      []string{session.Email},
+		Subject: content.Subject,
+		HTML:    fmt.Sprintf(content.BodyHTML, content.Modes[payload.Mode], confirmURL),
+		Text:    fmt.Sprintf(content.BodyText, content.Modes[payload.Mode], confirmURL),
+	}
+	result, err := a.mailer.Send(msg)
+	if err != nil {
+		a.log.Error("failed to send account deletion email", "user_id", session.UserID, "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Failed to send email"})
+		return
+	}
+
+	if err := a.logDataSubjectRequest(ctx, DataSubjectRequest{
+		UserID:    session.UserID,
+		EmailHash: a.hashDataSubjectEmail(session.Email),
+		Kind:      dataSubjectRequestDeletionRequested,
+		Mode:      payload.Mode,
+	}); err != nil {
+		a.log.Error("failed to log account deletion request", "user_id", session.UserID, "err", err)
+	}
+
+	a.log.Info("account deletion link sent", "user_id", session.UserID, "mode", payload.Mode, "message_id", result.ProviderMessageID)
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func (a *App) userDeletionConfirmHandler(c *gin.Context) {
+	var payload struct {
+		Token string `json:"token"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || strings.TrimSpace(payload.Token) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing_token", "message": "Token required"})
+		return
+	}
+
+	result, err := a.confirmDeletion(c.Request.Context(), strings.TrimSpace(payload.Token))
+	if err != nil {
+		var apiErr *apiError
+		if !errors.As(err, &apiErr) {
+			a.log.Error("failed to delete account", "err", err)
+		}
+		writeAPIError(c, err)
+		return
+	}
+
+	secure := strings.EqualFold(a.cfg.Env, "production")
+	c.SetCookie(userCookieName, "", -1, "/", "", secure, true)
+	c.JSON(http.StatusOK, result)
+}
+
+type adminPrivacyViewData struct {
+	adminBaseViewData
+	Email   string
+	Entries []adminDataSubjectRequestRow
+}
+
+type adminDataSubjectRequestRow struct {
+	CreatedAt string
+	UserID    int
+	EmailHash string
+	Kind      string
+	Mode      string
+	Detail    string
+}
+
+// adminPrivacyPageHandler shows the processing log of citizen data requests.
+// The log only holds e-mail hashes, so a search address is hashed first.
+func (a *App) adminPrivacyPageHandler(c *gin.Context) {
+	lang := a.adminLanguageFromRequest(c)
+	base := a.adminBaseData(c, "page_title_privacy", "privacy")
+	data := adminPrivacyViewData{adminBaseViewData: base, Email: strings.TrimSpace(c.Query("email"))}
+
+	emailHash := ""
+	if data.Email != "" {
+		emailHash = a.hashDataSubjectEmail(data.Email)
+	}
+	entries, err := a.dataSubjectRequests(c.Request.Context(), emailHash)
+	if err != nil {
+		a.log.Error("failed to list data subject requests", "err", err)
+		data.ErrorMessage = adminText(lang, "error_privacy_log_load_failed")
+		a.renderAdminTemplate(c, http.StatusInternalServerError, adminTemplatePrivacyPath, data)
+		return
+	}
+
+	data.Entries = make([]adminDataSubjectRequestRow, 0, len(entries))
+	for _, entry := range entries {
+		row := adminDataSubjectRequestRow{
+			CreatedAt: formatAdminTimestamp(entry.CreatedAt),
+			UserID:    entry.UserID,
+			EmailHash: entry.EmailHash,
+			Kind:      adminText(lang, "privacy_kind_"+entry.Kind),
+			Mode:      "—",
+			Detail:    formatDataSubjectRequestDetail(lang, entry),
+		}
+		if len(row.EmailHash) > 12 {
+			row.EmailHash = row.EmailHash[:12]
+		}
+		if entry.Mode != "" {
+			row.Mode = adminText(lang, "privacy_mode_"+entry.Mode)
+		}
+		data.Entries = append(data.Entries, row)
+	}
+	a.renderAdminTemplate(c, http.StatusOK, adminTemplatePrivacyPath, data)
+}
+
+func formatDataSubjectRequestDetail(lang string, entry DataSubjectRequest) string {
+	count := func(key string) int {
+		switch value := entry.Detail[key].(type) {
+		case float64:
+			return int(value)
+		case int:
+			return value
+		}
+		return 0
+	}
+	switch entry.Kind {
+	case dataSubjectRequestExport:
+		return fmt.Sprintf("%d %s", count("reports"), adminText(lang, "privacy_detail_reports"))
+	case dataSubjectRequestDeletionCompleted:
+		return fmt.Sprintf("%d %s, %d %s, %d %s",
+			count("deleted_reports"), adminText(lang, "privacy_detail_deleted"),
+			count("anonymized_reports"), adminText(lang, "privacy_detail_anonymized"),
+			count("held_reports"), adminText(lang, "privacy_detail_held"))
+	}
+	return ""
+}
@@ -0,0 +1,29 @@
+import { describe, expect, it } from 'vitest';
+import {
+  accountDeletionConfirmBody,
+  accountDeletionRequestBody,
+  accountDeletionResultKey,
+  USER_DATA_EXPORT_ENDPOINT,
+  USER_DELETION_CONFIRM_ENDPOINT,
+  USER_DELETION_ENDPOINT
+} from '$lib/client/account-data';
+
+describe('account data helpers', () => {
+  it('uses the expected account data endpoints', () => {
+    expect(USER_DATA_EXPORT_ENDPOINT).toBe('/api/v1/user/export');
+    expect(USER_DELETION_ENDPOINT).toBe('/api/v1/user/deletion');
+    expect(USER_DELETION_CONFIRM_ENDPOINT).toBe('/api/v1/user/deletion/confirm');
+  });
+
+  it('builds deletion request bodies', () => {
+    expect(JSON.parse(accountDeletionRequestBody('delete', 'en'))).toEqual({ mode: 'delete', language: 'en' });
+    expect(JSON.parse(accountDeletionConfirmBody('abc'))).toEqual({ token: 'abc' });
+  });
+
+  it('picks the confirmation message for the deletion result', () => {
+    const result = { mode: 'delete' as const, deletedReports: 2, anonymizedReports: 0, heldReports: 0 };
+    expect(accountDeletionResultKey(result)).toBe('account_delete_done_deleted');
+    expect(accountDeletionResultKey({ ...result, mode: 'anonymize' })).toBe('account_delete_done_anonymized');
+    expect(accountDeletionResultKey({ ...result, anonymizedReports: 1, heldReports: 1 })).toBe('account_delete_done_held');
+  });
+});
@@ -0,0 +1,32 @@
+import type { UiLanguage } from '$lib/i18n/translations';
+
+export const USER_DATA_EXPORT_ENDPOINT = '/api/v1/user/export';
+export const USER_DELETION_ENDPOINT = '/api/v1/user/deletion';
+export const USER_DELETION_CONFIRM_ENDPOINT = '/api/v1/user/deletion/confirm';
+
+export type AccountDeletionMode = 'anonymize' | 'delete';
+
+export interface AccountDeletionResult {
+  mode: AccountDeletionMode;
+  deletedReports: number;
+  anonymizedReports: number;
+  heldReports: number;
+}
+
+export const accountDeletionRequestBody = (
+  mode: AccountDeletionMode,
+  language: UiLanguage
+): string => {
+  return JSON.stringify({ mode, language });
+};
+
+export const accountDeletionConfirmBody = (token: string): string => {
+  return JSON.stringify({ token });
+};
+
+export const accountDeletionResultKey = (result: AccountDeletionResult): string => {
+  if (result.heldReports > 0) {
+    return 'account_delete_done_held';
+  }
+  return result.mode === 'delete' ? 'account_delete_done_deleted' : 'account_delete_done_anonymized';
+};
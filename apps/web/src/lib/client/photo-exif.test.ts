@@ -0,0 +1,40 @@
+import { describe, expect, it } from 'vitest';
+import { buildExifSegment, insertExifSegment, readPhotoExif } from '$lib/client/photo-exif';
+
+// SOI, a JFIF APP0 segment and EOI: enough structure for the EXIF helpers.
+const minimalJpeg = Uint8Array.from([
+  0xff, 0xd8, 0xff, 0xe0, 0x00, 0x10, 0x4a, 0x46, 0x49, 0x46, 0x00, 0x01, 0x01, 0x00, 0x00, 0x01,
+  0x00, 0x01, 0x00, 0x00, 0xff, 0xd9
+]);
+
+describe('photo exif', () => {
+  it('returns null for a JPEG without EXIF', () => {
+    expect(readPhotoExif(minimalJpeg)).toBeNull();
+  });
+
+  it('round-trips GPS position and capture time through a written segment', () => {
+    const jpeg = insertExifSegment(
+      minimalJpeg,
+      buildExifSegment({
+        lat: 52.090737,
+        lng: -5.12142,
+        dateTimeOriginal: '2026:10:17 14:03:22',
+        offsetTimeOriginal: '+02:00'
+      })
+    );
+
+    const exif = readPhotoExif(jpeg);
+    expect(exif?.lat).toBeCloseTo(52.090737, 6);
+    expect(exif?.lng).toBeCloseTo(-5.12142, 6);
+    expect(exif?.dateTimeOriginal).toBe('2026:10:17 14:03:22');
+    expect(exif?.offsetTimeOriginal).toBe('+02:00');
+  });
+
+  it('places the EXIF segment after the JFIF header', () => {
+    const jpeg = insertExifSegment(minimalJpeg, buildExifSegment({ lat: -33.5, lng: 151.25 }));
+
+    expect(Array.from(jpeg.subarray(20, 22))).toEqual([0xff, 0xe1]);
+    expect(Array.from(jpeg.subarray(jpeg.length - 2))).toEqual([0xff, 0xd9]);
+    expect(readPhotoExif(jpeg)).toEqual({ lat: -33.5, lng: 151.25 });
+  });
+});
@@ -0,0 +1,346 @@
+// Canvas re-encoding drops all metadata. The API compares a photo's EXIF GPS
+// position and capture time with the report, so the few tags it reads are
+// copied from the original JPEG into the re-encoded one. Orientation is not
+// copied: the canvas has already applied it.
+
+const TAG_EXIF_IFD = 0x8769;
+const TAG_GPS_IFD = 0x8825;
+const TAG_DATE_TIME = 0x0132;
+const TAG_DATE_TIME_ORIGINAL = 0x9003;
+const TAG_OFFSET_TIME_ORIGINAL = 0x9011;
+const TAG_GPS_LATITUDE_REF = 0x0001;
+const TAG_GPS_LATITUDE = 0x0002;
+const TAG_GPS_LONGITUDE_REF = 0x0003;
+const TAG_GPS_LONGITUDE = 0x0004;
+
+const TYPE_ASCII = 2;
+const TYPE_SHORT = 3;
+const TYPE_LONG = 4;
+const TYPE_RATIONAL = 5;
+
+const EXIF_HEADER = [0x45, 0x78, 0x69, 0x66, 0x00, 0x00]; // "Exif\0\0"
+const SECONDS_DENOMINATOR = 10000;
+
+export interface PhotoExif {
+  // Decimal degrees; south and west are negative.
+  lat?: number;
+  lng?: number;
+  // EXIF "YYYY:MM:DD HH:MM:SS" and optional "+HH:MM", copied verbatim.
+  dateTimeOriginal?: string;
+  offsetTimeOriginal?: string;
+}
+
+interface IfdEntry {
+  type: number;
+  count: number;
+  valueOffset: number;
+}
+
+const findJpegExif = (bytes: Uint8Array): DataView | null => {
+  if (bytes.length < 4 || bytes[0] !== 0xff || bytes[1] !== 0xd8) {
+    return null;
+  }
+  let offset = 2;
+  while (offset + 4 <= bytes.length) {
+    if (bytes[offset] !== 0xff) {
+      return null;
+    }
+    const marker = bytes[offset + 1];
+    if (marker === 0xff) {
+      offset += 1;
+      continue;
+    }
+    if (marker === 0xda || marker === 0xd9) {
+      return null;
+    }
+    const length = (bytes[offset + 2] << 8) | bytes[offset + 3];
+    if (length < 2 || offset + 2 + length > bytes.length) {
+      return null;
+    }
+    const segmentStart = offset + 4;
+    if (
+      marker === 0xe1 &&
+      length >= 2 + EXIF_HEADER.length + 8 &&
+      EXIF_HEADER.every((value, index) => bytes[segmentStart + index] === value)
+    ) {
+      const tiffStart = segmentStart + EXIF_HEADER.length;
+      const tiffLength = offset + 2 + length - tiffStart;
+      return new DataView(bytes.buffer, bytes.byteOffset + tiffStart, tiffLength);
+    }
+    offset += 2 + length;
+  }
+  return null;
+};
+
+const typeSize = (type: number): number => {
+  switch (type) {
+    case TYPE_ASCII:
+      return 1;
+    case TYPE_SHORT:
+      return 2;
+    case TYPE_LONG:
+      return 4;
+    case TYPE_RATIONAL:
+      return 8;
+    default:
+      return 0;
+  }
+};
+
+const readIfd = (view: DataView, offset: number, little: boolean): Map<number, IfdEntry> => {
+  const entries = new Map<number, IfdEntry>();
+  if (offset < 8 || offset + 2 > view.byteLength) {
+    return entries;
+  }
+  const count = view.getUint16(offset, little);
+  for (let index = 0; index < count; index += 1) {
+    const entryOffset = offset + 2 + index * 12;
+    if (entryOffset + 12 > view.byteLength) {
+      break;
+    }
+    const type = view.getUint16(entryOffset + 2, little);
+    const valueCount = view.getUint32(entryOffset + 4, little);
+    const size = typeSize(type) * valueCount;
+    if (size === 0) {
+      continue;
+    }
+    const valueOffset = size <= 4 ? entryOffset + 8 : view.getUint32(entryOffset + 8, little);
+    if (valueOffset + size > view.byteLength) {
+      continue;
+    }
+    entries.set(view.getUint16(entryOffset, little), { type, count: valueCount, valueOffset });
+  }
+  return entries;
+};
+
+const readAscii = (view: DataView, entry: IfdEntry | undefined): string | undefined => {
+  if (!entry || entry.type !== TYPE_ASCII) {
+    return undefined;
+  }
+  let text = '';
+  for (let index = 0; index < entry.count; index += 1) {
+    const code = view.getUint8(entry.valueOffset + index);
+    if (code === 0) {
+      break;
+    }
+    text += String.fromCharCode(code);
+  }
+  text = text.trim();
+  return text === '' ? undefined : text;
+};
+
+const readPointer = (view: DataView, entry: IfdEntry | undefined, little: boolean): number => {
+  if (entry?.type === TYPE_LONG) {
+    return view.getUint32(entry.valueOffset, little);
+  }
+  if (entry?.type === TYPE_SHORT) {
+    return view.getUint16(entry.valueOffset, little);
+  }
+  return 0;
+};
+
+const readCoordinate = (
+  view: DataView,
+  value: IfdEntry | undefined,
+  ref: IfdEntry | undefined,
+  negativeRef: string,
+  little: boolean
+): number | undefined => {
+  if (!value || value.type !== TYPE_RATIONAL || value.count < 3) {
+    return undefined;
+  }
+  const parts: number[] = [];
+  for (let index = 0; index < 3; index += 1) {
+    const numerator = view.getUint32(value.valueOffset + index * 8, little);
+    const denominator = view.getUint32(value.valueOffset + index * 8 + 4, little);
+    if (denominator === 0) {
+      return undefined;
+    }
+    parts.push(numerator / denominator);
+  }
+  const degrees = parts[0] + parts[1] / 60 + parts[2] / 3600;
+  return readAscii(view, ref)?.toUpperCase() === negativeRef ? -degrees : degrees;
+};
+
+// readPhotoExif returns the GPS position and capture time of a JPEG, or null
+// when it has neither.
+export const readPhotoExif = (bytes: Uint8Array): PhotoExif | null => {
+  const view = findJpegExif(bytes);
+  if (!view) {
+    return null;
+  }
+  const byteOrder = String.fromCharCode(view.getUint8(0), view.getUint8(1));
+  if (byteOrder !== 'II' && byteOrder !== 'MM') {
+    return null;
+  }
+  const little = byteOrder === 'II';
+  if (view.getUint16(2, little) !== 42) {
+    return null;
+  }
+
+  const ifd0 = readIfd(view, view.getUint32(4, little), little);
+  const exif: PhotoExif = {};
+
+  const exifIfd = readIfd(view, readPointer(view, ifd0.get(TAG_EXIF_IFD), little), little);
+  exif.dateTimeOriginal =
+    readAscii(view, exifIfd.get(TAG_DATE_TIME_ORIGINAL)) ??
+    readAscii(view, ifd0.get(TAG_DATE_TIME));
+  exif.offsetTimeOriginal = readAscii(view, exifIfd.get(TAG_OFFSET_TIME_ORIGINAL));
+
+  const gps = readIfd(view, readPointer(view, ifd0.get(TAG_GPS_IFD), little), little);
+  const lat = readCoordinate(
+    view,
+    gps.get(TAG_GPS_LATITUDE),
+    gps.get(TAG_GPS_LATITUDE_REF),
+    'S',
+    little
+  );
+  const lng = readCoordinate(
+    view,
+    gps.get(TAG_GPS_LONGITUDE),
+    gps.get(TAG_GPS_LONGITUDE_REF),
+    'W',
+    little
+  );
+  if (lat !== undefined && lng !== undefined && Math.abs(lat) <= 90 && Math.abs(lng) <= 180) {
+    exif.lat = lat;
+    exif.lng = lng;
+  }
+
+  if (exif.lat === undefined && exif.dateTimeOriginal === undefined) {
+    return null;
+  }
+  return exif;
+};
+
+interface OutputEntry {
+  tag: number;
+  type: number;
+  count: number;
+  // Big-endian value bytes; four bytes or fewer are stored inline.
+  value: number[];
+}
+
+const uint32Bytes = (value: number): number[] => [
+  (value >>> 24) & 0xff,
+  (value >>> 16) & 0xff,
+  (value >>> 8) & 0xff,
+  value & 0xff
+];
+
+const asciiEntry = (tag: number, text: string): OutputEntry => {
+  const value = [...Array.from(text, (char) => char.charCodeAt(0) & 0x7f), 0];
+  return { tag, type: TYPE_ASCII, count: value.length, value };
+};
+
+const pointerEntry = (tag: number, offset: number): OutputEntry => ({
+  tag,
+  type: TYPE_LONG,
+  count: 1,
+  value: uint32Bytes(offset)
+});
+
+const coordinateEntry = (tag: number, decimal: number): OutputEntry => {
+  const absolute = Math.abs(decimal);
+  const degrees = Math.floor(absolute);
+  const minutes = Math.floor((absolute - degrees) * 60);
+  const seconds = Math.round(((absolute - degrees) * 60 - minutes) * 60 * SECONDS_DENOMINATOR);
+  const value = [
+    ...uint32Bytes(degrees),
+    ...uint32Bytes(1),
+    ...uint32Bytes(minutes),
+    ...uint32Bytes(1),
+    ...uint32Bytes(seconds),
+    ...uint32Bytes(SECONDS_DENOMINATOR)
+  ];
+  return { tag, type: TYPE_RATIONAL, count: 3, value };
+};
+
+const ifdSize = (entries: OutputEntry[]): number =>
+  entries.reduce(
+    (size, entry) => size + 12 + (entry.value.length > 4 ? entry.value.length : 0),
+    2 + 4
+  );
+
+// writeIfd appends a big-endian IFD that starts at offset within the TIFF
+// payload, with its out-of-line values right behind it.
+const writeIfd = (out: number[], entries: OutputEntry[], offset: number): void => {
+  let dataOffset = offset + 2 + entries.length * 12 + 4;
+  const data: number[] = [];
+  out.push((entries.length >> 8) & 0xff, entries.length & 0xff);
+  for (const entry of entries) {
+    out.push((entry.tag >> 8) & 0xff, entry.tag & 0xff, 0, entry.type, ...uint32Bytes(entry.count));
+    if (entry.value.length <= 4) {
+      out.push(...entry.value, ...new Array<number>(4 - entry.value.length).fill(0));
+      continue;
+    }
+    out.push(...uint32Bytes(dataOffset));
+    data.push(...entry.value);
+    dataOffset += entry.value.length;
+  }
+  out.push(0, 0, 0, 0);
+  out.push(...data);
+};
+
+// buildExifSegment returns a JPEG APP1 segment holding only the given tags.
+export const buildExifSegment = (exif: PhotoExif): Uint8Array => {
+  const exifEntries: OutputEntry[] = [];
+  if (exif.dateTimeOriginal) {
+    exifEntries.push(asciiEntry(TAG_DATE_TIME_ORIGINAL, exif.dateTimeOriginal));
+  }
+  if (exif.offsetTimeOriginal) {
+    exifEntries.push(asciiEntry(TAG_OFFSET_TIME_ORIGINAL, exif.offsetTimeOriginal));
+  }
+  const gpsEntries: OutputEntry[] = [];
+  if (exif.lat !== undefined && exif.lng !== undefined) {
+    gpsEntries.push(
+      asciiEntry(TAG_GPS_LATITUDE_REF, exif.lat < 0 ? 'S' : 'N'),
+      coordinateEntry(TAG_GPS_LATITUDE, exif.lat),
+      asciiEntry(TAG_GPS_LONGITUDE_REF, exif.lng < 0 ? 'W' : 'E'),
+      coordinateEntry(TAG_GPS_LONGITUDE, exif.lng)
+    );
+  }
+
+  // IFD0 only points at the other two directories, which follow it.
+  const ifd0Entries: OutputEntry[] = [];
+  const ifd0Offset = 8;
+  const pointerCount = (exifEntries.length > 0 ? 1 : 0) + (gpsEntries.length > 0 ? 1 : 0);
+  let nextOffset = ifd0Offset + 2 + pointerCount * 12 + 4;
+  if (exifEntries.length > 0) {
+    ifd0Entries.push(pointerEntry(TAG_EXIF_IFD, nextOffset));
+    nextOffset += ifdSize(exifEntries);
+  }
+  if (gpsEntries.length > 0) {
+    ifd0Entries.push(pointerEntry(TAG_GPS_IFD, nextOffset));
+  }
+
+  const tiff: number[] = [0x4d, 0x4d, 0x00, 0x2a, ...uint32Bytes(ifd0Offset)];
+  writeIfd(tiff, ifd0Entries, ifd0Offset);
+  if (exifEntries.length > 0) {
+    writeIfd(tiff, exifEntries, tiff.length);
+  }
+  if (gpsEntries.length > 0) {
+    writeIfd(tiff, gpsEntries, tiff.length);
+  }
+
+  const length = 2 + EXIF_HEADER.length + tiff.length;
+  const header = [0xff, 0xe1, (length >> 8) & 0xff, length & 0xff, ...EXIF_HEADER];
+  return Uint8Array.from([...header, ...tiff]);
+};
+
+// insertExifSegment places segment after the SOI marker and a leading JFIF
+// APP0 segment, where readers look for it.
+export const insertExifSegment = (jpeg: Uint8Array, segment: Uint8Array): Uint8Array => {
+  if (jpeg.length < 4 || jpeg[0] !== 0xff || jpeg[1] !== 0xd8) {
+    return jpeg;
+  }
+  let offset = 2;
+  if (jpeg[2] === 0xff && jpeg[3] === 0xe0 && jpeg.length >= 6) {
+    offset = 4 + ((jpeg[4] << 8) | jpeg[5]);
+  }
+  const result = new Uint8Array(jpeg.length + segment.length);
+  result.set(jpeg.subarray(0, offset), 0);
+  result.set(segment, offset);
+  result.set(jpeg.subarray(offset), offset + segment.length);
+  return result;
+};
@@ -7,6 +7,7 @@ import {
   PHOTO_UPLOAD_QUALITY_STEP,
   PHOTO_UPLOAD_TARGET_MAX_BYTES
 } from '$lib/constants';
+import { buildExifSegment, insertExifSegment, readPhotoExif } from '$lib/client/photo-exif';
 
 export interface PhotoNormalizationCandidate {
   width: number;
@@ -153,6 +154,7 @@ export const normalizePhotoForUpload = async (
     throw new Error('Only image files are supported');
   }
 
+  const exif = readPhotoExif(new Uint8Array(await file.arrayBuffer()));
   const image = await loadImage(file);
   const candidates = buildNormalizationCandidates(image.naturalWidth, image.naturalHeight, config);
 
@@ -174,6 +176,14 @@ export const normalizePhotoForUpload = async (
     throw new Error('Failed to encode image');
   }
 
+  // The re-encode drops EXIF; carry over what the API checks the report against.
+  if (exif) {
+    const encoded = new Uint8Array(await bestBlob.arrayBuffer());
+    bestBlob = new Blob([insertExifSegment(encoded, buildExifSegment(exif))], {
+      type: 'image/jpeg'
+    });
+  }
+
   return new File([bestBlob], toJpegFileName(file.name), {
     type: 'image/jpeg',
     lastModified: Date.now()
@@ -30,6 +30,15 @@ describe('report submit failure resolver', () => {
     expect(failure.messageKey).toBe('report_error_rate_limited');
   });
 
+  it('surfaces locations outside the service area as a dedicated message', () => {
+    const failure = resolveReportSubmitFailure(
+      new ApiRequestError(400, 'out_of_service_area', 'Location is outside the service area')
+    );
+
+    expect(failure.queueOffline).toBe(false);
+    expect(failure.messageKey).toBe('report_error_out_of_service_area');
+  });
+
   it('queues offline for network failures', () => {
     const failure = resolveReportSubmitFailure(new TypeError('Failed to fetch'));
 
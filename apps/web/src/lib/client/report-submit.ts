@@ -14,6 +14,12 @@ export const resolveReportSubmitFailure = (error: unknown): ReportSubmitFailure
         messageKey: 'report_error_location_accuracy'
       };
     }
+    if (error.code === 'out_of_service_area') {
+      return {
+        queueOffline: false,
+        messageKey: 'report_error_out_of_service_area'
+      };
+    }
     if (error.code === 'rate_limited') {
       return {
         queueOffline: false,
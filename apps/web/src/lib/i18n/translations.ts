@@ -46,6 +46,8 @@ const nl = {
   landing_showcase_fig3_caption: 'Stationsbuurt — 5 dagen geleden gemeld',
   landing_showcase_fig4_alt: 'Verlaten fiets in de wijk',
   landing_showcase_fig4_caption: 'Rivierenbuurt — gisteren gemeld',
+  landing_showcase_before: 'Voor',
+  landing_showcase_after: 'Na',
   landing_showcase_cta_title: 'Werk samen met uw gemeente',
   landing_showcase_cta_desc:
     'ZwerfFiets biedt een gratis dashboard voor gemeentelijke medewerkers om meldingen te beheren en op te volgen.',
@@ -151,7 +153,7 @@ const nl = {
   privacy_gdpr_label: 'AVG / GDPR',
   privacy_gdpr_title: 'Wij respecteren uw rechten',
   privacy_gdpr_text:
-    'Als inwoner van de EU heeft u recht op inzage, correctie en verwijdering van uw persoonsgegevens. Omdat meldingen standaard anoniem zijn, zijn er in de meeste gevallen geen persoonsgegevens gekoppeld aan uw melding. Als u een e-mailadres heeft opgegeven en dit wilt laten verwijderen, kunt u contact met ons opnemen.',
+    'Als inwoner van de EU heeft u recht op inzage, correctie en verwijdering van uw persoonsgegevens. Omdat meldingen standaard anoniem zijn, zijn er in de meeste gevallen geen persoonsgegevens gekoppeld aan uw melding. Heeft u een account, dan kunt u onder Mijn meldingen uw gegevens downloaden of uw account verwijderen.',
   privacy_contact_label: 'CONTACT',
   privacy_contact_title: 'Vragen over privacy?',
   privacy_contact_text:
@@ -189,6 +191,8 @@ const nl = {
     'Locatienauwkeurigheid is te laag voor deze melding. Probeer buiten opnieuw met betere GPS-ontvangst.',
   report_error_rate_limited:
     'Je doet te veel meldingen in korte tijd. Wacht even en probeer opnieuw.',
+  report_error_out_of_service_area:
+    'Deze locatie ligt buiten Nederland. ZwerfFiets neemt alleen meldingen binnen het werkgebied aan.',
   report_error_submit_failed:
     'Melding kon niet worden verstuurd. Controleer je invoer en probeer opnieuw.',
   report_error_offline:
@@ -210,6 +214,25 @@ const nl = {
   my_reports_view_details: 'Bekijk details',
   my_reports_check_status: 'Controleer status',
   my_reports_logout: 'Uitloggen',
+  account_data_title: 'Mijn gegevens',
+  account_data_export_hint: 'Download een bestand met je account, je meldingen, hun geschiedenis en je foto\'s.',
+  account_data_export_button: 'Mijn gegevens downloaden',
+  account_delete_title: 'Account verwijderen',
+  account_delete_hint: 'Kies wat er met je meldingen gebeurt. We sturen je een e-mail met een link om het verwijderen te bevestigen.',
+  account_delete_mode_anonymize: 'Meldingen bewaren zonder mijn gegevens',
+  account_delete_mode_delete: 'Meldingen en foto\'s ook verwijderen',
+  account_delete_request_button: 'Verwijderlink versturen',
+  account_delete_request_sent: 'Check je e-mail om het verwijderen te bevestigen. De link is 1 uur geldig.',
+  account_delete_request_failed: 'Verwijderlink kon niet worden verstuurd.',
+  account_delete_confirm_title: 'Account verwijderen',
+  account_delete_confirm_hint: 'Dit kan niet ongedaan worden gemaakt.',
+  account_delete_confirm_button: 'Account definitief verwijderen',
+  account_delete_confirming: 'Bezig met verwijderen...',
+  account_delete_missing_token: 'Geen token gevonden.',
+  account_delete_failed: 'Account verwijderen is mislukt. De link is misschien verlopen of al gebruikt.',
+  account_delete_done_deleted: 'Je account, meldingen en foto\'s zijn verwijderd.',
+  account_delete_done_anonymized: 'Je account is verwijderd. Je meldingen zijn bewaard zonder gegevens die naar jou verwijzen.',
+  account_delete_done_held: 'Je account is verwijderd. Sommige meldingen moeten we wettelijk bewaren; die zijn geanonimiseerd.',
 
   admin_workspace_title: 'Beheeromgeving',
   admin_workspace_signed_in: 'Ingelogd als {{email}} ({{role}})',
@@ -383,6 +406,8 @@ const en: Record<keyof typeof nl, string> = {
   landing_showcase_fig3_caption: 'Station area — reported 5 days ago',
   landing_showcase_fig4_alt: 'Abandoned bike in the neighbourhood',
   landing_showcase_fig4_caption: 'Rivierenbuurt — reported yesterday',
+  landing_showcase_before: 'Before',
+  landing_showcase_after: 'After',
   landing_showcase_cta_title: 'Partner with your municipality',
   landing_showcase_cta_desc:
     'ZwerfFiets offers a free dashboard for municipal staff to manage and follow up on reports.',
@@ -488,7 +513,7 @@ const en: Record<keyof typeof nl, string> = {
   privacy_gdpr_label: 'GDPR',
   privacy_gdpr_title: 'We respect your rights',
   privacy_gdpr_text:
-    'As an EU resident, you have the right to access, correct, and delete your personal data. Since reports are anonymous by default, there are usually no personal data linked to your report. If you have provided an email address and wish to have it removed, please contact us.',
+    'As an EU resident, you have the right to access, correct, and delete your personal data. Since reports are anonymous by default, there are usually no personal data linked to your report. If you have an account, you can download your data or delete your account under My reports.',
   privacy_contact_label: 'CONTACT',
   privacy_contact_title: 'Questions about privacy?',
   privacy_contact_text:
@@ -526,6 +551,8 @@ const en: Record<keyof typeof nl, string> = {
     'Location accuracy is too low for this report. Try again outdoors with better GPS signal.',
   report_error_rate_limited:
     'Too many report attempts in a short time. Please wait and try again.',
+  report_error_out_of_service_area:
+    'This location is outside the Netherlands. ZwerfFiets only accepts reports within its service area.',
   report_error_submit_failed: 'Could not submit report. Check your input and try again.',
   report_error_offline:
     'You are offline or the server is unavailable. The report was queued and will retry when online.',
@@ -546,6 +573,25 @@ const en: Record<keyof typeof nl, string> = {
   my_reports_view_details: 'View details',
   my_reports_check_status: 'Check status',
   my_reports_logout: 'Logout',
+  account_data_title: 'My data',
+  account_data_export_hint: 'Download a file with your account, your reports, their history and your photos.',
+  account_data_export_button: 'Download my data',
+  account_delete_title: 'Delete account',
+  account_delete_hint: 'Choose what happens to your reports. We will email you a link to confirm the deletion.',
+  account_delete_mode_anonymize: 'Keep reports without my details',
+  account_delete_mode_delete: 'Delete reports and photos too',
+  account_delete_request_button: 'Send deletion link',
+  account_delete_request_sent: 'Check your email to confirm the deletion. The link is valid for 1 hour.',
+  account_delete_request_failed: 'Could not send the deletion link.',
+  account_delete_confirm_title: 'Delete account',
+  account_delete_confirm_hint: 'This cannot be undone.',
+  account_delete_confirm_button: 'Delete my account',
+  account_delete_confirming: 'Deleting...',
+  account_delete_missing_token: 'No token found.',
+  account_delete_failed: 'Could not delete the account. The link may have expired or been used already.',
+  account_delete_done_deleted: 'Your account, reports and photos have been deleted.',
+  account_delete_done_anonymized: 'Your account has been deleted. Your reports are kept without details that point to you.',
+  account_delete_done_held: 'Your account has been deleted. Some reports must be kept by law; those have been anonymized.',
 
   admin_workspace_title: 'Operator workspace',
   admin_workspace_signed_in: 'Signed in as {{email}} ({{role}})',
@@ -79,12 +79,17 @@ export interface OperatorReportView extends Report {
 export interface OperatorReportPhotoView {
   id: number;
   url: string;
+  thumb_url: string;
+  medium_url: string;
   mime_type: string;
   filename: string;
   size_bytes: number;
   created_at: string;
+  kind: ReportPhotoKind;
 }
 
+export type ReportPhotoKind = 'citizen' | 'field_check' | 'after';
+
 export interface SignalTimelineEntry {
   reportId: number;
   publicId: string;